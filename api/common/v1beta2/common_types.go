@@ -5,6 +5,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // KubernetesConfig will be the JSON struct for Basic Redis Config
@@ -20,6 +21,28 @@ type KubernetesConfig struct {
 	Service                              *ServiceConfig                                          `json:"service,omitempty"`
 	IgnoreAnnotations                    []string                                                `json:"ignoreAnnotations,omitempty"`
 	MinReadySeconds                      *int32                                                  `json:"minReadySeconds,omitempty"`
+	// Engine is the server run by the image, redis or valkey. It selects the binaries run in the pods,
+	// the name of the generated config file and the features the operator enables.
+	// +kubebuilder:default:=redis
+	// +optional
+	Engine Engine `json:"engine,omitempty"`
+}
+
+// Engine is the server run by the pods of a resource.
+// +kubebuilder:validation:Enum=redis;valkey
+type Engine string
+
+const (
+	EngineRedis  Engine = "redis"
+	EngineValkey Engine = "valkey"
+)
+
+// GetEngine returns the server run by the image, redis unless set.
+func (in *KubernetesConfig) GetEngine() Engine {
+	if in.Engine == "" {
+		return EngineRedis
+	}
+	return in.Engine
 }
 
 func (in *KubernetesConfig) GetServiceType() string {
@@ -146,6 +169,69 @@ type RedisExporter struct {
 	ImagePullPolicy corev1.PullPolicy            `json:"imagePullPolicy,omitempty"`
 	EnvVars         *[]corev1.EnvVar             `json:"env,omitempty"`
 	SecurityContext *corev1.SecurityContext      `json:"securityContext,omitempty"`
+	// ServiceMonitor has the operator create a Prometheus Operator ServiceMonitor scraping the exporter
+	// through the metrics Service, when the ServiceMonitor CRD is installed.
+	// +optional
+	ServiceMonitor *ServiceMonitorConfig `json:"serviceMonitor,omitempty"`
+	// PrometheusRule has the operator create a Prometheus Operator PrometheusRule with the default alerts
+	// of the setup type, when the PrometheusRule CRD is installed.
+	// +optional
+	PrometheusRule *PrometheusRuleConfig `json:"prometheusRule,omitempty"`
+}
+
+// ServiceMonitorConfig configures the ServiceMonitor scraping the redis exporter.
+// +k8s:deepcopy-gen=true
+type ServiceMonitorConfig struct {
+	// Interval at which the exporter is scraped, the interval of Prometheus when unset.
+	// +kubebuilder:validation:Pattern:="^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$"
+	// +optional
+	Interval string `json:"interval,omitempty"`
+	// ScrapeTimeout of a scrape, the timeout of Prometheus when unset. Not longer than the interval.
+	// +kubebuilder:validation:Pattern:="^(0|(([0-9]+)y)?(([0-9]+)w)?(([0-9]+)d)?(([0-9]+)h)?(([0-9]+)m)?(([0-9]+)s)?(([0-9]+)ms)?)$"
+	// +optional
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+	// Labels are added to the ServiceMonitor, e.g. the ones the serviceMonitorSelector of Prometheus matches.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Relabelings are applied to the targets before they are scraped.
+	// +optional
+	Relabelings []RelabelConfig `json:"relabelings,omitempty"`
+}
+
+// RelabelConfig is a Prometheus relabeling step, see
+// https://prometheus.io/docs/prometheus/latest/configuration/configuration/#relabel_config
+// +k8s:deepcopy-gen=true
+type RelabelConfig struct {
+	// SourceLabels whose values are concatenated with the separator and matched against the regex.
+	// +optional
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+	// +optional
+	Separator *string `json:"separator,omitempty"`
+	// TargetLabel written by the replace, hashmod, lowercase and uppercase actions.
+	// +optional
+	TargetLabel string `json:"targetLabel,omitempty"`
+	// +optional
+	Regex string `json:"regex,omitempty"`
+	// Modulus of the hash of the source label values, for the hashmod action.
+	// +optional
+	Modulus uint64 `json:"modulus,omitempty"`
+	// +optional
+	Replacement *string `json:"replacement,omitempty"`
+	// +kubebuilder:validation:Enum=replace;Replace;keep;Keep;drop;Drop;hashmod;HashMod;labelmap;LabelMap;labeldrop;LabelDrop;labelkeep;LabelKeep;lowercase;Lowercase;uppercase;Uppercase;keepequal;KeepEqual;dropequal;DropEqual
+	// +kubebuilder:default:=replace
+	// +optional
+	Action string `json:"action,omitempty"`
+}
+
+// PrometheusRuleConfig configures the PrometheusRule with the default alerts of the setup type.
+// +k8s:deepcopy-gen=true
+type PrometheusRuleConfig struct {
+	// Labels are added to the PrometheusRule, e.g. the ones the ruleSelector of Prometheus matches.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// AdditionalLabels are added to every alert, e.g. the ones Alertmanager routes them with.
+	// +optional
+	AdditionalLabels map[string]string `json:"additionalLabels,omitempty"`
 }
 
 // RedisConfig defines the external configuration of Redis
@@ -183,6 +269,24 @@ type TLSConfig struct {
 	KeyFile     string `json:"key,omitempty"`
 	// Reference to secret which contains the certificates
 	Secret corev1.SecretVolumeSource `json:"secret"`
+	// IssuerRef has the operator request the certificates from cert-manager with a Certificate that
+	// writes them to the secret, listing the DNS names of the services and of every pod as SANs.
+	// cert-manager writes the ca.crt, tls.crt and tls.key keys.
+	// +optional
+	IssuerRef *CertIssuerRef `json:"issuerRef,omitempty"`
+}
+
+// CertIssuerRef references the cert-manager issuer signing the certificates of the pods
+// +k8s:deepcopy-gen=true
+type CertIssuerRef struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Kind is Issuer for an issuer in the namespace of the resource, or ClusterIssuer.
+	// +kubebuilder:default=Issuer
+	Kind string `json:"kind,omitempty"`
+	// Group is the API group of the issuer, only set for external issuers.
+	// +kubebuilder:default="cert-manager.io"
+	Group string `json:"group,omitempty"`
 }
 
 // Sidecar for each Redis pods
@@ -278,6 +382,30 @@ type InitContainer struct {
 	SecurityContext *corev1.SecurityContext      `json:"securityContext,omitempty"`
 }
 
+// RestoreFrom seeds the data volumes from a completed RedisBackup in the same namespace, or from an
+// AOF archive, before redis-server starts for the first time. Volumes that already hold data are left untouched.
+// The backup must exist for as long as restoreFrom is set.
+// +k8s:deepcopy-gen=true
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="restoreFrom is immutable"
+// +kubebuilder:validation:XValidation:rule="has(self.backupName) != has(self.pointInTime)",message="exactly one of backupName or pointInTime must be set"
+type RestoreFrom struct {
+	// +kubebuilder:validation:MinLength=1
+	BackupName string `json:"backupName,omitempty"`
+	// PointInTime replays the AOF archive configured in aofArchive up to a timestamp.
+	PointInTime *PointInTimeRestore `json:"pointInTime,omitempty"`
+}
+
+// PointInTimeRestore selects the state of an archived pod at a given time
+// +k8s:deepcopy-gen=true
+type PointInTimeRestore struct {
+	// SourcePod is the pod whose archive is replayed, e.g. redis-standalone-0.
+	// The archive is looked up in the bucket of aofArchive, under the namespace of this resource.
+	// +kubebuilder:validation:MinLength=1
+	SourcePod string `json:"sourcePod"`
+	// TargetTime is the last second whose writes are replayed, with the granularity of the AOF timestamp annotations.
+	TargetTime metav1.Time `json:"targetTime"`
+}
+
 // +k8s:deepcopy-gen=true
 type ACLConfig struct {
 	// Secret-based ACL configuration.
@@ -305,3 +433,95 @@ func (a *ACLConfig) Validate() error {
 
 	return nil
 }
+
+// UpgradeStrategyType selects who rolls the pods of a resource onto a new revision of their StatefulSet.
+// +kubebuilder:validation:Enum=RollingUpdate;Managed
+type UpgradeStrategyType string
+
+const (
+	// UpgradeStrategyRollingUpdate leaves the pods to the StatefulSet controller, following
+	// kubernetesConfig.updateStrategy.
+	UpgradeStrategyRollingUpdate UpgradeStrategyType = "RollingUpdate"
+	// UpgradeStrategyManaged sets the StatefulSets to OnDelete and lets the operator restart the pods:
+	// the replicas first, then the master once it failed over to an upgraded replica.
+	UpgradeStrategyManaged UpgradeStrategyType = "Managed"
+)
+
+// Condition types shared by Redis, RedisReplication, RedisSentinel and RedisCluster.
+// Ready, Progressing and Degraded are mutually consistent: a resource is never Ready and Degraded at once,
+// and Progressing is false once the spec at observedGeneration is fully rolled out.
+const (
+	// ConditionReady is true when the resource serves clients as specified.
+	ConditionReady = "Ready"
+	// ConditionProgressing is true while the operator converges on the spec: pods are created or rolled out,
+	// or the topology is bootstrapped.
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded is true when the resource failed to reconcile or is unhealthy, and needs attention.
+	ConditionDegraded = "Degraded"
+	// ConditionScalingInProgress is true while the number of pods of a serving resource is being changed.
+	// The initial creation of the pods is reported by Progressing only.
+	ConditionScalingInProgress = "ScalingInProgress"
+	// ConditionConfigDrift is true when the running configuration differs from the spec,
+	// e.g. redisConfig.dynamicConfig could not be applied.
+	ConditionConfigDrift = "ConfigDrift"
+)
+
+// ConditionedStatus is embedded in the status of every Redis resource
+// +k8s:deepcopy-gen=true
+type ConditionedStatus struct {
+	// ObservedGeneration is the generation of the spec the status was computed for.
+	// GitOps tools treat the resource as progressing while it is behind metadata.generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are the Ready, Progressing, Degraded, ScalingInProgress and ConfigDrift conditions,
+	// plus the kind specific ones.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// VersionStatus is embedded in the status of resources whose pods the operator moves between versions of
+// Redis. Versions are formatted as `<engine>-<major>.<minor>.<patch>`, e.g. `redis-7.2.4`, and are left
+// empty while the image tag doesn't pin one.
+// +k8s:deepcopy-gen=true
+type VersionStatus struct {
+	// CurrentVersion is the version every pod ran when the last version change completed.
+	// +optional
+	CurrentVersion string `json:"currentVersion,omitempty"`
+	// TargetVersion is the version of the image in the spec. The pods are moving to it while it differs
+	// from CurrentVersion.
+	// +optional
+	TargetVersion string `json:"targetVersion,omitempty"`
+}
+
+// PasswordRotationPhase is a phase of the rotation of the password of the default user of the Redis
+// servers to the one of the redisSecret.
+// +kubebuilder:validation:Enum=AddPassword;UpdateMasterAuth;RemovePassword;Completed
+type PasswordRotationPhase string
+
+const (
+	// PasswordRotationAddPassword adds the new password to the default user, next to the old one.
+	PasswordRotationAddPassword PasswordRotationPhase = "AddPassword"
+	// PasswordRotationUpdateMasterAuth sets masterauth on every server, and the auth-pass of the master
+	// on every sentinel, to the new password.
+	PasswordRotationUpdateMasterAuth PasswordRotationPhase = "UpdateMasterAuth"
+	// PasswordRotationRemovePassword removes the old password from the default user.
+	PasswordRotationRemovePassword PasswordRotationPhase = "RemovePassword"
+	// PasswordRotationCompleted means the servers only accept the new password.
+	PasswordRotationCompleted PasswordRotationPhase = "Completed"
+)
+
+// PasswordRotationStatus reports the last rotation of the password of the redisSecret, which the operator
+// applies to the running servers without restarting them.
+// +k8s:deepcopy-gen=true
+type PasswordRotationStatus struct {
+	// Phase is the phase the rotation runs next, Completed once it is done.
+	Phase PasswordRotationPhase `json:"phase"`
+	// SecretResourceVersion is the resource version of the redisSecret the rotation started from.
+	// +optional
+	SecretResourceVersion string `json:"secretResourceVersion,omitempty"`
+	// LastTransitionTime is when the rotation entered its phase.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
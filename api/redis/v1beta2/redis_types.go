@@ -17,7 +17,10 @@ limitations under the License.
 package v1beta2
 
 import (
+	"fmt"
+
 	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -26,6 +29,7 @@ import (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // RedisSpec defines the desired state of Redis
+// +kubebuilder:validation:XValidation:rule="!has(self.restoreFrom) || !has(self.restoreFrom.pointInTime) || has(self.aofArchive)",message="restoreFrom.pointInTime requires aofArchive"
 type RedisSpec struct {
 	KubernetesConfig              common.KubernetesConfig    `json:"kubernetesConfig"`
 	RedisExporter                 *common.RedisExporter      `json:"redisExporter,omitempty"`
@@ -47,6 +51,9 @@ type RedisSpec struct {
 	TerminationGracePeriodSeconds *int64                     `json:"terminationGracePeriodSeconds,omitempty" protobuf:"varint,4,opt,name=terminationGracePeriodSeconds"`
 	EnvVars                       *[]corev1.EnvVar           `json:"env,omitempty"`
 	HostPort                      *int                       `json:"hostPort,omitempty"`
+	RestoreFrom                   *common.RestoreFrom        `json:"restoreFrom,omitempty"`
+	// AOFArchive runs a sidecar shipping the AOF files of every pod to object storage for point-in-time restores.
+	AOFArchive *rbvb2.AOFArchive `json:"aofArchive,omitempty"`
 }
 
 func (cr *RedisSpec) GetRedisDynamicConfig() []string {
@@ -56,12 +63,62 @@ func (cr *RedisSpec) GetRedisDynamicConfig() []string {
 	return []string{}
 }
 
+// RedisPhase is the lifecycle phase of a standalone Redis
+type RedisPhase string
+
+const (
+	// RedisInitializing means the pod is not serving yet.
+	RedisInitializing RedisPhase = "Initializing"
+	// RedisReady means the pod answers PING and its configuration is applied.
+	RedisReady RedisPhase = "Ready"
+	// RedisFailed means the operator could not create or configure the instance.
+	RedisFailed RedisPhase = "Failed"
+)
+
+// Condition types reported in RedisStatus besides the shared ones
+const (
+	// RedisConditionReady is true when the pod is ready and answers PING.
+	RedisConditionReady = common.ConditionReady
+	// RedisConditionConfigApplied is true once redisConfig.dynamicConfig is applied with CONFIG SET.
+	RedisConditionConfigApplied = "ConfigApplied"
+	// RedisConditionPersistenceHealthy is false when the last RDB save or AOF write failed.
+	RedisConditionPersistenceHealthy = "PersistenceHealthy"
+)
+
+// ConnectionInfo provides connection details for clients to connect to Redis
+type ConnectionInfo struct {
+	// Host is the service FQDN
+	Host string `json:"host,omitempty"`
+	// Port is the service port
+	Port int `json:"port,omitempty"`
+}
+
 // RedisStatus defines the observed state of Redis
-type RedisStatus struct{}
+type RedisStatus struct {
+	// Phase summarizes the conditions.
+	Phase                    RedisPhase `json:"phase,omitempty"`
+	common.ConditionedStatus `json:",inline"`
+	common.VersionStatus     `json:",inline"`
+	// RedisVersion is the redis_version reported by the running server.
+	RedisVersion string `json:"redisVersion,omitempty"`
+	// UsedMemory is the used_memory reported by the running server, in bytes.
+	UsedMemory int64 `json:"usedMemory,omitempty"`
+	// ConnectionInfo provides connection details for clients to connect to Redis
+	// +optional
+	ConnectionInfo *ConnectionInfo `json:"connectionInfo,omitempty"`
+	// PasswordRotation reports the last rotation of the password of the redisSecret, if any.
+	// +optional
+	PasswordRotation *common.PasswordRotationStatus `json:"passwordRotation,omitempty"`
+}
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The current phase of the Redis"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether Redis is ready"
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.redisVersion",description="The running Redis version"
+// +kubebuilder:printcolumn:name="Memory",type="integer",JSONPath=".status.usedMemory",description="Memory used by Redis in bytes",priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age of Redis"
 
 // Redis is the Schema for the redis API
 type Redis struct {
@@ -72,6 +129,15 @@ type Redis struct {
 	Status RedisStatus `json:"status,omitempty"`
 }
 
+// GetConnectionInfo returns the address of the service clients connect to.
+// The dnsDomain parameter should be the cluster DNS domain (e.g., "cluster.local").
+func (cr *Redis) GetConnectionInfo(dnsDomain string) *ConnectionInfo {
+	return &ConnectionInfo{
+		Host: fmt.Sprintf("%s.%s.svc.%s", cr.Name, cr.Namespace, dnsDomain),
+		Port: 6379,
+	}
+}
+
 // +kubebuilder:object:root=true
 
 // RedisList contains a list of Redis
@@ -0,0 +1,203 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"path"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Kinds of Redis resources a RedisBackup can target.
+const (
+	TargetKindRedis            = "Redis"
+	TargetKindRedisReplication = "RedisReplication"
+	TargetKindRedisCluster     = "RedisCluster"
+)
+
+// Keys looked up in the S3 credentials secret.
+const (
+	S3AccessKeyIDKey     = "AWS_ACCESS_KEY_ID"
+	S3SecretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+)
+
+// RedisBackupSpec defines the desired state of RedisBackup
+type RedisBackupSpec struct {
+	// Target is the Redis, RedisReplication or RedisCluster in the same namespace to back up.
+	Target BackupTarget `json:"target"`
+	// Storage is the object storage the RDB snapshots are uploaded to.
+	Storage BackupStorage `json:"storage"`
+}
+
+// BackupTarget references the Redis resource to back up
+type BackupTarget struct {
+	// +kubebuilder:validation:Enum=Redis;RedisReplication;RedisCluster
+	Kind string `json:"kind"`
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// BackupStorage describes where backup artifacts are stored
+// +kubebuilder:validation:XValidation:rule="has(self.s3) != has(self.volumeSnapshot)",message="exactly one of s3 or volumeSnapshot must be set"
+type BackupStorage struct {
+	// S3 uploads the RDB file of every master to an S3-compatible bucket.
+	S3 *S3Storage `json:"s3,omitempty"`
+	// VolumeSnapshot takes CSI VolumeSnapshots of the data volumes instead, which is faster for large datasets.
+	VolumeSnapshot *VolumeSnapshotStorage `json:"volumeSnapshot,omitempty"`
+}
+
+// VolumeSnapshotStorage stores backups as snapshot.storage.k8s.io/v1 VolumeSnapshots of the data PVCs
+type VolumeSnapshotStorage struct {
+	// VolumeSnapshotClassName is the class of the snapshots, the default class of the CSI driver is used if unset.
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// S3Storage is an S3-compatible bucket such as AWS S3 or MinIO
+type S3Storage struct {
+	// Endpoint is the host[:port] of the S3 API, e.g. s3.amazonaws.com or minio.minio.svc:9000.
+	// +kubebuilder:validation:MinLength=1
+	Endpoint string `json:"endpoint"`
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+	// Prefix is prepended to every object key written for this backup.
+	Prefix string `json:"prefix,omitempty"`
+	Region string `json:"region,omitempty"`
+	// Insecure talks plain HTTP to the endpoint instead of HTTPS.
+	// +kubebuilder:default=false
+	Insecure bool `json:"insecure,omitempty"`
+	// CredentialsSecret is the name of a secret holding the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY keys.
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// ObjectKey returns the key an artifact of the given backup is stored under.
+func (s *S3Storage) ObjectKey(namespace, backupName, file string) string {
+	return path.Join(s.Prefix, namespace, backupName, file)
+}
+
+// ArchivePrefix returns the key prefix the AOF archive of the given pod is stored under.
+func (s *S3Storage) ArchivePrefix(namespace, podName string) string {
+	return path.Join(s.Prefix, namespace, podName, "aof")
+}
+
+// AOFArchive continuously ships the multi-part AOF files of every pod to object storage,
+// so the data can be restored to any point in time covered by the archive. It requires
+// Redis 7 or later with persistence enabled.
+type AOFArchive struct {
+	S3 S3Storage `json:"s3"`
+	// Interval between two uploads of new AOF data. It bounds the writes lost by a restore.
+	// +kubebuilder:default="1m"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+type RedisBackupPhase string
+
+// Status Field of the Redis Backup
+const (
+	RedisBackupRunning   RedisBackupPhase = "Running"
+	RedisBackupCompleted RedisBackupPhase = "Completed"
+	RedisBackupFailed    RedisBackupPhase = "Failed"
+)
+
+// RedisBackupStatus defines the observed state of RedisBackup
+type RedisBackupStatus struct {
+	Phase RedisBackupPhase `json:"phase,omitempty"`
+	// Reason explains why the backup failed.
+	Reason         string       `json:"reason,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Shards holds one entry per master of the target, recorded when the backup starts. A shard is
+	// complete once its ObjectKey or VolumeSnapshot is set.
+	Shards []BackupShard `json:"shards,omitempty"`
+	// VolumeSnapshots are the VolumeSnapshots taken of every data PVC, masters and replicas alike.
+	VolumeSnapshots []string `json:"volumeSnapshots,omitempty"`
+	// VolumeSnapshotsTaken is set once the VolumeSnapshots of all the pods were created, the backup then
+	// waits for them to become ready.
+	VolumeSnapshotsTaken bool `json:"volumeSnapshotsTaken,omitempty"`
+	// Save is the background save the backup waits for before it uploads the dump file or snapshots
+	// the volume of the pod. The pods are saved one after the other.
+	Save *BackgroundSave `json:"save,omitempty"`
+}
+
+// BackgroundSave tracks the BGSAVE of a pod across reconciles
+type BackgroundSave struct {
+	// Pod is the pod saving its dataset.
+	Pod string `json:"pod"`
+	// StartTime is when the backup first asked the pod to save. The backup fails if the save did not
+	// finish in time.
+	StartTime metav1.Time `json:"startTime"`
+	// Started is set once the pod forked the save. BGSAVE is retried until then while another child
+	// process, a BGSAVE or an AOF rewrite, runs.
+	Started bool `json:"started,omitempty"`
+	// MasterReplOffset is the master_repl_offset reported when BGSAVE was issued.
+	MasterReplOffset int64 `json:"masterReplOffset,omitempty"`
+	// Slots are the slot ranges the pod served, only recorded for RedisCluster targets.
+	Slots []string `json:"slots,omitempty"`
+}
+
+// BackupShard records the RDB snapshot of a single master
+type BackupShard struct {
+	// Pod is the master the snapshot was taken from.
+	Pod string `json:"pod"`
+	// ObjectKey is the key of the uploaded dump.rdb in the bucket.
+	ObjectKey string `json:"objectKey,omitempty"`
+	// VolumeSnapshot is the VolumeSnapshot of the data PVC of the master, set instead of ObjectKey.
+	VolumeSnapshot string `json:"volumeSnapshot,omitempty"`
+	// Size is the size of the uploaded dump.rdb in bytes.
+	Size int64 `json:"size,omitempty"`
+	// MasterReplOffset is the master_repl_offset reported when BGSAVE was issued.
+	MasterReplOffset int64 `json:"masterReplOffset"`
+	// Slots are the slot ranges the master served, only recorded for RedisCluster targets.
+	Slots []string `json:"slots,omitempty"`
+}
+
+// IsFinished reports whether the backup reached a terminal phase.
+func (s *RedisBackupStatus) IsFinished() bool {
+	return s.Phase == RedisBackupCompleted || s.Phase == RedisBackupFailed
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.target.name",description="The backed up resource"
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=".spec.target.kind",description="Kind of the backed up resource"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The current phase of the backup"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age of Backup"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.reason",description="The reason the backup failed",priority=1
+
+// RedisBackup is the Schema for the redisbackups API
+type RedisBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisBackupSpec   `json:"spec"`
+	Status RedisBackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RedisBackupList contains a list of RedisBackup
+type RedisBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisBackup `json:"items"`
+}
+
+//nolint:gochecknoinits
+func init() {
+	SchemeBuilder.Register(&RedisBackup{}, &RedisBackupList{})
+}
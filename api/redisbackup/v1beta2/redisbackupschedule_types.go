@@ -0,0 +1,128 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScheduleLabelKey is set on every RedisBackup created by a RedisBackupSchedule.
+const ScheduleLabelKey = "redis.opstreelabs.in/backup-schedule"
+
+// ConcurrencyPolicy describes how a scheduled backup is handled while a previous one is still running.
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent starts the new backup alongside the running ones.
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+	// ForbidConcurrent skips the new backup while another one is running.
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+	// ReplaceConcurrent deletes the running backups and starts the new one.
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// RedisBackupScheduleSpec defines the desired state of RedisBackupSchedule
+type RedisBackupScheduleSpec struct {
+	// Schedule in standard cron format, e.g. "0 2 * * *". Times are evaluated in UTC.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+	// +kubebuilder:default=Forbid
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// StartingDeadlineSeconds skips a run that could not be started within this many seconds
+	// of its scheduled time, e.g. while the operator was down or the schedule was suspended.
+	// +kubebuilder:validation:Minimum=0
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// Suspend stops new backups from being scheduled, retention is still enforced.
+	// +kubebuilder:default=false
+	Suspend bool `json:"suspend,omitempty"`
+	// BackupTemplate is the spec of the RedisBackup created on every run.
+	BackupTemplate RedisBackupSpec `json:"backupTemplate"`
+	// Retention decides which completed backups are kept, all of them are kept if unset.
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+}
+
+// RetentionPolicy keeps the union of the backups selected by each rule, the rest are
+// deleted together with their artifacts. The daily, weekly and monthly rules keep the
+// newest completed backup of each of the last N days, ISO weeks and months that have one.
+// +kubebuilder:validation:MinProperties=1
+type RetentionPolicy struct {
+	// +kubebuilder:validation:Minimum=1
+	KeepLast *int32 `json:"keepLast,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	KeepDaily *int32 `json:"keepDaily,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	KeepWeekly *int32 `json:"keepWeekly,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	KeepMonthly *int32 `json:"keepMonthly,omitempty"`
+}
+
+// HasRule reports whether the policy has a rule keeping at least one backup. A policy without one
+// would delete every completed backup, so it keeps all of them like an unset policy.
+func (p *RetentionPolicy) HasRule() bool {
+	if p == nil {
+		return false
+	}
+	for _, n := range []*int32{p.KeepLast, p.KeepDaily, p.KeepWeekly, p.KeepMonthly} {
+		if n != nil && *n > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// RedisBackupScheduleStatus defines the observed state of RedisBackupSchedule
+type RedisBackupScheduleStatus struct {
+	// LastScheduleTime is the last time a backup was due.
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// LastSuccessfulTime is the completion time of the newest completed backup.
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	// Active lists the backups that are still running.
+	Active []string `json:"active,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule",description="The cron schedule"
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend",description="Whether scheduling is suspended"
+// +kubebuilder:printcolumn:name="LastSchedule",type="date",JSONPath=".status.lastScheduleTime",description="Last time a backup was scheduled"
+// +kubebuilder:printcolumn:name="LastSuccessful",type="date",JSONPath=".status.lastSuccessfulTime",description="Last time a backup completed"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age of Schedule"
+
+// RedisBackupSchedule is the Schema for the redisbackupschedules API
+type RedisBackupSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisBackupScheduleSpec   `json:"spec"`
+	Status RedisBackupScheduleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RedisBackupScheduleList contains a list of RedisBackupSchedule
+type RedisBackupScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisBackupSchedule `json:"items"`
+}
+
+//nolint:gochecknoinits
+func init() {
+	SchemeBuilder.Register(&RedisBackupSchedule{}, &RedisBackupScheduleList{})
+}
@@ -17,6 +17,10 @@ limitations under the License.
 package v1beta2
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -62,8 +66,74 @@ type RedisClusterSpec struct {
 	// +optional
 	// +kubebuilder:validation:Enum=OrderedReady;Parallel
 	PodManagementPolicy *string `json:"podManagementPolicy,omitempty"`
+	// RestoreFrom seeds every leader with the RDB of one shard of the backup and
+	// assigns it the slots that shard owned, instead of creating an empty cluster.
+	// The cluster needs at least as many leaders as the backup has shards: the extra
+	// leaders start empty, and leader 0 serves the slots no shard owned.
+	RestoreFrom *common.RestoreFrom `json:"restoreFrom,omitempty"`
+	// ReplicasPerShard is the number of replicas of each leader. When set, the number of followers is
+	// the number of leaders times ReplicasPerShard, and redisFollower.replicas must be unset. The
+	// replicas of a leader are placed on other nodes and zones than the leader when possible.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ReplicasPerShard *int32 `json:"replicasPerShard,omitempty"`
+	// SlotRanges pins the hash slots to the leaders, keyed by the ordinal of the leader pod,
+	// e.g. `"0": ["0-8191", "12000"]`. The ranges must cover each of the 16384 slots exactly once,
+	// and the operator moves the slots that are served by another leader. An ordinal stands for
+	// the shard of the leader pod: once the pod was failed over, the slots go to the master it
+	// replicates. Mutually exclusive with redisLeader.slotWeights.
+	// +optional
+	SlotRanges map[string][]string `json:"slotRanges,omitempty"`
+	// UpgradeStrategy selects how the pods are rolled onto a new revision. `Managed` upgrades one shard
+	// at a time: the operator restarts the replicas of the shard, fails the master over to an upgraded
+	// replica in sync, and restarts the old master last. kubernetesConfig.updateStrategy is ignored then.
+	// +kubebuilder:default:=RollingUpdate
+	// +optional
+	UpgradeStrategy common.UpgradeStrategyType `json:"upgradeStrategy,omitempty"`
+	// ExternalAccess exposes every pod to clients outside of the Kubernetes cluster through a Service of
+	// its own, and has the node announce the address of that Service so the clients follow the MOVED and
+	// ASK redirections of the cluster. Mutually exclusive with a NodePort kubernetesConfig.service.
+	// +optional
+	ExternalAccess *ExternalAccess `json:"externalAccess,omitempty"`
+}
+
+// ManagedUpgrade returns whether the operator rolls the pods onto a new revision of their StatefulSets.
+func (cr *RedisClusterSpec) ManagedUpgrade() bool {
+	return cr.UpgradeStrategy == common.UpgradeStrategyManaged
+}
+
+// LoadBalancerAccess returns whether every pod is exposed through a LoadBalancer Service of its own.
+func (cr *RedisClusterSpec) LoadBalancerAccess() bool {
+	return cr.ExternalAccess != nil && cr.ExternalAccess.Type == ExternalAccessLoadBalancer
+}
+
+// ExternalAccessType is the type of the Services exposing the pods outside of the Kubernetes cluster.
+// +kubebuilder:validation:Enum=LoadBalancer
+type ExternalAccessType string
+
+// ExternalAccessLoadBalancer exposes every pod through a LoadBalancer Service. The node announces the
+// IP of the load balancer, or its hostname with Redis 7 and later when the load balancer only has one.
+const ExternalAccessLoadBalancer ExternalAccessType = "LoadBalancer"
+
+// ExternalAccess configures the Services exposing every pod outside of the Kubernetes cluster
+type ExternalAccess struct {
+	// +kubebuilder:default:=LoadBalancer
+	Type ExternalAccessType `json:"type,omitempty"`
+	// Annotations of the Service of every pod, such as the annotations of the cloud provider selecting
+	// an internal load balancer.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// LoadBalancerClass of the Service of every pod.
+	// +optional
+	LoadBalancerClass *string `json:"loadBalancerClass,omitempty"`
+	// LoadBalancerSourceRanges restricts the clients of the load balancers to these CIDRs.
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
 }
 
+// ClusterSlots is the number of hash slots of a Redis cluster.
+const ClusterSlots = 16384
+
 // Node-conf needs to be added only in redis cluster
 type ClusterStorage struct {
 	// +kubebuilder:default=false
@@ -76,12 +146,27 @@ func (cr *RedisClusterSpec) GetReplicaCounts(t string) int32 {
 	replica := cr.ClusterSize
 	if t == "leader" && cr.RedisLeader.Replicas != nil {
 		replica = cr.RedisLeader.Replicas
+	} else if t == "follower" && cr.ReplicasPerShard != nil {
+		return cr.GetReplicaCounts("leader") * *cr.ReplicasPerShard
 	} else if t == "follower" && cr.RedisFollower.Replicas != nil {
 		replica = cr.RedisFollower.Replicas
 	}
 	return *replica
 }
 
+// GetReplicasPerShard returns the number of replicas of each leader: ReplicasPerShard when set, and
+// otherwise the followers spread over the leaders, rounded up.
+func (cr *RedisClusterSpec) GetReplicasPerShard() int32 {
+	if cr.ReplicasPerShard != nil {
+		return *cr.ReplicasPerShard
+	}
+	leaders := cr.GetReplicaCounts("leader")
+	if leaders == 0 {
+		return 0
+	}
+	return (cr.GetReplicaCounts("follower") + leaders - 1) / leaders
+}
+
 // GetRedisLeaderResources returns the resources for the redis leader, if not set, it will return the default resources
 func (cr *RedisClusterSpec) GetRedisLeaderResources() *corev1.ResourceRequirements {
 	if cr.RedisLeader.Resources != nil {
@@ -111,12 +196,111 @@ func (cr *RedisClusterSpec) GetRedisFollowerResources() *corev1.ResourceRequirem
 	return cr.KubernetesConfig.Resources
 }
 
+// GetSlotWeights returns the weights of RedisLeader.SlotWeights by leader ordinal, leaving out the
+// ordinals past the number of leaders.
+func (cr *RedisClusterSpec) GetSlotWeights() (map[int32]int32, error) {
+	leaders := cr.GetReplicaCounts("leader")
+	weights := make(map[int32]int32, len(cr.RedisLeader.SlotWeights))
+	total := int64(leaders)
+	for key, weight := range cr.RedisLeader.SlotWeights {
+		ordinal, err := parseOrdinal(key)
+		if err != nil {
+			return nil, err
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("weight %d of leader %s is negative", weight, key)
+		}
+		if ordinal >= leaders {
+			continue
+		}
+		weights[ordinal] = weight
+		total += int64(weight) - 1
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("every leader has weight 0")
+	}
+	return weights, nil
+}
+
+// GetSlotOwners returns the ordinal of the leader each slot is pinned to by SlotRanges, indexed by
+// slot, or nil when SlotRanges is unset.
+func (cr *RedisClusterSpec) GetSlotOwners() ([]int32, error) {
+	if len(cr.SlotRanges) == 0 {
+		return nil, nil
+	}
+	leaders := cr.GetReplicaCounts("leader")
+	owners := make([]int32, ClusterSlots)
+	for slot := range owners {
+		owners[slot] = -1
+	}
+	for key, ranges := range cr.SlotRanges {
+		ordinal, err := parseOrdinal(key)
+		if err != nil {
+			return nil, err
+		}
+		if ordinal >= leaders {
+			return nil, fmt.Errorf("leader %s does not exist, the cluster has %d leaders", key, leaders)
+		}
+		for _, r := range ranges {
+			start, end, err := ParseSlotRange(r)
+			if err != nil {
+				return nil, err
+			}
+			for slot := start; slot <= end; slot++ {
+				if owners[slot] != -1 && owners[slot] != ordinal {
+					return nil, fmt.Errorf("slot %d is assigned to both leader %d and leader %d", slot, owners[slot], ordinal)
+				}
+				owners[slot] = ordinal
+			}
+		}
+	}
+	for slot, owner := range owners {
+		if owner == -1 {
+			return nil, fmt.Errorf("slot %d is not assigned to any leader", slot)
+		}
+	}
+	return owners, nil
+}
+
+// ParseSlotRange parses a single slot such as `5461` or an inclusive range of slots such as `0-5460`.
+func ParseSlotRange(s string) (start, end int, err error) {
+	first, last, isRange := strings.Cut(strings.TrimSpace(s), "-")
+	if start, err = strconv.Atoi(first); err != nil {
+		return 0, 0, fmt.Errorf("invalid slot range %q", s)
+	}
+	end = start
+	if isRange {
+		if end, err = strconv.Atoi(last); err != nil {
+			return 0, 0, fmt.Errorf("invalid slot range %q", s)
+		}
+	}
+	if start < 0 || end >= ClusterSlots || start > end {
+		return 0, 0, fmt.Errorf("invalid slot range %q, slots go from 0 to %d", s, ClusterSlots-1)
+	}
+	return start, end, nil
+}
+
+func parseOrdinal(key string) (int32, error) {
+	ordinal, err := strconv.ParseInt(key, 10, 32)
+	if err != nil || ordinal < 0 {
+		return 0, fmt.Errorf("%q is not the ordinal of a leader", key)
+	}
+	return int32(ordinal), nil
+}
+
 // RedisLeader interface will have the redis leader configuration
 type RedisLeader struct {
 	common.RedisLeader            `json:",inline"`
 	SecurityContext               *corev1.SecurityContext      `json:"securityContext,omitempty"`
 	TerminationGracePeriodSeconds *int64                       `json:"terminationGracePeriodSeconds,omitempty" protobuf:"varint,4,opt,name=terminationGracePeriodSeconds"`
 	Resources                     *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// SlotWeights sets the share of the hash slots served by each leader relative to the others,
+	// keyed by the ordinal of the leader pod, e.g. `"2": 2` for a leader on a node twice as large.
+	// Leaders missing from the map have weight 1 and weight 0 moves every slot off a leader.
+	// As with slotRanges, a weight applies to the master of the shard of the leader pod.
+	// Mutually exclusive with slotRanges.
+	// +optional
+	SlotWeights map[string]int32 `json:"slotWeights,omitempty"`
 }
 
 // RedisFollower interface will have the redis follower configuration
@@ -130,12 +314,73 @@ type RedisFollower struct {
 // RedisClusterStatus defines the observed state of RedisCluster
 // +kubebuilder:subresource:status
 type RedisClusterStatus struct {
-	State  RedisClusterState `json:"state,omitempty"`
-	Reason string            `json:"reason,omitempty"`
+	common.ConditionedStatus `json:",inline"`
+	common.VersionStatus     `json:",inline"`
+	State                    RedisClusterState `json:"state,omitempty"`
+	Reason                   string            `json:"reason,omitempty"`
 	// +kubebuilder:default=0
 	ReadyLeaderReplicas int32 `json:"readyLeaderReplicas,omitempty"`
 	// +kubebuilder:default=0
 	ReadyFollowerReplicas int32 `json:"readyFollowerReplicas,omitempty"`
+	// Shards reports the slots served by each master of the cluster, in slot order.
+	// +optional
+	Shards []ShardStatus `json:"shards,omitempty"`
+	// ScaleDown is the checkpoint of the scale-down in progress, if any. The operator resumes the
+	// scale-down from it after a restart.
+	// +optional
+	ScaleDown *ScaleDownStatus `json:"scaleDown,omitempty"`
+	// PasswordRotation reports the last rotation of the password of the redisSecret, if any.
+	// +optional
+	PasswordRotation *common.PasswordRotationStatus `json:"passwordRotation,omitempty"`
+}
+
+// ScaleDownStep is a step of the scale-down of the leaders of a RedisCluster. The shards are removed
+// one at a time, starting from the last one.
+// +kubebuilder:validation:Enum=PromoteTargets;Failover;RemoveFollowers;Reshard;RemoveLeader;Rebalance
+type ScaleDownStep string
+
+const (
+	// ScaleDownPromoteTargets fails over the remaining leader pods that don't run a master.
+	ScaleDownPromoteTargets ScaleDownStep = "PromoteTargets"
+	// ScaleDownFailover fails over the leader pod of the shard being removed if it doesn't run the master.
+	ScaleDownFailover ScaleDownStep = "Failover"
+	// ScaleDownRemoveFollowers removes the replicas of the shard being removed from the cluster.
+	ScaleDownRemoveFollowers ScaleDownStep = "RemoveFollowers"
+	// ScaleDownReshard moves the slots of the shard being removed to the target shard, a batch at a time.
+	ScaleDownReshard ScaleDownStep = "Reshard"
+	// ScaleDownRemoveLeader removes the emptied master of the shard from the cluster.
+	ScaleDownRemoveLeader ScaleDownStep = "RemoveLeader"
+	// ScaleDownRebalance rebalances the slots between the remaining leaders.
+	ScaleDownRebalance ScaleDownStep = "Rebalance"
+)
+
+// ScaleDownStatus is the checkpoint of a scale-down of the leaders, recorded after every step.
+type ScaleDownStatus struct {
+	// Step is the step to run next.
+	Step ScaleDownStep `json:"step"`
+	// FromLeaders is the number of leaders the scale-down started from.
+	FromLeaders int32 `json:"fromLeaders"`
+	// ToLeaders is the number of leaders the scale-down ends with.
+	ToLeaders int32 `json:"toLeaders"`
+	// Shard is the ordinal of the leader being removed.
+	Shard int32 `json:"shard"`
+	// Target is the ordinal of the leader receiving the slots of Shard.
+	Target int32 `json:"target"`
+	// MigratedSlots lists the slots of Shard already moved to Target, e.g. `0-1023`.
+	// +optional
+	MigratedSlots string `json:"migratedSlots,omitempty"`
+}
+
+// ShardStatus reports the slots served by a master of the cluster.
+type ShardStatus struct {
+	// Pod is the name of the pod running the master, empty when it can't be resolved.
+	Pod string `json:"pod,omitempty"`
+	// NodeID is the cluster node ID of the master.
+	NodeID string `json:"nodeID"`
+	// Slots is the number of slots served by the master.
+	Slots int32 `json:"slots"`
+	// SlotRanges lists the slots served by the master, e.g. `0-5460,5462`.
+	SlotRanges string `json:"slotRanges,omitempty"`
 }
 
 type RedisClusterState string
@@ -151,17 +396,26 @@ const (
 const (
 	RedisClusterInitializing RedisClusterState = "Initializing"
 	RedisClusterBootstrap    RedisClusterState = "Bootstrap"
-	// RedisClusterReady means the RedisCluster is ready for use, we use redis-cli --cluster check 127.0.0.1:6379 to check the cluster status
+	// RedisClusterReady means the RedisCluster is ready for use, as checked by the equivalent of redis-cli --cluster check
 	RedisClusterReady  RedisClusterState = "Ready"
 	RedisClusterFailed RedisClusterState = "Failed"
 )
 
+// Condition types reported in RedisClusterStatus besides the shared ones
+const (
+	// RedisClusterConditionZoneRedundant is true when every shard runs its master and its replicas
+	// in at least two zones, read from the topology.kubernetes.io/zone label of their nodes.
+	RedisClusterConditionZoneRedundant = "ZoneRedundant"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="ClusterSize",type=integer,JSONPath=`.spec.clusterSize`,description=Current cluster node count
 // +kubebuilder:printcolumn:name="ReadyLeaderReplicas",type="integer",JSONPath=".status.readyLeaderReplicas",description="Number of ready leader replicas"
 // +kubebuilder:printcolumn:name="ReadyFollowerReplicas",type="integer",JSONPath=".status.readyFollowerReplicas",description="Number of ready follower replicas"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the Redis Cluster is ready"
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.currentVersion",description="The Redis version every pod runs",priority=1
 // +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state",description="The current state of the Redis Cluster",priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age of Cluster",priority=1
 // +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.reason",description="The reason for the current state",priority=1
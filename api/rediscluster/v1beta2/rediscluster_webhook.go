@@ -95,6 +95,42 @@ func (r *RedisCluster) validate(_ *RedisCluster) (admission.Warnings, error) {
 		}
 	}
 
+	if r.Spec.ReplicasPerShard != nil && r.Spec.RedisFollower.Replicas != nil {
+		errors = append(errors, field.Forbidden(
+			field.NewPath("spec").Child("replicasPerShard"),
+			"replicasPerShard and redisFollower.replicas are mutually exclusive",
+		))
+	}
+
+	if r.Spec.ExternalAccess != nil && r.Spec.KubernetesConfig.GetServiceType() == "NodePort" {
+		errors = append(errors, field.Forbidden(
+			field.NewPath("spec").Child("externalAccess"),
+			"externalAccess and a NodePort kubernetesConfig.service are mutually exclusive",
+		))
+	}
+
+	// Validate the slot distribution
+	if len(r.Spec.SlotRanges) > 0 && len(r.Spec.RedisLeader.SlotWeights) > 0 {
+		errors = append(errors, field.Forbidden(
+			field.NewPath("spec").Child("slotRanges"),
+			"slotRanges and redisLeader.slotWeights are mutually exclusive",
+		))
+	}
+	if _, err := r.Spec.GetSlotOwners(); err != nil {
+		errors = append(errors, field.Invalid(
+			field.NewPath("spec").Child("slotRanges"),
+			r.Spec.SlotRanges,
+			err.Error(),
+		))
+	}
+	if _, err := r.Spec.GetSlotWeights(); err != nil {
+		errors = append(errors, field.Invalid(
+			field.NewPath("spec").Child("redisLeader", "slotWeights"),
+			r.Spec.RedisLeader.SlotWeights,
+			err.Error(),
+		))
+	}
+
 	if len(errors) == 0 {
 		return nil, nil
 	}
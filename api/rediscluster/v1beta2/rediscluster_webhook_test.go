@@ -52,6 +52,121 @@ func TestRedisClusterWebhook(t *testing.T) {
 			},
 			Check: webhook.ValidationWebhookFailed("only one of 'secret' or 'persistentVolumeClaim' can be specified"),
 		},
+		{
+			Name:      "success-create-v1beta2-rediscluster-slot-ranges",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.SlotRanges = map[string][]string{
+					"0": {"0-8000"},
+					"1": {"8001-16382"},
+					"2": {"16383"},
+				}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookSucceeded,
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-slot-ranges-uncovered",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.SlotRanges = map[string][]string{"0": {"0-8191"}, "1": {"8192-16382"}}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("slot 16383 is not assigned to any leader"),
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-slot-ranges-unknown-leader",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.SlotRanges = map[string][]string{"0": {"0-8191"}, "3": {"8192-16383"}}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("leader 3 does not exist"),
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-slot-ranges-and-weights",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.SlotRanges = map[string][]string{"0": {"0-16383"}}
+				cluster.Spec.RedisLeader.SlotWeights = map[string]int32{"0": 2}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("mutually exclusive"),
+		},
+		{
+			Name:      "success-create-v1beta2-rediscluster-slot-weights",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.RedisLeader.SlotWeights = map[string]int32{"0": 2, "1": 0}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookSucceeded,
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-slot-weights-all-zero",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.RedisLeader.SlotWeights = map[string]int32{"0": 0, "1": 0, "2": 0}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("every leader has weight 0"),
+		},
+		{
+			Name:      "success-create-v1beta2-rediscluster-replicas-per-shard",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(6))
+				cluster.Spec.ReplicasPerShard = ptr.To(int32(2))
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookSucceeded,
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-replicas-per-shard-and-follower-replicas",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.ReplicasPerShard = ptr.To(int32(2))
+				cluster.Spec.RedisFollower.Replicas = ptr.To(int32(6))
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("replicasPerShard and redisFollower.replicas are mutually exclusive"),
+		},
+		{
+			Name:      "failed-create-v1beta2-rediscluster-external-access-and-nodeport",
+			Operation: admissionv1beta1.Create,
+			Object: func(t *testing.T, uid string) []byte {
+				t.Helper()
+				cluster := mkRedisCluster(uid)
+				cluster.Spec.ClusterSize = ptr.To(int32(3))
+				cluster.Spec.ExternalAccess = &v1beta2.ExternalAccess{Type: v1beta2.ExternalAccessLoadBalancer}
+				cluster.Spec.KubernetesConfig.Service = &common.ServiceConfig{ServiceType: "NodePort"}
+				return marshal(t, cluster)
+			},
+			Check: webhook.ValidationWebhookFailed("externalAccess and a NodePort kubernetesConfig.service are mutually exclusive"),
+		},
 	}
 
 	gvk := metav1.GroupVersionKind{
@@ -2,10 +2,12 @@ package v1beta2
 
 import (
 	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// +kubebuilder:validation:XValidation:rule="!has(self.restoreFrom) || !has(self.restoreFrom.pointInTime) || has(self.aofArchive)",message="restoreFrom.pointInTime requires aofArchive"
 type RedisReplicationSpec struct {
 	Size                          *int32                            `json:"clusterSize"`
 	KubernetesConfig              common.KubernetesConfig           `json:"kubernetesConfig"`
@@ -39,7 +41,16 @@ type RedisReplicationSpec struct {
 	// is ignored.
 	// +optional
 	// +kubebuilder:validation:Enum=OrderedReady;Parallel
-	PodManagementPolicy *string `json:"podManagementPolicy,omitempty"`
+	PodManagementPolicy *string             `json:"podManagementPolicy,omitempty"`
+	RestoreFrom         *common.RestoreFrom `json:"restoreFrom,omitempty"`
+	// AOFArchive runs a sidecar shipping the AOF files of every pod to object storage for point-in-time restores.
+	AOFArchive *rbvb2.AOFArchive `json:"aofArchive,omitempty"`
+	// UpgradeStrategy selects how the pods are rolled onto a new revision. `Managed` has the operator
+	// restart the replicas first, fail the master over once to an upgraded replica in sync, and restart
+	// the old master last. kubernetesConfig.updateStrategy is ignored then.
+	// +kubebuilder:default:=RollingUpdate
+	// +optional
+	UpgradeStrategy common.UpgradeStrategyType `json:"upgradeStrategy,omitempty"`
 }
 
 type Sentinel struct {
@@ -82,16 +93,23 @@ type ConnectionInfo struct {
 
 // RedisStatus defines the observed state of Redis
 type RedisReplicationStatus struct {
-	MasterNode string `json:"masterNode,omitempty"`
+	common.ConditionedStatus `json:",inline"`
+	common.VersionStatus     `json:",inline"`
+	MasterNode               string `json:"masterNode,omitempty"`
 	// ConnectionInfo provides connection details for clients to connect to Redis
 	// +optional
 	ConnectionInfo *ConnectionInfo `json:"connectionInfo,omitempty"`
+	// PasswordRotation reports the last rotation of the password of the redisSecret, if any.
+	// +optional
+	PasswordRotation *common.PasswordRotationStatus `json:"passwordRotation,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Master",type="string",JSONPath=".status.masterNode"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the replication is ready"
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.currentVersion",description="The Redis version every pod runs",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Redis is the Schema for the redis API
@@ -1,6 +1,10 @@
 package v1beta2
 
-import "fmt"
+import (
+	"fmt"
+
+	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+)
 
 func (cr *RedisReplication) EnableSentinel() bool {
 	return cr != nil && cr.Spec.Sentinel != nil && cr.Spec.Sentinel.Size > 0
@@ -30,6 +34,11 @@ func (cr *RedisReplication) MasterService() string {
 	return cr.Name + "-master"
 }
 
+// ManagedUpgrade returns whether the operator rolls the pods onto a new revision of the StatefulSet.
+func (cr *RedisReplication) ManagedUpgrade() bool {
+	return cr.Spec.UpgradeStrategy == common.UpgradeStrategyManaged
+}
+
 // GetConnectionInfo returns connection info for clients based on the mode.
 // The dnsDomain parameter should be the cluster DNS domain (e.g., "cluster.local").
 func (cr *RedisReplication) GetConnectionInfo(dnsDomain string) *ConnectionInfo {
@@ -22,6 +22,7 @@ package v1beta2
 
 import (
 	commonv1beta2 "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	redisbackupv1beta2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -241,6 +242,16 @@ func (in *RedisReplicationSpec) DeepCopyInto(out *RedisReplicationSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.RestoreFrom != nil {
+		in, out := &in.RestoreFrom, &out.RestoreFrom
+		*out = new(commonv1beta2.RestoreFrom)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AOFArchive != nil {
+		in, out := &in.AOFArchive, &out.AOFArchive
+		*out = new(redisbackupv1beta2.AOFArchive)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisReplicationSpec.
@@ -256,11 +267,18 @@ func (in *RedisReplicationSpec) DeepCopy() *RedisReplicationSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisReplicationStatus) DeepCopyInto(out *RedisReplicationStatus) {
 	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	out.VersionStatus = in.VersionStatus
 	if in.ConnectionInfo != nil {
 		in, out := &in.ConnectionInfo, &out.ConnectionInfo
 		*out = new(ConnectionInfo)
 		**out = **in
 	}
+	if in.PasswordRotation != nil {
+		in, out := &in.PasswordRotation, &out.PasswordRotation
+		*out = new(commonv1beta2.PasswordRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisReplicationStatus.
@@ -51,11 +51,55 @@ type RedisSentinelConfig struct {
 	common.RedisSentinelConfig `json:",inline"`
 }
 
-type RedisSentinelStatus struct{}
+// RedisSentinelStatus defines the observed state of RedisSentinel
+type RedisSentinelStatus struct {
+	common.ConditionedStatus `json:",inline"`
+	// MasterAddress is the address of the master monitored by most sentinels.
+	MasterAddress string `json:"masterAddress,omitempty"`
+	// MastersAgree is false when reachable sentinels report different masters, which indicates a split brain.
+	MastersAgree bool `json:"mastersAgree"`
+	// QuorumReachable is true when SENTINEL CKQUORUM succeeds on a majority of the sentinels.
+	QuorumReachable bool `json:"quorumReachable"`
+	// LastFailoverTime is when the monitored master was last observed to change.
+	LastFailoverTime *metav1.Time `json:"lastFailoverTime,omitempty"`
+	// Sentinels holds the view of every sentinel pod.
+	// +optional
+	Sentinels []SentinelPeerStatus `json:"sentinels,omitempty"`
+}
+
+// SentinelPeerStatus is what a single sentinel reports about the monitored master group
+type SentinelPeerStatus struct {
+	// Pod is the sentinel pod.
+	Pod string `json:"pod"`
+	// Status is the master status seen by the sentinel: ok, sdown or odown, or unreachable if the sentinel
+	// could not be queried and unmonitored if it does not monitor the master group.
+	Status string `json:"status"`
+	// MasterAddress is the master the sentinel monitors.
+	MasterAddress string `json:"masterAddress,omitempty"`
+	// Slaves is the number of replicas the sentinel knows about.
+	Slaves int32 `json:"slaves,omitempty"`
+	// Sentinels is the number of sentinels, itself included, the sentinel knows about.
+	Sentinels int32 `json:"sentinels,omitempty"`
+	// Quorum is the result of SENTINEL CKQUORUM on this sentinel.
+	Quorum bool `json:"quorum,omitempty"`
+	// Message is the SENTINEL CKQUORUM reply, or the error if the sentinel could not be queried.
+	Message string `json:"message,omitempty"`
+}
+
+// Sentinel master states reported in SentinelPeerStatus
+const (
+	SentinelPeerUnreachable = "unreachable"
+	SentinelPeerUnmonitored = "unmonitored"
+)
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 //+kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Master",type="string",JSONPath=".status.masterAddress",description="The monitored master"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the sentinels are ready"
+// +kubebuilder:printcolumn:name="Quorum",type="boolean",JSONPath=".status.quorumReachable",description="Whether a majority of sentinels reach the quorum"
+// +kubebuilder:printcolumn:name="LastFailover",type="date",JSONPath=".status.lastFailoverTime",description="Last observed failover"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Redis is the Schema for the redis API
 type RedisSentinel struct {
@@ -32,7 +32,7 @@ func (in *RedisSentinel) DeepCopyInto(out *RedisSentinel) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinel.
@@ -247,6 +247,16 @@ func (in *RedisSentinelSpec) DeepCopy() *RedisSentinelSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisSentinelStatus) DeepCopyInto(out *RedisSentinelStatus) {
 	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.LastFailoverTime != nil {
+		in, out := &in.LastFailoverTime, &out.LastFailoverTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Sentinels != nil {
+		in, out := &in.Sentinels, &out.Sentinels
+		*out = make([]SentinelPeerStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinelStatus.
@@ -258,3 +268,18 @@ func (in *RedisSentinelStatus) DeepCopy() *RedisSentinelStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SentinelPeerStatus) DeepCopyInto(out *SentinelPeerStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SentinelPeerStatus.
+func (in *SentinelPeerStatus) DeepCopy() *SentinelPeerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SentinelPeerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
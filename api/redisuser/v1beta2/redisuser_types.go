@@ -0,0 +1,160 @@
+/*
+Copyright 2020 Opstree Solutions.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Kinds of Redis resources a RedisUser can target.
+const (
+	TargetKindRedis            = "Redis"
+	TargetKindRedisReplication = "RedisReplication"
+	TargetKindRedisCluster     = "RedisCluster"
+)
+
+// Keys of the Secret the credentials of the user are generated into.
+const (
+	UsernameKey = "username"
+	PasswordKey = "password"
+)
+
+// RedisUserSpec defines the desired state of RedisUser
+type RedisUserSpec struct {
+	// Target is the Redis, RedisReplication or RedisCluster in the same namespace the user is created on.
+	Target UserTarget `json:"target"`
+	// Username is the name of the ACL user, the name of the RedisUser if unset.
+	// +kubebuilder:validation:Pattern=`^[^\s]+$`
+	// +kubebuilder:validation:XValidation:rule="self != 'default'",message="the default user is managed through the redisSecret"
+	Username string `json:"username,omitempty"`
+	// Enabled turns the user off without deleting it when false, refusing new connections of the user.
+	// +kubebuilder:default=true
+	Enabled *bool `json:"enabled,omitempty"`
+	// Commands are the command rules of the user, such as +@read, +set or -flushall, applied in order.
+	// +kubebuilder:validation:items:Pattern=`^[+-][^\s]+$`
+	Commands []string `json:"commands,omitempty"`
+	// Keys are the key patterns the user may access, such as app:*.
+	// +kubebuilder:validation:items:Pattern=`^[^\s]+$`
+	Keys []string `json:"keys,omitempty"`
+	// Channels are the Pub/Sub channel patterns the user may access, such as events:*. Requires Redis 6.2 or later.
+	// +kubebuilder:validation:items:Pattern=`^[^\s]+$`
+	Channels []string `json:"channels,omitempty"`
+	// PasswordSecret is an existing Secret holding the password of the user. A password is generated into
+	// the Secret named after the RedisUser if unset.
+	PasswordSecret *common.ExistingPasswordSecret `json:"passwordSecret,omitempty"`
+}
+
+// UserTarget references the Redis resource the user is created on
+type UserTarget struct {
+	// +kubebuilder:validation:Enum=Redis;RedisReplication;RedisCluster
+	Kind string `json:"kind"`
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// RedisUserStatus defines the observed state of RedisUser
+type RedisUserStatus struct {
+	common.ConditionedStatus `json:",inline"`
+	// Username is the name of the ACL user last applied to the servers, deleted from them once it changes.
+	Username string `json:"username,omitempty"`
+	// Secret is the Secret holding the credentials of the user.
+	Secret string `json:"secret,omitempty"`
+	// SyncedNodes is the number of servers the user is applied to, out of the running servers of the target.
+	SyncedNodes string `json:"syncedNodes,omitempty"`
+	// Nodes holds the sync status of the user on every running server of the target.
+	Nodes []UserNodeStatus `json:"nodes,omitempty"`
+}
+
+// UserNodeStatus is the sync status of the user on a single server
+type UserNodeStatus struct {
+	// Pod is the pod of the server.
+	Pod string `json:"pod"`
+	// Synced is true once ACL SETUSER applied the current spec of the user to the server.
+	Synced bool `json:"synced"`
+	// Persisted is true once ACL SAVE wrote the user to the ACL file of the server, so it survives a restart.
+	// Servers without a writable ACL file get the user back from the operator after a restart.
+	Persisted bool `json:"persisted"`
+	// Error is the reason the user couldn't be applied or persisted.
+	Error string `json:"error,omitempty"`
+	// LastSyncTime is when the sync status of the server last changed.
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// GetUsername returns the name of the ACL user.
+func (ru *RedisUser) GetUsername() string {
+	if ru.Spec.Username != "" {
+		return ru.Spec.Username
+	}
+	return ru.Name
+}
+
+// GeneratedSecretName returns the name of the Secret the password of the user is generated into.
+func (ru *RedisUser) GeneratedSecretName() string {
+	return ru.Name + "-redis-user"
+}
+
+// Rules returns the ACL rules of the user, without its password. They start with reset so the
+// user ends up with exactly the access of the spec.
+func (s *RedisUserSpec) Rules() []string {
+	rules := []string{"reset"}
+	if s.Enabled == nil || *s.Enabled {
+		rules = append(rules, "on")
+	} else {
+		rules = append(rules, "off")
+	}
+	for _, key := range s.Keys {
+		rules = append(rules, "~"+key)
+	}
+	for _, channel := range s.Channels {
+		rules = append(rules, "&"+channel)
+	}
+	return append(rules, s.Commands...)
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".spec.target.name",description="The resource the user is created on"
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=".spec.target.kind",description="Kind of the resource the user is created on"
+// +kubebuilder:printcolumn:name="Username",type="string",JSONPath=".status.username",description="The name of the ACL user"
+// +kubebuilder:printcolumn:name="Synced",type="string",JSONPath=".status.syncedNodes",description="Servers the user is applied to"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the user is applied to every server"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age of User"
+
+// RedisUser is the Schema for the redisusers API
+type RedisUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisUserSpec   `json:"spec"`
+	Status RedisUserStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RedisUserList contains a list of RedisUser
+type RedisUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisUser `json:"items"`
+}
+
+//nolint:gochecknoinits
+func init() {
+	SchemeBuilder.Register(&RedisUser{}, &RedisUserList{})
+}
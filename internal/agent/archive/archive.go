@@ -0,0 +1,217 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/objectstore"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
+)
+
+const (
+	// AOFDirName is the default appenddirname of redis-server.
+	AOFDirName = "appendonlydir"
+	// stateFileName keeps what has been archived from the data volume across restarts.
+	stateFileName = "aof-archive.json"
+	// finalSyncTimeout bounds the upload of the last writes when the pod stops.
+	finalSyncTimeout = 20 * time.Second
+)
+
+// Run uploads new AOF data of the pod every interval until ctx is done, configured through environment variables.
+func Run(ctx context.Context) error {
+	var (
+		dataDir   = util.CoalesceEnv1("DATA_DIR", "/data")
+		podName   = util.CoalesceEnv1("POD_NAME", "")
+		namespace = util.CoalesceEnv1("POD_NAMESPACE", "")
+	)
+	if podName == "" || namespace == "" {
+		return errors.New("POD_NAME and POD_NAMESPACE are required")
+	}
+	interval, err := time.ParseDuration(util.CoalesceEnv1("ARCHIVE_INTERVAL", "1m"))
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid ARCHIVE_INTERVAL: %v", err)
+	}
+	insecure, _ := strconv.ParseBool(util.CoalesceEnv1("ARCHIVE_S3_INSECURE", "false"))
+	store, err := objectstore.NewS3Store(objectstore.Config{
+		Endpoint:        util.CoalesceEnv1("ARCHIVE_S3_ENDPOINT", ""),
+		Bucket:          util.CoalesceEnv1("ARCHIVE_S3_BUCKET", ""),
+		Region:          util.CoalesceEnv1("ARCHIVE_S3_REGION", ""),
+		AccessKeyID:     util.CoalesceEnv1("AWS_ACCESS_KEY_ID", ""),
+		SecretAccessKey: util.CoalesceEnv1("AWS_SECRET_ACCESS_KEY", ""),
+		Insecure:        insecure,
+	})
+	if err != nil {
+		return err
+	}
+	s3 := rbvb2.S3Storage{Prefix: util.CoalesceEnv1("ARCHIVE_S3_PREFIX", "")}
+
+	a := &archiver{
+		store:     store,
+		prefix:    s3.ArchivePrefix(namespace, podName),
+		aofDir:    filepath.Join(dataDir, AOFDirName),
+		statePath: filepath.Join(dataDir, stateFileName),
+		now:       time.Now,
+	}
+	if err := a.loadState(); err != nil {
+		return err
+	}
+	log.Printf("Archiving %s to %s every %s", a.aofDir, a.prefix, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := a.sync(ctx); err != nil {
+			log.Printf("Failed to archive AOF: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			// Ship what was written since the last tick before the pod goes away.
+			finalCtx, cancel := context.WithTimeout(context.Background(), finalSyncTimeout)
+			defer cancel()
+			return a.sync(finalCtx)
+		}
+	}
+}
+
+// state is persisted on the data volume, so a restarted archiver resumes where it stopped.
+type state struct {
+	// Timeline is the archive timeline the volume is written to.
+	Timeline string `json:"timeline"`
+	// Offsets are the archived sizes of the live AOF files.
+	Offsets map[string]int64 `json:"offsets"`
+	// Manifest is the last archived manifest.
+	Manifest string `json:"manifest"`
+}
+
+type archiver struct {
+	store     objectstore.Store
+	prefix    string
+	aofDir    string
+	statePath string
+	now       func() time.Time
+	state     state
+}
+
+func (a *archiver) loadState() error {
+	raw, err := os.ReadFile(a.statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, &a.state)
+}
+
+func (a *archiver) saveState() error {
+	raw, err := json.Marshal(a.state)
+	if err != nil {
+		return err
+	}
+	tmp := a.statePath + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, a.statePath)
+}
+
+// sync uploads the data appended to the base and incr files since the last call, then the manifest if it changed.
+// The manifest goes last so every archived manifest only references files that are archived.
+func (a *archiver) sync(ctx context.Context) error {
+	manifestPath, err := findManifest(a.aofDir)
+	if err != nil || manifestPath == "" {
+		// redis-server has not created the AOF yet.
+		return err
+	}
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return err
+	}
+	entries, err := ParseManifest(raw)
+	if err != nil {
+		return err
+	}
+
+	if a.state.Timeline == "" {
+		a.state.Timeline = a.now().UTC().Format(timeFormat)
+		log.Printf("Starting archive timeline %s", a.state.Timeline)
+	}
+	if a.state.Offsets == nil {
+		a.state.Offsets = map[string]int64{}
+	}
+
+	live := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if entry.Type == FileTypeHistory {
+			continue
+		}
+		live[entry.File] = true
+		if err := a.uploadAppended(ctx, entry.File); err != nil {
+			return err
+		}
+	}
+	for file := range a.state.Offsets {
+		if !live[file] {
+			delete(a.state.Offsets, file)
+		}
+	}
+
+	if string(raw) != a.state.Manifest {
+		if _, err := a.store.Upload(ctx, ManifestKey(a.prefix, a.state.Timeline, a.now()), bytes.NewReader(raw)); err != nil {
+			return fmt.Errorf("failed to upload manifest: %w", err)
+		}
+		a.state.Manifest = string(raw)
+	}
+	return a.saveState()
+}
+
+// uploadAppended uploads the part of file written since its last upload as a new segment.
+func (a *archiver) uploadAppended(ctx context.Context, file string) error {
+	f, err := os.Open(filepath.Join(a.aofDir, file))
+	if errors.Is(err, os.ErrNotExist) {
+		// Removed by a rewrite finishing after the manifest was read, the next manifest no longer lists it.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	offset := a.state.Offsets[file]
+	size := info.Size()
+	if size <= offset {
+		return nil
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	key := SegmentKey(a.prefix, a.state.Timeline, file, offset)
+	if _, err := a.store.Upload(ctx, key, io.LimitReader(f, size-offset)); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	a.state.Offsets[file] = size
+	return nil
+}
+
+// findManifest returns the path of the manifest in dir, or "" if there is none.
+func findManifest(dir string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.manifest"))
+	if err != nil || len(matches) == 0 {
+		return "", err
+	}
+	return matches[0], nil
+}
@@ -0,0 +1,160 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/objectstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	objectstore.Store
+	objects map[string][]byte
+}
+
+func (m *memoryStore) Upload(_ context.Context, key string, r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	m.objects[key] = data
+	return int64(len(data)), nil
+}
+
+func (m *memoryStore) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for key := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func newTestArchiver(t *testing.T) (*archiver, *memoryStore, *time.Time) {
+	t.Helper()
+	dataDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dataDir, AOFDirName), 0o755))
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := &memoryStore{objects: map[string][]byte{}}
+	return &archiver{
+		store:     store,
+		prefix:    "ns/redis-0/aof",
+		aofDir:    filepath.Join(dataDir, AOFDirName),
+		statePath: filepath.Join(dataDir, stateFileName),
+		now:       func() time.Time { return now },
+	}, store, &now
+}
+
+func writeAOFFile(t *testing.T, dir, name, content string, appendData bool) {
+	t.Helper()
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendData {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(filepath.Join(dir, name), flags, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestSync(t *testing.T) {
+	a, store, now := newTestArchiver(t)
+	ctx := context.Background()
+
+	require.NoError(t, a.sync(ctx))
+	assert.Empty(t, store.objects, "nothing is archived before redis-server creates the AOF")
+
+	manifest := "file appendonly.aof.1.base.rdb seq 1 type b\nfile appendonly.aof.1.incr.aof seq 1 type i\n"
+	writeAOFFile(t, a.aofDir, "appendonly.aof.manifest", manifest, false)
+	writeAOFFile(t, a.aofDir, "appendonly.aof.1.base.rdb", "REDIS0011", false)
+	writeAOFFile(t, a.aofDir, "appendonly.aof.1.incr.aof", "first", false)
+	require.NoError(t, a.sync(ctx))
+
+	timeline := "20240501T100000Z"
+	assert.Equal(t, "REDIS0011", string(store.objects[SegmentKey(a.prefix, timeline, "appendonly.aof.1.base.rdb", 0)]))
+	assert.Equal(t, "first", string(store.objects[SegmentKey(a.prefix, timeline, "appendonly.aof.1.incr.aof", 0)]))
+	assert.Equal(t, manifest, string(store.objects[ManifestKey(a.prefix, timeline, *now)]))
+	assert.Len(t, store.objects, 3)
+
+	*now = now.Add(time.Minute)
+	writeAOFFile(t, a.aofDir, "appendonly.aof.1.incr.aof", "second", true)
+	require.NoError(t, a.sync(ctx))
+	assert.Equal(t, "second", string(store.objects[SegmentKey(a.prefix, timeline, "appendonly.aof.1.incr.aof", 5)]))
+	assert.Len(t, store.objects, 4, "an unchanged manifest is not uploaded again")
+
+	t.Run("restarted archiver resumes from its state", func(t *testing.T) {
+		restarted := &archiver{store: store, prefix: a.prefix, aofDir: a.aofDir, statePath: a.statePath, now: a.now}
+		require.NoError(t, restarted.loadState())
+		require.NoError(t, restarted.sync(ctx))
+		assert.Equal(t, timeline, restarted.state.Timeline)
+		assert.Len(t, store.objects, 4)
+	})
+
+	t.Run("rewrite uploads the new files and manifest", func(t *testing.T) {
+		*now = now.Add(time.Minute)
+		rewritten := "file appendonly.aof.2.base.rdb seq 2 type b\nfile appendonly.aof.1.incr.aof seq 1 type h\nfile appendonly.aof.2.incr.aof seq 2 type i\n"
+		writeAOFFile(t, a.aofDir, "appendonly.aof.2.base.rdb", "REDIS0011new", false)
+		writeAOFFile(t, a.aofDir, "appendonly.aof.2.incr.aof", "third", false)
+		writeAOFFile(t, a.aofDir, "appendonly.aof.manifest", rewritten, false)
+		require.NoError(t, os.Remove(filepath.Join(a.aofDir, "appendonly.aof.1.base.rdb")))
+		require.NoError(t, a.sync(ctx))
+
+		assert.Equal(t, rewritten, string(store.objects[ManifestKey(a.prefix, timeline, *now)]))
+		assert.Equal(t, "third", string(store.objects[SegmentKey(a.prefix, timeline, "appendonly.aof.2.incr.aof", 0)]))
+		assert.NotContains(t, a.state.Offsets, "appendonly.aof.1.base.rdb")
+		assert.NotContains(t, a.state.Offsets, "appendonly.aof.1.incr.aof")
+	})
+}
+
+func TestNewIndex(t *testing.T) {
+	prefix := "backups/ns/redis-0/aof"
+	t1 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	keys := []string{
+		ManifestKey(prefix, "20240501T100000Z", t2),
+		ManifestKey(prefix, "20240501T100000Z", t1),
+		SegmentKey(prefix, "20240501T100000Z", "appendonly.aof.1.incr.aof", 10),
+		SegmentKey(prefix, "20240501T100000Z", "appendonly.aof.1.incr.aof", 0),
+		prefix + "/20240501T100000Z/unknown",
+	}
+	idx := NewIndex(prefix, keys)
+
+	require.Len(t, idx.Manifests, 2)
+	assert.Equal(t, t1, idx.Manifests[0].Time)
+	assert.Equal(t, "20240501T100000Z", idx.Manifests[0].Timeline)
+	segments := idx.Segments["20240501T100000Z"]["appendonly.aof.1.incr.aof"]
+	require.Len(t, segments, 2)
+	assert.Equal(t, []int64{0, 10}, []int64{segments[0].Offset, segments[1].Offset})
+
+	m, ok := idx.LatestManifestBefore(t2.Add(-time.Second))
+	require.True(t, ok)
+	assert.Equal(t, t1, m.Time)
+	_, ok = idx.LatestManifestBefore(t1.Add(-time.Second))
+	assert.False(t, ok)
+}
+
+func TestParseManifest(t *testing.T) {
+	entries, err := ParseManifest([]byte("file appendonly.aof.2.base.rdb seq 2 type b\nfile appendonly.aof.3.incr.aof seq 3 type i\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []ManifestEntry{
+		{File: "appendonly.aof.2.base.rdb", Seq: 2, Type: FileTypeBase},
+		{File: "appendonly.aof.3.incr.aof", Seq: 3, Type: FileTypeIncr},
+	}, entries)
+	assert.Equal(t, "appendonly.aof.2.base.rdb", BaseFile(entries))
+	assert.True(t, bytes.Equal(FormatManifest(entries), []byte("file appendonly.aof.2.base.rdb seq 2 type b\nfile appendonly.aof.3.incr.aof seq 3 type i\n")))
+
+	_, err = ParseManifest([]byte("file appendonly.aof.2.base.rdb seq\n"))
+	assert.Error(t, err)
+}
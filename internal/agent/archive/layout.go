@@ -0,0 +1,99 @@
+package archive
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The archive of a pod is split into timelines, a new one starts whenever the archiver finds a data volume
+// it has not archived before, e.g. a new or restored volume. Within a timeline, the AOF files are uploaded as
+// segments keyed by their start offset, and every version of the manifest is uploaded under the time it was seen:
+//
+//	<prefix>/<timeline>/files/<file>/<offset>
+//	<prefix>/<timeline>/manifests/<time>
+const (
+	timeFormat   = "20060102T150405Z"
+	filesDir     = "files"
+	manifestsDir = "manifests"
+)
+
+// SegmentKey returns the key of the segment of file starting at offset.
+func SegmentKey(prefix, timeline, file string, offset int64) string {
+	return path.Join(prefix, timeline, filesDir, file, fmt.Sprintf("%020d", offset))
+}
+
+// ManifestKey returns the key of the manifest seen at t.
+func ManifestKey(prefix, timeline string, t time.Time) string {
+	return path.Join(prefix, timeline, manifestsDir, t.UTC().Format(timeFormat))
+}
+
+// Segment is an uploaded part of an AOF file
+type Segment struct {
+	Key    string
+	Offset int64
+}
+
+// ManifestVersion is an uploaded version of the manifest
+type ManifestVersion struct {
+	Key      string
+	Timeline string
+	Time     time.Time
+}
+
+// Index lists the objects of a pod archive.
+type Index struct {
+	// Manifests are sorted by time.
+	Manifests []ManifestVersion
+	// Segments are indexed by timeline and file name, and sorted by offset.
+	Segments map[string]map[string][]Segment
+}
+
+// NewIndex sorts the keys listed below prefix into an Index, unknown keys are ignored.
+func NewIndex(prefix string, keys []string) *Index {
+	idx := &Index{Segments: map[string]map[string][]Segment{}}
+	for _, key := range keys {
+		parts := strings.Split(strings.TrimPrefix(strings.TrimPrefix(key, prefix), "/"), "/")
+		switch {
+		case len(parts) == 3 && parts[1] == manifestsDir:
+			t, err := time.Parse(timeFormat, parts[2])
+			if err != nil {
+				continue
+			}
+			idx.Manifests = append(idx.Manifests, ManifestVersion{Key: key, Timeline: parts[0], Time: t})
+		case len(parts) == 4 && parts[1] == filesDir:
+			offset, err := strconv.ParseInt(parts[3], 10, 64)
+			if err != nil {
+				continue
+			}
+			if idx.Segments[parts[0]] == nil {
+				idx.Segments[parts[0]] = map[string][]Segment{}
+			}
+			idx.Segments[parts[0]][parts[2]] = append(idx.Segments[parts[0]][parts[2]], Segment{Key: key, Offset: offset})
+		}
+	}
+	sort.Slice(idx.Manifests, func(i, j int) bool {
+		return idx.Manifests[i].Time.Before(idx.Manifests[j].Time)
+	})
+	for _, files := range idx.Segments {
+		for _, segments := range files {
+			sort.Slice(segments, func(i, j int) bool {
+				return segments[i].Offset < segments[j].Offset
+			})
+		}
+	}
+	return idx
+}
+
+// LatestManifestBefore returns the newest manifest seen at or before t.
+func (idx *Index) LatestManifestBefore(t time.Time) (ManifestVersion, bool) {
+	for i := len(idx.Manifests) - 1; i >= 0; i-- {
+		if !idx.Manifests[i].Time.After(t) {
+			return idx.Manifests[i], true
+		}
+	}
+	return ManifestVersion{}, false
+}
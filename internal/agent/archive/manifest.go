@@ -0,0 +1,78 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// File types of the multi-part AOF manifest.
+const (
+	FileTypeBase    = "b"
+	FileTypeHistory = "h"
+	FileTypeIncr    = "i"
+)
+
+// ManifestEntry is a line of the multi-part AOF manifest, e.g. `file appendonly.aof.2.incr.aof seq 2 type i`.
+type ManifestEntry struct {
+	File string
+	Seq  int64
+	Type string
+}
+
+// ParseManifest parses the manifest redis-server keeps next to the AOF files.
+func ParseManifest(data []byte) ([]ManifestEntry, error) {
+	var entries []ManifestEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields)%2 != 0 {
+			return nil, fmt.Errorf("invalid manifest line %q", line)
+		}
+		var entry ManifestEntry
+		for i := 0; i < len(fields); i += 2 {
+			switch fields[i] {
+			case "file":
+				entry.File = fields[i+1]
+			case "seq":
+				seq, err := strconv.ParseInt(fields[i+1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid manifest line %q", line)
+				}
+				entry.Seq = seq
+			case "type":
+				entry.Type = fields[i+1]
+			}
+		}
+		if entry.File == "" || entry.Type == "" {
+			return nil, fmt.Errorf("invalid manifest line %q", line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// FormatManifest renders entries in the manifest format redis-server loads.
+func FormatManifest(entries []ManifestEntry) []byte {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "file %s seq %d type %s\n", e.File, e.Seq, e.Type)
+	}
+	return buf.Bytes()
+}
+
+// BaseFile returns the file name of the base entry, or "" if there is none.
+func BaseFile(entries []ManifestEntry) string {
+	for _, e := range entries {
+		if e.Type == FileTypeBase {
+			return e.File
+		}
+	}
+	return ""
+}
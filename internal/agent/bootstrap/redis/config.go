@@ -30,23 +30,33 @@ supervised no
 pidfile /var/run/redis.pid
 `
 
+// defaultConfigFile returns the path of the config file the operator starts the server of engine with.
+func defaultConfigFile(engine string) string {
+	if engine == util.EngineValkey {
+		return "/etc/redis/valkey.conf"
+	}
+	return "/etc/redis/redis.conf"
+}
+
 // GenerateConfig generates Redis configuration file
 func GenerateConfig() error {
-	confPath := util.CoalesceEnv1("REDIS_CONFIG_FILE", "/etc/redis/redis.conf")
+	engine := util.CoalesceEnv1(consts.ENV_KEY_REDIS_ENGINE, util.EngineRedis)
+	confPath := util.CoalesceEnv1("REDIS_CONFIG_FILE", defaultConfigFile(engine))
 	cfg := agentutil.NewConfig(confPath, defaultRedisConfig)
 
 	var (
-		persistenceEnabled = util.CoalesceEnv1("PERSISTENCE_ENABLED", "false")
-		dataDir            = util.CoalesceEnv1("DATA_DIR", "/data")
-		nodeConfDir        = util.CoalesceEnv1("NODE_CONF_DIR", "/node-conf")
-		externalConfigFile = util.CoalesceEnv1("EXTERNAL_CONFIG_FILE", "/etc/redis/external.conf.d/redis-additional.conf")
-		redisMajorVersion  = util.CoalesceEnv1("REDIS_MAJOR_VERSION", "v7")
-		redisPort          = util.CoalesceEnv1("REDIS_PORT", "6379")
-		nodeport           = util.CoalesceEnv1("NODEPORT", "false")
-		tlsMode            = util.CoalesceEnv1("TLS_MODE", "false")
-		clusterMode        = util.CoalesceEnv1("SETUP_MODE", "standalone")
-		aclMode            = util.CoalesceEnv1("ACL_MODE", "")
-		aclFilePath        = util.CoalesceEnv1("ACL_FILE_PATH", "/etc/redis/user.acl")
+		persistenceEnabled  = util.CoalesceEnv1("PERSISTENCE_ENABLED", "false")
+		aofTimestampEnabled = util.CoalesceEnv1("AOF_TIMESTAMP_ENABLED", "false")
+		dataDir             = util.CoalesceEnv1("DATA_DIR", "/data")
+		nodeConfDir         = util.CoalesceEnv1("NODE_CONF_DIR", "/node-conf")
+		externalConfigFile  = util.CoalesceEnv1("EXTERNAL_CONFIG_FILE", "/etc/redis/external.conf.d/redis-additional.conf")
+		redisMajorVersion   = util.CoalesceEnv1("REDIS_MAJOR_VERSION", "v7")
+		redisPort           = util.CoalesceEnv1("REDIS_PORT", "6379")
+		nodeport            = util.CoalesceEnv1("NODEPORT", "false")
+		tlsMode             = util.CoalesceEnv1("TLS_MODE", "false")
+		clusterMode         = util.CoalesceEnv1("SETUP_MODE", "standalone")
+		aclMode             = util.CoalesceEnv1("ACL_MODE", "")
+		aclFilePath         = util.CoalesceEnv1("ACL_FILE_PATH", "/etc/redis/user.acl")
 	)
 
 	if val, ok := util.CoalesceEnv("REDIS_PASSWORD", ""); ok && val != "" {
@@ -58,6 +68,14 @@ func GenerateConfig() error {
 		cfg.Append("protected-mode", "no")
 	}
 
+	// The operator passes the address of the load balancer of every pod of the StatefulSet, a pod
+	// announces the IP, or the hostname, of its own.
+	podHostname, _ := os.Hostname()
+	envSuffix := strings.ReplaceAll(podHostname, "-", "_")
+	loadBalancerIP := os.Getenv("announce_ip_" + envSuffix)
+	loadBalancerHostname := os.Getenv("announce_hostname_" + envSuffix)
+	preferHostname := false
+
 	if clusterMode == "cluster" {
 		nodeConfPath := filepath.Join(nodeConfDir, "nodes.conf")
 
@@ -85,6 +103,8 @@ func GenerateConfig() error {
 		var clusterAnnounceIP string
 		if nodeport == "true" {
 			clusterAnnounceIP = os.Getenv("HOST_IP")
+		} else if loadBalancerIP != "" {
+			clusterAnnounceIP = loadBalancerIP
 		} else {
 			clusterAnnounceIP, err = util.GetLocalIP()
 			if err != nil {
@@ -94,7 +114,11 @@ func GenerateConfig() error {
 		if clusterAnnounceIP != "" {
 			cfg.Append("cluster-announce-ip", clusterAnnounceIP)
 		}
-		if util.IsRedisVersionAtLeastV7(redisMajorVersion) {
+		if util.HasRedis7Features(engine, redisMajorVersion) && loadBalancerHostname != "" {
+			// Clients outside of Kubernetes follow the redirections to the hostname of the load balancer.
+			cfg.Append("cluster-announce-hostname", loadBalancerHostname)
+			preferHostname = true
+		} else if util.HasRedis7Features(engine, redisMajorVersion) {
 			fqdnName, err := fqdn.FqdnHostname()
 			if err != nil {
 				log.Printf("Warning: Failed to get FQDN: %v", err)
@@ -102,6 +126,12 @@ func GenerateConfig() error {
 				cfg.Append("cluster-announce-hostname", fqdnName)
 			}
 		}
+		// Valkey 8 shows the name of the pod next to the node in CLUSTER NODES and in its logs.
+		if engine == util.EngineValkey && util.RedisMajorVersion(redisMajorVersion) >= 8 {
+			if podHostname, err := os.Hostname(); err == nil {
+				cfg.Append("cluster-announce-human-nodename", podHostname)
+			}
+		}
 	} else {
 		fmt.Println("Setting up redis in standalone mode")
 	}
@@ -117,13 +147,18 @@ func GenerateConfig() error {
 
 		if clusterMode == "cluster" {
 			cfg.Append("tls-cluster", "yes")
-			if util.IsRedisVersionAtLeastV7(redisMajorVersion) && nodeport == "false" {
-				cfg.Append("cluster-preferred-endpoint-type", "hostname")
+			// Behind a load balancer IP the redirections carry the IP, the FQDN of the pod doesn't resolve
+			// outside of Kubernetes.
+			if util.HasRedis7Features(engine, redisMajorVersion) && nodeport == "false" && loadBalancerIP == "" {
+				preferHostname = true
 			}
 		}
 	} else {
 		fmt.Println("Running without TLS mode")
 	}
+	if preferHostname {
+		cfg.Append("cluster-preferred-endpoint-type", "hostname")
+	}
 
 	if aclMode == "true" {
 		fmt.Println("ACL_MODE is true, modifying ACL file path to", aclFilePath)
@@ -139,6 +174,10 @@ func GenerateConfig() error {
 		cfg.Append("Appendonly", "yes")
 		cfg.Append("Appendfilename", "\"Appendonly.aof\"")
 		cfg.Append("dir", dataDir)
+		// Timestamp annotations let a point-in-time restore truncate an archived AOF, they need Redis 7.
+		if aofTimestampEnabled == "true" && util.HasRedis7Features(engine, redisMajorVersion) {
+			cfg.Append("aof-timestamp-enabled", "yes")
+		}
 	} else {
 		fmt.Println("Running without persistence mode")
 	}
@@ -151,9 +190,8 @@ func GenerateConfig() error {
 	}
 
 	if nodeport == "true" {
-		podHostname, _ := os.Hostname()
-		announcePortVar := "announce_port_" + strings.ReplaceAll(podHostname, "-", "_")
-		announceBusPortVar := "announce_bus_port_" + strings.ReplaceAll(podHostname, "-", "_")
+		announcePortVar := "announce_port_" + envSuffix
+		announceBusPortVar := "announce_bus_port_" + envSuffix
 
 		// Get environment variables
 		clusterAnnouncePort := os.Getenv(announcePortVar)
@@ -149,3 +149,123 @@ func Test_GenerateConfig_RedisMajorVersionGates(t *testing.T) {
 		})
 	}
 }
+
+func Test_GenerateConfig_AOFTimestamps(t *testing.T) {
+	tests := []struct {
+		name              string
+		persistence       string
+		redisMajorVersion string
+		expectTimestamps  bool
+	}{
+		{name: "enabled with persistence on v7", persistence: "true", redisMajorVersion: "v7", expectTimestamps: true},
+		{name: "not supported before v7", persistence: "true", redisMajorVersion: "v6", expectTimestamps: false},
+		{name: "ignored without persistence", persistence: "false", redisMajorVersion: "v7", expectTimestamps: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confPath := filepath.Join(t.TempDir(), "redis.conf")
+
+			t.Setenv("REDIS_CONFIG_FILE", confPath)
+			t.Setenv("SETUP_MODE", "standalone")
+			t.Setenv("PERSISTENCE_ENABLED", tt.persistence)
+			t.Setenv("AOF_TIMESTAMP_ENABLED", "true")
+			t.Setenv("REDIS_MAJOR_VERSION", tt.redisMajorVersion)
+
+			require.NoError(t, GenerateConfig())
+
+			raw, err := os.ReadFile(confPath)
+			require.NoError(t, err)
+			if tt.expectTimestamps {
+				assert.Contains(t, string(raw), "aof-timestamp-enabled yes")
+			} else {
+				assert.NotContains(t, string(raw), "aof-timestamp-enabled")
+			}
+		})
+	}
+}
+
+func Test_GenerateConfig_Valkey(t *testing.T) {
+	assert.Equal(t, "/etc/redis/redis.conf", defaultConfigFile("redis"))
+	assert.Equal(t, "/etc/redis/valkey.conf", defaultConfigFile("valkey"))
+
+	confPath := filepath.Join(t.TempDir(), "valkey.conf")
+	t.Setenv("REDIS_CONFIG_FILE", confPath)
+	t.Setenv("REDIS_ENGINE", "valkey")
+	t.Setenv("SETUP_MODE", "standalone")
+	t.Setenv("PERSISTENCE_ENABLED", "true")
+	t.Setenv("AOF_TIMESTAMP_ENABLED", "true")
+	// Valkey has the features of Redis 7 whatever the major version says.
+	t.Setenv("REDIS_MAJOR_VERSION", "v6")
+
+	require.NoError(t, GenerateConfig())
+	raw, err := os.ReadFile(confPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "aof-timestamp-enabled yes")
+}
+
+func Test_GenerateConfig_LoadBalancer(t *testing.T) {
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	envSuffix := strings.ReplaceAll(hostname, "-", "_")
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		expected    []string
+		notExpected []string
+	}{
+		{
+			name:        "load balancer IP",
+			env:         map[string]string{"announce_ip_" + envSuffix: "203.0.113.10"},
+			expected:    []string{"cluster-announce-ip 203.0.113.10"},
+			notExpected: []string{"cluster-preferred-endpoint-type"},
+		},
+		{
+			name: "load balancer hostname",
+			env:  map[string]string{"announce_hostname_" + envSuffix: "redis-0.elb.example.com"},
+			expected: []string{
+				"cluster-announce-hostname redis-0.elb.example.com",
+				"cluster-preferred-endpoint-type hostname",
+			},
+		},
+		{
+			name:        "address of another pod",
+			env:         map[string]string{"announce_ip_other_pod": "203.0.113.11"},
+			expected:    []string{"cluster-preferred-endpoint-type hostname"},
+			notExpected: []string{"203.0.113.11"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			confPath := filepath.Join(tmp, "redis.conf")
+
+			t.Setenv("REDIS_CONFIG_FILE", confPath)
+			t.Setenv("SETUP_MODE", "cluster")
+			t.Setenv("NODE_CONF_DIR", tmp)
+			t.Setenv("NODEPORT", "false")
+			t.Setenv("TLS_MODE", "true")
+			t.Setenv("REDIS_TLS_CERT", "/tls/tls.crt")
+			t.Setenv("REDIS_TLS_CERT_KEY", "/tls/tls.key")
+			t.Setenv("REDIS_MAJOR_VERSION", "v7")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			require.NoError(t, GenerateConfig())
+
+			raw, err := os.ReadFile(confPath)
+			require.NoError(t, err)
+			conf := string(raw)
+			for _, line := range tt.expected {
+				assert.Contains(t, conf, line)
+			}
+			for _, line := range tt.notExpected {
+				assert.NotContains(t, conf, line)
+			}
+			assert.LessOrEqual(t, strings.Count(conf, "cluster-preferred-endpoint-type"), 1)
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package restore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/agent/archive"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/objectstore"
+)
+
+// restoreAOF rebuilds the AOF directory of the archived pod as it was at target, unless the data directory
+// already holds data. Writes annotated with a later timestamp are dropped from the incr files.
+func restoreAOF(ctx context.Context, store objectstore.Store, prefix string, target time.Time, dataDir string) error {
+	if hasData(dataDir) {
+		return nil
+	}
+
+	keys, err := store.List(ctx, prefix+"/")
+	if err != nil {
+		return fmt.Errorf("failed to list archive %s: %w", prefix, err)
+	}
+	idx := archive.NewIndex(prefix, keys)
+	entries, timeline, err := restoreManifest(ctx, store, idx, target)
+	if err != nil {
+		return err
+	}
+
+	// Build the directory next to its destination and move it in place once complete,
+	// so an interrupted restore is retried instead of loaded.
+	tmpDir, err := os.MkdirTemp(dataDir, "restore-aof-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var kept []archive.ManifestEntry
+	for _, entry := range entries {
+		if entry.Type == archive.FileTypeHistory {
+			continue
+		}
+		segments := idx.Segments[timeline][entry.File]
+		if entry.Type == archive.FileTypeBase && len(segments) == 0 {
+			return fmt.Errorf("base file %s is missing from the archive", entry.File)
+		}
+		if err := writeArchivedFile(ctx, store, segments, filepath.Join(tmpDir, entry.File), entry.Type == archive.FileTypeIncr, target); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", entry.File, err)
+		}
+		kept = append(kept, entry)
+	}
+	manifestName, err := manifestFileName(archive.BaseFile(kept))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, manifestName), archive.FormatManifest(kept), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpDir, filepath.Join(dataDir, archive.AOFDirName)); err != nil {
+		return err
+	}
+	log.Printf("Restored %s to %s from timeline %s", prefix, target.UTC().Format(time.RFC3339), timeline)
+	return nil
+}
+
+// restoreManifest returns the manifest to restore: the newest one seen before target, extended by the
+// later versions sharing its base, which list the incr files opened by a rewrite still running at that time.
+func restoreManifest(ctx context.Context, store objectstore.Store, idx *archive.Index, target time.Time) ([]archive.ManifestEntry, string, error) {
+	chosen, ok := idx.LatestManifestBefore(target)
+	if !ok {
+		return nil, "", fmt.Errorf("archive holds no data before %s", target.UTC().Format(time.RFC3339))
+	}
+	entries, err := downloadManifest(ctx, store, chosen.Key)
+	if err != nil {
+		return nil, "", err
+	}
+	base := archive.BaseFile(entries)
+	if base == "" {
+		return nil, "", fmt.Errorf("manifest %s has no base file", chosen.Key)
+	}
+	for _, m := range idx.Manifests {
+		if m.Timeline != chosen.Timeline || !m.Time.After(chosen.Time) {
+			continue
+		}
+		later, err := downloadManifest(ctx, store, m.Key)
+		if err != nil {
+			return nil, "", err
+		}
+		if archive.BaseFile(later) != base {
+			break
+		}
+		entries = later
+	}
+	return entries, chosen.Timeline, nil
+}
+
+func downloadManifest(ctx context.Context, store objectstore.Store, key string) ([]archive.ManifestEntry, error) {
+	r, err := store.Download(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	defer r.Close()
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	return archive.ParseManifest(raw)
+}
+
+// writeArchivedFile concatenates the segments of a file into path, truncating incr files at target.
+func writeArchivedFile(ctx context.Context, store objectstore.Store, segments []archive.Segment, path string, truncate bool, target time.Time) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	r := &segmentReader{ctx: ctx, store: store, segments: segments}
+	defer r.Close()
+	if truncate {
+		err = truncateAOF(r, f, target)
+	} else {
+		_, err = io.Copy(f, r)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// segmentReader reads the segments of a file in order, opening each one when the previous one is exhausted.
+type segmentReader struct {
+	ctx      context.Context
+	store    objectstore.Store
+	segments []archive.Segment
+	current  io.ReadCloser
+	offset   int64
+}
+
+func (r *segmentReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.segments) == 0 {
+				return 0, io.EOF
+			}
+			next := r.segments[0]
+			if next.Offset != r.offset {
+				return 0, fmt.Errorf("archive has a gap at offset %d, next segment starts at %d", r.offset, next.Offset)
+			}
+			current, err := r.store.Download(r.ctx, next.Key)
+			if err != nil {
+				return 0, fmt.Errorf("failed to download %s: %w", next.Key, err)
+			}
+			r.current = current
+			r.segments = r.segments[1:]
+		}
+		n, err := r.current.Read(p)
+		r.offset += int64(n)
+		if errors.Is(err, io.EOF) {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *segmentReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
+
+// truncateAOF copies the commands of an AOF file up to the first timestamp annotation after target.
+// A transaction cut by target and a command cut by the end of the archive are dropped as a whole.
+func truncateAOF(r io.Reader, w io.Writer, target time.Time) error {
+	br := bufio.NewReader(r)
+	var transaction bytes.Buffer
+	inTransaction := false
+	for {
+		line, err := br.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch line[0] {
+		case '#':
+			if ts, ok := strings.CutPrefix(strings.TrimRight(line, "\r\n"), "#TS:"); ok {
+				if sec, err := strconv.ParseInt(ts, 10, 64); err == nil && time.Unix(sec, 0).After(target) {
+					return nil
+				}
+			}
+			if inTransaction {
+				transaction.WriteString(line)
+			} else if _, err := io.WriteString(w, line); err != nil {
+				return err
+			}
+		case '*':
+			cmd, name, complete, err := readCommand(br, line)
+			if err != nil {
+				return err
+			}
+			if !complete {
+				return nil
+			}
+			switch {
+			case strings.EqualFold(name, "multi"):
+				inTransaction = true
+				transaction.Reset()
+				transaction.Write(cmd)
+			case inTransaction:
+				transaction.Write(cmd)
+				if strings.EqualFold(name, "exec") {
+					inTransaction = false
+					if _, err := w.Write(transaction.Bytes()); err != nil {
+						return err
+					}
+				}
+			default:
+				if _, err := w.Write(cmd); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("unexpected AOF content %q", line)
+		}
+	}
+}
+
+// readCommand reads the bulk strings of the RESP array whose header is already read,
+// complete is false if the input ends in the middle of the command.
+func readCommand(br *bufio.Reader, header string) (cmd []byte, name string, complete bool, err error) {
+	n, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+	if err != nil {
+		return nil, "", false, fmt.Errorf("invalid AOF array header %q", header)
+	}
+	buf := bytes.NewBufferString(header)
+	for i := 0; i < n; i++ {
+		line, err := br.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			return nil, "", false, nil
+		}
+		if err != nil {
+			return nil, "", false, err
+		}
+		if line[0] != '$' {
+			return nil, "", false, fmt.Errorf("invalid AOF bulk header %q", line)
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil || size < 0 {
+			return nil, "", false, fmt.Errorf("invalid AOF bulk header %q", line)
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(br, arg); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, "", false, nil
+			}
+			return nil, "", false, err
+		}
+		if i == 0 {
+			name = string(arg[:size])
+		}
+		buf.WriteString(line)
+		buf.Write(arg)
+	}
+	return buf.Bytes(), name, true, nil
+}
+
+// manifestFileName derives the manifest name from a base file name such as appendonly.aof.1.base.rdb.
+func manifestFileName(base string) (string, error) {
+	head, _, found := strings.Cut(base, ".base.")
+	idx := strings.LastIndex(head, ".")
+	if !found || idx < 0 {
+		return "", fmt.Errorf("unexpected base file name %q", base)
+	}
+	return head[:idx] + ".manifest", nil
+}
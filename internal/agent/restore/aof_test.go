@@ -0,0 +1,141 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/agent/archive"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resp(args ...string) string {
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	return b.String()
+}
+
+func TestTruncateAOF(t *testing.T) {
+	target := time.Unix(1000, 0)
+	set := resp("SET", "k", "v")
+
+	tests := []struct {
+		name string
+		aof  string
+		want string
+	}{
+		{
+			name: "stops at the first later timestamp",
+			aof:  "#TS:999\r\n" + set + "#TS:1000\r\n" + set + "#TS:1001\r\n" + resp("FLUSHALL"),
+			want: "#TS:999\r\n" + set + "#TS:1000\r\n" + set,
+		},
+		{
+			name: "drops a command cut by the end of the archive",
+			aof:  "#TS:999\r\n" + set + set[:10],
+			want: "#TS:999\r\n" + set,
+		},
+		{
+			name: "drops a transaction cut by the target",
+			aof:  "#TS:999\r\n" + resp("MULTI") + set + "#TS:1001\r\n" + set + resp("EXEC"),
+			want: "#TS:999\r\n",
+		},
+		{
+			name: "keeps a complete transaction",
+			aof:  "#TS:999\r\n" + resp("MULTI") + set + resp("EXEC") + "#TS:1001\r\n" + set,
+			want: "#TS:999\r\n" + resp("MULTI") + set + resp("EXEC"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, truncateAOF(strings.NewReader(tt.aof), &out, target))
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+
+	t.Run("rejects unexpected content", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.Error(t, truncateAOF(strings.NewReader("garbage\r\n"), &out, target))
+	})
+}
+
+func TestRestoreAOF(t *testing.T) {
+	prefix := "ns/redis-0/aof"
+	timeline := "20240501T100000Z"
+	t1 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	incr := "appendonly.aof.1.incr.aof"
+	before := "#TS:" + strconv.FormatInt(t1.Add(time.Minute).Unix(), 10) + "\r\n" + resp("SET", "k", "v")
+	after := "#TS:" + strconv.FormatInt(t2.Add(time.Minute).Unix(), 10) + "\r\n" + resp("FLUSHALL")
+
+	store := &fakeStore{objects: map[string][]byte{
+		archive.ManifestKey(prefix, timeline, t1):                            []byte("file appendonly.aof.1.base.rdb seq 1 type b\nfile appendonly.aof.1.incr.aof seq 1 type i\n"),
+		archive.SegmentKey(prefix, timeline, "appendonly.aof.1.base.rdb", 0): []byte("REDIS0011"),
+		archive.SegmentKey(prefix, timeline, incr, 0):                        []byte(before[:7]),
+		archive.SegmentKey(prefix, timeline, incr, 7):                        []byte(before[7:] + after),
+		// A rewrite after the FLUSHALL starts a new chain that must not be picked for earlier targets.
+		archive.ManifestKey(prefix, timeline, t2.Add(2*time.Minute)):         []byte("file appendonly.aof.2.base.rdb seq 2 type b\nfile appendonly.aof.2.incr.aof seq 2 type i\n"),
+		archive.SegmentKey(prefix, timeline, "appendonly.aof.2.base.rdb", 0): []byte("REDIS0011empty"),
+	}}
+
+	t.Run("replays up to the target", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, restoreAOF(context.Background(), store, prefix, t2, dir))
+
+		aofDir := filepath.Join(dir, archive.AOFDirName)
+		manifest, err := os.ReadFile(filepath.Join(aofDir, "appendonly.aof.manifest"))
+		require.NoError(t, err)
+		assert.Equal(t, "file appendonly.aof.1.base.rdb seq 1 type b\nfile appendonly.aof.1.incr.aof seq 1 type i\n", string(manifest))
+		base, err := os.ReadFile(filepath.Join(aofDir, "appendonly.aof.1.base.rdb"))
+		require.NoError(t, err)
+		assert.Equal(t, "REDIS0011", string(base))
+		data, err := os.ReadFile(filepath.Join(aofDir, incr))
+		require.NoError(t, err)
+		assert.Equal(t, before, string(data))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "the temporary directory is cleaned up")
+	})
+
+	t.Run("target before the archive", func(t *testing.T) {
+		assert.Error(t, restoreAOF(context.Background(), store, prefix, t1.Add(-time.Second), t.TempDir()))
+	})
+
+	t.Run("volume with data is left untouched", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "dump.rdb"), []byte("REDIS"), 0o644))
+		require.NoError(t, restoreAOF(context.Background(), store, prefix, t2, dir))
+		_, err := os.Stat(filepath.Join(dir, archive.AOFDirName))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("gap in the archive", func(t *testing.T) {
+		gappy := &fakeStore{objects: map[string][]byte{}}
+		for k, v := range store.objects {
+			gappy.objects[k] = v
+		}
+		delete(gappy.objects, archive.SegmentKey(prefix, timeline, incr, 0))
+		dir := t.TempDir()
+		assert.Error(t, restoreAOF(context.Background(), gappy, prefix, t2, dir))
+		_, err := os.Stat(filepath.Join(dir, archive.AOFDirName))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestManifestFileName(t *testing.T) {
+	name, err := manifestFileName("Appendonly.aof.3.base.rdb")
+	require.NoError(t, err)
+	assert.Equal(t, "Appendonly.aof.manifest", name)
+
+	_, err = manifestFileName("dump.rdb")
+	assert.Error(t, err)
+}
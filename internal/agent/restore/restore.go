@@ -0,0 +1,125 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/objectstore"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
+)
+
+// existingDataFiles are the files redis-server loads on startup, any of them means the volume is in use.
+var existingDataFiles = []string{"dump.rdb", "appendonlydir", "appendonly.aof"}
+
+// Run downloads the RDB file of the pod, or replays an AOF archive up to a timestamp, into the data directory,
+// configured through environment variables.
+func Run(ctx context.Context) error {
+	var (
+		dataDir    = util.CoalesceEnv1("DATA_DIR", "/data")
+		podName    = util.CoalesceEnv1("POD_NAME", "")
+		keys       = strings.Split(util.CoalesceEnv1("RESTORE_OBJECT_KEYS", ""), ",")
+		perOrdinal = util.CoalesceEnv1("RESTORE_PER_ORDINAL", "false") == "true"
+	)
+
+	insecure, _ := strconv.ParseBool(util.CoalesceEnv1("RESTORE_S3_INSECURE", "false"))
+	store, err := objectstore.NewS3Store(objectstore.Config{
+		Endpoint:        util.CoalesceEnv1("RESTORE_S3_ENDPOINT", ""),
+		Bucket:          util.CoalesceEnv1("RESTORE_S3_BUCKET", ""),
+		Region:          util.CoalesceEnv1("RESTORE_S3_REGION", ""),
+		AccessKeyID:     util.CoalesceEnv1("AWS_ACCESS_KEY_ID", ""),
+		SecretAccessKey: util.CoalesceEnv1("AWS_SECRET_ACCESS_KEY", ""),
+		Insecure:        insecure,
+	})
+	if err != nil {
+		return err
+	}
+
+	if prefix := util.CoalesceEnv1("RESTORE_AOF_PREFIX", ""); prefix != "" {
+		target, err := time.Parse(time.RFC3339, util.CoalesceEnv1("RESTORE_TARGET_TIME", ""))
+		if err != nil {
+			return fmt.Errorf("invalid RESTORE_TARGET_TIME: %w", err)
+		}
+		return restoreAOF(ctx, store, prefix, target, dataDir)
+	}
+
+	key, err := objectKey(keys, podName, perOrdinal)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		log.Printf("No backup shard for pod %s, starting empty", podName)
+		return nil
+	}
+	return restore(ctx, store, key, dataDir)
+}
+
+// objectKey picks the key restored by the pod, an empty key means the pod has no shard to restore.
+func objectKey(keys []string, podName string, perOrdinal bool) (string, error) {
+	if len(keys) == 0 || keys[0] == "" {
+		return "", fmt.Errorf("RESTORE_OBJECT_KEYS is empty")
+	}
+	if !perOrdinal {
+		return keys[0], nil
+	}
+	idx := strings.LastIndex(podName, "-")
+	ordinal, err := strconv.Atoi(podName[idx+1:])
+	if idx < 0 || err != nil {
+		return "", fmt.Errorf("cannot get ordinal of pod %q", podName)
+	}
+	if ordinal >= len(keys) {
+		return "", nil
+	}
+	return keys[ordinal], nil
+}
+
+// restore writes the object to dump.rdb unless the data directory already holds data,
+// so restarted pods never overwrite what they have written since the restore.
+func restore(ctx context.Context, store objectstore.Store, key, dataDir string) error {
+	if hasData(dataDir) {
+		return nil
+	}
+
+	r, err := store.Download(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	defer r.Close()
+
+	// Write to a temporary file first so an interrupted download is retried instead of loaded.
+	tmp, err := os.CreateTemp(dataDir, "restore-*.rdb")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dataDir, "dump.rdb")); err != nil {
+		return err
+	}
+	log.Printf("Restored %s (%d bytes) into %s", key, n, dataDir)
+	return nil
+}
+
+// hasData reports whether redis-server would load data from dataDir.
+func hasData(dataDir string) bool {
+	for _, name := range existingDataFiles {
+		if _, err := os.Stat(filepath.Join(dataDir, name)); err == nil {
+			log.Printf("Found %s in %s, skipping restore", name, dataDir)
+			return true
+		}
+	}
+	return false
+}
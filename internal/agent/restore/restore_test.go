@@ -0,0 +1,100 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/objectstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	objectstore.Store
+	objects map[string][]byte
+}
+
+func (f *fakeStore) Download(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := f.objects[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (f *fakeStore) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func TestObjectKey(t *testing.T) {
+	keys := []string{"b/leader-0.rdb", "b/leader-1.rdb"}
+
+	tests := []struct {
+		name       string
+		keys       []string
+		pod        string
+		perOrdinal bool
+		want       string
+		wantErr    bool
+	}{
+		{name: "shared key", keys: keys, pod: "redis-replication-2", want: "b/leader-0.rdb"},
+		{name: "ordinal key", keys: keys, pod: "cluster-leader-1", perOrdinal: true, want: "b/leader-1.rdb"},
+		{name: "ordinal without shard", keys: keys, pod: "cluster-leader-2", perOrdinal: true, want: ""},
+		{name: "pod without ordinal", keys: keys, pod: "cluster", perOrdinal: true, wantErr: true},
+		{name: "no keys", keys: []string{""}, pod: "redis-0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := objectKey(tt.keys, tt.pod, tt.perOrdinal)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	store := &fakeStore{objects: map[string][]byte{"b/redis-0.rdb": []byte("REDIS0011")}}
+
+	t.Run("empty volume is seeded", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, restore(context.Background(), store, "b/redis-0.rdb", dir))
+		data, err := os.ReadFile(filepath.Join(dir, "dump.rdb"))
+		require.NoError(t, err)
+		assert.Equal(t, "REDIS0011", string(data))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("volume with data is left untouched", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "appendonlydir"), 0o755))
+		require.NoError(t, restore(context.Background(), store, "b/redis-0.rdb", dir))
+		_, err := os.Stat(filepath.Join(dir, "dump.rdb"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("failed download leaves no file behind", func(t *testing.T) {
+		dir := t.TempDir()
+		require.Error(t, restore(context.Background(), store, "b/missing.rdb", dir))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,22 @@
+package archive
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/agent/archive"
+	"github.com/spf13/cobra"
+)
+
+func CMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "archive",
+		Short: "Archive continuously uploads the AOF files of redis to object storage for point-in-time restores",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return archive.Run(ctx)
+		},
+	}
+}
@@ -1,7 +1,9 @@
 package agent
 
 import (
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/cmd/agent/archive"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/cmd/agent/bootstrap"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/cmd/agent/restore"
 	"github.com/spf13/cobra"
 )
 
@@ -12,5 +14,7 @@ func CMD() *cobra.Command {
 		Short: "Agent is a tool which run as a init/sidecar container along with redis/sentinel",
 	}
 	agentCmd.AddCommand(bootstrap.CMD())
+	agentCmd.AddCommand(restore.CMD())
+	agentCmd.AddCommand(archive.CMD())
 	return agentCmd
 }
@@ -0,0 +1,16 @@
+package restore
+
+import (
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/agent/restore"
+	"github.com/spf13/cobra"
+)
+
+func CMD() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore",
+		Short: "Restore seeds an empty data volume with the RDB file of a backup",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return restore.Run(cmd.Context())
+		},
+	}
+}
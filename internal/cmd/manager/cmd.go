@@ -17,6 +17,7 @@ limitations under the License.
 package manager
 
 import (
+	"context"
 	"flag"
 	"time"
 
@@ -27,21 +28,30 @@ import (
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/operator"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/scheme"
+	draincontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/drain"
 	rediscontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redis"
+	redisbackupcontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redisbackup"
+	redisbackupschedulecontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redisbackupschedule"
 	redisclustercontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/rediscluster"
 	redisreplicationcontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redisreplication"
 	redissentinelcontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redissentinel"
+	redisusercontroller "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/redisuser"
 	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/features"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/monitoring"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/objectstore"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
 	coreWebhook "github.com/OT-CONTAINER-KIT/redis-operator/internal/webhook"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -63,6 +73,7 @@ type managerOptions struct {
 	enableWebhooks          bool
 	maxConcurrentReconciles int
 	featureGatesString      string
+	tracingEndpoint         string
 	zapOptions              zap.Options
 }
 
@@ -102,7 +113,9 @@ func addFlags(cmd *cobra.Command, opts *managerOptions) {
 	cmd.Flags().IntVar(&opts.maxConcurrentReconciles, "max-concurrent-reconciles", 3, "Maximum number of concurrent reconciles per controller. Reconciles for distinct objects run in parallel (controller-runtime still serializes per object), so a single slow or stuck reconcile cannot starve other Redis resources across namespaces.")
 	cmd.Flags().StringVar(&opts.featureGatesString, "feature-gates", envs.GetFeatureGates(), "A set of key=value pairs that describe feature gates for alpha/experimental features. "+
 		"Options are:\n  GenerateConfigInInitContainer=true|false: enables using init container for config generation"+
-		"\n  AvoidCommandLinePassword=true|false: prevents using -a <password> in redis-cli commands")
+		"\n  AvoidCommandLinePassword=true|false: deprecated, has no effect since the operator no longer runs redis-cli")
+	cmd.Flags().StringVar(&opts.tracingEndpoint, "tracing-endpoint", "", "The OTLP/HTTP endpoint URL the traces of the reconciles are exported to, e.g. 'http://otel-collector:4318/v1/traces'. "+
+		"If empty, tracing is enabled by the OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variables only.")
 	cmd.Flags().Duration(
 		operator.KubeClientTimeoutMGRFlag,
 		60*time.Second,
@@ -127,6 +140,7 @@ func runManager(opts *managerOptions) error {
 
 	monitoring.RegisterRedisReplicationMetrics()
 	monitoring.RegisterRedisClusterMetrics()
+	monitoring.RegisterRedisSentinelMetrics()
 
 	setupLog.Info("setting up v1beta2 scheme")
 	scheme.SetupV1beta2Scheme()
@@ -135,6 +149,22 @@ func runManager(opts *managerOptions) error {
 		return err
 	}
 
+	if opts.tracingEndpoint != "" || envs.IsOTLPEndpointSet() {
+		shutdown, err := tracing.Setup(context.Background(), opts.tracingEndpoint)
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			return err
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				setupLog.Error(err, "unable to flush the traces")
+			}
+		}()
+		setupLog.Info("exporting traces over OTLP")
+	}
+
 	// Config to talk to k8s api server
 	cfg := ctrl.GetConfigOrDie()
 
@@ -218,6 +248,11 @@ func createControllerOptions(opts *managerOptions) ctrl.Options {
 			options.Cache.DefaultNamespaces[ns] = cache.Config{}
 		}
 	}
+	if features.Enabled(features.DrainHandover) {
+		options.Cache.ByObject = map[client.Object]cache.ByObject{
+			&corev1.Pod{}: draincontroller.PodCache(),
+		}
+	}
 
 	return options
 }
@@ -243,29 +278,35 @@ func setupControllers(mgr ctrl.Manager, k8sClient kubernetes.Interface, maxConcu
 	healer := redis.NewHealer(k8sClient)
 
 	if err := (&rediscontroller.Reconciler{
-		Client:      mgr.GetClient(),
-		K8sClient:   k8sClient,
-		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+		Client:        mgr.GetClient(),
+		K8sClient:     k8sClient,
+		Checker:       redis.NewChecker(k8sClient),
+		Recorder:      mgr.GetEventRecorderFor("redis-controller"),
+		StatefulSet:   k8sutils.NewStatefulSetService(k8sClient),
+		SecretWatcher: intctrlutil.NewResourceWatcher(),
 	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Redis")
 		return err
 	}
 	if err := (&redisclustercontroller.Reconciler{
-		Client:      mgr.GetClient(),
-		K8sClient:   k8sClient,
-		Healer:      healer,
-		Checker:     redis.NewChecker(k8sClient),
-		Recorder:    mgr.GetEventRecorderFor("rediscluster-controller"),
-		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+		Client:        mgr.GetClient(),
+		K8sClient:     k8sClient,
+		Healer:        healer,
+		Checker:       redis.NewChecker(k8sClient),
+		Recorder:      mgr.GetEventRecorderFor("rediscluster-controller"),
+		StatefulSet:   k8sutils.NewStatefulSetService(k8sClient),
+		SecretWatcher: intctrlutil.NewResourceWatcher(),
 	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RedisCluster")
 		return err
 	}
 	if err := (&redisreplicationcontroller.Reconciler{
-		Client:      mgr.GetClient(),
-		K8sClient:   k8sClient,
-		Healer:      healer,
-		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+		Client:        mgr.GetClient(),
+		K8sClient:     k8sClient,
+		Healer:        healer,
+		Recorder:      mgr.GetEventRecorderFor("redisreplication-controller"),
+		StatefulSet:   k8sutils.NewStatefulSetService(k8sClient),
+		SecretWatcher: intctrlutil.NewResourceWatcher(),
 	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RedisReplication")
 		return err
@@ -275,11 +316,54 @@ func setupControllers(mgr ctrl.Manager, k8sClient kubernetes.Interface, maxConcu
 		Checker:            redis.NewChecker(k8sClient),
 		Healer:             healer,
 		K8sClient:          k8sClient,
+		Recorder:           mgr.GetEventRecorderFor("redissentinel-controller"),
 		ReplicationWatcher: intctrlutil.NewResourceWatcher(),
 	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RedisSentinel")
 		return err
 	}
+	if err := (&redisbackupcontroller.Reconciler{
+		Client:      mgr.GetClient(),
+		K8sClient:   k8sClient,
+		Checker:     redis.NewChecker(k8sClient),
+		Snapshotter: redis.NewSnapshotter(k8sClient),
+		Recorder:    mgr.GetEventRecorderFor("redisbackup-controller"),
+		NewStore:    objectstore.NewS3Store,
+		StreamFile:  k8sutils.StreamPodFile,
+	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RedisBackup")
+		return err
+	}
+	if err := (&redisbackupschedulecontroller.Reconciler{
+		Client:   mgr.GetClient(),
+		Recorder: mgr.GetEventRecorderFor("redisbackupschedule-controller"),
+		Now:      time.Now,
+	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RedisBackupSchedule")
+		return err
+	}
+	if err := (&redisusercontroller.Reconciler{
+		Client:        mgr.GetClient(),
+		K8sClient:     k8sClient,
+		Recorder:      mgr.GetEventRecorderFor("redisuser-controller"),
+		SecretWatcher: intctrlutil.NewResourceWatcher(),
+		ApplyUser:     k8sutils.ApplyRedisUser,
+		DeleteUser:    k8sutils.DeleteRedisUser,
+	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RedisUser")
+		return err
+	}
+	if features.Enabled(features.DrainHandover) {
+		if err := (&draincontroller.Reconciler{
+			Client:      mgr.GetClient(),
+			K8sClient:   k8sClient,
+			Recorder:    mgr.GetEventRecorderFor("redis-drain-controller"),
+			RedisClient: redisservice.NewClient(),
+		}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles}); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Drain")
+			return err
+		}
+	}
 
 	return nil
 }
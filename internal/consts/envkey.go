@@ -2,4 +2,5 @@ package consts
 
 const (
 	ENV_KEY_REDIS_MAX_MEMORY = "REDIS_MAX_MEMORY"
+	ENV_KEY_REDIS_ENGINE     = "REDIS_ENGINE"
 )
@@ -1,30 +1,142 @@
 package events
 
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// PodAnnotation is the annotation of the events naming the pod they are about.
+const PodAnnotation = "redis.opstreelabs.in/pod"
+
 const (
 	EventReasonRedisClusterDownscale = "RedisClusterDownscale"
+	EventReasonRedisBackupCompleted  = "RedisBackupCompleted"
+	EventReasonRedisBackupFailed     = "RedisBackupFailed"
+
+	EventReasonRedisBackupScheduled       = "RedisBackupScheduled"
+	EventReasonRedisBackupSkipped         = "RedisBackupSkipped"
+	EventReasonRedisBackupMissed          = "RedisBackupMissed"
+	EventReasonRedisBackupReplaced        = "RedisBackupReplaced"
+	EventReasonRedisBackupPruned          = "RedisBackupPruned"
+	EventReasonRedisBackupPruneFailed     = "RedisBackupPruneFailed"
+	EventReasonRedisBackupScheduleInvalid = "RedisBackupScheduleInvalid"
+
+	EventReasonRedisRestoreFailed   = "RedisRestoreFailed"
+	EventReasonRedisClusterRestored = "RedisClusterRestored"
+
+	EventReasonRedisClusterZoneFailover = "RedisClusterZoneFailover"
+
+	EventReasonRedisMasterHandover       = "RedisMasterHandover"
+	EventReasonRedisMasterHandoverFailed = "RedisMasterHandoverFailed"
+
+	EventReasonRedisUpgradePodRestarted = "RedisUpgradePodRestarted"
+	EventReasonRedisUpgradeFailover     = "RedisUpgradeFailover"
+	EventReasonRedisUpgradeFailed       = "RedisUpgradeFailed"
+
+	EventReasonRedisVersionChangeBlocked   = "RedisVersionChangeBlocked"
+	EventReasonRedisVersionChangeCompleted = "RedisVersionChangeCompleted"
+	EventReasonRedisConfigDeprecated       = "RedisConfigDeprecated"
+
+	EventReasonRedisPasswordRotationStarted   = "RedisPasswordRotationStarted"
+	EventReasonRedisPasswordRotationCompleted = "RedisPasswordRotationCompleted"
+	EventReasonRedisPasswordRotationFailed    = "RedisPasswordRotationFailed"
+
+	EventReasonRedisUserSynced       = "RedisUserSynced"
+	EventReasonRedisUserSyncFailed   = "RedisUserSyncFailed"
+	EventReasonRedisUserTargetAbsent = "RedisUserTargetAbsent"
+
+	EventReasonRedisTLSReloaded          = "RedisTLSReloaded"
+	EventReasonRedisTLSReloadFailed      = "RedisTLSReloadFailed"
+	EventReasonRedisTLSCertificateFailed = "RedisTLSCertificateFailed"
+
+	EventReasonRedisAnnouncedAddressRestart  = "RedisAnnouncedAddressRestart"
+	EventReasonRedisAnnouncedAddressFailover = "RedisAnnouncedAddressFailover"
+	EventReasonRedisAnnouncedAddressFailed   = "RedisAnnouncedAddressFailed"
+
+	EventReasonRedisMonitoringFailed = "RedisMonitoringFailed"
+
+	EventReasonRedisNodeAdded               = "RedisNodeAdded"
+	EventReasonRedisNodeAddFailed           = "RedisNodeAddFailed"
+	EventReasonRedisNodeRemoved             = "RedisNodeRemoved"
+	EventReasonRedisNodeRemoveFailed        = "RedisNodeRemoveFailed"
+	EventReasonRedisSlotsMoved              = "RedisSlotsMoved"
+	EventReasonRedisSlotsMoveFailed         = "RedisSlotsMoveFailed"
+	EventReasonRedisFailover                = "RedisFailover"
+	EventReasonRedisFailoverFailed          = "RedisFailoverFailed"
+	EventReasonRedisNodeReconnected         = "RedisNodeReconnected"
+	EventReasonRedisNodeReconnectFailed     = "RedisNodeReconnectFailed"
+	EventReasonRedisReplicationRelinked     = "RedisReplicationRelinked"
+	EventReasonRedisReplicationRelinkFailed = "RedisReplicationRelinkFailed"
+	EventReasonRedisReplicaAttached         = "RedisReplicaAttached"
+	EventReasonRedisReplicaAttachFailed     = "RedisReplicaAttachFailed"
+	EventReasonRedisSentinelReset           = "RedisSentinelReset"
+	EventReasonRedisSentinelResetFailed     = "RedisSentinelResetFailed"
+	EventReasonRedisPVCResized              = "RedisPVCResized"
+	EventReasonRedisPVCResizeFailed         = "RedisPVCResizeFailed"
 )
 
 type Event struct {
 	EventType string
 	Reason    string
+	Pod       string
 	Message   string
 }
 
+// Recorder collects the events of the topology changes made by a reconcile. The events are emitted on
+// the reconciled object as they are added when the Recorder has one.
 type Recorder struct {
-	events []Event
+	mu       sync.Mutex
+	events   []Event
+	recorder record.EventRecorder
+	object   runtime.Object
 }
 
 func NewRecorder() *Recorder {
 	return &Recorder{events: []Event{}}
 }
 
-func (r *Recorder) AddEvent(typ, reason, message string) {
-	if r.events == nil {
-		r.events = []Event{}
+// NewObjectRecorder returns a Recorder emitting its events on object with recorder, which may be nil.
+func NewObjectRecorder(recorder record.EventRecorder, object runtime.Object) *Recorder {
+	return &Recorder{events: []Event{}, recorder: recorder, object: object}
+}
+
+// AddEvent records an event of type typ, corev1.EventTypeNormal or corev1.EventTypeWarning, about pod.
+// The pod is set in the PodAnnotation of the event, it is empty when the event is about no pod in
+// particular.
+func (r *Recorder) AddEvent(typ, reason, pod, message string) {
+	r.mu.Lock()
+	r.events = append(r.events, Event{EventType: typ, Reason: reason, Pod: pod, Message: message})
+	r.mu.Unlock()
+	if r.recorder == nil || r.object == nil {
+		return
+	}
+	if pod == "" {
+		r.recorder.Event(r.object, typ, reason, message)
+		return
 	}
-	r.events = append(r.events, Event{EventType: typ, Reason: reason, Message: message})
+	r.recorder.AnnotatedEventf(r.object, map[string]string{PodAnnotation: pod}, typ, reason, "%s", message)
 }
 
 func (r *Recorder) Events() []Event {
-	return r.events
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event{}, r.events...)
+}
+
+type recorderKey struct{}
+
+// IntoContext returns ctx carrying r, the Recorder of the events of the reconcile run in it.
+func IntoContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// FromContext returns the Recorder of ctx, or one only collecting the events when ctx has none.
+func FromContext(ctx context.Context) *Recorder {
+	if r, ok := ctx.Value(recorderKey{}).(*Recorder); ok {
+		return r
+	}
+	return NewRecorder()
 }
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type emitted struct {
+	object      runtime.Object
+	annotations map[string]string
+	message     string
+}
+
+// fakeRecorder records the events emitted along with their annotations.
+type fakeRecorder struct {
+	events []emitted
+}
+
+func (f *fakeRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	f.AnnotatedEventf(object, nil, eventtype, reason, "%s", message)
+}
+
+func (f *fakeRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	f.AnnotatedEventf(object, nil, eventtype, reason, messageFmt, args...)
+}
+
+func (f *fakeRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	f.events = append(f.events, emitted{
+		object:      object,
+		annotations: annotations,
+		message:     fmt.Sprintf("%s %s %s", eventtype, reason, fmt.Sprintf(messageFmt, args...)),
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	fake := &fakeRecorder{}
+	object := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster"}}
+	recorder := NewObjectRecorder(fake, object)
+	ctx := IntoContext(context.Background(), recorder)
+
+	FromContext(ctx).AddEvent(corev1.EventTypeNormal, EventReasonRedisFailover, "redis-cluster-leader-1", "redis-cluster-leader-1 took over from its master")
+	FromContext(ctx).AddEvent(corev1.EventTypeWarning, EventReasonRedisSlotsMoveFailed, "", "100% done")
+
+	assert.Equal(t, []Event{
+		{EventType: corev1.EventTypeNormal, Reason: EventReasonRedisFailover, Pod: "redis-cluster-leader-1", Message: "redis-cluster-leader-1 took over from its master"},
+		{EventType: corev1.EventTypeWarning, Reason: EventReasonRedisSlotsMoveFailed, Message: "100% done"},
+	}, recorder.Events())
+	assert.Equal(t, []emitted{
+		{object: object, annotations: map[string]string{PodAnnotation: "redis-cluster-leader-1"}, message: "Normal RedisFailover redis-cluster-leader-1 took over from its master"},
+		{object: object, message: "Warning RedisSlotsMoveFailed 100% done"},
+	}, fake.events)
+}
+
+func TestFromContext_NoRecorder(t *testing.T) {
+	// The events of the functions run outside of a reconcile are dropped.
+	recorder := FromContext(context.Background())
+	recorder.AddEvent(corev1.EventTypeNormal, EventReasonRedisNodeAdded, "redis-cluster-leader-3", "Added redis-cluster-leader-3 to the cluster as an empty master")
+	assert.Len(t, recorder.Events(), 1)
+	assert.Empty(t, FromContext(context.Background()).Events())
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	rr "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -19,6 +21,32 @@ type Checker interface {
 	GetMasterFromReplication(ctx context.Context, rr *rr.RedisReplication) (corev1.Pod, error)
 	GetPassword(ctx context.Context, ns string, secret *commonapi.ExistingPasswordSecret) (string, error)
 	CheckClusterSlotsAssigned(ctx context.Context, cr *rcvb2.RedisCluster) (bool, error)
+	// GetClusterMasters returns the leader and follower pods currently acting as masters of the cluster.
+	GetClusterMasters(ctx context.Context, cr *rcvb2.RedisCluster) ([]corev1.Pod, error)
+	// GetServerInfo returns the version, memory and persistence health reported by INFO on the pod.
+	GetServerInfo(ctx context.Context, pod corev1.Pod, port, password string, tlsConfig *commonapi.TLSConfig) (*ServerInfo, error)
+	// GetSentinelViews asks every sentinel pod about the master group it monitors.
+	GetSentinelViews(ctx context.Context, rs *rsvb2.RedisSentinel) ([]SentinelView, error)
+}
+
+// SentinelView is what a single sentinel reports about the monitored master group
+type SentinelView struct {
+	Pod string
+	// Master is nil if the sentinel does not monitor the group.
+	Master *redis.SentinelMasterInfo
+	// Quorum is the result of SENTINEL CKQUORUM, QuorumMessage its reply.
+	Quorum        bool
+	QuorumMessage string
+	// Err is set if the sentinel could not be queried.
+	Err error
+}
+
+// ServerInfo is the part of INFO surfaced in the status of a resource
+type ServerInfo struct {
+	Version    string
+	UsedMemory int64
+	// PersistenceError describes the last failed RDB save or AOF write, empty when persistence is healthy.
+	PersistenceError string
 }
 
 type checker struct {
@@ -128,3 +156,87 @@ func (c *checker) CheckClusterSlotsAssigned(ctx context.Context, cr *rcvb2.Redis
 
 	return allAssigned, nil
 }
+
+func (c *checker) GetClusterMasters(ctx context.Context, cr *rcvb2.RedisCluster) ([]corev1.Pod, error) {
+	password, err := c.GetPassword(ctx, cr.Namespace, cr.Spec.KubernetesConfig.ExistingPasswordSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	var masters []corev1.Pod
+	for _, role := range []string{"leader", "follower"} {
+		for i := int32(0); i < cr.Spec.GetReplicaCounts(role); i++ {
+			podName := fmt.Sprintf("%s-%s-%d", cr.Name, role, i)
+			pod, err := c.k8s.CoreV1().Pods(cr.Namespace).Get(ctx, podName, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			connInfo := createConnectionInfo(ctx, *pod, password, cr.Spec.TLS, c.k8s, cr.Namespace, strconv.Itoa(*cr.Spec.Port))
+			isMaster, err := c.redis.Connect(connInfo).IsMaster(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if isMaster {
+				masters = append(masters, *pod)
+			}
+		}
+	}
+	return masters, nil
+}
+
+func (c *checker) GetServerInfo(ctx context.Context, pod corev1.Pod, port, password string, tlsConfig *commonapi.TLSConfig) (*ServerInfo, error) {
+	connInfo := createConnectionInfo(ctx, pod, password, tlsConfig, c.k8s, pod.Namespace, port)
+	info, err := c.redis.Connect(connInfo).GetInfo(ctx, "default")
+	if err != nil {
+		return nil, err
+	}
+	return newServerInfo(info), nil
+}
+
+func newServerInfo(info map[string]string) *ServerInfo {
+	usedMemory, _ := strconv.ParseInt(info["used_memory"], 10, 64)
+	result := &ServerInfo{
+		Version:    info["redis_version"],
+		UsedMemory: usedMemory,
+	}
+	switch {
+	case info["rdb_last_bgsave_status"] == "err":
+		result.PersistenceError = "last RDB save failed"
+	case info["aof_enabled"] == "1" && info["aof_last_write_status"] == "err":
+		result.PersistenceError = "last AOF write failed"
+	case info["aof_enabled"] == "1" && info["aof_last_bgrewrite_status"] == "err":
+		result.PersistenceError = "last AOF rewrite failed"
+	}
+	return result
+}
+
+func (c *checker) GetSentinelViews(ctx context.Context, rs *rsvb2.RedisSentinel) ([]SentinelView, error) {
+	pods, err := getSentinelPods(ctx, c.k8s, rs)
+	if err != nil {
+		return nil, err
+	}
+	password, err := c.GetPassword(ctx, rs.Namespace, rs.Spec.KubernetesConfig.ExistingPasswordSecret)
+	if err != nil {
+		return nil, err
+	}
+	masterGroupName := rs.Spec.RedisSentinelConfig.MasterGroupName
+
+	views := make([]SentinelView, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		view := SentinelView{Pod: pod.Name}
+		connInfo := createConnectionInfo(ctx, pod, password, rs.Spec.TLS, c.k8s, rs.Namespace, "26379")
+		svc := c.redis.Connect(connInfo)
+		info, err := svc.GetInfoSentinel(ctx)
+		if err == nil && info != nil {
+			for i := range info.Masters {
+				if info.Masters[i].Name == masterGroupName {
+					view.Master = &info.Masters[i]
+				}
+			}
+			view.Quorum, view.QuorumMessage, err = svc.SentinelCKQuorum(ctx, masterGroupName)
+		}
+		view.Err = err
+		views = append(views, view)
+	}
+	return views, nil
+}
@@ -0,0 +1,41 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServerInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		info map[string]string
+		want *ServerInfo
+	}{
+		{
+			name: "healthy",
+			info: map[string]string{"redis_version": "7.2.4", "used_memory": "1048576", "rdb_last_bgsave_status": "ok", "aof_enabled": "0"},
+			want: &ServerInfo{Version: "7.2.4", UsedMemory: 1048576},
+		},
+		{
+			name: "failed bgsave",
+			info: map[string]string{"redis_version": "7.2.4", "rdb_last_bgsave_status": "err"},
+			want: &ServerInfo{Version: "7.2.4", PersistenceError: "last RDB save failed"},
+		},
+		{
+			name: "failed aof write",
+			info: map[string]string{"redis_version": "7.2.4", "aof_enabled": "1", "aof_last_write_status": "err"},
+			want: &ServerInfo{Version: "7.2.4", PersistenceError: "last AOF write failed"},
+		},
+		{
+			name: "aof status ignored when disabled",
+			info: map[string]string{"redis_version": "7.2.4", "aof_enabled": "0", "aof_last_write_status": "err"},
+			want: &ServerInfo{Version: "7.2.4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, newServerInfo(tt.info))
+		})
+	}
+}
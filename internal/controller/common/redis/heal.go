@@ -109,6 +109,12 @@ func (h *healer) SentinelSet(ctx context.Context, rs *rsvb2.RedisSentinel, maste
 	if err != nil {
 		return err
 	}
+	// The auth-pass is set on every pass, so the sentinels follow a rotation of the password of the
+	// master, which the operator applies to the running servers.
+	masterPass, err := h.replicationPassword(ctx, rs)
+	if err != nil {
+		return err
+	}
 	for _, pod := range pods.Items {
 		connInfo := createConnectionInfo(ctx, pod, sentinelPass, rs.Spec.TLS, h.k8s, rs.Namespace, "26379")
 
@@ -116,6 +122,7 @@ func (h *healer) SentinelSet(ctx context.Context, rs *rsvb2.RedisSentinel, maste
 			"down-after-milliseconds": rs.Spec.RedisSentinelConfig.DownAfterMilliseconds,
 			"parallel-syncs":          rs.Spec.RedisSentinelConfig.ParallelSyncs,
 			"failover-timeout":        rs.Spec.RedisSentinelConfig.FailoverTimeout,
+			"auth-pass":               masterPass,
 		} {
 			if v == "" {
 				continue
@@ -164,15 +171,9 @@ func (h *healer) SentinelMonitor(ctx context.Context, rs *rsvb2.RedisSentinel, m
 		return err
 	}
 
-	var masterPass string
-	if rs.Spec.RedisSentinelConfig.RedisReplicationPassword != nil && rs.Spec.RedisSentinelConfig.RedisReplicationPassword.SecretKeyRef != nil {
-		masterPass, err = NewChecker(h.k8s).GetPassword(ctx, rs.Namespace, &commonapi.ExistingPasswordSecret{
-			Name: &rs.Spec.RedisSentinelConfig.RedisReplicationPassword.SecretKeyRef.Name,
-			Key:  &rs.Spec.RedisSentinelConfig.RedisReplicationPassword.SecretKeyRef.Key,
-		})
-		if err != nil {
-			return err
-		}
+	masterPass, err := h.replicationPassword(ctx, rs)
+	if err != nil {
+		return err
 	}
 
 	for _, pod := range pods.Items {
@@ -197,8 +198,25 @@ func (h *healer) SentinelMonitor(ctx context.Context, rs *rsvb2.RedisSentinel, m
 	return nil
 }
 
+// replicationPassword returns the password the sentinels of rs authenticate to the master with, empty when
+// it requires none.
+func (h *healer) replicationPassword(ctx context.Context, rs *rsvb2.RedisSentinel) (string, error) {
+	ref := rs.Spec.RedisSentinelConfig.RedisReplicationPassword
+	if ref == nil || ref.SecretKeyRef == nil {
+		return "", nil
+	}
+	return NewChecker(h.k8s).GetPassword(ctx, rs.Namespace, &commonapi.ExistingPasswordSecret{
+		Name: &ref.SecretKeyRef.Name,
+		Key:  &ref.SecretKeyRef.Key,
+	})
+}
+
 func (h *healer) getSentinelPods(ctx context.Context, rs *rsvb2.RedisSentinel) (*v1.PodList, error) {
-	sentinelSTS, err := h.k8s.AppsV1().StatefulSets(rs.Namespace).Get(ctx, rs.GetStatefulSetName(), metav1.GetOptions{})
+	return getSentinelPods(ctx, h.k8s, rs)
+}
+
+func getSentinelPods(ctx context.Context, k8s kubernetes.Interface, rs *rsvb2.RedisSentinel) (*v1.PodList, error) {
+	sentinelSTS, err := k8s.AppsV1().StatefulSets(rs.Namespace).Get(ctx, rs.GetStatefulSetName(), metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -207,7 +225,7 @@ func (h *healer) getSentinelPods(ctx context.Context, rs *rsvb2.RedisSentinel) (
 	for k, v := range sentinelSTS.Spec.Selector.MatchLabels {
 		labels = append(labels, fmt.Sprintf("%s=%s", k, v))
 	}
-	pods, err := h.k8s.CoreV1().Pods(rs.Namespace).List(ctx, metav1.ListOptions{
+	pods, err := k8s.CoreV1().Pods(rs.Namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: strings.Join(labels, ","),
 	})
 	if err != nil {
@@ -311,6 +329,7 @@ func createConnectionInfo(ctx context.Context, pod v1.Pod, password string, tlsC
 		Host:     pod.Status.PodIP,
 		Port:     port,
 		Password: password,
+		Pod:      pod.Name,
 	}
 
 	// Configure TLS if enabled
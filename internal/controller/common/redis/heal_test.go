@@ -85,6 +85,14 @@ func (f *fakeRedisService) SentinelReset(context.Context, string) error {
 	return nil
 }
 
+func (f *fakeRedisService) SentinelFailover(context.Context, string) error {
+	return nil
+}
+
+func (f *fakeRedisService) SentinelCKQuorum(context.Context, string) (bool, string, error) {
+	return true, "OK", nil
+}
+
 func (f *fakeRedisService) GetInfoSentinel(context.Context) (*redisservice.InfoSentinelResult, error) {
 	return &redisservice.InfoSentinelResult{}, nil
 }
@@ -93,6 +101,22 @@ func (f *fakeRedisService) GetClusterInfo(context.Context) (*redisservice.Cluste
 	return &redisservice.ClusterStatus{}, nil
 }
 
+func (f *fakeRedisService) GetInfo(context.Context, string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (f *fakeRedisService) BgSave(context.Context) error {
+	return nil
+}
+
+func (f *fakeRedisService) GetRDBPath(context.Context) (string, error) {
+	return "/data/dump.rdb", nil
+}
+
+func (f *fakeRedisService) GetClusterMySlots(context.Context) ([]string, error) {
+	return nil, nil
+}
+
 func newLabeledRedisPod(name string, labels map[string]string, podIP string, phase corev1.PodPhase, ready bool) *corev1.Pod {
 	podLabels := map[string]string{}
 	for key, value := range labels {
@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// SnapshotResult describes an RDB snapshot started on a pod
+type SnapshotResult struct {
+	// MasterReplOffset is the replication offset observed when BGSAVE was issued.
+	MasterReplOffset int64
+	// Slots are the slot ranges served by the node, only set in cluster mode.
+	Slots []string
+}
+
+// Snapshotter takes RDB snapshots without blocking: StartSnapshot forks the background save, and
+// SnapshotFinished is polled until it completed.
+type Snapshotter interface {
+	// StartSnapshot issues BGSAVE on the pod. It returns a nil result when the server refused to fork
+	// because another child, a running BGSAVE or AOF rewrite, is active, the call is to be retried then.
+	StartSnapshot(ctx context.Context, pod corev1.Pod, port, password string, tlsConfig *commonapi.TLSConfig) (*SnapshotResult, error)
+	// SnapshotFinished returns the path of the dump file inside the redis container once the background
+	// save of the pod finished, and an empty path while it is running. It fails if the save failed.
+	SnapshotFinished(ctx context.Context, pod corev1.Pod, port, password string, tlsConfig *commonapi.TLSConfig) (string, error)
+}
+
+type snapshotter struct {
+	redis redis.Client
+	k8s   kubernetes.Interface
+}
+
+func NewSnapshotter(clientset kubernetes.Interface) Snapshotter {
+	return &snapshotter{
+		k8s:   clientset,
+		redis: redis.NewClient(),
+	}
+}
+
+func (s *snapshotter) connect(ctx context.Context, pod corev1.Pod, port, password string, tlsConfig *commonapi.TLSConfig) redis.Service {
+	return s.redis.Connect(createConnectionInfo(ctx, pod, password, tlsConfig, s.k8s, pod.Namespace, port))
+}
+
+func (s *snapshotter) StartSnapshot(ctx context.Context, pod corev1.Pod, port, password string, tlsConfig *commonapi.TLSConfig) (*SnapshotResult, error) {
+	svc := s.connect(ctx, pod, port, password, tlsConfig)
+
+	var slots []string
+	cluster, err := svc.GetInfo(ctx, "cluster")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster info of %s: %w", pod.Name, err)
+	}
+	if cluster["cluster_enabled"] == "1" {
+		if slots, err = svc.GetClusterMySlots(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get slots of %s: %w", pod.Name, err)
+		}
+	}
+
+	replication, err := svc.GetInfo(ctx, "replication")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replication info of %s: %w", pod.Name, err)
+	}
+	offset, _ := strconv.ParseInt(replication["master_repl_offset"], 10, 64)
+	if err := svc.BgSave(ctx); err != nil {
+		if isBgSaveBusy(err) {
+			log.FromContext(ctx).V(1).Info("Background save refused, retrying later", "pod", pod.Name, "reason", err.Error())
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to start BGSAVE on %s: %w", pod.Name, err)
+	}
+	return &SnapshotResult{MasterReplOffset: offset, Slots: slots}, nil
+}
+
+func (s *snapshotter) SnapshotFinished(ctx context.Context, pod corev1.Pod, port, password string, tlsConfig *commonapi.TLSConfig) (string, error) {
+	svc := s.connect(ctx, pod, port, password, tlsConfig)
+
+	persistence, err := svc.GetInfo(ctx, "persistence")
+	if err != nil {
+		return "", fmt.Errorf("failed to get persistence info of %s: %w", pod.Name, err)
+	}
+	if persistence["rdb_bgsave_in_progress"] != "0" {
+		return "", nil
+	}
+	if status := persistence["rdb_last_bgsave_status"]; status != "ok" {
+		return "", fmt.Errorf("BGSAVE on %s failed, rdb_last_bgsave_status is %q", pod.Name, status)
+	}
+	rdbPath, err := svc.GetRDBPath(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get rdb path of %s: %w", pod.Name, err)
+	}
+	return rdbPath, nil
+}
+
+func isBgSaveBusy(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "already in progress") || strings.Contains(msg, "rewriting in progress")
+}
@@ -0,0 +1,163 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+var snapshotPod = corev1.Pod{
+	ObjectMeta: metav1.ObjectMeta{Name: "redis-0", Namespace: "default"},
+	Status:     corev1.PodStatus{PodIP: "10.0.0.10"},
+}
+
+func TestStartSnapshot(t *testing.T) {
+	tests := []struct {
+		name      string
+		bgSaveErr error
+		wantErr   bool
+		wantNil   bool
+	}{
+		{
+			name: "save forked",
+		},
+		{
+			name:      "busy with another save",
+			bgSaveErr: errors.New("ERR Background save already in progress"),
+			wantNil:   true,
+		},
+		{
+			name:      "busy with an AOF rewrite",
+			bgSaveErr: errors.New("ERR Background append only file rewriting in progress"),
+			wantNil:   true,
+		},
+		{
+			name:      "unexpected BGSAVE error",
+			bgSaveErr: errors.New("NOAUTH Authentication required"),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &snapshotRedisService{}
+			if tt.bgSaveErr != nil {
+				svc.bgSaveErrs = []error{tt.bgSaveErr}
+			}
+			s := &snapshotter{k8s: k8sfake.NewSimpleClientset(), redis: &snapshotRedisClient{svc: svc}}
+
+			result, err := s.StartSnapshot(context.Background(), snapshotPod, "6379", "", nil)
+
+			assert.Equal(t, 1, svc.saves)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, result)
+				return
+			}
+			assert.Equal(t, int64(4242), result.MasterReplOffset)
+			assert.Equal(t, []string{"0-5460"}, result.Slots)
+		})
+	}
+}
+
+func TestSnapshotFinished(t *testing.T) {
+	tests := []struct {
+		name       string
+		inProgress string
+		lastStatus string
+		wantPath   string
+		wantErr    bool
+	}{
+		{
+			name:       "save running",
+			inProgress: "1",
+			lastStatus: "ok",
+		},
+		{
+			name:       "save completed",
+			inProgress: "0",
+			lastStatus: "ok",
+			wantPath:   "/data/dump.rdb",
+		},
+		{
+			name:       "failed save is reported",
+			inProgress: "0",
+			lastStatus: "err",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &snapshotRedisService{inProgress: []string{tt.inProgress}, lastStatus: tt.lastStatus}
+			s := &snapshotter{k8s: k8sfake.NewSimpleClientset(), redis: &snapshotRedisClient{svc: svc}}
+
+			rdbPath, err := s.SnapshotFinished(context.Background(), snapshotPod, "6379", "", nil)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, rdbPath)
+		})
+	}
+}
+
+type snapshotRedisClient struct {
+	svc *snapshotRedisService
+}
+
+func (c *snapshotRedisClient) Connect(*redisservice.ConnectionInfo) redisservice.Service {
+	return c.svc
+}
+
+type snapshotRedisService struct {
+	fakeRedisService
+	bgSaveErrs []error
+	inProgress []string
+	lastStatus string
+	saves      int
+}
+
+func (s *snapshotRedisService) GetClusterMySlots(context.Context) ([]string, error) {
+	return []string{"0-5460"}, nil
+}
+
+func (s *snapshotRedisService) BgSave(context.Context) error {
+	s.saves++
+	if len(s.bgSaveErrs) > 0 {
+		err := s.bgSaveErrs[0]
+		s.bgSaveErrs = s.bgSaveErrs[1:]
+		return err
+	}
+	return nil
+}
+
+func (s *snapshotRedisService) GetInfo(_ context.Context, section string) (map[string]string, error) {
+	switch section {
+	case "replication":
+		return map[string]string{"master_repl_offset": "4242"}, nil
+	case "cluster":
+		return map[string]string{"cluster_enabled": "1"}, nil
+	}
+	inProgress := s.inProgress[0]
+	if len(s.inProgress) > 1 {
+		s.inProgress = s.inProgress[1:]
+	}
+	return map[string]string{
+		"rdb_bgsave_in_progress": inProgress,
+		"rdb_last_bgsave_status": s.lastStatus,
+	}, nil
+}
@@ -3,9 +3,72 @@ package common
 import (
 	"context"
 
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// Reasons shared by the controllers for the conditions of commonapi.ConditionedStatus
+const (
+	ReasonReady           = "Ready"
+	ReasonReconcileFailed = "ReconcileFailed"
+	ReasonRollout         = "Rollout"
+	ReasonComplete        = "Complete"
+	ReasonHealthy         = "Healthy"
+	ReasonScaling         = "Scaling"
+	ReasonStable          = "Stable"
+	ReasonInSync          = "InSync"
+	ReasonConfigSetFailed = "ConfigSetFailed"
+)
+
 func UpdateStatus(ctx context.Context, client client.Client, obj client.Object) error {
 	return client.Status().Update(ctx, obj)
 }
+
+// NewCondition returns a condition of condType that is True if value holds.
+func NewCondition(condType string, value bool, reason, message string) metav1.Condition {
+	status := metav1.ConditionFalse
+	if value {
+		status = metav1.ConditionTrue
+	}
+	return metav1.Condition{Type: condType, Status: status, Reason: reason, Message: message}
+}
+
+// SetConditions records the conditions observed for generation, keeping the transition time of the ones
+// whose status did not change, and marks the status as computed for generation.
+func SetConditions(status *commonapi.ConditionedStatus, generation int64, conditions ...metav1.Condition) {
+	status.ObservedGeneration = generation
+	for _, condition := range conditions {
+		condition.ObservedGeneration = generation
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}
+}
+
+// SetReconcileFailed records a reconcile pass that failed before the resource could be observed:
+// the resource is Degraded and no longer Ready, the other conditions are left as they were.
+func SetReconcileFailed(status *commonapi.ConditionedStatus, generation int64, err error) {
+	SetConditions(status, generation,
+		NewCondition(commonapi.ConditionReady, false, ReasonReconcileFailed, err.Error()),
+		NewCondition(commonapi.ConditionProgressing, false, ReasonReconcileFailed, err.Error()),
+		NewCondition(commonapi.ConditionDegraded, true, ReasonReconcileFailed, err.Error()),
+	)
+}
+
+// IsScaling reports whether a resource is scaling. It starts when the number of pods of a Ready resource
+// differs from the spec and lasts until the resource is Ready again with the desired number of pods,
+// so the initial creation of the pods is never reported as scaling.
+func IsScaling(status commonapi.ConditionedStatus, countsDiffer, ready bool) bool {
+	if meta.IsStatusConditionTrue(status.Conditions, commonapi.ConditionScalingInProgress) {
+		return countsDiffer || !ready
+	}
+	return countsDiffer && meta.IsStatusConditionTrue(status.Conditions, commonapi.ConditionReady)
+}
+
+// ScalingCondition returns the ScalingInProgress condition for IsScaling.
+func ScalingCondition(scaling bool, message string) metav1.Condition {
+	if scaling {
+		return NewCondition(commonapi.ConditionScalingInProgress, true, ReasonScaling, message)
+	}
+	return NewCondition(commonapi.ConditionScalingInProgress, false, ReasonStable, "The number of pods matches the spec")
+}
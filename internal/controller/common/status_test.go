@@ -0,0 +1,64 @@
+package common
+
+import (
+	"errors"
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetConditions(t *testing.T) {
+	status := commonapi.ConditionedStatus{}
+	SetConditions(&status, 1, NewCondition(commonapi.ConditionReady, true, ReasonReady, "ready"))
+	transition := meta.FindStatusCondition(status.Conditions, commonapi.ConditionReady).LastTransitionTime
+
+	SetConditions(&status, 2, NewCondition(commonapi.ConditionReady, true, ReasonReady, "still ready"))
+	ready := meta.FindStatusCondition(status.Conditions, commonapi.ConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, int64(2), status.ObservedGeneration)
+	assert.Equal(t, int64(2), ready.ObservedGeneration)
+	assert.Equal(t, "still ready", ready.Message)
+	assert.Equal(t, transition, ready.LastTransitionTime)
+
+	SetReconcileFailed(&status, 3, errors.New("boom"))
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, commonapi.ConditionReady))
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, commonapi.ConditionDegraded))
+	assert.Equal(t, "boom", meta.FindStatusCondition(status.Conditions, commonapi.ConditionDegraded).Message)
+}
+
+func TestIsScaling(t *testing.T) {
+	withConditions := func(ready, scaling bool) commonapi.ConditionedStatus {
+		status := commonapi.ConditionedStatus{}
+		SetConditions(&status, 1,
+			NewCondition(commonapi.ConditionReady, ready, ReasonReady, ""),
+			ScalingCondition(scaling, ""),
+		)
+		return status
+	}
+
+	tests := []struct {
+		name         string
+		status       commonapi.ConditionedStatus
+		countsDiffer bool
+		ready        bool
+		want         bool
+	}{
+		{name: "initial creation", status: commonapi.ConditionedStatus{}, countsDiffer: true, want: false},
+		{name: "ready resource resized", status: withConditions(true, false), countsDiffer: true, want: true},
+		{name: "ready resource unchanged", status: withConditions(true, false), ready: true, want: false},
+		{name: "new pods not ready yet", status: withConditions(false, true), want: true},
+		{name: "ready again", status: withConditions(false, true), ready: true, want: false},
+		{name: "pod restart", status: withConditions(true, false), ready: false, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsScaling(tt.status, tt.countsDiffer, tt.ready))
+		})
+	}
+
+	assert.Equal(t, metav1.ConditionTrue, ScalingCondition(true, "Scaling to 3 pods").Status)
+}
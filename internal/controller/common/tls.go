@@ -0,0 +1,46 @@
+package common
+
+import (
+	"context"
+	"strings"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ReconcileTLS requests the TLS secret of obj from cert-manager with certificate, watches it, and reloads
+// a change of its certificates on the running servers with reload. A failed reload is only reported, the
+// servers keep their certificates until the next change of the secret.
+func ReconcileTLS(ctx context.Context, recorder record.EventRecorder, watcher *intctrlutil.ResourceWatcher, obj client.Object, tlsConfig *commonapi.TLSConfig,
+	certificate func(context.Context) error, reload func(context.Context) ([]string, error),
+) (ctrl.Result, error) {
+	if tlsConfig == nil {
+		return intctrlutil.Reconciled()
+	}
+	if err := certificate(ctx); err != nil {
+		recorder.Event(obj, corev1.EventTypeWarning, events.EventReasonRedisTLSCertificateFailed, err.Error())
+		return intctrlutil.RequeueE(ctx, err, "failed to reconcile the TLS certificates")
+	}
+	watcher.Watch(ctx,
+		types.NamespacedName{Namespace: obj.GetNamespace(), Name: tlsConfig.Secret.SecretName},
+		types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()},
+	)
+
+	reloaded, err := reload(ctx)
+	if len(reloaded) > 0 {
+		recorder.Eventf(obj, corev1.EventTypeNormal, events.EventReasonRedisTLSReloaded,
+			"Reloaded the certificates of the TLS secret on %s", strings.Join(reloaded, ", "))
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to reload the certificates of the TLS secret")
+		recorder.Event(obj, corev1.EventTypeWarning, events.EventReasonRedisTLSReloadFailed, err.Error())
+	}
+	return intctrlutil.Reconciled()
+}
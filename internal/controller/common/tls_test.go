@@ -0,0 +1,54 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestReconcileTLS(t *testing.T) {
+	ctx := context.Background()
+	obj := &rvb2.Redis{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"}}
+	tlsConfig := &commonapi.TLSConfig{Secret: corev1.SecretVolumeSource{SecretName: "redis-tls"}}
+	certificate := func(context.Context) error { return nil }
+	reload := func(context.Context) ([]string, error) { return []string{"redis-0"}, nil }
+
+	t.Run("disabled", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		fail := func(context.Context) error { return errors.New("unexpected") }
+		_, err := ReconcileTLS(ctx, recorder, intctrlutil.NewResourceWatcher(), obj, nil, fail, reload)
+		assert.NoError(t, err)
+		assert.Empty(t, recorder.Events)
+	})
+
+	t.Run("reloaded", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		_, err := ReconcileTLS(ctx, recorder, intctrlutil.NewResourceWatcher(), obj, tlsConfig, certificate, reload)
+		assert.NoError(t, err)
+		assert.Contains(t, <-recorder.Events, "Reloaded the certificates of the TLS secret on redis-0")
+	})
+
+	t.Run("certificate failed", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		fail := func(context.Context) error { return errors.New("no issuer") }
+		_, err := ReconcileTLS(ctx, recorder, intctrlutil.NewResourceWatcher(), obj, tlsConfig, fail, reload)
+		assert.EqualError(t, err, "no issuer")
+		assert.Contains(t, <-recorder.Events, "no issuer")
+	})
+
+	t.Run("reload failure is only reported", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		fail := func(context.Context) ([]string, error) { return nil, errors.New("connection refused") }
+		_, err := ReconcileTLS(ctx, recorder, intctrlutil.NewResourceWatcher(), obj, tlsConfig, certificate, fail)
+		assert.NoError(t, err)
+		assert.Contains(t, <-recorder.Events, "connection refused")
+	})
+}
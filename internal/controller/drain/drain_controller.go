@@ -0,0 +1,302 @@
+package drain
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Reasons of the in-service condition of the pods
+const (
+	reasonInService           = "InService"
+	reasonReplicationLinkDown = "ReplicationLinkDown"
+	reasonUnreachable         = "Unreachable"
+	reasonDraining            = "Draining"
+)
+
+const (
+	// inServicePollInterval is how often a pod that is out of service is checked again.
+	inServicePollInterval = 10 * time.Second
+	// handoverPollInterval is how often a handover in progress is checked, it leaves a CLUSTER FAILOVER
+	// the time to complete before it is retried.
+	handoverPollInterval = 5 * time.Second
+)
+
+// Reconciler hands the master role of the RedisCluster and RedisReplication pods over to a healthy
+// replica before the pods are evicted or rolled, so the master doesn't go down with SIGTERM. It also
+// reports in the condition of the readiness gate of the pods whether their Redis server is in service,
+// which holds a rolling update until the replicas of the rolled pods are in sync.
+type Reconciler struct {
+	client.Client
+	K8sClient kubernetes.Interface
+	Recorder  record.EventRecorder
+	// RedisClient connects to the sentinels of a RedisReplication.
+	RedisClient redis.Client
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, req.NamespacedName, pod); err != nil {
+		return intctrlutil.RequeueECheck(ctx, err, "failed to get pod")
+	}
+	if !hasInServiceGate(pod) {
+		return intctrlutil.Reconciled()
+	}
+	sts, h, err := r.ownerOf(ctx, pod)
+	if err != nil {
+		return intctrlutil.RequeueECheck(ctx, err, "failed to get the owner of the pod")
+	}
+	if h == nil {
+		return intctrlutil.Reconciled()
+	}
+
+	terminating := pod.DeletionTimestamp != nil
+	if !terminating && pod.Status.PodIP == "" {
+		return intctrlutil.Reconciled()
+	}
+	if terminating {
+		if err := r.setInService(ctx, pod, reasonDraining, "The pod is terminating"); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to update the in-service condition", "Pod", pod.Name)
+		}
+	}
+	rolled := false
+	if !terminating {
+		pods := &corev1.PodList{}
+		if err := r.List(ctx, pods, client.InNamespace(sts.Namespace), client.MatchingLabels(sts.Spec.Selector.MatchLabels)); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to list the pods of the statefulset", "StatefulSet", sts.Name)
+		}
+		rolled = nextToRoll(sts, pods.Items) == pod.Name
+	}
+
+	if terminating || rolled {
+		inProgress, err := h.handover(ctx, pod)
+		if err != nil {
+			r.Recorder.Eventf(h.object(), corev1.EventTypeWarning, events.EventReasonRedisMasterHandoverFailed,
+				"Failed to hand the master role of %s over to a replica: %v", pod.Name, err)
+			return intctrlutil.RequeueE(ctx, err, "failed to hand the master role over", "Pod", pod.Name)
+		}
+		if inProgress {
+			why := "rolled"
+			if terminating {
+				why = "terminated"
+			}
+			r.Recorder.Eventf(h.object(), corev1.EventTypeNormal, events.EventReasonRedisMasterHandover,
+				"Handing the master role of %s over to a replica before the pod is %s", pod.Name, why)
+			return intctrlutil.RequeueAfter(ctx, handoverPollInterval, "waiting for the master role to be handed over", "Pod", pod.Name)
+		}
+	}
+	if terminating {
+		return intctrlutil.Reconciled()
+	}
+
+	reason, message := reasonInService, "The Redis server serves its role"
+	state, err := h.replication(ctx, pod)
+	switch {
+	case err != nil:
+		log.FromContext(ctx).V(1).Info("Failed to get the replication state of the pod", "Pod", pod.Name, "Error", err.Error())
+		reason, message = reasonUnreachable, "The Redis server is unreachable"
+	case !state.LinkUp:
+		reason, message = reasonReplicationLinkDown, "The link of the replica to its master is down"
+	}
+	if err := r.setInService(ctx, pod, reason, message); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update the in-service condition", "Pod", pod.Name)
+	}
+	if reason != reasonInService {
+		return intctrlutil.RequeueAfter(ctx, inServicePollInterval, "waiting for the pod to be in service", "Pod", pod.Name, "Reason", reason)
+	}
+	return intctrlutil.Reconciled()
+}
+
+// ownerOf returns the StatefulSet of pod along with the handover of the RedisCluster or
+// RedisReplication owning it, nil when the pod doesn't belong to either.
+func (r *Reconciler) ownerOf(ctx context.Context, pod *corev1.Pod) (*appsv1.StatefulSet, handover, error) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil || ref.Kind != "StatefulSet" {
+		return nil, nil, nil
+	}
+	sts := &appsv1.StatefulSet{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: ref.Name}, sts); err != nil {
+		return nil, nil, err
+	}
+	ref = metav1.GetControllerOf(sts)
+	if ref == nil {
+		return sts, nil, nil
+	}
+	key := types.NamespacedName{Namespace: sts.Namespace, Name: ref.Name}
+	switch ref.Kind {
+	case "RedisCluster":
+		cr := &rcvb2.RedisCluster{}
+		if err := r.Get(ctx, key, cr); err != nil {
+			return nil, nil, err
+		}
+		return sts, &clusterHandover{k8s: r.K8sClient, cr: cr}, nil
+	case "RedisReplication":
+		cr := &rrvb2.RedisReplication{}
+		if err := r.Get(ctx, key, cr); err != nil {
+			return nil, nil, err
+		}
+		return sts, &replicationHandover{k8s: r.K8sClient, redis: r.RedisClient, cr: cr}, nil
+	}
+	return sts, nil, nil
+}
+
+// setInService sets the in-service condition of pod, true when reason is reasonInService.
+func (r *Reconciler) setInService(ctx context.Context, pod *corev1.Pod, reason, message string) error {
+	patch := client.StrategicMergeFrom(pod.DeepCopy())
+	if !setInServiceCondition(pod, reason, message, metav1.Now()) {
+		return nil
+	}
+	log.FromContext(ctx).V(1).Info("Updating the in-service condition", "Pod", pod.Name, "Reason", reason)
+	return r.Status().Patch(ctx, pod, patch)
+}
+
+// setInServiceCondition sets the in-service condition of pod, true when reason is reasonInService,
+// and returns whether it changed. The transition time only moves when the status changes.
+func setInServiceCondition(pod *corev1.Pod, reason, message string, now metav1.Time) bool {
+	status := corev1.ConditionFalse
+	if reason == reasonInService {
+		status = corev1.ConditionTrue
+	}
+	for i := range pod.Status.Conditions {
+		c := &pod.Status.Conditions[i]
+		if c.Type != k8sutils.PodConditionInService {
+			continue
+		}
+		if c.Status == status && c.Reason == reason && c.Message == message {
+			return false
+		}
+		if c.Status != status {
+			c.LastTransitionTime = now
+		}
+		c.Status, c.Reason, c.Message = status, reason, message
+		return true
+	}
+	pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+		Type:               k8sutils.PodConditionInService,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: now,
+	})
+	return true
+}
+
+// hasInServiceGate returns whether the readiness of pod is gated on the in-service condition.
+func hasInServiceGate(pod *corev1.Pod) bool {
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == k8sutils.PodConditionInService {
+			return true
+		}
+	}
+	return false
+}
+
+// nextToRoll returns the pod the StatefulSet controller deletes next in the rolling update of sts: the
+// pod with the highest ordinal at or above the partition that doesn't run the update revision. It is
+// empty when the pods are up to date or the pods aren't rolled by the StatefulSet controller.
+func nextToRoll(sts *appsv1.StatefulSet, pods []corev1.Pod) string {
+	strategy := sts.Spec.UpdateStrategy
+	if strategy.Type == appsv1.OnDeleteStatefulSetStrategyType || sts.Status.UpdateRevision == "" {
+		return ""
+	}
+	var partition int32
+	if strategy.RollingUpdate != nil && strategy.RollingUpdate.Partition != nil {
+		partition = *strategy.RollingUpdate.Partition
+	}
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	next, nextOrdinal := "", int32(-1)
+	for _, pod := range pods {
+		ordinal, ok := podOrdinal(sts.Name, pod.Name)
+		if !ok || ordinal < partition || ordinal >= replicas || ordinal < nextOrdinal ||
+			pod.Labels[appsv1.ControllerRevisionHashLabelKey] == sts.Status.UpdateRevision {
+			continue
+		}
+		next, nextOrdinal = pod.Name, ordinal
+	}
+	return next
+}
+
+// podOrdinal returns the ordinal of the pod named name of the StatefulSet named sts.
+func podOrdinal(sts, name string) (int32, bool) {
+	suffix, ok := strings.CutPrefix(name, sts+"-")
+	if !ok {
+		return 0, false
+	}
+	ordinal, err := strconv.ParseInt(suffix, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(ordinal), true
+}
+
+// podsOfStatefulSet maps a StatefulSet to its pods, so a new revision hands over the next pod to roll.
+func (r *Reconciler) podsOfStatefulSet(ctx context.Context, obj client.Object) []reconcile.Request {
+	sts, ok := obj.(*appsv1.StatefulSet)
+	if !ok || sts.Spec.Selector == nil {
+		return nil
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(sts.Namespace), client.MatchingLabels(sts.Spec.Selector.MatchLabels)); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list the pods of the statefulset", "StatefulSet", sts.Name)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}})
+	}
+	return requests
+}
+
+// PodCache restricts the pods cached by the manager to the pods of the Redis resources, the drain
+// controller only watches some of them and the other controllers read pods from the API server.
+func PodCache() cache.ByObject {
+	// The requirement is valid, NewRequirement only fails on invalid keys or values.
+	setupType, _ := labels.NewRequirement("redis_setup_type", selection.Exists, nil)
+	return cache.ByObject{Label: labels.NewSelector().Add(*setupType)}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+//
+// It only watches the pods gated on the in-service condition, which the RedisCluster and
+// RedisReplication pods are when the DrainHandover feature gate is enabled.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	gated := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		pod, ok := obj.(*corev1.Pod)
+		return ok && hasInServiceGate(pod)
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("redis-drain").
+		For(&corev1.Pod{}, builder.WithPredicates(gated)).
+		Watches(&appsv1.StatefulSet{}, handler.EnqueueRequestsFromMapFunc(r.podsOfStatefulSet)).
+		WithOptions(opts).
+		Complete(tracing.Reconciler("Drain", r))
+}
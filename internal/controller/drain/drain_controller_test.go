@@ -0,0 +1,115 @@
+package drain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func revisionPod(name, revision string) corev1.Pod {
+	return corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{appsv1.ControllerRevisionHashLabelKey: revision},
+	}}
+}
+
+func Test_nextToRoll(t *testing.T) {
+	pods := []corev1.Pod{
+		revisionPod("redis-leader-0", "old"),
+		revisionPod("redis-leader-1", "old"),
+		revisionPod("redis-leader-2", "new"),
+		revisionPod("redis-leader-3", "old"),
+	}
+	tests := []struct {
+		name     string
+		strategy appsv1.StatefulSetUpdateStrategy
+		replicas int32
+		update   string
+		pods     []corev1.Pod
+		want     string
+	}{
+		{
+			name:     "highest ordinal not on the update revision",
+			replicas: 3,
+			update:   "new",
+			want:     "redis-leader-1",
+		},
+		{
+			name:     "pods beyond the replicas are scaled down, not rolled",
+			replicas: 4,
+			update:   "new",
+			want:     "redis-leader-3",
+		},
+		{
+			name: "pods below the partition are not rolled",
+			strategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: ptr.To(int32(2))},
+			},
+			replicas: 3,
+			update:   "new",
+		},
+		{
+			name:     "on delete is not rolled by the statefulset controller",
+			strategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+			replicas: 3,
+			update:   "new",
+		},
+		{
+			name:     "pods on the update revision",
+			replicas: 2,
+			update:   "new",
+			pods:     []corev1.Pod{revisionPod("redis-leader-0", "new"), revisionPod("redis-leader-1", "new")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "redis-leader"},
+				Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To(tt.replicas), UpdateStrategy: tt.strategy},
+				Status:     appsv1.StatefulSetStatus{UpdateRevision: tt.update},
+			}
+			if tt.pods == nil {
+				tt.pods = pods
+			}
+			assert.Equal(t, tt.want, nextToRoll(sts, tt.pods))
+		})
+	}
+}
+
+func Test_podOrdinal(t *testing.T) {
+	ordinal, ok := podOrdinal("redis-leader", "redis-leader-12")
+	assert.True(t, ok)
+	assert.Equal(t, int32(12), ordinal)
+	_, ok = podOrdinal("redis-leader", "redis-follower-1")
+	assert.False(t, ok)
+	_, ok = podOrdinal("redis", "redis-leader-1")
+	assert.False(t, ok)
+}
+
+func Test_setInServiceCondition(t *testing.T) {
+	pod := &corev1.Pod{}
+	start := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, setInServiceCondition(pod, reasonReplicationLinkDown, "down", start))
+	require.Len(t, pod.Status.Conditions, 1)
+	assert.Equal(t, k8sutils.PodConditionInService, pod.Status.Conditions[0].Type)
+	assert.Equal(t, corev1.ConditionFalse, pod.Status.Conditions[0].Status)
+
+	later := metav1.NewTime(start.Add(time.Minute))
+	assert.False(t, setInServiceCondition(pod, reasonReplicationLinkDown, "down", later))
+	// A new reason with the same status keeps the transition time.
+	assert.True(t, setInServiceCondition(pod, reasonUnreachable, "unreachable", later))
+	assert.Equal(t, start, pod.Status.Conditions[0].LastTransitionTime)
+
+	assert.True(t, setInServiceCondition(pod, reasonInService, "in service", later))
+	require.Len(t, pod.Status.Conditions, 1)
+	assert.Equal(t, corev1.ConditionTrue, pod.Status.Conditions[0].Status)
+	assert.Equal(t, later, pod.Status.Conditions[0].LastTransitionTime)
+}
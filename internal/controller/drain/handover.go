@@ -0,0 +1,78 @@
+package drain
+
+import (
+	"context"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// handover hands the master role of the pods of a resource over to its replicas.
+type handover interface {
+	// object returns the resource owning the pods, the events are reported on.
+	object() client.Object
+	// replication returns the replication state of the Redis server of pod.
+	replication(ctx context.Context, pod *corev1.Pod) (k8sutils.PodReplication, error)
+	// handover starts handing the master role of pod over to a replica, and returns whether it is in
+	// progress. It returns false once pod isn't a master, or when its role can't be handed over.
+	handover(ctx context.Context, pod *corev1.Pod) (bool, error)
+}
+
+// clusterHandover hands the slots of the masters of a RedisCluster over with a CLUSTER FAILOVER. A
+// cluster without followers has no replica to hand them over to.
+type clusterHandover struct {
+	k8s kubernetes.Interface
+	cr  *rcvb2.RedisCluster
+}
+
+func (h *clusterHandover) object() client.Object {
+	return h.cr
+}
+
+func (h *clusterHandover) replication(ctx context.Context, pod *corev1.Pod) (k8sutils.PodReplication, error) {
+	return k8sutils.GetRedisClusterPodReplication(ctx, h.k8s, h.cr, pod)
+}
+
+func (h *clusterHandover) handover(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	if h.cr.Spec.GetReplicaCounts("follower") == 0 {
+		return false, nil
+	}
+	replica, err := k8sutils.HandoverRedisClusterMaster(ctx, h.k8s, h.cr, pod)
+	return replica != "", err
+}
+
+// replicationHandover hands the master of a RedisReplication over with a sentinel FAILOVER. Without
+// sentinel there is no handover, the master role stays with the pod until the operator elects a new
+// master after the pod is gone.
+type replicationHandover struct {
+	k8s   kubernetes.Interface
+	redis redis.Client
+	cr    *rrvb2.RedisReplication
+}
+
+func (h *replicationHandover) object() client.Object {
+	return h.cr
+}
+
+func (h *replicationHandover) replication(ctx context.Context, pod *corev1.Pod) (k8sutils.PodReplication, error) {
+	return k8sutils.GetRedisReplicationPodReplication(ctx, h.k8s, h.cr, pod)
+}
+
+func (h *replicationHandover) handover(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	if !h.cr.EnableSentinel() || h.cr.Spec.GetReplicationCounts("replication") < 2 {
+		return false, nil
+	}
+	state, err := h.replication(ctx, pod)
+	if err != nil {
+		return false, err
+	}
+	if !state.Master {
+		return false, nil
+	}
+	return k8sutils.HandoverRedisReplicationMaster(ctx, h.k8s, h.redis, h.cr)
+}
@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reconcilePassword watches the redisSecret, and rotates a change of its password onto the running server
+// one phase per reconcile. It returns whether the rotation is still in progress.
+func (r *Reconciler) reconcilePassword(ctx context.Context, instance *rvb2.Redis) (bool, error) {
+	secret := instance.Spec.KubernetesConfig.ExistingPasswordSecret
+	if secret == nil {
+		return false, nil
+	}
+	r.SecretWatcher.Watch(ctx,
+		types.NamespacedName{Namespace: instance.Namespace, Name: *secret.Name},
+		types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name},
+	)
+
+	rotation, err := k8sutils.RotateRedisPassword(ctx, r.K8sClient, instance)
+	if rotation != nil && !equality.Semantic.DeepEqual(rotation, instance.Status.PasswordRotation) {
+		previous := instance.Status.PasswordRotation
+		instance.Status.PasswordRotation = rotation
+		if uErr := common.UpdateStatus(ctx, r.Client, instance); uErr != nil {
+			return false, uErr
+		}
+		switch {
+		case rotation.Phase == commonapi.PasswordRotationAddPassword && (previous == nil || previous.Phase == commonapi.PasswordRotationCompleted):
+			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisPasswordRotationStarted,
+				"Rotating the password of the redisSecret onto the running pod")
+		case rotation.Phase == commonapi.PasswordRotationCompleted:
+			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisPasswordRotationCompleted,
+				"The pod only accepts the new password of the redisSecret")
+		}
+	}
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisPasswordRotationFailed, err.Error())
+		return false, err
+	}
+	return rotation != nil && rotation.Phase != commonapi.PasswordRotationCompleted, nil
+}
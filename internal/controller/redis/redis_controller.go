@@ -22,14 +22,19 @@ import (
 
 	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
 	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 const (
@@ -40,7 +45,10 @@ const (
 type Reconciler struct {
 	client.Client
 	k8sutils.StatefulSet
-	K8sClient kubernetes.Interface
+	K8sClient     kubernetes.Interface
+	Checker       redis.Checker
+	Recorder      record.EventRecorder
+	SecretWatcher *intctrlutil.ResourceWatcher
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -50,6 +58,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err != nil {
 		return intctrlutil.RequeueECheck(ctx, err, "failed to get redis instance")
 	}
+	ctx = events.IntoContext(ctx, events.NewObjectRecorder(r.Recorder, instance))
 	if instance.GetDeletionTimestamp() != nil {
 		if err = k8sutils.HandleRedisFinalizer(ctx, r.Client, instance, RedisFinalizer); err != nil {
 			return intctrlutil.RequeueE(ctx, err, "failed to handle redis finalizer")
@@ -62,43 +71,88 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err = k8sutils.AddFinalizer(ctx, instance, RedisFinalizer, r.Client); err != nil {
 		return intctrlutil.RequeueE(ctx, err, "failed to add finalizer")
 	}
-	err = k8sutils.CreateStandaloneRedis(ctx, instance, r.K8sClient)
+	restore, err := k8sutils.ResolveRestoreSource(ctx, r.Client, r.K8sClient, instance.Namespace, instance.Name, instance.Spec.RestoreFrom, instance.Spec.AOFArchive, 1)
 	if err != nil {
-		return intctrlutil.RequeueE(ctx, err, "failed to create redis")
+		return r.fail(ctx, instance, err, "failed to resolve restoreFrom")
+	}
+	// Rotate a change of the password of the redisSecret onto the running server before anything else
+	// connects to it with it.
+	if rotating, err := r.reconcilePassword(ctx, instance); err != nil {
+		return r.fail(ctx, instance, err, "failed to rotate the password")
+	} else if rotating {
+		return intctrlutil.RequeueAfter(ctx, time.Second, "rotating the password", "Phase", instance.Status.PasswordRotation.Phase)
+	}
+	// Check a change of the Redis version before the StatefulSet rolls it out, and halt it once rolled
+	// out when the upgraded pod can't start.
+	if err = r.reconcileVersion(ctx, instance); err != nil {
+		return r.fail(ctx, instance, err, "version change blocked")
+	}
+	if _, err = r.reconcileTLS(ctx, instance); err != nil {
+		return r.fail(ctx, instance, err, "failed to reconcile the TLS certificates")
+	}
+	if err = k8sutils.CreateOrUpdateRedisMonitoring(ctx, r.Client, instance); err != nil {
+		log.FromContext(ctx).Error(err, "failed to reconcile the monitoring of the exporter")
+	}
+	err = k8sutils.CreateStandaloneRedis(ctx, instance, r.K8sClient, restore)
+	if err != nil {
+		return r.fail(ctx, instance, err, "failed to create redis")
 	}
 	err = k8sutils.CreateStandaloneService(ctx, instance, r.K8sClient)
 	if err != nil {
-		return intctrlutil.RequeueE(ctx, err, "failed to create service")
+		return r.fail(ctx, instance, err, "failed to create service")
 	}
 
+	obs := observation{
+		stsReady:      r.IsStatefulSetReady(ctx, instance.Namespace, instance.Name),
+		configApplied: true,
+	}
 	if len(instance.Spec.GetRedisDynamicConfig()) > 0 {
-		if !r.IsStatefulSetReady(ctx, instance.Namespace, instance.Name) {
-			return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for redis statefulset to be ready before applying dynamic config")
-		}
-		applied, err := k8sutils.SetRedisStandaloneDynamicConfig(ctx, r.K8sClient, instance)
-		if err != nil {
-			return intctrlutil.RequeueE(ctx, err, "failed to set dynamic config")
-		}
-		if !applied {
-			return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for redis to become reachable to apply dynamic config")
+		obs.configApplied = false
+		if obs.stsReady {
+			obs.configApplied, obs.configErr = k8sutils.SetRedisStandaloneDynamicConfig(ctx, r.K8sClient, instance)
 		}
 	}
-	return intctrlutil.Reconciled()
+	if obs.stsReady {
+		obs.info, obs.infoErr = r.serverInfo(ctx, instance)
+	}
+	if err = r.updateStatus(ctx, instance, obs); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update redis status")
+	}
+
+	if obs.configErr != nil {
+		return intctrlutil.RequeueE(ctx, obs.configErr, "failed to set dynamic config")
+	}
+	if !obs.stsReady && !obs.configApplied {
+		return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for redis statefulset to be ready before applying dynamic config")
+	}
+	if !obs.configApplied {
+		return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for redis to become reachable to apply dynamic config")
+	}
+	if obs.stsReady && obs.infoErr != nil {
+		return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for redis to become reachable")
+	}
+	return intctrlutil.RequeueAfter(ctx, statusRefreshInterval, "")
+}
+
+// fail records err in the status before requeueing.
+func (r *Reconciler) fail(ctx context.Context, instance *rvb2.Redis, err error, msg string) (ctrl.Result, error) {
+	if statusErr := r.updateStatus(ctx, instance, observation{err: err}); statusErr != nil {
+		log.FromContext(ctx).Error(statusErr, "failed to update redis status")
+	}
+	return intctrlutil.RequeueE(ctx, err, msg)
 }
 
 // SetupWithManager sets up the controller with the Manager.
 //
 // Unlike RedisCluster, RedisReplication, and RedisSentinel controllers, the Redis standalone
-// controller does not require periodic requeue. Those controllers need timed requeues to
-// continuously monitor cluster topology, replication health, slot distribution, and sentinel
-// readiness — state that can change independently of Kubernetes resource events. The standalone
-// controller only creates a StatefulSet and a Service with no ongoing distributed state to poll,
-// so a timed requeue is unnecessary.
+// controller has no distributed state to repair. It only requeues periodically to refresh the
+// version and memory usage reported in its status.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&rvb2.Redis{}).
 		WithOptions(opts).
 		Owns(&corev1.Service{}).
 		Owns(&appsv1.StatefulSet{}).
-		Complete(r)
+		WatchesMetadata(&corev1.Secret{}, r.SecretWatcher).
+		Complete(tracing.Reconciler("Redis", r))
 }
@@ -23,6 +23,8 @@ import (
 	"time"
 
 	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -95,9 +97,11 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&Reconciler{
-		Client:      k8sManager.GetClient(),
-		K8sClient:   k8sClient,
-		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+		Client:        k8sManager.GetClient(),
+		K8sClient:     k8sClient,
+		Checker:       redis.NewChecker(k8sClient),
+		StatefulSet:   k8sutils.NewStatefulSetService(k8sClient),
+		SecretWatcher: intctrlutil.NewResourceWatcher(),
 	}).SetupWithManager(k8sManager, controller.Options{})
 	Expect(err).ToNot(HaveOccurred())
 
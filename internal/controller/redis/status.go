@@ -0,0 +1,126 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// statusRefreshInterval is how often the version and memory usage reported in the status are refreshed.
+const statusRefreshInterval = time.Minute
+
+// observation is what a reconcile pass found out about the instance.
+type observation struct {
+	// err is set when the StatefulSet or the services could not be reconciled.
+	err           error
+	stsReady      bool
+	configApplied bool
+	configErr     error
+	info          *redis.ServerInfo
+	infoErr       error
+}
+
+// newStatus derives the status from an observation, keeping the transition times of unchanged conditions
+// and the last known version and memory usage while the server can't be queried.
+func newStatus(instance *rvb2.Redis, obs observation) rvb2.RedisStatus {
+	status := *instance.Status.DeepCopy()
+	status.ConnectionInfo = instance.GetConnectionInfo(envs.GetServiceDNSDomain())
+	if obs.info != nil {
+		status.RedisVersion = obs.info.Version
+		status.UsedMemory = obs.info.UsedMemory
+	}
+
+	ready := metav1.Condition{Type: rvb2.RedisConditionReady, Status: metav1.ConditionTrue, Reason: "Ready", Message: "Redis is ready"}
+	switch {
+	case obs.err != nil:
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, "ReconcileFailed", obs.err.Error()
+	case !obs.stsReady:
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, "PodNotReady", "Waiting for the StatefulSet to be ready"
+	case obs.infoErr != nil:
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, "Unreachable", obs.infoErr.Error()
+	}
+
+	config := metav1.Condition{Type: rvb2.RedisConditionConfigApplied, Status: metav1.ConditionTrue, Reason: "Applied", Message: "Dynamic config is applied"}
+	switch {
+	case len(instance.Spec.GetRedisDynamicConfig()) == 0:
+		config.Reason, config.Message = "NoDynamicConfig", "No dynamic config is set"
+	case obs.configErr != nil:
+		config.Status, config.Reason, config.Message = metav1.ConditionFalse, "ConfigSetFailed", obs.configErr.Error()
+	case !obs.configApplied:
+		config.Status, config.Reason, config.Message = metav1.ConditionFalse, "Pending", "Waiting for Redis to become reachable"
+	}
+
+	persistence := metav1.Condition{Type: rvb2.RedisConditionPersistenceHealthy, Status: metav1.ConditionTrue, Reason: "Healthy", Message: "Last RDB save and AOF write succeeded"}
+	switch {
+	case obs.info == nil:
+		persistence.Status, persistence.Reason, persistence.Message = metav1.ConditionUnknown, "NotObserved", "Redis can't be queried"
+	case obs.info.PersistenceError != "":
+		persistence.Status, persistence.Reason, persistence.Message = metav1.ConditionFalse, "PersistenceFailed", obs.info.PersistenceError
+	}
+
+	progressing := common.NewCondition(commonapi.ConditionProgressing, false, common.ReasonComplete, "The spec is rolled out")
+	degraded := common.NewCondition(commonapi.ConditionDegraded, false, common.ReasonHealthy, "Redis is healthy")
+	switch {
+	case obs.err != nil:
+		status.Phase = rvb2.RedisFailed
+		progressing.Reason, progressing.Message = ready.Reason, ready.Message
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, ready.Reason, ready.Message
+	case obs.configErr != nil:
+		status.Phase = rvb2.RedisFailed
+		progressing.Reason, progressing.Message = config.Reason, config.Message
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, config.Reason, config.Message
+	case ready.Status == metav1.ConditionTrue && config.Status == metav1.ConditionTrue:
+		status.Phase = rvb2.RedisReady
+	default:
+		status.Phase = rvb2.RedisInitializing
+		progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionTrue, ready.Reason, ready.Message
+		if ready.Status == metav1.ConditionTrue {
+			progressing.Reason, progressing.Message = config.Reason, config.Message
+		}
+	}
+	if persistence.Status == metav1.ConditionFalse && degraded.Status == metav1.ConditionFalse {
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, persistence.Reason, persistence.Message
+	}
+
+	drift := common.NewCondition(commonapi.ConditionConfigDrift, config.Status == metav1.ConditionFalse, config.Reason, config.Message)
+	if drift.Status == metav1.ConditionFalse {
+		drift.Reason, drift.Message = common.ReasonInSync, "The running config matches the spec"
+	}
+
+	common.SetConditions(&status.ConditionedStatus, instance.Generation,
+		ready, config, persistence, progressing, degraded, drift,
+		// A standalone instance always runs a single pod.
+		common.ScalingCondition(false, ""),
+	)
+	return status
+}
+
+// serverInfo queries INFO on the pod of the instance.
+func (r *Reconciler) serverInfo(ctx context.Context, instance *rvb2.Redis) (*redis.ServerInfo, error) {
+	pod, err := r.K8sClient.CoreV1().Pods(instance.Namespace).Get(ctx, instance.Name+"-0", metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	password, err := r.Checker.GetPassword(ctx, instance.Namespace, instance.Spec.KubernetesConfig.ExistingPasswordSecret)
+	if err != nil {
+		return nil, err
+	}
+	return r.Checker.GetServerInfo(ctx, *pod, "6379", password, instance.Spec.TLS)
+}
+
+// updateStatus writes the status derived from obs, skipping the write if nothing changed.
+func (r *Reconciler) updateStatus(ctx context.Context, instance *rvb2.Redis, obs observation) error {
+	status := newStatus(instance, obs)
+	if equality.Semantic.DeepEqual(instance.Status, status) {
+		return nil
+	}
+	instance.Status = status
+	return common.UpdateStatus(ctx, r.Client, instance)
+}
@@ -0,0 +1,176 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+
+	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewStatus(t *testing.T) {
+	instance := &rvb2.Redis{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default", Generation: 3}}
+	withDynamicConfig := instance.DeepCopy()
+	withDynamicConfig.Spec.RedisConfig = &common.RedisConfig{DynamicConfig: []string{"maxmemory 100mb"}}
+	healthy := &redis.ServerInfo{Version: "7.2.4", UsedMemory: 1048576}
+
+	tests := []struct {
+		name        string
+		instance    *rvb2.Redis
+		obs         observation
+		phase       rvb2.RedisPhase
+		ready       metav1.ConditionStatus
+		config      metav1.ConditionStatus
+		persistence metav1.ConditionStatus
+	}{
+		{
+			name:        "ready",
+			instance:    instance,
+			obs:         observation{stsReady: true, configApplied: true, info: healthy},
+			phase:       rvb2.RedisReady,
+			ready:       metav1.ConditionTrue,
+			config:      metav1.ConditionTrue,
+			persistence: metav1.ConditionTrue,
+		},
+		{
+			name:        "statefulset not ready",
+			instance:    withDynamicConfig,
+			obs:         observation{},
+			phase:       rvb2.RedisInitializing,
+			ready:       metav1.ConditionFalse,
+			config:      metav1.ConditionFalse,
+			persistence: metav1.ConditionUnknown,
+		},
+		{
+			name:        "dynamic config rejected",
+			instance:    withDynamicConfig,
+			obs:         observation{stsReady: true, configApplied: true, configErr: errors.New("ERR unknown option"), info: healthy},
+			phase:       rvb2.RedisFailed,
+			ready:       metav1.ConditionTrue,
+			config:      metav1.ConditionFalse,
+			persistence: metav1.ConditionTrue,
+		},
+		{
+			name:        "failed bgsave",
+			instance:    instance,
+			obs:         observation{stsReady: true, configApplied: true, info: &redis.ServerInfo{Version: "7.2.4", PersistenceError: "last RDB save failed"}},
+			phase:       rvb2.RedisReady,
+			ready:       metav1.ConditionTrue,
+			config:      metav1.ConditionTrue,
+			persistence: metav1.ConditionFalse,
+		},
+		{
+			name:        "reconcile error",
+			instance:    instance,
+			obs:         observation{err: errors.New("statefulset is invalid")},
+			phase:       rvb2.RedisFailed,
+			ready:       metav1.ConditionFalse,
+			config:      metav1.ConditionTrue,
+			persistence: metav1.ConditionUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := newStatus(tt.instance, tt.obs)
+			assert.Equal(t, tt.phase, status.Phase)
+			assert.Equal(t, int64(3), status.ObservedGeneration)
+			assert.Equal(t, "redis.default.svc.cluster.local", status.ConnectionInfo.Host)
+			assert.Equal(t, 6379, status.ConnectionInfo.Port)
+			for condType, want := range map[string]metav1.ConditionStatus{
+				rvb2.RedisConditionReady:              tt.ready,
+				rvb2.RedisConditionConfigApplied:      tt.config,
+				rvb2.RedisConditionPersistenceHealthy: tt.persistence,
+			} {
+				condition := meta.FindStatusCondition(status.Conditions, condType)
+				require.NotNil(t, condition, condType)
+				assert.Equal(t, want, condition.Status, condType)
+				assert.Equal(t, int64(3), condition.ObservedGeneration)
+			}
+		})
+	}
+
+	t.Run("keeps the last known version while unreachable", func(t *testing.T) {
+		ready := instance.DeepCopy()
+		ready.Status = newStatus(ready, observation{stsReady: true, configApplied: true, info: healthy})
+		transition := meta.FindStatusCondition(ready.Status.Conditions, rvb2.RedisConditionReady).LastTransitionTime
+
+		status := newStatus(ready, observation{stsReady: true, configApplied: true, infoErr: errors.New("connection refused")})
+		assert.Equal(t, rvb2.RedisInitializing, status.Phase)
+		assert.Equal(t, "7.2.4", status.RedisVersion)
+		assert.Equal(t, int64(1048576), status.UsedMemory)
+		assert.Equal(t, "connection refused", meta.FindStatusCondition(status.Conditions, rvb2.RedisConditionReady).Message)
+
+		again := newStatus(ready, observation{stsReady: true, configApplied: true, info: healthy})
+		assert.Equal(t, transition, meta.FindStatusCondition(again.Conditions, rvb2.RedisConditionReady).LastTransitionTime)
+	})
+}
+
+func TestNewStatusSharedConditions(t *testing.T) {
+	instance := &rvb2.Redis{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default", Generation: 2}}
+	withDynamicConfig := instance.DeepCopy()
+	withDynamicConfig.Spec.RedisConfig = &common.RedisConfig{DynamicConfig: []string{"maxmemory 100mb"}}
+	healthy := &redis.ServerInfo{Version: "7.2.4"}
+
+	tests := []struct {
+		name        string
+		instance    *rvb2.Redis
+		obs         observation
+		progressing metav1.ConditionStatus
+		degraded    metav1.ConditionStatus
+		drift       metav1.ConditionStatus
+	}{
+		{
+			name:        "ready",
+			instance:    withDynamicConfig,
+			obs:         observation{stsReady: true, configApplied: true, info: healthy},
+			progressing: metav1.ConditionFalse,
+			degraded:    metav1.ConditionFalse,
+			drift:       metav1.ConditionFalse,
+		},
+		{
+			name:        "rolling out",
+			instance:    instance,
+			obs:         observation{},
+			progressing: metav1.ConditionTrue,
+			degraded:    metav1.ConditionFalse,
+			drift:       metav1.ConditionFalse,
+		},
+		{
+			name:        "dynamic config rejected",
+			instance:    withDynamicConfig,
+			obs:         observation{stsReady: true, configErr: errors.New("ERR unknown option"), info: healthy},
+			progressing: metav1.ConditionFalse,
+			degraded:    metav1.ConditionTrue,
+			drift:       metav1.ConditionTrue,
+		},
+		{
+			name:        "failed bgsave",
+			instance:    instance,
+			obs:         observation{stsReady: true, configApplied: true, info: &redis.ServerInfo{PersistenceError: "last RDB save failed"}},
+			progressing: metav1.ConditionFalse,
+			degraded:    metav1.ConditionTrue,
+			drift:       metav1.ConditionFalse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := newStatus(tt.instance, tt.obs)
+			for condType, want := range map[string]metav1.ConditionStatus{
+				common.ConditionProgressing:       tt.progressing,
+				common.ConditionDegraded:          tt.degraded,
+				common.ConditionConfigDrift:       tt.drift,
+				common.ConditionScalingInProgress: metav1.ConditionFalse,
+			} {
+				condition := meta.FindStatusCondition(status.Conditions, condType)
+				require.NotNil(t, condition, condType)
+				assert.Equal(t, want, condition.Status, condType)
+				assert.Equal(t, int64(2), condition.ObservedGeneration)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package redis
+
+import (
+	"context"
+
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// reconcileVersion checks a change of the Redis version before the StatefulSet rolls it out, records the
+// current and target versions, and returns an error while the change fails its checks.
+func (r *Reconciler) reconcileVersion(ctx context.Context, instance *rvb2.Redis) error {
+	check, err := k8sutils.CheckRedisVersion(ctx, r.K8sClient, instance)
+	for _, message := range check.Deprecated {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisConfigDeprecated, message)
+	}
+	if check.Status != instance.Status.VersionStatus {
+		instance.Status.VersionStatus = check.Status
+		if uErr := common.UpdateStatus(ctx, r.Client, instance); uErr != nil {
+			return uErr
+		}
+	}
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisVersionChangeBlocked, err.Error())
+		return err
+	}
+	if check.Completed {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonRedisVersionChangeCompleted,
+			"Every pod runs %s", check.Status.CurrentVersion)
+	}
+	return nil
+}
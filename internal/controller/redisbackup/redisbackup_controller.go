@@ -0,0 +1,367 @@
+package redisbackup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/objectstore"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	RedisBackupFinalizer = "redisBackupFinalizer"
+
+	// snapshotPollInterval is how often a backup checks whether the background save of a pod finished.
+	snapshotPollInterval = 5 * time.Second
+	// snapshotTimeout bounds the wait for the background save of a pod, including the time another
+	// child process of the server keeps it from forking.
+	snapshotTimeout = 30 * time.Minute
+)
+
+// Reconciler reconciles a RedisBackup object
+type Reconciler struct {
+	client.Client
+	K8sClient   kubernetes.Interface
+	Checker     redis.Checker
+	Snapshotter redis.Snapshotter
+	Recorder    record.EventRecorder
+	// NewStore opens the object storage of a backup, overridable in tests.
+	NewStore func(cfg objectstore.Config) (objectstore.Store, error)
+	// StreamFile copies a file out of a pod, overridable in tests.
+	StreamFile func(ctx context.Context, client kubernetes.Interface, pod *corev1.Pod, path string, w io.Writer) error
+}
+
+// backupSource is a resolved backup target: the masters to snapshot and how to talk to them
+type backupSource struct {
+	masters []corev1.Pod
+	// statefulSets own the data PVCs of the target.
+	statefulSets []string
+	port         string
+	password     string
+	tlsConfig    *commonapi.TLSConfig
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	instance := &rbvb2.RedisBackup{}
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		return intctrlutil.RequeueECheck(ctx, err, "failed to get RedisBackup instance")
+	}
+	if k8sutils.IsDeleted(instance) {
+		return r.finalize(ctx, instance)
+	}
+	if common.ShouldSkipReconcile(ctx, instance) {
+		return intctrlutil.Reconciled()
+	}
+	// Finished backups get the finalizer too, their uploads are deleted with them.
+	if instance.Spec.Storage.S3 != nil {
+		if err := k8sutils.AddFinalizer(ctx, instance, RedisBackupFinalizer, r.Client); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to add finalizer")
+		}
+	}
+	if instance.Status.IsFinished() {
+		return intctrlutil.Reconciled()
+	}
+
+	// The backup progresses one pod at a time and records each step in its status, so a
+	// backup left Running by a restarted operator resumes where it stopped.
+	if instance.Status.Phase != rbvb2.RedisBackupRunning {
+		instance.Status.Phase = rbvb2.RedisBackupRunning
+		instance.Status.StartTime = &metav1.Time{Time: metav1.Now().Time}
+		if err := common.UpdateStatus(ctx, r.Client, instance); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to mark RedisBackup as running")
+		}
+	}
+
+	if instance.Spec.Storage.VolumeSnapshot != nil {
+		return r.reconcileVolumeSnapshots(ctx, instance)
+	}
+
+	return r.reconcileUploads(ctx, instance)
+}
+
+// finish moves the backup to its terminal phase, Failed if err is set and Completed otherwise.
+func (r *Reconciler) finish(ctx context.Context, instance *rbvb2.RedisBackup, err error, completedMsg string) (ctrl.Result, error) {
+	instance.Status.Save = nil
+	if err != nil {
+		// Only the shards backed up are kept, they are the artifacts to prune with the backup.
+		instance.Status.Shards = slices.DeleteFunc(instance.Status.Shards, func(shard rbvb2.BackupShard) bool {
+			return shard.ObjectKey == "" && shard.VolumeSnapshot == ""
+		})
+		log.FromContext(ctx).Error(err, "RedisBackup failed")
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisBackupFailed, err.Error())
+		instance.Status.Phase = rbvb2.RedisBackupFailed
+		instance.Status.Reason = err.Error()
+	} else {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisBackupCompleted, completedMsg)
+		instance.Status.Phase = rbvb2.RedisBackupCompleted
+		instance.Status.Reason = ""
+	}
+	instance.Status.CompletionTime = &metav1.Time{Time: metav1.Now().Time}
+	if err := common.UpdateStatus(ctx, r.Client, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisBackup status")
+	}
+	return intctrlutil.Reconciled()
+}
+
+// reconcileUploads snapshots the masters of the target one after the other and uploads their dump
+// files. Each reconcile makes a single step, so no worker is held while a pod saves its dataset.
+func (r *Reconciler) reconcileUploads(ctx context.Context, instance *rbvb2.RedisBackup) (ctrl.Result, error) {
+	source, err := r.resolveSource(ctx, instance)
+	if err != nil {
+		return r.finish(ctx, instance, err, "")
+	}
+	if err := recordShards(instance, source); err != nil {
+		return r.finish(ctx, instance, err, "")
+	}
+	idx := slices.IndexFunc(instance.Status.Shards, func(shard rbvb2.BackupShard) bool { return shard.ObjectKey == "" })
+	if idx < 0 {
+		return r.finish(ctx, instance, nil, fmt.Sprintf("Uploaded %d RDB snapshot(s) to bucket %s", len(instance.Status.Shards), instance.Spec.Storage.S3.Bucket))
+	}
+	shard := &instance.Status.Shards[idx]
+	pod, err := r.K8sClient.CoreV1().Pods(instance.Namespace).Get(ctx, shard.Pod, metav1.GetOptions{})
+	if err != nil {
+		return r.finish(ctx, instance, err, "")
+	}
+	rdbPath, err := r.save(ctx, instance, pod, source)
+	if err != nil {
+		return r.finish(ctx, instance, err, "")
+	}
+	if rdbPath == "" {
+		return r.waitForSave(ctx, instance)
+	}
+
+	store, err := r.openStore(ctx, instance)
+	if err != nil {
+		return r.finish(ctx, instance, err, "")
+	}
+	key := instance.Spec.Storage.S3.ObjectKey(instance.Namespace, instance.Name, pod.Name+path.Ext(rdbPath))
+	size, err := r.upload(ctx, store, pod, rdbPath, key)
+	if err != nil {
+		return r.finish(ctx, instance, err, "")
+	}
+	log.FromContext(ctx).Info("Uploaded RDB snapshot", "pod", pod.Name, "key", key, "size", size)
+	shard.ObjectKey = key
+	shard.Size = size
+	shard.MasterReplOffset = instance.Status.Save.MasterReplOffset
+	shard.Slots = instance.Status.Save.Slots
+	instance.Status.Save = nil
+	if err := common.UpdateStatus(ctx, r.Client, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to record uploaded RDB snapshot")
+	}
+	return intctrlutil.Requeue()
+}
+
+// recordShards records a shard per master of the target on the first pass. The masters are not
+// resolved again afterwards, so a failover during the backup does not back up a shard twice.
+func recordShards(rb *rbvb2.RedisBackup, source *backupSource) error {
+	if len(rb.Status.Shards) > 0 {
+		return nil
+	}
+	if len(source.masters) == 0 {
+		return fmt.Errorf("no master found for %s %s", rb.Spec.Target.Kind, rb.Spec.Target.Name)
+	}
+	for i := range source.masters {
+		rb.Status.Shards = append(rb.Status.Shards, rbvb2.BackupShard{Pod: source.masters[i].Name})
+	}
+	return nil
+}
+
+// save advances the background save of pod by one step and records its progress in the status. It
+// returns the path of the dump file once the save finished, and an empty path while it runs.
+func (r *Reconciler) save(ctx context.Context, rb *rbvb2.RedisBackup, pod *corev1.Pod, source *backupSource) (string, error) {
+	save := rb.Status.Save
+	if save == nil || save.Pod != pod.Name {
+		save = &rbvb2.BackgroundSave{Pod: pod.Name, StartTime: metav1.Now()}
+		rb.Status.Save = save
+	}
+	if save.Started {
+		rdbPath, err := r.Snapshotter.SnapshotFinished(ctx, *pod, source.port, source.password, source.tlsConfig)
+		if err != nil || rdbPath != "" {
+			return rdbPath, err
+		}
+	} else {
+		result, err := r.Snapshotter.StartSnapshot(ctx, *pod, source.port, source.password, source.tlsConfig)
+		if err != nil {
+			return "", err
+		}
+		if result != nil {
+			save.Started = true
+			save.MasterReplOffset = result.MasterReplOffset
+			save.Slots = result.Slots
+		}
+	}
+	if time.Since(save.StartTime.Time) > snapshotTimeout {
+		return "", fmt.Errorf("background save of %s did not finish within %s", pod.Name, snapshotTimeout)
+	}
+	return "", nil
+}
+
+// waitForSave records the progress of the background save and requeues the backup until it finished.
+func (r *Reconciler) waitForSave(ctx context.Context, rb *rbvb2.RedisBackup) (ctrl.Result, error) {
+	if err := common.UpdateStatus(ctx, r.Client, rb); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to record the background save of the RedisBackup")
+	}
+	return intctrlutil.RequeueAfter(ctx, snapshotPollInterval, "waiting for the background save", "pod", rb.Status.Save.Pod)
+}
+
+// upload pipes the file out of the pod straight into the bucket without buffering it on disk.
+func (r *Reconciler) upload(ctx context.Context, store objectstore.Store, pod *corev1.Pod, rdbPath, key string) (int64, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(r.StreamFile(ctx, r.K8sClient, pod, rdbPath, pw))
+	}()
+	size, err := store.Upload(ctx, key, pr)
+	// Unblock the exec stream if the upload gave up early.
+	pr.CloseWithError(err)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upload %s to %s: %w", rdbPath, key, err)
+	}
+	return size, nil
+}
+
+func (r *Reconciler) resolveSource(ctx context.Context, rb *rbvb2.RedisBackup) (*backupSource, error) {
+	key := types.NamespacedName{Namespace: rb.Namespace, Name: rb.Spec.Target.Name}
+	switch rb.Spec.Target.Kind {
+	case rbvb2.TargetKindRedis:
+		cr := &rvb2.Redis{}
+		if err := r.Get(ctx, key, cr); err != nil {
+			return nil, err
+		}
+		pod, err := r.K8sClient.CoreV1().Pods(cr.Namespace).Get(ctx, cr.Name+"-0", metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return r.newSource(ctx, cr.Namespace, []corev1.Pod{*pod}, []string{cr.Name}, "6379", &cr.Spec.KubernetesConfig, cr.Spec.TLS)
+	case rbvb2.TargetKindRedisReplication:
+		cr := &rrvb2.RedisReplication{}
+		if err := r.Get(ctx, key, cr); err != nil {
+			return nil, err
+		}
+		master, err := r.Checker.GetMasterFromReplication(ctx, cr)
+		if err != nil {
+			return nil, err
+		}
+		var masters []corev1.Pod
+		if master.Name != "" {
+			masters = append(masters, master)
+		}
+		return r.newSource(ctx, cr.Namespace, masters, []string{cr.Name}, "6379", &cr.Spec.KubernetesConfig, cr.Spec.TLS)
+	case rbvb2.TargetKindRedisCluster:
+		cr := &rcvb2.RedisCluster{}
+		if err := r.Get(ctx, key, cr); err != nil {
+			return nil, err
+		}
+		masters, err := r.Checker.GetClusterMasters(ctx, cr)
+		if err != nil {
+			return nil, err
+		}
+		return r.newSource(ctx, cr.Namespace, masters, []string{cr.Name + "-leader", cr.Name + "-follower"}, strconv.Itoa(*cr.Spec.Port), &cr.Spec.KubernetesConfig, cr.Spec.TLS)
+	default:
+		return nil, fmt.Errorf("unsupported backup target kind %q", rb.Spec.Target.Kind)
+	}
+}
+
+func (r *Reconciler) newSource(ctx context.Context, ns string, masters []corev1.Pod, statefulSets []string, port string, k8sConfig *commonapi.KubernetesConfig, tlsConfig *commonapi.TLSConfig) (*backupSource, error) {
+	password, err := r.Checker.GetPassword(ctx, ns, k8sConfig.ExistingPasswordSecret)
+	if err != nil {
+		return nil, err
+	}
+	return &backupSource{
+		masters:      masters,
+		statefulSets: statefulSets,
+		port:         port,
+		password:     password,
+		tlsConfig:    tlsConfig,
+	}, nil
+}
+
+func (r *Reconciler) openStore(ctx context.Context, rb *rbvb2.RedisBackup) (objectstore.Store, error) {
+	secret, err := r.K8sClient.CoreV1().Secrets(rb.Namespace).Get(ctx, rb.Spec.Storage.S3.CredentialsSecret, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return r.NewStore(objectstore.ConfigFromS3(rb.Spec.Storage.S3, secret))
+}
+
+// finalize deletes the uploaded dump files of the backup before it goes away. A backup deleted
+// while it runs is only finalized once its current step returned, so the dump being uploaded then
+// is deleted as well.
+func (r *Reconciler) finalize(ctx context.Context, instance *rbvb2.RedisBackup) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(instance, RedisBackupFinalizer) {
+		return intctrlutil.Reconciled()
+	}
+	if err := r.deleteArtifacts(ctx, instance); err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, events.EventReasonRedisBackupPruneFailed, "Failed to delete artifacts: %v", err)
+		return intctrlutil.RequeueE(ctx, err, "failed to delete the artifacts of the RedisBackup")
+	}
+	controllerutil.RemoveFinalizer(instance, RedisBackupFinalizer)
+	if err := r.Update(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to remove finalizer")
+	}
+	return intctrlutil.Reconciled()
+}
+
+// deleteArtifacts removes every object under the key prefix of the backup, which includes a dump
+// uploaded before the status recorded it. VolumeSnapshots are owned by the backup and garbage
+// collected with it.
+func (r *Reconciler) deleteArtifacts(ctx context.Context, rb *rbvb2.RedisBackup) error {
+	if rb.Spec.Storage.S3 == nil {
+		return nil
+	}
+	store, err := r.openStore(ctx, rb)
+	if apierrors.IsNotFound(err) {
+		// Nothing can be deleted without the credentials, keeping the backup would only block
+		// the deletion of its namespace.
+		log.FromContext(ctx).Info("Credentials secret not found, leaving the artifacts of the RedisBackup in place", "secret", rb.Spec.Storage.S3.CredentialsSecret)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	keys, err := store.List(ctx, rb.Spec.Storage.S3.ObjectKey(rb.Namespace, rb.Name, "")+"/")
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&rbvb2.RedisBackup{}).
+		WithOptions(opts).
+		Complete(tracing.Reconciler("RedisBackup", r))
+}
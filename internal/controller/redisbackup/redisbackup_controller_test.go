@@ -0,0 +1,396 @@
+package redisbackup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/objectstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeChecker struct {
+	redis.Checker
+	clusterMasters []corev1.Pod
+}
+
+func (f *fakeChecker) GetPassword(context.Context, string, *commonapi.ExistingPasswordSecret) (string, error) {
+	return "", nil
+}
+
+func (f *fakeChecker) GetClusterMasters(context.Context, *rcvb2.RedisCluster) ([]corev1.Pod, error) {
+	return f.clusterMasters, nil
+}
+
+type fakeSnapshotter struct {
+	err error
+	// failPod is the only pod the snapshot fails on when set.
+	failPod string
+	// running is the number of polls a save runs for.
+	running int
+	polls   map[string]int
+}
+
+func (f *fakeSnapshotter) StartSnapshot(_ context.Context, pod corev1.Pod, _, _ string, _ *commonapi.TLSConfig) (*redis.SnapshotResult, error) {
+	if f.err != nil && (f.failPod == "" || f.failPod == pod.Name) {
+		return nil, f.err
+	}
+	return &redis.SnapshotResult{MasterReplOffset: 1024}, nil
+}
+
+func (f *fakeSnapshotter) SnapshotFinished(_ context.Context, pod corev1.Pod, _, _ string, _ *commonapi.TLSConfig) (string, error) {
+	if f.polls == nil {
+		f.polls = map[string]int{}
+	}
+	f.polls[pod.Name]++
+	if f.polls[pod.Name] <= f.running {
+		return "", nil
+	}
+	return "/data/dump.rdb", nil
+}
+
+type memoryStore struct {
+	objects map[string][]byte
+}
+
+func (m *memoryStore) Upload(_ context.Context, key string, r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	m.objects[key] = data
+	return int64(len(data)), nil
+}
+
+func (m *memoryStore) Download(_ context.Context, key string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(m.objects[key])), nil
+}
+
+func (m *memoryStore) Delete(_ context.Context, key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memoryStore) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for key := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func newTestReconciler(t *testing.T, snapshotErr error, store *memoryStore) (*Reconciler, types.NamespacedName) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, rvb2.AddToScheme(scheme))
+	require.NoError(t, rbvb2.AddToScheme(scheme))
+	require.NoError(t, rcvb2.AddToScheme(scheme))
+
+	backup := &rbvb2.RedisBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+		Spec: rbvb2.RedisBackupSpec{
+			Target: rbvb2.BackupTarget{Kind: rbvb2.TargetKindRedis, Name: "redis"},
+			Storage: rbvb2.BackupStorage{S3: &rbvb2.S3Storage{
+				Endpoint:          "minio:9000",
+				Bucket:            "backups",
+				Prefix:            "prod",
+				CredentialsSecret: "s3",
+			}},
+		},
+	}
+	standalone := &rvb2.Redis{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"}}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(backup, standalone).
+		WithStatusSubresource(&rbvb2.RedisBackup{}).
+		Build()
+
+	k8sClient := k8sfake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-0", Namespace: "default"}},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: "default"},
+			Data: map[string][]byte{
+				rbvb2.S3AccessKeyIDKey:     []byte("access"),
+				rbvb2.S3SecretAccessKeyKey: []byte("secret"),
+			},
+		},
+	)
+
+	return &Reconciler{
+		Client:      cl,
+		K8sClient:   k8sClient,
+		Checker:     &fakeChecker{},
+		Snapshotter: &fakeSnapshotter{err: snapshotErr},
+		Recorder:    record.NewFakeRecorder(10),
+		NewStore: func(cfg objectstore.Config) (objectstore.Store, error) {
+			assert.Equal(t, "access", cfg.AccessKeyID)
+			assert.Equal(t, "secret", cfg.SecretAccessKey)
+			return store, nil
+		},
+		StreamFile: func(_ context.Context, _ kubernetes.Interface, pod *corev1.Pod, path string, w io.Writer) error {
+			_, err := io.WriteString(w, "REDIS0011"+pod.Name+path)
+			return err
+		},
+	}, types.NamespacedName{Name: backup.Name, Namespace: backup.Namespace}
+}
+
+// reconcileUntilDone reconciles the backup until it stops requeueing, and returns the number of reconciles.
+func reconcileUntilDone(t *testing.T, r *Reconciler, key types.NamespacedName) int {
+	t.Helper()
+	for i := 1; i <= 20; i++ {
+		result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+		require.NoError(t, err)
+		if !result.Requeue && result.RequeueAfter == 0 {
+			return i
+		}
+	}
+	t.Fatal("the backup did not finish")
+	return 0
+}
+
+// reconcileUntilRequeueAfter reconciles the backup until it requeues after interval, and returns the number of reconciles.
+func reconcileUntilRequeueAfter(t *testing.T, r *Reconciler, key types.NamespacedName, interval time.Duration) int {
+	t.Helper()
+	for i := 1; i <= 20; i++ {
+		result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+		require.NoError(t, err)
+		if result.RequeueAfter == interval {
+			return i
+		}
+	}
+	t.Fatalf("the backup did not requeue after %s", interval)
+	return 0
+}
+
+func TestReconcileUploadsSnapshot(t *testing.T) {
+	store := &memoryStore{objects: map[string][]byte{}}
+	r, key := newTestReconciler(t, nil, store)
+	r.Snapshotter = &fakeSnapshotter{running: 2}
+
+	// The save is started, then polled while it runs, then the dump is uploaded.
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, snapshotPollInterval, result.RequeueAfter)
+	backup := &rbvb2.RedisBackup{}
+	require.NoError(t, r.Get(context.Background(), key, backup))
+	assert.Equal(t, rbvb2.RedisBackupRunning, backup.Status.Phase)
+	require.NotNil(t, backup.Status.Save)
+	assert.Equal(t, "redis-0", backup.Status.Save.Pod)
+	assert.True(t, backup.Status.Save.Started)
+	assert.Empty(t, store.objects)
+
+	assert.Equal(t, 4, reconcileUntilDone(t, r, key))
+	require.NoError(t, r.Get(context.Background(), key, backup))
+	assert.Equal(t, rbvb2.RedisBackupCompleted, backup.Status.Phase)
+	assert.Nil(t, backup.Status.Save)
+	assert.NotNil(t, backup.Status.StartTime)
+	assert.NotNil(t, backup.Status.CompletionTime)
+	require.Len(t, backup.Status.Shards, 1)
+
+	shard := backup.Status.Shards[0]
+	assert.Equal(t, "redis-0", shard.Pod)
+	assert.Equal(t, "prod/default/nightly/redis-0.rdb", shard.ObjectKey)
+	assert.Equal(t, int64(1024), shard.MasterReplOffset)
+	assert.Equal(t, "REDIS0011redis-0/data/dump.rdb", string(store.objects[shard.ObjectKey]))
+	assert.Equal(t, int64(len(store.objects[shard.ObjectKey])), shard.Size)
+}
+
+func TestReconcileDeletesArtifactsOfDeletedBackup(t *testing.T) {
+	store := &memoryStore{objects: map[string][]byte{
+		"prod/default/nightly-2/redis-0.rdb": []byte("REDIS0011"),
+	}}
+	r, key := newTestReconciler(t, nil, store)
+	reconcileUntilDone(t, r, key)
+	backup := &rbvb2.RedisBackup{}
+	require.NoError(t, r.Get(context.Background(), key, backup))
+	assert.Contains(t, backup.Finalizers, RedisBackupFinalizer)
+
+	// A dump uploaded before the status recorded it is deleted as well.
+	store.objects["prod/default/nightly/redis-1.rdb"] = []byte("REDIS0011")
+	require.NoError(t, r.Delete(context.Background(), backup))
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	assert.True(t, apierrors.IsNotFound(r.Get(context.Background(), key, backup)))
+	assert.Equal(t, []string{"prod/default/nightly-2/redis-0.rdb"}, slices.Collect(maps.Keys(store.objects)))
+}
+
+func TestReconcileMarksBackupFailed(t *testing.T) {
+	store := &memoryStore{objects: map[string][]byte{}}
+	r, key := newTestReconciler(t, errors.New("rdb_last_bgsave_status is \"err\""), store)
+
+	reconcileUntilDone(t, r, key)
+
+	backup := &rbvb2.RedisBackup{}
+	require.NoError(t, r.Get(context.Background(), key, backup))
+	assert.Equal(t, rbvb2.RedisBackupFailed, backup.Status.Phase)
+	assert.Contains(t, backup.Status.Reason, "rdb_last_bgsave_status")
+	assert.Empty(t, store.objects)
+
+	// A finished backup is never retried.
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, r.Get(context.Background(), key, backup))
+	assert.Equal(t, rbvb2.RedisBackupFailed, backup.Status.Phase)
+}
+
+func TestReconcileFailsWhenSaveTimesOut(t *testing.T) {
+	r, key := newTestReconciler(t, nil, &memoryStore{objects: map[string][]byte{}})
+	r.Snapshotter = &fakeSnapshotter{running: 1000}
+	ctx := context.Background()
+
+	backup := &rbvb2.RedisBackup{}
+	require.NoError(t, r.Get(ctx, key, backup))
+	backup.Status = rbvb2.RedisBackupStatus{
+		Phase:  rbvb2.RedisBackupRunning,
+		Shards: []rbvb2.BackupShard{{Pod: "redis-0"}},
+		Save: &rbvb2.BackgroundSave{
+			Pod:       "redis-0",
+			StartTime: metav1.NewTime(time.Now().Add(-snapshotTimeout - time.Minute)),
+			Started:   true,
+		},
+	}
+	require.NoError(t, r.Status().Update(ctx, backup))
+
+	reconcileUntilDone(t, r, key)
+	require.NoError(t, r.Get(ctx, key, backup))
+	assert.Equal(t, rbvb2.RedisBackupFailed, backup.Status.Phase)
+	assert.Contains(t, backup.Status.Reason, "did not finish")
+	assert.Empty(t, backup.Status.Shards)
+	assert.Nil(t, backup.Status.Save)
+}
+
+func TestReconcileRecordsShardsUploadedBeforeFailure(t *testing.T) {
+	store := &memoryStore{objects: map[string][]byte{}}
+	r, key := newTestReconciler(t, nil, store)
+	ctx := context.Background()
+	r.Snapshotter = &fakeSnapshotter{err: errors.New("connection refused"), failPod: "cluster-leader-1"}
+	masters := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster-leader-0", Namespace: "default"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster-leader-1", Namespace: "default"}},
+	}
+	r.Checker = &fakeChecker{clusterMasters: masters}
+	for i := range masters {
+		_, err := r.K8sClient.CoreV1().Pods("default").Create(ctx, &masters[i], metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	require.NoError(t, r.Create(ctx, &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec:       rcvb2.RedisClusterSpec{Port: ptr.To(6379)},
+	}))
+	backup := &rbvb2.RedisBackup{}
+	require.NoError(t, r.Get(ctx, key, backup))
+	backup.Spec.Target = rbvb2.BackupTarget{Kind: rbvb2.TargetKindRedisCluster, Name: "cluster"}
+	require.NoError(t, r.Update(ctx, backup))
+
+	reconcileUntilDone(t, r, key)
+
+	// The dump of the first master was uploaded, it is recorded so that retention prunes it.
+	require.NoError(t, r.Get(ctx, key, backup))
+	assert.Equal(t, rbvb2.RedisBackupFailed, backup.Status.Phase)
+	require.Len(t, backup.Status.Shards, 1)
+	assert.Equal(t, "prod/default/nightly/cluster-leader-0.rdb", backup.Status.Shards[0].ObjectKey)
+	assert.Contains(t, store.objects, backup.Status.Shards[0].ObjectKey)
+}
+
+func TestReconcileTakesVolumeSnapshots(t *testing.T) {
+	r, key := newTestReconciler(t, nil, &memoryStore{objects: map[string][]byte{}})
+	scheme := r.Scheme()
+	scheme.AddKnownTypeWithName(k8sutils.VolumeSnapshotGVK, &unstructured.Unstructured{})
+	ctx := context.Background()
+
+	backup := &rbvb2.RedisBackup{}
+	require.NoError(t, r.Get(ctx, key, backup))
+	backup.Spec.Storage = rbvb2.BackupStorage{VolumeSnapshot: &rbvb2.VolumeSnapshotStorage{VolumeSnapshotClassName: ptr.To("csi-snapclass")}}
+	require.NoError(t, r.Update(ctx, backup))
+	_, err := r.K8sClient.AppsV1().StatefulSets("default").Create(ctx, &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To(int32(1))},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, reconcileUntilRequeueAfter(t, r, key, volumeSnapshotPollInterval))
+	require.NoError(t, r.Get(ctx, key, backup))
+	assert.Equal(t, rbvb2.RedisBackupRunning, backup.Status.Phase)
+	assert.True(t, backup.Status.VolumeSnapshotsTaken)
+	assert.Equal(t, []string{"nightly-redis-0"}, backup.Status.VolumeSnapshots)
+	require.Len(t, backup.Status.Shards, 1)
+	assert.Equal(t, "nightly-redis-0", backup.Status.Shards[0].VolumeSnapshot)
+	assert.Equal(t, int64(1024), backup.Status.Shards[0].MasterReplOffset)
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(k8sutils.VolumeSnapshotGVK)
+	require.NoError(t, r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "nightly-redis-0"}, snapshot))
+	pvc, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	assert.Equal(t, "redis-redis-0", pvc)
+	class, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+	assert.Equal(t, "csi-snapclass", class)
+	require.Len(t, snapshot.GetOwnerReferences(), 1)
+	assert.Equal(t, "nightly", snapshot.GetOwnerReferences()[0].Name)
+
+	// Still cutting.
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, volumeSnapshotPollInterval, result.RequeueAfter)
+
+	require.NoError(t, unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse"))
+	require.NoError(t, r.Update(ctx, snapshot))
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, r.Get(ctx, key, backup))
+	assert.Equal(t, rbvb2.RedisBackupCompleted, backup.Status.Phase)
+	assert.NotNil(t, backup.Status.CompletionTime)
+}
+
+func TestReconcileFailsOnVolumeSnapshotError(t *testing.T) {
+	r, key := newTestReconciler(t, nil, &memoryStore{objects: map[string][]byte{}})
+	r.Scheme().AddKnownTypeWithName(k8sutils.VolumeSnapshotGVK, &unstructured.Unstructured{})
+	ctx := context.Background()
+
+	backup := &rbvb2.RedisBackup{}
+	require.NoError(t, r.Get(ctx, key, backup))
+	backup.Spec.Storage = rbvb2.BackupStorage{VolumeSnapshot: &rbvb2.VolumeSnapshotStorage{}}
+	require.NoError(t, r.Update(ctx, backup))
+	backup.Status = rbvb2.RedisBackupStatus{Phase: rbvb2.RedisBackupRunning, VolumeSnapshots: []string{"nightly-redis-0"}, VolumeSnapshotsTaken: true}
+	require.NoError(t, r.Status().Update(ctx, backup))
+
+	snapshot := k8sutils.NewVolumeSnapshot("default", "nightly-redis-0", "redis-redis-0", nil)
+	snapshot.Object["status"] = map[string]interface{}{"error": map[string]interface{}{"message": "driver quota exceeded"}}
+	require.NoError(t, r.Create(ctx, snapshot))
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.NoError(t, r.Get(ctx, key, backup))
+	assert.Equal(t, rbvb2.RedisBackupFailed, backup.Status.Phase)
+	assert.Contains(t, backup.Status.Reason, "driver quota exceeded")
+}
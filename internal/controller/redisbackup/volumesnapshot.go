@@ -0,0 +1,155 @@
+package redisbackup
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// volumeSnapshotPollInterval is how often a backup checks whether its VolumeSnapshots are ready.
+const volumeSnapshotPollInterval = 10 * time.Second
+
+// reconcileVolumeSnapshots takes the VolumeSnapshots of the backup one pod at a time, then waits for
+// the CSI driver to report all of them ready.
+func (r *Reconciler) reconcileVolumeSnapshots(ctx context.Context, instance *rbvb2.RedisBackup) (ctrl.Result, error) {
+	if !instance.Status.VolumeSnapshotsTaken {
+		return r.takeVolumeSnapshot(ctx, instance)
+	}
+
+	ready, err := r.volumeSnapshotsReady(ctx, instance)
+	if err != nil {
+		return r.finish(ctx, instance, err, "")
+	}
+	if !ready {
+		return intctrlutil.RequeueAfter(ctx, volumeSnapshotPollInterval, "waiting for VolumeSnapshots to become ready")
+	}
+	return r.finish(ctx, instance, nil, fmt.Sprintf("Created %d VolumeSnapshot(s)", len(instance.Status.VolumeSnapshots)))
+}
+
+// takeVolumeSnapshot issues BGSAVE on the next pod of the target without a VolumeSnapshot and snapshots
+// its data PVC once the save finished, so each volume holds a fresh dump.rdb. Only the snapshots of the
+// masters are recorded as shards, the ones of the replicas are kept for restoring the volumes of a
+// cluster one to one.
+func (r *Reconciler) takeVolumeSnapshot(ctx context.Context, rb *rbvb2.RedisBackup) (ctrl.Result, error) {
+	source, err := r.resolveSource(ctx, rb)
+	if err != nil {
+		return r.finish(ctx, rb, err, "")
+	}
+	if err := recordShards(rb, source); err != nil {
+		return r.finish(ctx, rb, err, "")
+	}
+	stsName, ordinal, err := r.nextVolumeSnapshotPod(ctx, rb, source)
+	if err != nil {
+		return r.finish(ctx, rb, err, "")
+	}
+	if stsName == "" {
+		for _, shard := range rb.Status.Shards {
+			if shard.VolumeSnapshot == "" {
+				return r.finish(ctx, rb, fmt.Errorf("master %s is not a pod of %v", shard.Pod, source.statefulSets), "")
+			}
+		}
+		rb.Status.VolumeSnapshotsTaken = true
+		if err := common.UpdateStatus(ctx, r.Client, rb); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to record RedisBackup VolumeSnapshots")
+		}
+		return intctrlutil.RequeueAfter(ctx, volumeSnapshotPollInterval, "waiting for VolumeSnapshots to become ready")
+	}
+
+	pod, err := r.K8sClient.CoreV1().Pods(rb.Namespace).Get(ctx, fmt.Sprintf("%s-%d", stsName, ordinal), metav1.GetOptions{})
+	if err != nil {
+		return r.finish(ctx, rb, err, "")
+	}
+	rdbPath, err := r.save(ctx, rb, pod, source)
+	if err != nil {
+		return r.finish(ctx, rb, err, "")
+	}
+	if rdbPath == "" {
+		return r.waitForSave(ctx, rb)
+	}
+
+	name := rb.Name + "-" + pod.Name
+	pvcName := k8sutils.DataPVCName(stsName, ordinal)
+	if err := r.createVolumeSnapshot(ctx, rb, name, pvcName); err != nil {
+		return r.finish(ctx, rb, err, "")
+	}
+	log.FromContext(ctx).Info("Created VolumeSnapshot", "pod", pod.Name, "PVC", pvcName, "VolumeSnapshot", name)
+	rb.Status.VolumeSnapshots = append(rb.Status.VolumeSnapshots, name)
+	for i := range rb.Status.Shards {
+		if rb.Status.Shards[i].Pod == pod.Name {
+			rb.Status.Shards[i].VolumeSnapshot = name
+			rb.Status.Shards[i].MasterReplOffset = rb.Status.Save.MasterReplOffset
+			rb.Status.Shards[i].Slots = rb.Status.Save.Slots
+		}
+	}
+	rb.Status.Save = nil
+	if err := common.UpdateStatus(ctx, r.Client, rb); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to record RedisBackup VolumeSnapshot")
+	}
+	return intctrlutil.Requeue()
+}
+
+// nextVolumeSnapshotPod returns the StatefulSet and ordinal of the first pod of the target whose data
+// PVC has not been snapshotted yet, and an empty StatefulSet name when all of them have been.
+func (r *Reconciler) nextVolumeSnapshotPod(ctx context.Context, rb *rbvb2.RedisBackup, source *backupSource) (string, int, error) {
+	for _, stsName := range source.statefulSets {
+		sts, err := r.K8sClient.AppsV1().StatefulSets(rb.Namespace).Get(ctx, stsName, metav1.GetOptions{})
+		if err != nil {
+			return "", 0, err
+		}
+		for i := 0; i < int(ptr.Deref(sts.Spec.Replicas, 1)); i++ {
+			if !slices.Contains(rb.Status.VolumeSnapshots, fmt.Sprintf("%s-%s-%d", rb.Name, stsName, i)) {
+				return stsName, i, nil
+			}
+		}
+	}
+	return "", 0, nil
+}
+
+// createVolumeSnapshot creates a VolumeSnapshot owned by the backup, so it is deleted along with it.
+// A snapshot left by an interrupted earlier pass is reused.
+func (r *Reconciler) createVolumeSnapshot(ctx context.Context, rb *rbvb2.RedisBackup, name, pvcName string) error {
+	snapshot := k8sutils.NewVolumeSnapshot(rb.Namespace, name, pvcName, rb.Spec.Storage.VolumeSnapshot.VolumeSnapshotClassName)
+	if err := controllerutil.SetControllerReference(rb, snapshot, r.Scheme()); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, snapshot); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create VolumeSnapshot %s of PVC %s: %w", name, pvcName, err)
+	}
+	return nil
+}
+
+// volumeSnapshotsReady reports whether every VolumeSnapshot of the backup can be restored from,
+// and fails if one of them was deleted or the CSI driver reported an error.
+func (r *Reconciler) volumeSnapshotsReady(ctx context.Context, rb *rbvb2.RedisBackup) (bool, error) {
+	allReady := true
+	for _, name := range rb.Status.VolumeSnapshots {
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(k8sutils.VolumeSnapshotGVK)
+		if err := r.Get(ctx, types.NamespacedName{Namespace: rb.Namespace, Name: name}, snapshot); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, fmt.Errorf("VolumeSnapshot %s was deleted before it became ready", name)
+			}
+			return false, err
+		}
+		ready, errMsg := k8sutils.VolumeSnapshotState(snapshot)
+		if errMsg != "" {
+			return false, fmt.Errorf("VolumeSnapshot %s failed: %s", name, errMsg)
+		}
+		allReady = allReady && ready
+	}
+	return allReady, nil
+}
@@ -0,0 +1,237 @@
+package redisbackupschedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Reconciler reconciles a RedisBackupSchedule object
+type Reconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+	// Now returns the current time, overridable in tests.
+	Now func() time.Time
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	instance := &rbvb2.RedisBackupSchedule{}
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		return intctrlutil.RequeueECheck(ctx, err, "failed to get RedisBackupSchedule instance")
+	}
+	if k8sutils.IsDeleted(instance) {
+		return intctrlutil.Reconciled()
+	}
+	if common.ShouldSkipReconcile(ctx, instance) {
+		return intctrlutil.Reconciled()
+	}
+
+	sched, err := cron.ParseStandard(instance.Spec.Schedule)
+	if err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, events.EventReasonRedisBackupScheduleInvalid, "Invalid schedule %q: %v", instance.Spec.Schedule, err)
+		return intctrlutil.Reconciled()
+	}
+
+	backups := &rbvb2.RedisBackupList{}
+	if err := r.List(ctx, backups, client.InNamespace(instance.Namespace), client.MatchingLabels{rbvb2.ScheduleLabelKey: instance.Name}); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to list scheduled backups")
+	}
+	if err := r.release(ctx, instance, backups.Items); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to release scheduled backups")
+	}
+	children, err := r.prune(ctx, instance, backups.Items)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to prune expired backups")
+	}
+
+	now := r.Now()
+	since := instance.CreationTimestamp.Time
+	if instance.Status.LastScheduleTime != nil {
+		since = instance.Status.LastScheduleTime.Time
+	}
+	if d := instance.Spec.StartingDeadlineSeconds; d != nil {
+		if deadline := now.Add(-time.Duration(*d) * time.Second); deadline.After(since) {
+			since = deadline
+		}
+	}
+	if !instance.Spec.Suspend {
+		run, err := mostRecentRun(sched, since, now)
+		switch {
+		case errors.Is(err, errTooManyMissedRuns):
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, events.EventReasonRedisBackupMissed, "Skipped more than %d missed backups, set or decrease startingDeadlineSeconds", maxMissedRuns)
+			instance.Status.LastScheduleTime = &metav1.Time{Time: now}
+		case !run.IsZero():
+			children, err = r.run(ctx, instance, children, run)
+			if err != nil {
+				return intctrlutil.RequeueE(ctx, err, "failed to schedule backup")
+			}
+			instance.Status.LastScheduleTime = &metav1.Time{Time: run}
+		}
+	}
+
+	instance.Status.Active = nil
+	for _, b := range children {
+		if !b.Status.IsFinished() {
+			instance.Status.Active = append(instance.Status.Active, b.Name)
+		}
+		if b.Status.Phase == rbvb2.RedisBackupCompleted && b.Status.CompletionTime != nil &&
+			(instance.Status.LastSuccessfulTime == nil || instance.Status.LastSuccessfulTime.Before(b.Status.CompletionTime)) {
+			instance.Status.LastSuccessfulTime = b.Status.CompletionTime.DeepCopy()
+		}
+	}
+	if err := common.UpdateStatus(ctx, r.Client, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisBackupSchedule status")
+	}
+
+	// Child backups changing phase also trigger a reconcile through their schedule label, so
+	// the requeue only has to cover the next scheduled run.
+	return intctrlutil.RequeueAfter(ctx, sched.Next(now).Sub(now), "waiting for next scheduled backup")
+}
+
+// maxMissedRuns bounds the scheduled times walked by mostRecentRun, like the CronJob controller does.
+const maxMissedRuns = 100
+
+var errTooManyMissedRuns = errors.New("too many missed runs")
+
+// mostRecentRun returns the latest scheduled time in (since, now], or the zero time if no run is due.
+// It gives up with errTooManyMissedRuns once more than maxMissedRuns times were missed.
+func mostRecentRun(sched cron.Schedule, since, now time.Time) (time.Time, error) {
+	var last time.Time
+	missed := 0
+	for t := sched.Next(since); !t.After(now); t = sched.Next(t) {
+		if missed++; missed > maxMissedRuns {
+			return time.Time{}, errTooManyMissedRuns
+		}
+		last = t
+	}
+	return last, nil
+}
+
+// run creates the backup due at the given time, honouring the concurrency policy, and returns the updated children.
+func (r *Reconciler) run(ctx context.Context, instance *rbvb2.RedisBackupSchedule, children []rbvb2.RedisBackup, at time.Time) ([]rbvb2.RedisBackup, error) {
+	var active, rest []rbvb2.RedisBackup
+	for _, b := range children {
+		if b.Status.IsFinished() {
+			rest = append(rest, b)
+		} else {
+			active = append(active, b)
+		}
+	}
+
+	if len(active) > 0 {
+		switch instance.Spec.ConcurrencyPolicy {
+		case rbvb2.ForbidConcurrent, "":
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, events.EventReasonRedisBackupSkipped, "Skipped scheduled backup, %d backup(s) still running", len(active))
+			return children, nil
+		case rbvb2.ReplaceConcurrent:
+			for i := range active {
+				if err := r.Delete(ctx, &active[i]); err != nil && !apierrors.IsNotFound(err) {
+					return nil, err
+				}
+				r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonRedisBackupReplaced, "Deleted running backup %s", active[i].Name)
+			}
+			children = rest
+		}
+	}
+
+	backup := &rbvb2.RedisBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", instance.Name, at.Unix()),
+			Namespace: instance.Namespace,
+			Labels:    map[string]string{rbvb2.ScheduleLabelKey: instance.Name},
+		},
+		Spec: *instance.Spec.BackupTemplate.DeepCopy(),
+	}
+	if err := r.Create(ctx, backup); err != nil {
+		// A retried reconcile may already have created the backup for this run.
+		if apierrors.IsAlreadyExists(err) {
+			return children, nil
+		}
+		return nil, err
+	}
+	log.FromContext(ctx).Info("Created scheduled backup", "backup", backup.Name)
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonRedisBackupScheduled, "Created backup %s", backup.Name)
+	return append(children, *backup), nil
+}
+
+// release drops the owner reference to the schedule from the backups created by earlier versions, which
+// made deleting the schedule garbage collect its backups along with their artifacts.
+func (r *Reconciler) release(ctx context.Context, instance *rbvb2.RedisBackupSchedule, backups []rbvb2.RedisBackup) error {
+	for i := range backups {
+		b := &backups[i]
+		if !metav1.IsControlledBy(b, instance) {
+			continue
+		}
+		patch := client.MergeFrom(b.DeepCopy())
+		if err := controllerutil.RemoveControllerReference(instance, b, r.Scheme()); err != nil {
+			return err
+		}
+		if err := r.Patch(ctx, b, patch); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// prune deletes the backups expired by the retention policy and returns the remaining ones. The
+// finalizer of a backup deletes its artifacts.
+func (r *Reconciler) prune(ctx context.Context, instance *rbvb2.RedisBackupSchedule, backups []rbvb2.RedisBackup) ([]rbvb2.RedisBackup, error) {
+	expired := make(map[string]bool)
+	for _, b := range expiredBackups(backups, instance.Spec.Retention) {
+		if err := r.Delete(ctx, &b); err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		expired[b.Name] = true
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonRedisBackupPruned, "Deleted expired backup %s", b.Name)
+	}
+
+	remaining := make([]rbvb2.RedisBackup, 0, len(backups)-len(expired))
+	for _, b := range backups {
+		if !expired[b.Name] {
+			remaining = append(remaining, b)
+		}
+	}
+	return remaining, nil
+}
+
+// scheduleOfBackup maps a RedisBackup to the schedule named by its label. The backups are not owned by
+// their schedule, so deleting the schedule keeps the backups and their artifacts.
+func scheduleOfBackup(_ context.Context, obj client.Object) []reconcile.Request {
+	name := obj.GetLabels()[rbvb2.ScheduleLabelKey]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&rbvb2.RedisBackupSchedule{}).
+		Watches(&rbvb2.RedisBackup{}, handler.EnqueueRequestsFromMapFunc(scheduleOfBackup)).
+		WithOptions(opts).
+		Complete(tracing.Reconciler("RedisBackupSchedule", r))
+}
@@ -0,0 +1,270 @@
+package redisbackupschedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var created = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func newSchedule(policy rbvb2.ConcurrencyPolicy, retention *rbvb2.RetentionPolicy) *rbvb2.RedisBackupSchedule {
+	return &rbvb2.RedisBackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "nightly",
+			Namespace:         "default",
+			CreationTimestamp: metav1.Time{Time: created},
+		},
+		Spec: rbvb2.RedisBackupScheduleSpec{
+			Schedule:          "0 2 * * *",
+			ConcurrencyPolicy: policy,
+			BackupTemplate: rbvb2.RedisBackupSpec{
+				Target:  rbvb2.BackupTarget{Kind: rbvb2.TargetKindRedis, Name: "redis"},
+				Storage: rbvb2.BackupStorage{S3: &rbvb2.S3Storage{Bucket: "backups", CredentialsSecret: "s3"}},
+			},
+			Retention: retention,
+		},
+	}
+}
+
+func childBackup(name string, phase rbvb2.RedisBackupPhase, completed time.Time) *rbvb2.RedisBackup {
+	b := &rbvb2.RedisBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			Labels:            map[string]string{rbvb2.ScheduleLabelKey: "nightly"},
+			CreationTimestamp: metav1.Time{Time: completed.Add(-time.Minute)},
+		},
+		Spec:   newSchedule("", nil).Spec.BackupTemplate,
+		Status: rbvb2.RedisBackupStatus{Phase: phase},
+	}
+	if phase == rbvb2.RedisBackupCompleted {
+		b.Status.CompletionTime = &metav1.Time{Time: completed}
+	}
+	return b
+}
+
+func newTestReconciler(t *testing.T, now time.Time, objs ...client.Object) *Reconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, rbvb2.AddToScheme(scheme))
+
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&rbvb2.RedisBackupSchedule{}, &rbvb2.RedisBackup{}).
+		Build()
+
+	return &Reconciler{
+		Client:   cl,
+		Recorder: record.NewFakeRecorder(10),
+		Now:      func() time.Time { return now },
+	}
+}
+
+func listBackups(t *testing.T, r *Reconciler) []rbvb2.RedisBackup {
+	t.Helper()
+	backups := &rbvb2.RedisBackupList{}
+	require.NoError(t, r.List(context.Background(), backups, client.InNamespace("default")))
+	return backups.Items
+}
+
+var key = types.NamespacedName{Name: "nightly", Namespace: "default"}
+
+func TestReconcileCreatesDueBackup(t *testing.T) {
+	now := time.Date(2024, 3, 3, 2, 30, 0, 0, time.UTC)
+	r := newTestReconciler(t, now, newSchedule(rbvb2.ForbidConcurrent, nil))
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, 23*time.Hour+30*time.Minute, result.RequeueAfter)
+
+	// Only the most recent missed run is created.
+	backups := listBackups(t, r)
+	require.Len(t, backups, 1)
+	run := time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC)
+	assert.Equal(t, "nightly-1709431200", backups[0].Name)
+	assert.Equal(t, "nightly", backups[0].Labels[rbvb2.ScheduleLabelKey])
+	assert.Equal(t, "redis", backups[0].Spec.Target.Name)
+	assert.Empty(t, backups[0].OwnerReferences, "the backups outlive their schedule")
+
+	schedule := &rbvb2.RedisBackupSchedule{}
+	require.NoError(t, r.Get(context.Background(), key, schedule))
+	require.NotNil(t, schedule.Status.LastScheduleTime)
+	assert.True(t, schedule.Status.LastScheduleTime.Time.Equal(run))
+	assert.Equal(t, []string{"nightly-1709431200"}, schedule.Status.Active)
+
+	// Reconciling again before the next run does not create another backup.
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Len(t, listBackups(t, r), 1)
+}
+
+func TestReconcileConcurrencyPolicy(t *testing.T) {
+	now := time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		policy rbvb2.ConcurrencyPolicy
+		want   []string
+	}{
+		{policy: rbvb2.ForbidConcurrent, want: []string{"running"}},
+		{policy: rbvb2.ReplaceConcurrent, want: []string{"nightly-1709258400"}},
+		{policy: rbvb2.AllowConcurrent, want: []string{"nightly-1709258400", "running"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			r := newTestReconciler(t, now,
+				newSchedule(tt.policy, nil),
+				childBackup("running", rbvb2.RedisBackupRunning, created),
+			)
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+
+			var got []string
+			for _, b := range listBackups(t, r) {
+				got = append(got, b.Name)
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestReconcileSuspendedSchedule(t *testing.T) {
+	now := time.Date(2024, 3, 3, 2, 30, 0, 0, time.UTC)
+	schedule := newSchedule(rbvb2.ForbidConcurrent, nil)
+	schedule.Spec.Suspend = true
+	r := newTestReconciler(t, now, schedule)
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Empty(t, listBackups(t, r))
+}
+
+func TestReconcileStartingDeadline(t *testing.T) {
+	// The 02:00 run is 30 minutes late, e.g. because the schedule was just unsuspended.
+	now := time.Date(2024, 3, 3, 2, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		deadline *int64
+		want     int
+	}{
+		{name: "no deadline", want: 1},
+		{name: "within deadline", deadline: ptr.To[int64](3600), want: 1},
+		{name: "past deadline", deadline: ptr.To[int64](600), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := newSchedule(rbvb2.ForbidConcurrent, nil)
+			schedule.Spec.StartingDeadlineSeconds = tt.deadline
+			r := newTestReconciler(t, now, schedule)
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+			assert.Len(t, listBackups(t, r), tt.want)
+		})
+	}
+}
+
+func TestReconcileTooManyMissedRuns(t *testing.T) {
+	now := time.Date(2024, 3, 3, 2, 30, 0, 0, time.UTC)
+	schedule := newSchedule(rbvb2.ForbidConcurrent, nil)
+	schedule.Spec.Schedule = "* * * * *"
+	r := newTestReconciler(t, now, schedule)
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Empty(t, listBackups(t, r))
+	assert.Contains(t, <-r.Recorder.(*record.FakeRecorder).Events, "RedisBackupMissed")
+
+	// The missed runs are skipped, the next one is scheduled again.
+	require.NoError(t, r.Get(context.Background(), key, schedule))
+	require.NotNil(t, schedule.Status.LastScheduleTime)
+	assert.True(t, schedule.Status.LastScheduleTime.Time.Equal(now))
+	r.Now = func() time.Time { return now.Add(time.Minute) }
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Len(t, listBackups(t, r), 1)
+}
+
+func TestReconcilePrunesExpiredBackups(t *testing.T) {
+	now := time.Date(2024, 3, 3, 1, 0, 0, 0, time.UTC)
+	r := newTestReconciler(t, now,
+		newSchedule(rbvb2.ForbidConcurrent, &rbvb2.RetentionPolicy{KeepLast: ptr.To(int32(1))}),
+		childBackup("old", rbvb2.RedisBackupCompleted, created.Add(2*time.Hour)),
+		childBackup("new", rbvb2.RedisBackupCompleted, created.Add(26*time.Hour)),
+	)
+	// The schedule already ran at 2024-03-02 02:00.
+	schedule := &rbvb2.RedisBackupSchedule{}
+	require.NoError(t, r.Get(context.Background(), key, schedule))
+	schedule.Status.LastScheduleTime = &metav1.Time{Time: created.Add(26 * time.Hour)}
+	require.NoError(t, r.Status().Update(context.Background(), schedule))
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	backups := listBackups(t, r)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "new", backups[0].Name)
+
+	require.NoError(t, r.Get(context.Background(), key, schedule))
+	require.NotNil(t, schedule.Status.LastSuccessfulTime)
+	assert.True(t, schedule.Status.LastSuccessfulTime.Time.Equal(created.Add(26*time.Hour)))
+	assert.Empty(t, schedule.Status.Active)
+}
+
+func TestReconcileDeletedScheduleKeepsBackups(t *testing.T) {
+	now := time.Date(2024, 3, 3, 2, 30, 0, 0, time.UTC)
+	r := newTestReconciler(t, now, newSchedule(rbvb2.ForbidConcurrent, nil))
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.Len(t, listBackups(t, r), 1)
+
+	schedule := &rbvb2.RedisBackupSchedule{}
+	require.NoError(t, r.Get(context.Background(), key, schedule))
+	require.NoError(t, r.Delete(context.Background(), schedule))
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	backups := listBackups(t, r)
+	require.Len(t, backups, 1)
+	assert.Empty(t, backups[0].OwnerReferences, "nothing garbage collects the backups of a deleted schedule")
+	assert.Equal(t, []reconcile.Request{{NamespacedName: key}}, scheduleOfBackup(context.Background(), &backups[0]))
+	assert.Empty(t, scheduleOfBackup(context.Background(), &rbvb2.RedisBackup{}))
+}
+
+func TestReconcileReleasesOwnedBackups(t *testing.T) {
+	now := time.Date(2024, 3, 1, 1, 30, 0, 0, time.UTC)
+	schedule := newSchedule(rbvb2.ForbidConcurrent, nil)
+	schedule.UID = "schedule-uid"
+	owned := childBackup("owned", rbvb2.RedisBackupCompleted, created.Add(time.Hour))
+	owned.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: rbvb2.GroupVersion.String(), Kind: "RedisBackupSchedule", Name: "nightly", UID: "schedule-uid",
+		Controller: ptr.To(true), BlockOwnerDeletion: ptr.To(true),
+	}}
+	r := newTestReconciler(t, now, schedule, owned)
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	backups := listBackups(t, r)
+	require.Len(t, backups, 1)
+	assert.Empty(t, backups[0].OwnerReferences)
+}
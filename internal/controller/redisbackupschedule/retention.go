@@ -0,0 +1,92 @@
+package redisbackupschedule
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+)
+
+// expiredBackups returns the backups the retention policy no longer keeps.
+// Running backups are never expired, failed ones are expired as soon as a newer
+// backup has completed, and completed ones are kept if any rule selects them.
+// A policy without any positive rule keeps everything, like no policy.
+func expiredBackups(backups []rbvb2.RedisBackup, policy *rbvb2.RetentionPolicy) []rbvb2.RedisBackup {
+	if !policy.HasRule() {
+		return nil
+	}
+
+	var completed, failed []rbvb2.RedisBackup
+	for _, b := range backups {
+		switch b.Status.Phase {
+		case rbvb2.RedisBackupCompleted:
+			completed = append(completed, b)
+		case rbvb2.RedisBackupFailed:
+			failed = append(failed, b)
+		}
+	}
+	sort.Slice(completed, func(i, j int) bool {
+		return backupTime(&completed[i]).After(backupTime(&completed[j]))
+	})
+
+	keep := make(map[string]bool, len(completed))
+	if policy.KeepLast != nil {
+		for i := 0; i < len(completed) && i < int(*policy.KeepLast); i++ {
+			keep[completed[i].Name] = true
+		}
+	}
+	keepPerPeriod(completed, policy.KeepDaily, keep, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepPerPeriod(completed, policy.KeepWeekly, keep, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	keepPerPeriod(completed, policy.KeepMonthly, keep, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+
+	var expired []rbvb2.RedisBackup
+	for _, b := range completed {
+		if !keep[b.Name] {
+			expired = append(expired, b)
+		}
+	}
+	if len(completed) > 0 {
+		newest := completed[0].CreationTimestamp.Time
+		for _, b := range failed {
+			if b.CreationTimestamp.Time.Before(newest) {
+				expired = append(expired, b)
+			}
+		}
+	}
+	return expired
+}
+
+// keepPerPeriod keeps the newest backup of each of the last n periods; backups must be sorted newest first.
+func keepPerPeriod(backups []rbvb2.RedisBackup, n *int32, keep map[string]bool, period func(time.Time) string) {
+	if n == nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for i := range backups {
+		if len(seen) >= int(*n) {
+			return
+		}
+		p := period(backupTime(&backups[i]).UTC())
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		keep[backups[i].Name] = true
+	}
+}
+
+// backupTime is the time a backup is accounted to by the retention policy.
+func backupTime(b *rbvb2.RedisBackup) time.Time {
+	if b.Status.CompletionTime != nil {
+		return b.Status.CompletionTime.Time
+	}
+	return b.CreationTimestamp.Time
+}
@@ -0,0 +1,136 @@
+package redisbackupschedule
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func backupAt(name string, phase rbvb2.RedisBackupPhase, created time.Time) rbvb2.RedisBackup {
+	b := rbvb2.RedisBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.Time{Time: created}},
+		Status:     rbvb2.RedisBackupStatus{Phase: phase},
+	}
+	if phase == rbvb2.RedisBackupCompleted {
+		b.Status.CompletionTime = &metav1.Time{Time: created.Add(time.Minute)}
+	}
+	return b
+}
+
+// dailyBackups returns one completed backup per day at 02:00 UTC for the given number of days ending on 2024-03-31.
+func dailyBackups(days int) []rbvb2.RedisBackup {
+	end := time.Date(2024, 3, 31, 2, 0, 0, 0, time.UTC)
+	var backups []rbvb2.RedisBackup
+	for i := 0; i < days; i++ {
+		t := end.AddDate(0, 0, -i)
+		backups = append(backups, backupAt(t.Format("2006-01-02"), rbvb2.RedisBackupCompleted, t))
+	}
+	return backups
+}
+
+func names(backups []rbvb2.RedisBackup) []string {
+	out := make([]string, 0, len(backups))
+	for _, b := range backups {
+		out = append(out, b.Name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestExpiredBackups(t *testing.T) {
+	now := time.Date(2024, 3, 31, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		backups []rbvb2.RedisBackup
+		policy  *rbvb2.RetentionPolicy
+		kept    int
+		expired []string
+	}{
+		{
+			name:    "no policy keeps everything",
+			backups: dailyBackups(10),
+			kept:    10,
+		},
+		{
+			name:    "policy without a positive rule keeps everything",
+			backups: dailyBackups(10),
+			policy:  &rbvb2.RetentionPolicy{KeepLast: ptr.To(int32(0))},
+			kept:    10,
+		},
+		{
+			name:    "empty policy keeps everything",
+			backups: dailyBackups(10),
+			policy:  &rbvb2.RetentionPolicy{},
+			kept:    10,
+		},
+		{
+			name:    "keep last",
+			backups: dailyBackups(5),
+			policy:  &rbvb2.RetentionPolicy{KeepLast: ptr.To(int32(2))},
+			kept:    2,
+			expired: []string{"2024-03-27", "2024-03-28", "2024-03-29"},
+		},
+		{
+			name:    "daily keeps one per day",
+			backups: append(dailyBackups(3), backupAt("extra", rbvb2.RedisBackupCompleted, now.Add(-time.Hour))),
+			policy:  &rbvb2.RetentionPolicy{KeepDaily: ptr.To(int32(7))},
+			kept:    3,
+			expired: []string{"extra"},
+		},
+		{
+			// 2024-03-31 is a Sunday, so the newest backups of the last two ISO weeks are the 31st and the 24th.
+			name:    "weekly and keep last are combined",
+			backups: dailyBackups(14),
+			policy:  &rbvb2.RetentionPolicy{KeepLast: ptr.To(int32(1)), KeepWeekly: ptr.To(int32(2))},
+			kept:    2,
+		},
+		{
+			name:    "monthly keeps the newest backup of each month",
+			backups: dailyBackups(60),
+			policy:  &rbvb2.RetentionPolicy{KeepMonthly: ptr.To(int32(3))},
+			kept:    2,
+		},
+		{
+			name: "failed backups expire once a newer backup completed",
+			backups: []rbvb2.RedisBackup{
+				backupAt("old-failed", rbvb2.RedisBackupFailed, now.Add(-2*time.Hour)),
+				backupAt("completed", rbvb2.RedisBackupCompleted, now.Add(-time.Hour)),
+				backupAt("new-failed", rbvb2.RedisBackupFailed, now),
+				backupAt("running", rbvb2.RedisBackupRunning, now),
+			},
+			policy:  &rbvb2.RetentionPolicy{KeepLast: ptr.To(int32(1))},
+			kept:    3,
+			expired: []string{"old-failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expired := expiredBackups(tt.backups, tt.policy)
+			assert.Len(t, tt.backups, tt.kept+len(expired))
+			if tt.expired != nil {
+				assert.Equal(t, tt.expired, names(expired))
+			}
+		})
+	}
+}
+
+func TestExpiredBackupsWeeklySelection(t *testing.T) {
+	policy := &rbvb2.RetentionPolicy{KeepWeekly: ptr.To(int32(2))}
+	expired := expiredBackups(dailyBackups(14), policy)
+
+	expiredNames := make(map[string]bool)
+	for _, b := range expired {
+		expiredNames[b.Name] = true
+	}
+	for _, kept := range []string{"2024-03-31", "2024-03-24"} {
+		assert.False(t, expiredNames[kept], "%s should be kept", kept)
+	}
+	assert.Len(t, expired, 12)
+}
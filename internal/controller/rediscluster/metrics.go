@@ -0,0 +1,51 @@
+package rediscluster
+
+import (
+	"context"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/monitoring"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// recordTopology exports the slot coverage, the open slots and the replicas of each shard of the cluster.
+func (r *Reconciler) recordTopology(ctx context.Context, instance *rcvb2.RedisCluster) {
+	topology, err := k8sutils.GetRedisClusterTopology(ctx, r.K8sClient, instance)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to get the topology of the cluster")
+		return
+	}
+	monitoring.RedisClusterSlotsAssigned.WithLabelValues(instance.Namespace, instance.Name).Set(float64(topology.SlotsAssigned))
+	monitoring.RedisClusterSlotsOk.WithLabelValues(instance.Namespace, instance.Name).Set(float64(topology.SlotsOK))
+	monitoring.RedisClusterSlotsPfail.WithLabelValues(instance.Namespace, instance.Name).Set(float64(topology.SlotsPFail))
+	monitoring.RedisClusterSlotsFail.WithLabelValues(instance.Namespace, instance.Name).Set(float64(topology.SlotsFail))
+	monitoring.RedisClusterOpenSlots.WithLabelValues(instance.Namespace, instance.Name).Set(float64(topology.OpenSlots))
+
+	// Replace the series of the shards, dropping the ones of the masters no longer serving slots.
+	monitoring.RedisClusterShardReplicas.DeletePartialMatch(prometheus.Labels{"namespace": instance.Namespace, "instance": instance.Name})
+	for shard, replicas := range topology.ShardReplicas {
+		monitoring.RedisClusterShardReplicas.WithLabelValues(instance.Namespace, instance.Name, shard).Set(float64(replicas))
+	}
+}
+
+// runOperation runs fn, an operation on the nodes of the cluster, in a span named after it, and counts
+// its failure.
+func runOperation(ctx context.Context, instance *rcvb2.RedisCluster, operation string, fn func(ctx context.Context) error) error {
+	ctx, span := tracing.Start(ctx, operation)
+	err := fn(ctx)
+	tracing.End(span, err)
+	if err != nil {
+		monitoring.RedisClusterFailedExecTotal.WithLabelValues(instance.Namespace, instance.Name, operation).Inc()
+	}
+	return err
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
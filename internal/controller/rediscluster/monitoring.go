@@ -0,0 +1,20 @@
+package rediscluster
+
+import (
+	"context"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcileMonitoring creates the ServiceMonitors and the PrometheusRule of the exporters when the
+// Prometheus Operator is installed. Scraping is not needed to serve, a failure doesn't hold back the reconcile.
+func (r *Reconciler) reconcileMonitoring(ctx context.Context, instance *rcvb2.RedisCluster) {
+	if err := k8sutils.CreateOrUpdateRedisClusterMonitoring(ctx, r.Client, instance); err != nil {
+		log.FromContext(ctx).Error(err, "failed to reconcile the monitoring of the exporters")
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisMonitoringFailed, err.Error())
+	}
+}
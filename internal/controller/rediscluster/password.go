@@ -0,0 +1,50 @@
+package rediscluster
+
+import (
+	"context"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reconcilePassword watches the redisSecret, and rotates a change of its password onto the running nodes
+// one phase per reconcile. It returns whether the rotation is still in progress.
+func (r *Reconciler) reconcilePassword(ctx context.Context, instance *rcvb2.RedisCluster) (bool, error) {
+	secret := instance.Spec.KubernetesConfig.ExistingPasswordSecret
+	if secret == nil {
+		return false, nil
+	}
+	r.SecretWatcher.Watch(ctx,
+		types.NamespacedName{Namespace: instance.Namespace, Name: *secret.Name},
+		types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name},
+	)
+
+	rotation, err := k8sutils.RotateRedisClusterPassword(ctx, r.K8sClient, instance)
+	if rotation != nil && !equality.Semantic.DeepEqual(rotation, instance.Status.PasswordRotation) {
+		previous := instance.Status.PasswordRotation
+		status := *instance.Status.DeepCopy()
+		status.PasswordRotation = rotation
+		if _, sErr := r.writeStatus(ctx, instance, status); sErr != nil {
+			return false, sErr
+		}
+		instance.Status = status
+		switch {
+		case rotation.Phase == commonapi.PasswordRotationAddPassword && (previous == nil || previous.Phase == commonapi.PasswordRotationCompleted):
+			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisPasswordRotationStarted,
+				"Rotating the password of the redisSecret onto the running nodes")
+		case rotation.Phase == commonapi.PasswordRotationCompleted:
+			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisPasswordRotationCompleted,
+				"The nodes only accept the new password of the redisSecret")
+		}
+	}
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisPasswordRotationFailed, err.Error())
+		return false, err
+	}
+	return rotation != nil && rotation.Phase != commonapi.PasswordRotationCompleted, nil
+}
@@ -18,10 +18,12 @@ package rediscluster
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"time"
 
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
@@ -29,6 +31,7 @@ import (
 	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/monitoring"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
 	retry "github.com/avast/retry-go"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -49,10 +52,11 @@ const (
 type Reconciler struct {
 	client.Client
 	k8sutils.StatefulSet
-	Healer    redis.Healer
-	Checker   redis.Checker
-	K8sClient kubernetes.Interface
-	Recorder  record.EventRecorder
+	Healer        redis.Healer
+	Checker       redis.Checker
+	K8sClient     kubernetes.Interface
+	Recorder      record.EventRecorder
+	SecretWatcher *intctrlutil.ResourceWatcher
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -63,6 +67,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err != nil {
 		return intctrlutil.RequeueECheck(ctx, err, "failed to get redis cluster instance")
 	}
+	ctx = events.IntoContext(ctx, events.NewObjectRecorder(r.Recorder, instance))
 	if instance.GetDeletionTimestamp() != nil {
 		if err = k8sutils.HandleRedisClusterFinalizer(ctx, r.Client, instance, RedisClusterFinalizer); err != nil {
 			return intctrlutil.RequeueE(ctx, err, "failed to handle redis cluster finalizer")
@@ -74,85 +79,74 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 	instance.SetDefault()
 
+	result, err := r.reconcile(ctx, instance)
+	if err != nil {
+		status := *instance.Status.DeepCopy()
+		common.SetReconcileFailed(&status.ConditionedStatus, instance.Generation, err)
+		if _, sErr := r.writeStatus(ctx, instance, status); sErr != nil {
+			logger.Error(sErr, "failed to record reconcile failure in RedisCluster conditions")
+		}
+	}
+	return result, err
+}
+
+func (r *Reconciler) reconcile(ctx context.Context, instance *rcvb2.RedisCluster) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	var err error
+
 	leaderReplicas := instance.Spec.GetReplicaCounts("leader")
 	followerReplicas := instance.Spec.GetReplicaCounts("follower")
 	totalReplicas := leaderReplicas + followerReplicas
 
+	timer := monitoring.NewPhaseTimer(ctx, monitoring.RedisClusterReconcileDurationSeconds, instance.Namespace, instance.Name)
+	defer timer.Done()
+
+	ctx = timer.Phase("finalizer")
 	if err = k8sutils.AddFinalizer(ctx, instance, RedisClusterFinalizer, r.Client); err != nil {
 		return intctrlutil.RequeueE(ctx, err, "failed to add finalizer")
 	}
 
-	// Check if the cluster is downscaled
-	if leaderCount := r.GetStatefulSetReplicas(ctx, instance.Namespace, instance.Name+"-leader"); leaderReplicas < leaderCount {
+	// Rotate a change of the password of the redisSecret onto the running nodes before anything else,
+	// a scale-down in progress included, connects to them with it.
+	ctx = timer.Phase("password")
+	if rotating, err := r.reconcilePassword(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to rotate the password")
+	} else if rotating {
+		return intctrlutil.RequeueAfter(ctx, time.Second, "rotating the password", "Phase", instance.Status.PasswordRotation.Phase)
+	}
+
+	// Check if the cluster is downscaled, or resume the scale-down in progress
+	ctx = timer.Phase("scaledown")
+	if leaderCount := r.GetStatefulSetReplicas(ctx, instance.Namespace, instance.Name+"-leader"); leaderReplicas < leaderCount || instance.Status.ScaleDown != nil {
 		if !r.IsStatefulSetReady(ctx, instance.Namespace, instance.Name+"-leader") || !r.IsStatefulSetReady(ctx, instance.Namespace, instance.Name+"-follower") {
 			return intctrlutil.Reconciled()
 		}
+		if instance.Status.ScaleDown != nil {
+			return r.scaleDown(ctx, instance, leaderCount)
+		}
 		if masterCount := k8sutils.CheckRedisNodeCount(ctx, r.K8sClient, instance, "leader"); masterCount == leaderCount {
-			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisClusterDownscale, "Redis cluster is downscaling...")
-			logger.Info("Redis cluster is downscaling...", "Current.LeaderReplicas", leaderCount, "Desired.LeaderReplicas", leaderReplicas)
-
-			// Before resharding, ensure all remaining leader pods (the transfer targets) are masters.
-			// After scale-out, a failover may have converted some leader pods to slaves, which causes
-			// reshard to fail with "The specified node is not known or not a master".
-			// We handle one failover per reconcile cycle and requeue — the loop will converge
-			// over successive reconciliations until all target pods are masters.
-			for i := int32(0); i < leaderReplicas; i++ {
-				if !(k8sutils.VerifyLeaderPod(ctx, r.K8sClient, instance, i)) {
-					logger.Info("Transfer target leader pod is not a master, initiating failover before scale-down", "Pod.Index", i)
-					if err = k8sutils.ClusterFailover(ctx, r.K8sClient, instance, i); err != nil {
-						logger.Error(err, "Failed to initiate cluster failover for transfer target")
-						return intctrlutil.RequeueE(ctx, err, "")
-					}
-					return intctrlutil.RequeueAfter(ctx, time.Second*10, "Waiting for failover to complete before scale-down")
-				}
-			}
-
-			for shardIdx := leaderCount - 1; shardIdx >= leaderReplicas; shardIdx-- {
-				logger.Info("Remove the shard", "Shard.Index", shardIdx)
-				//  Imp if the last index of leader sts is not leader make it then
-				// check whether the redis is leader or not ?
-				// if not true then make it leader pod
-				if !(k8sutils.VerifyLeaderPod(ctx, r.K8sClient, instance, shardIdx)) {
-					// lastLeaderPod is slaving right now Make it the master Pod
-					// We have to bring a manual failover here to make it a leaderPod
-					// clusterFailover should also include the clusterReplicate since we have to map the followers to new leader
-					logger.Info("Cluster Failover is initiated", "Shard.Index", shardIdx)
-					if err = k8sutils.ClusterFailover(ctx, r.K8sClient, instance, shardIdx); err != nil {
-						logger.Error(err, "Failed to initiate cluster failover")
-						return intctrlutil.RequeueE(ctx, err, "")
-					}
-				}
-				// Step 1 Remove the Follower Node
-				k8sutils.RemoveRedisFollowerNodesFromCluster(ctx, r.K8sClient, instance, shardIdx)
-				monitoring.RedisClusterRemoveFollowerAttempt.WithLabelValues(instance.Namespace, instance.Name).Inc()
-				// Step 2 Reshard the Cluster
-				// We round robin over the remaining nodes to pick a node where to move the shard to.
-				// This helps reduce the chance of overloading/OOMing the remaining nodes
-				// and makes the subsequent rebalancing step more efficient.
-				// TODO: consider doing the resharding in parallel
-				shardMoveNodeIdx := shardIdx % leaderReplicas
-				k8sutils.ReshardRedisCluster(ctx, r.K8sClient, instance, shardIdx, shardMoveNodeIdx, true)
-				monitoring.RedisClusterReshardTotal.WithLabelValues(instance.Namespace, instance.Name).Inc()
-			}
-			// Step 3 Rebalance the cluster. With a single remaining leader there is
-			// nothing to rebalance: all slots were already resharded to leader-0 and
-			// the rebalance command targets leader-1, which is no longer part of the
-			// cluster at this point.
-			if leaderReplicas > 1 {
-				logger.Info("Redis cluster is downscaled... Rebalancing the cluster")
-				k8sutils.RebalanceRedisCluster(ctx, r.K8sClient, instance)
-				logger.Info("Redis cluster is downscaled... Rebalancing the cluster is done")
-				monitoring.RedisClusterRebalanceTotal.WithLabelValues(instance.Namespace, instance.Name).Inc()
-			} else {
-				logger.Info("Redis cluster is downscaled... Skipping rebalance for single-node cluster")
-			}
-			return intctrlutil.RequeueAfter(ctx, time.Second*10, "")
+			return r.scaleDown(ctx, instance, leaderCount)
 		} else {
 			logger.Info("masterCount is not equal to leader statefulset replicas,skip downscale", "masterCount", masterCount, "leaderReplicas", leaderReplicas)
 		}
 	}
 
+	// Check a change of the Redis version before the StatefulSets roll it out, and halt it once rolled
+	// out when an upgraded pod can't start.
+	ctx = timer.Phase("version")
+	if err = r.reconcileVersion(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "version change blocked")
+	}
+
+	// Request the certificates from cert-manager, and reload a change of the TLS secret on the running nodes.
+	ctx = timer.Phase("tls")
+	if result, err := r.reconcileTLS(ctx, instance); err != nil {
+		return result, err
+	}
+	r.reconcileMonitoring(ctx, instance)
+
 	// Mark the cluster status as initializing if there are no leader or follower nodes
+	ctx = timer.Phase("resources")
 	if (instance.Status.ReadyLeaderReplicas == 0 && instance.Status.ReadyFollowerReplicas == 0) ||
 		instance.Status.ReadyLeaderReplicas != leaderReplicas {
 		requeue, err := r.updateStatus(ctx, instance, rcvb2.RedisClusterStatus{
@@ -173,7 +167,19 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err != nil {
 		return intctrlutil.RequeueE(ctx, err, "")
 	}
-	err = k8sutils.CreateRedisLeader(ctx, instance, r.K8sClient)
+	err = k8sutils.EnsureRedisClusterLoadBalancerServices(ctx, instance, "leader", r.K8sClient)
+	if errors.Is(err, k8sutils.ErrLoadBalancerPending) {
+		return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for the load balancers of the pods", "reason", err.Error())
+	}
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	restore, err := k8sutils.ResolveRestoreSource(ctx, r.Client, r.K8sClient, instance.Namespace, instance.Name+"-leader", instance.Spec.RestoreFrom, nil, int(leaderReplicas))
+	if err != nil {
+		events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisRestoreFailed, "", err.Error())
+		return intctrlutil.RequeueE(ctx, err, "failed to resolve restoreFrom")
+	}
+	err = k8sutils.CreateRedisLeader(ctx, instance, r.K8sClient, restore)
 	if err != nil {
 		return intctrlutil.RequeueE(ctx, err, "")
 	}
@@ -210,6 +216,22 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		if err != nil {
 			return intctrlutil.RequeueE(ctx, err, "")
 		}
+		err = k8sutils.EnsureRedisClusterLoadBalancerServices(ctx, instance, "follower", r.K8sClient)
+		if errors.Is(err, k8sutils.ErrLoadBalancerPending) {
+			return intctrlutil.RequeueAfter(ctx, time.Second*10, "waiting for the load balancers of the pods", "reason", err.Error())
+		}
+		if err != nil {
+			return intctrlutil.RequeueE(ctx, err, "")
+		}
+		// Remove the followers beyond the desired count from the cluster before the StatefulSet deletes their pods,
+		// so the remaining leaders do not keep failed replicas around.
+		if followerCount := r.GetStatefulSetReplicas(ctx, instance.Namespace, instance.Name+"-follower"); followerReplicas < followerCount {
+			if err = runOperation(ctx, instance, "remove_follower", func(ctx context.Context) error {
+				return k8sutils.RemoveRedisFollowerPodsFromCluster(ctx, r.K8sClient, instance, followerReplicas, followerCount)
+			}); err != nil {
+				return intctrlutil.RequeueE(ctx, err, "failed to remove the surplus followers from the cluster")
+			}
+		}
 		err = k8sutils.CreateRedisFollower(ctx, instance, r.K8sClient)
 		if err != nil {
 			return intctrlutil.RequeueE(ctx, err, "")
@@ -252,6 +274,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	// Mark the cluster status as bootstrapping if all the leader and follower nodes are ready
+	ctx = timer.Phase("bootstrap")
 	if instance.Status.ReadyLeaderReplicas != leaderReplicas || instance.Status.ReadyFollowerReplicas != followerReplicas {
 		requeue, err := r.updateStatus(ctx, instance, rcvb2.RedisClusterStatus{
 			State:                 rcvb2.RedisClusterBootstrap,
@@ -275,7 +298,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		} else {
 			if !slotsAssigned {
 				logger.Info("Start creating a single-node redis cluster")
-				k8sutils.ExecuteRedisClusterCommand(ctx, r.K8sClient, instance)
+				if err := r.createCluster(ctx, instance, restore); err != nil {
+					return intctrlutil.RequeueE(ctx, err, "failed to create the cluster")
+				}
 			}
 		}
 	}
@@ -286,7 +311,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		if leaderCount != leaderReplicas {
 			logger.Info("Not all leader are part of the cluster...", "Leaders.Count", leaderCount, "Instance.Size", leaderReplicas)
 			if leaderCount < leaderReplicas {
-				scaleUp, err := r.shouldScaleUpExistingCluster(ctx, instance, leaderCount)
+				scaleUp, err := r.shouldScaleUpExistingCluster(ctx, instance, leaderCount, restore)
 				if err != nil {
 					return intctrlutil.RequeueE(ctx, err, "failed to determine whether an existing cluster is being scaled up")
 				}
@@ -298,22 +323,25 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 						logger.Error(err, "Failed to fix redis cluster slots, proceeding with scale-up")
 					}
 					// Step 2 : Add Redis Node
-					k8sutils.AddRedisNodeToCluster(ctx, r.K8sClient, instance)
+					err = runOperation(ctx, instance, "add_leader", func(ctx context.Context) error {
+						return k8sutils.AddRedisNodeToCluster(ctx, r.K8sClient, instance)
+					})
 					monitoring.RedisClusterAddingNodeAttempt.WithLabelValues(instance.Namespace, instance.Name).Inc()
+					if err != nil {
+						return intctrlutil.RequeueE(ctx, err, "failed to add the leader to the cluster")
+					}
 
 					return intctrlutil.RequeueAfter(ctx, 10*time.Second, "added node, waiting for cluster convergence before rebalancing")
 				}
 				// No functioning cluster exists yet, create one from scratch.
 				logger.Info("Creating cluster", "Current.Leaders", leaderCount, "Desired.Leaders", leaderReplicas)
-				k8sutils.ExecuteRedisClusterCommand(ctx, r.K8sClient, instance)
+				if err := r.createCluster(ctx, instance, restore); err != nil {
+					return intctrlutil.RequeueE(ctx, err, "failed to create the cluster")
+				}
 			}
 		} else {
-			stable, err := k8sutils.ClusterStableNoOpenSlots(ctx, r.K8sClient, instance)
-			if err != nil {
-				return ctrl.Result{}, err
-			}
-			if !stable {
-				return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+			if err := k8sutils.CheckRedisCluster(ctx, r.K8sClient, instance); err != nil {
+				return intctrlutil.RequeueAfter(ctx, 10*time.Second, "waiting for the cluster to be stable before rebalancing", "reason", err.Error())
 			}
 
 			empty, err := k8sutils.ClusterHasEmptyMasters(ctx, r.K8sClient, instance)
@@ -321,12 +349,20 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 				return ctrl.Result{}, err
 			}
 			if empty {
-				k8sutils.RebalanceRedisClusterEmptyMasters(ctx, r.K8sClient, instance)
+				if err = runOperation(ctx, instance, "rebalance", func(ctx context.Context) error {
+					return k8sutils.RebalanceRedisClusterEmptyMasters(ctx, r.K8sClient, instance)
+				}); err != nil {
+					return intctrlutil.RequeueE(ctx, err, "failed to rebalance the cluster onto the empty masters")
+				}
 			}
 
 			if followerReplicas > 0 {
 				logger.Info("All leader are part of the cluster, adding follower/replicas", "Leaders.Count", leaderCount, "Instance.Size", leaderReplicas, "Follower.Replicas", followerReplicas)
-				k8sutils.ExecuteRedisReplicationCommand(ctx, r.K8sClient, instance)
+				if err = runOperation(ctx, instance, "add_follower", func(ctx context.Context) error {
+					return k8sutils.ExecuteRedisReplicationCommand(ctx, r.K8sClient, instance)
+				}); err != nil {
+					return intctrlutil.RequeueE(ctx, err, "failed to add the followers to the cluster")
+				}
 			} else {
 				logger.Info("no follower/replicas configured, skipping replication configuration", "Leaders.Count", leaderCount, "Leader.Size", leaderReplicas, "Follower.Replicas", followerReplicas)
 			}
@@ -335,9 +371,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	logger.Info("Number of Redis nodes match desired")
+	ctx = timer.Phase("repair")
 	unhealthyNodeCount, err := k8sutils.UnhealthyNodesInCluster(ctx, r.K8sClient, instance)
 	if err != nil {
 		logger.Error(err, "failed to determine unhealthy node count in cluster")
+	} else {
+		monitoring.RedisClusterUnhealthy.WithLabelValues(instance.Namespace, instance.Name).Set(boolToFloat(unhealthyNodeCount > 0))
 	}
 	if int(totalReplicas) > 1 && unhealthyNodeCount > 0 {
 		requeue, err := r.updateStatus(ctx, instance, rcvb2.RedisClusterStatus{
@@ -354,7 +393,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 
 		logger.Info("Cluster has unhealthy nodes; attempting to repair disconnected nodes")
-		if err = k8sutils.RepairDisconnectedNodes(ctx, r.K8sClient, instance); err != nil {
+		if err = runOperation(ctx, instance, "repair_disconnected", func(ctx context.Context) error {
+			return k8sutils.RepairDisconnectedNodes(ctx, r.K8sClient, instance)
+		}); err != nil {
 			logger.Error(err, "failed to repair disconnected nodes")
 		}
 
@@ -392,7 +433,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// one INFO replication call per connected follower (in line with the other
 	// per-reconcile checks in this loop, e.g. CheckRedisNodeCount).
 	if followerReplicas > 0 {
-		repaired, err := k8sutils.RepairStaleReplication(ctx, r.K8sClient, instance)
+		var repaired int
+		err := runOperation(ctx, instance, "repair_replication", func(ctx context.Context) (err error) {
+			repaired, err = k8sutils.RepairStaleReplication(ctx, r.K8sClient, instance)
+			return err
+		})
 		if err != nil {
 			logger.Error(err, "failed to repair stale replication links")
 		}
@@ -401,20 +446,73 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 	}
 
-	// Check If there is No Empty Master Node
-	if k8sutils.CheckRedisNodeCount(ctx, r.K8sClient, instance, "") == totalReplicas {
-		k8sutils.CheckIfEmptyMasters(ctx, r.K8sClient, instance)
+	// With upgradeStrategy Managed, roll the pods onto the update revision of their StatefulSets one
+	// shard at a time, and leave the topology as is until they all run it.
+	ctx = timer.Phase("upgrade")
+	upgrading := false
+	if instance.Spec.ManagedUpgrade() {
+		step, uErr := k8sutils.UpgradeRedisClusterPods(ctx, r.K8sClient, instance)
+		upgrading = uErr != nil || step.Outdated > 0
+		recordUpgradeStep(ctx, step, uErr)
+	}
+	// Behind per-pod load balancers, restart the nodes still announcing a previous address of theirs the
+	// same way, clients outside of Kubernetes can't follow the redirections to them.
+	if !upgrading && instance.Spec.LoadBalancerAccess() {
+		step, aErr := k8sutils.RollRedisClusterStaleAnnouncements(ctx, r.K8sClient, instance)
+		upgrading = aErr != nil || step.Outdated > 0
+		recordAnnouncementStep(ctx, step, aErr)
+	}
+
+	// Converge the slots to spec.slotRanges or spec.redisLeader.slotWeights, or onto the empty masters,
+	// the replicas to spec.replicasPerShard away from their leaders, and the masters over the zones.
+	ctx = timer.Phase("balance")
+	var zones *k8sutils.ClusterZones
+	if !upgrading && k8sutils.CheckRedisNodeCount(ctx, r.K8sClient, instance, "") == totalReplicas {
+		if err = runOperation(ctx, instance, "balance_slots", func(ctx context.Context) error {
+			return k8sutils.BalanceRedisClusterSlots(ctx, r.K8sClient, instance)
+		}); err != nil {
+			logger.Error(err, "failed to balance the slots of the cluster")
+		}
+		if followerReplicas > 0 {
+			if err = runOperation(ctx, instance, "balance_replicas", func(ctx context.Context) error {
+				return k8sutils.BalanceRedisClusterReplicas(ctx, r.K8sClient, instance)
+			}); err != nil {
+				logger.Error(err, "failed to balance the replicas of the cluster")
+			}
+		}
+		var z k8sutils.ClusterZones
+		if zErr := runOperation(ctx, instance, "balance_zones", func(ctx context.Context) (err error) {
+			z, err = k8sutils.BalanceRedisClusterZones(ctx, r.K8sClient, instance)
+			return err
+		}); zErr != nil {
+			logger.Error(zErr, "failed to balance the masters over the zones")
+		} else {
+			zones = &z
+			if z.FailedOver != "" {
+				events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisClusterZoneFailover, z.FailedOver,
+					fmt.Sprintf("Failed over to %s to spread the masters over the zones", z.FailedOver))
+			}
+		}
 	}
 
 	// Mark the cluster status as ready if all the leader and follower nodes are ready
 	// and the cluster is not already in Ready state (to avoid unnecessary status updates)
+	ctx = timer.Phase("status")
 	if instance.Status.ReadyLeaderReplicas == leaderReplicas && instance.Status.ReadyFollowerReplicas == followerReplicas && instance.Status.State != rcvb2.RedisClusterReady {
 		monitoring.RedisClusterHealthy.WithLabelValues(instance.Namespace, instance.Name).Set(0)
 		if k8sutils.RedisClusterStatusHealth(ctx, r.K8sClient, instance) {
 			monitoring.RedisClusterHealthy.WithLabelValues(instance.Namespace, instance.Name).Set(1)
 			// Apply dynamic config to all Redis instances in the cluster
-			if err = k8sutils.SetRedisClusterDynamicConfig(ctx, r.K8sClient, instance); err != nil {
+			if err = runOperation(ctx, instance, "config_set", func(ctx context.Context) error {
+				return k8sutils.SetRedisClusterDynamicConfig(ctx, r.K8sClient, instance)
+			}); err != nil {
 				logger.Error(err, "Failed to set dynamic config")
+				status := *instance.Status.DeepCopy()
+				common.SetConditions(&status.ConditionedStatus, instance.Generation,
+					common.NewCondition(commonapi.ConditionConfigDrift, true, common.ReasonConfigSetFailed, err.Error()))
+				if _, sErr := r.updateStatus(ctx, instance, status); sErr != nil {
+					logger.Error(sErr, "failed to record config drift")
+				}
 				return intctrlutil.RequeueE(ctx, err, "failed to set dynamic config")
 			}
 
@@ -440,20 +538,37 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 	}
 
+	// Record the generation of a spec change that kept the state, e.g. new resource limits,
+	// along with the current slot distribution and zone redundancy.
+	status := *instance.Status.DeepCopy()
+	if shards, sErr := k8sutils.GetRedisClusterShards(ctx, r.K8sClient, instance); sErr != nil {
+		logger.Error(sErr, "failed to get the slot distribution of the cluster")
+	} else {
+		status.Shards = shards
+	}
+	if zones != nil {
+		common.SetConditions(&status.ConditionedStatus, instance.Generation, zoneRedundantCondition(*zones))
+	}
+	r.recordTopology(ctx, instance)
+	if _, err = r.updateStatus(ctx, instance, status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+
 	return intctrlutil.RequeueAfter(ctx, time.Second*10, "")
 }
 
 // shouldScaleUpExistingCluster reports whether the missing leaders should be
-// added to an already-formed cluster (`--cluster add-node`) instead of running
-// the initial cluster creation command. More than two leaders always means the
-// cluster has been formed, since `--cluster create` requires at least three
-// nodes. With one or two leaders the cluster is either a formed single-node
-// cluster being scaled up (issue #1521) or an initial creation that has not
-// completed yet; slot assignment distinguishes the two, because a formed
-// cluster has all 16384 slots assigned and running `--cluster create` against
-// its non-empty nodes would fail.
-func (r *Reconciler) shouldScaleUpExistingCluster(ctx context.Context, instance *rcvb2.RedisCluster, leaderCount int32) (bool, error) {
-	if leaderCount > 2 {
+// added to an already-formed cluster instead of creating the cluster. More than
+// two leaders always means the cluster has been formed, since a multi-leader
+// cluster is created with at least three shards. With one or two leaders the
+// cluster is either a formed single-node cluster being scaled up (issue #1521)
+// or an initial creation that has not completed yet; slot assignment
+// distinguishes the two, because a formed cluster has all 16384 slots assigned
+// and creating a cluster from its non-empty nodes would fail. A cluster
+// restored from a backup is only formed once all slots are assigned, whatever
+// the number of leaders.
+func (r *Reconciler) shouldScaleUpExistingCluster(ctx context.Context, instance *rcvb2.RedisCluster, leaderCount int32, restore *k8sutils.RestoreSource) (bool, error) {
+	if leaderCount > 2 && restore == nil {
 		return true, nil
 	}
 	if leaderCount == 0 {
@@ -462,7 +577,68 @@ func (r *Reconciler) shouldScaleUpExistingCluster(ctx context.Context, instance
 	return r.Checker.CheckClusterSlotsAssigned(ctx, instance)
 }
 
+// createCluster forms the cluster from the leaders, restoring the slot ownership
+// recorded in the backup when the leaders were seeded from one.
+func (r *Reconciler) createCluster(ctx context.Context, instance *rcvb2.RedisCluster, restore *k8sutils.RestoreSource) error {
+	if restore == nil {
+		return runOperation(ctx, instance, "create", func(ctx context.Context) error {
+			return k8sutils.ExecuteRedisClusterCommand(ctx, r.K8sClient, instance)
+		})
+	}
+	if err := runOperation(ctx, instance, "restore", func(ctx context.Context) error {
+		return k8sutils.RestoreRedisClusterTopology(ctx, r.K8sClient, instance, restore)
+	}); err != nil {
+		events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisRestoreFailed, "", err.Error())
+		return err
+	}
+	events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisClusterRestored, "",
+		fmt.Sprintf("Restored slot ownership of %d shard(s) from backup %s", len(restore.Shards), instance.Spec.RestoreFrom.BackupName))
+	return nil
+}
+
+// updateStatus moves the cluster to the state of status, deriving the shared conditions from it.
+// A status without conditions or shards keeps the current ones of rc, and the checkpoint of the
+// scale-down in progress is always kept: it is only changed by checkpointScaleDown. So are the
+// version and password rotation statuses.
 func (r *Reconciler) updateStatus(ctx context.Context, rc *rcvb2.RedisCluster, status rcvb2.RedisClusterStatus) (requeue bool, err error) {
+	status = *status.DeepCopy()
+	if status.Conditions == nil {
+		status.ConditionedStatus = *rc.Status.ConditionedStatus.DeepCopy()
+	}
+	if status.Shards == nil {
+		status.Shards = rc.Status.DeepCopy().Shards
+	}
+	status.ScaleDown = rc.Status.ScaleDown.DeepCopy()
+	status.VersionStatus = rc.Status.VersionStatus
+	status.PasswordRotation = rc.Status.PasswordRotation.DeepCopy()
+	r.deriveConditions(ctx, rc, &status)
+	return r.writeStatus(ctx, rc, status)
+}
+
+// checkpointScaleDown records the progress of the scale-down of rc, or its completion when scaleDown is nil.
+func (r *Reconciler) checkpointScaleDown(ctx context.Context, rc *rcvb2.RedisCluster, scaleDown *rcvb2.ScaleDownStatus) error {
+	status := *rc.Status.DeepCopy()
+	status.ScaleDown = scaleDown.DeepCopy()
+	r.deriveConditions(ctx, rc, &status)
+	if _, err := r.writeStatus(ctx, rc, status); err != nil {
+		return err
+	}
+	rc.Status = status
+	return nil
+}
+
+// deriveConditions sets the shared conditions of status from its state and the number of pods of rc.
+func (r *Reconciler) deriveConditions(ctx context.Context, rc *rcvb2.RedisCluster, status *rcvb2.RedisClusterStatus) {
+	leaders, followers := rc.Spec.GetReplicaCounts("leader"), rc.Spec.GetReplicaCounts("follower")
+	countsDiffer := status.ReadyLeaderReplicas != leaders || status.ReadyFollowerReplicas != followers || status.ScaleDown != nil
+	if current := r.GetStatefulSetReplicas(ctx, rc.Namespace, rc.Name+"-leader"); current != 0 && current != leaders {
+		countsDiffer = true
+	}
+	setConditions(rc, status, countsDiffer)
+}
+
+// writeStatus updates the status of the cluster unless it is unchanged, retrying once on a conflict.
+func (r *Reconciler) writeStatus(ctx context.Context, rc *rcvb2.RedisCluster, status rcvb2.RedisClusterStatus) (requeue bool, err error) {
 	if reflect.DeepEqual(rc.Status, status) {
 		return false, nil
 	}
@@ -484,7 +660,7 @@ func (r *Reconciler) updateStatus(ctx context.Context, rc *rcvb2.RedisCluster, s
 		copy.Status = status
 		return true, common.UpdateStatus(ctx, r.Client, copy)
 	}
-	return false, nil
+	return false, err
 }
 
 // getStatefulSetReadyReplicas returns the number of ready replicas reported by
@@ -506,5 +682,6 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options)
 		For(&rcvb2.RedisCluster{}).
 		Owns(&appsv1.StatefulSet{}).
 		WithOptions(opts).
-		Complete(r)
+		WatchesMetadata(&corev1.Secret{}, r.SecretWatcher).
+		Complete(tracing.Reconciler("RedisCluster", r))
 }
@@ -24,6 +24,7 @@ import (
 
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -96,11 +97,12 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&Reconciler{
-		Client:      k8sManager.GetClient(),
-		K8sClient:   k8sClient,
-		Healer:      redis.NewHealer(k8sClient),
-		Recorder:    k8sManager.GetEventRecorderFor("rediscluster-controller"),
-		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+		Client:        k8sManager.GetClient(),
+		K8sClient:     k8sClient,
+		Healer:        redis.NewHealer(k8sClient),
+		Recorder:      k8sManager.GetEventRecorderFor("rediscluster-controller"),
+		StatefulSet:   k8sutils.NewStatefulSetService(k8sClient),
+		SecretWatcher: intctrlutil.NewResourceWatcher(),
 	}).SetupWithManager(k8sManager, controller.Options{})
 	Expect(err).ToNot(HaveOccurred())
 
@@ -17,6 +17,8 @@ import (
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/utils/ptr"
@@ -175,11 +177,23 @@ var _ = Describe("Redis Cluster Controller", func() {
 			}, timeout, interval).Should(Succeed())
 		}
 
+		// getClusterStatus returns the state of the cluster, without the conditions derived from it.
 		getClusterStatus := func() (rcvb2.RedisClusterStatus, error) {
 			rc := &rcvb2.RedisCluster{}
 			err := k8sClient.Get(context.Background(), types.NamespacedName{Name: degradedName, Namespace: ns}, rc)
+			rc.Status.ConditionedStatus = common.ConditionedStatus{}
 			return rc.Status, err
 		}
+		getCondition := func(condType string) (metav1.ConditionStatus, error) {
+			rc := &rcvb2.RedisCluster{}
+			if err := k8sClient.Get(context.Background(), types.NamespacedName{Name: degradedName, Namespace: ns}, rc); err != nil {
+				return "", err
+			}
+			if condition := meta.FindStatusCondition(rc.Status.Conditions, condType); condition != nil {
+				return condition.Status, nil
+			}
+			return metav1.ConditionUnknown, nil
+		}
 
 		It("should leave the Ready state and decrease the ready replica counts", func() {
 			redisCluster := &rcvb2.RedisCluster{
@@ -207,6 +221,8 @@ var _ = Describe("Redis Cluster Controller", func() {
 				ReadyLeaderReplicas:   3,
 				ReadyFollowerReplicas: 3,
 			}))
+			Expect(getCondition(common.ConditionProgressing)).To(Equal(metav1.ConditionTrue))
+			Expect(getCondition(common.ConditionReady)).To(Equal(metav1.ConditionFalse))
 
 			By("simulating a cluster that has reached the Ready state")
 			Eventually(func() error {
@@ -7,7 +7,15 @@ import (
 
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 type fakeChecker struct {
@@ -26,6 +34,7 @@ func TestShouldScaleUpExistingCluster(t *testing.T) {
 	tests := []struct {
 		name           string
 		leaderCount    int32
+		restore        *k8sutils.RestoreSource
 		slotsAssigned  bool
 		checkErr       error
 		wantScaleUp    bool
@@ -63,6 +72,22 @@ func TestShouldScaleUpExistingCluster(t *testing.T) {
 			leaderCount: 3,
 			wantScaleUp: true,
 		},
+		{
+			name:           "partially restored cluster keeps restoring the topology",
+			leaderCount:    3,
+			restore:        &k8sutils.RestoreSource{},
+			slotsAssigned:  false,
+			wantScaleUp:    false,
+			wantCheckerHit: true,
+		},
+		{
+			name:           "restored cluster with all slots assigned scales up via add-node",
+			leaderCount:    3,
+			restore:        &k8sutils.RestoreSource{},
+			slotsAssigned:  true,
+			wantScaleUp:    true,
+			wantCheckerHit: true,
+		},
 		{
 			name:           "slot check error is propagated",
 			leaderCount:    1,
@@ -76,7 +101,7 @@ func TestShouldScaleUpExistingCluster(t *testing.T) {
 			checker := &fakeChecker{slotsAssigned: tt.slotsAssigned, err: tt.checkErr}
 			r := &Reconciler{Checker: checker}
 
-			scaleUp, err := r.shouldScaleUpExistingCluster(context.Background(), &rcvb2.RedisCluster{}, tt.leaderCount)
+			scaleUp, err := r.shouldScaleUpExistingCluster(context.Background(), &rcvb2.RedisCluster{}, tt.leaderCount, tt.restore)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -88,3 +113,22 @@ func TestShouldScaleUpExistingCluster(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckpointScaleDownReturnsUpdateError(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, rcvb2.AddToScheme(scheme))
+	// The cluster is missing from the API server, so the status update fails without a conflict.
+	r := &Reconciler{
+		Client:      fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&rcvb2.RedisCluster{}).Build(),
+		StatefulSet: k8sutils.NewStatefulSetService(k8sfake.NewSimpleClientset()),
+	}
+	rc := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster", Namespace: "default"},
+		Spec:       rcvb2.RedisClusterSpec{ClusterSize: ptr.To(int32(3))},
+	}
+
+	err := r.checkpointScaleDown(context.Background(), rc, &rcvb2.ScaleDownStatus{FromLeaders: 3, ToLeaders: 2, Shard: 2})
+
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.Nil(t, rc.Status.ScaleDown)
+}
@@ -0,0 +1,162 @@
+package rediscluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/monitoring"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// scaleDownSlotBatch is the number of slots moved by a single reshard step, so the progress of a
+// reshard is checkpointed every few seconds rather than once per shard.
+const scaleDownSlotBatch = 1024
+
+// planScaleDown returns the checkpoint to resume the scale-down of state from, starting a scale-down
+// from fromLeaders leaders when state is nil. When the spec asks for fewer leaders than planned, the
+// shards past the planned ones are removed too, and the shard in progress moves its remaining slots to
+// another leader if its target is among them; a spec asking for more leaders is applied by a scale-up
+// once the scale-down completes.
+func planScaleDown(state *rcvb2.ScaleDownStatus, fromLeaders, toLeaders int32) *rcvb2.ScaleDownStatus {
+	if state == nil {
+		return &rcvb2.ScaleDownStatus{
+			Step:        rcvb2.ScaleDownPromoteTargets,
+			FromLeaders: fromLeaders,
+			ToLeaders:   toLeaders,
+			Shard:       fromLeaders - 1,
+			Target:      (fromLeaders - 1) % toLeaders,
+		}
+	}
+	state = state.DeepCopy()
+	if toLeaders < state.ToLeaders {
+		if state.Step == rcvb2.ScaleDownRebalance {
+			state.Shard, state.Step = state.ToLeaders-1, rcvb2.ScaleDownFailover
+			state.Target = state.Shard % toLeaders
+		} else if state.Target >= toLeaders {
+			// The slots already moved to the old target are moved again once it is removed.
+			state.Target, state.MigratedSlots = state.Shard%toLeaders, ""
+		}
+		state.ToLeaders = toLeaders
+	}
+	return state
+}
+
+// scaleDown runs the next step of the scale-down of the leaders and checkpoints its progress in the
+// status, so that each reconcile resumes where the previous one stopped, even across restarts of the
+// operator. A failed step is retried by the next reconcile and reported in the Degraded condition.
+func (r *Reconciler) scaleDown(ctx context.Context, instance *rcvb2.RedisCluster, leaderCount int32) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	leaderReplicas := instance.Spec.GetReplicaCounts("leader")
+
+	if instance.Status.ScaleDown == nil {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisClusterDownscale, "Redis cluster is downscaling...")
+		logger.Info("Redis cluster is downscaling...", "Current.LeaderReplicas", leaderCount, "Desired.LeaderReplicas", leaderReplicas)
+	}
+	state := planScaleDown(instance.Status.ScaleDown, leaderCount, leaderReplicas)
+
+	logger.Info("Running scale-down step", "Step", state.Step, "Shard.Index", state.Shard, "Target.Index", state.Target)
+	var next *rcvb2.ScaleDownStatus
+	var requeueAfter time.Duration
+	err := runOperation(ctx, instance, "scale_down", func(ctx context.Context) (err error) {
+		next, requeueAfter, err = r.runScaleDownStep(ctx, instance, state)
+		return err
+	})
+	if err != nil {
+		// Keep the checkpoint of the steps that completed so far; the failed step is retried.
+		if cErr := r.checkpointScaleDown(ctx, instance, state); cErr != nil {
+			logger.Error(cErr, "failed to checkpoint the scale-down")
+		}
+		return intctrlutil.RequeueE(ctx, fmt.Errorf("scale-down of shard %d failed at step %s: %w", state.Shard, state.Step, err), "")
+	}
+	if err := r.checkpointScaleDown(ctx, instance, next); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to checkpoint the scale-down")
+	}
+	if next == nil {
+		logger.Info("Redis cluster is downscaled", "LeaderReplicas", state.ToLeaders)
+	}
+	return intctrlutil.RequeueAfter(ctx, requeueAfter, "")
+}
+
+// runScaleDownStep runs the step of state and returns the checkpoint of the step to run next, nil once
+// the scale-down is complete, along with the delay before running it. Every step can be run again.
+func (r *Reconciler) runScaleDownStep(ctx context.Context, instance *rcvb2.RedisCluster, state *rcvb2.ScaleDownStatus) (*rcvb2.ScaleDownStatus, time.Duration, error) {
+	next := state.DeepCopy()
+	switch state.Step {
+	case rcvb2.ScaleDownPromoteTargets:
+		// The slots can only be moved to masters. After a scale-out, a failover may have turned some
+		// leader pods into replicas: fail over one of them per reconcile until all of them are masters.
+		for i := int32(0); i < state.ToLeaders; i++ {
+			if !k8sutils.VerifyLeaderPod(ctx, r.K8sClient, instance, i) {
+				log.FromContext(ctx).Info("Transfer target leader pod is not a master, initiating failover before scale-down", "Pod.Index", i)
+				return next, 10 * time.Second, k8sutils.ClusterFailover(ctx, r.K8sClient, instance, i)
+			}
+		}
+		next.Step = rcvb2.ScaleDownFailover
+
+	case rcvb2.ScaleDownFailover:
+		if !k8sutils.VerifyLeaderPod(ctx, r.K8sClient, instance, state.Shard) {
+			log.FromContext(ctx).Info("Cluster Failover is initiated", "Shard.Index", state.Shard)
+			return next, 10 * time.Second, k8sutils.ClusterFailover(ctx, r.K8sClient, instance, state.Shard)
+		}
+		next.Step = rcvb2.ScaleDownRemoveFollowers
+
+	case rcvb2.ScaleDownRemoveFollowers:
+		monitoring.RedisClusterRemoveFollowerAttempt.WithLabelValues(instance.Namespace, instance.Name).Inc()
+		if err := k8sutils.RemoveRedisFollowerNodesFromCluster(ctx, r.K8sClient, instance, state.Shard); err != nil {
+			return nil, 0, err
+		}
+		next.Step = rcvb2.ScaleDownReshard
+
+	case rcvb2.ScaleDownReshard:
+		// Close the slots left open by a batch that was interrupted before moving on.
+		if err := k8sutils.FixRedisCluster(ctx, r.K8sClient, instance); err != nil {
+			return nil, 0, err
+		}
+		migrated, remaining, err := k8sutils.MoveRedisClusterSlots(ctx, r.K8sClient, instance, state.Shard, state.Target, scaleDownSlotBatch, state.MigratedSlots)
+		if err != nil {
+			return nil, 0, err
+		}
+		next.MigratedSlots = migrated
+		if remaining == 0 {
+			next.Step = rcvb2.ScaleDownRemoveLeader
+		}
+
+	case rcvb2.ScaleDownRemoveLeader:
+		pod := k8sutils.RedisDetails{PodName: fmt.Sprintf("%s-leader-%d", instance.Name, state.Shard), Namespace: instance.Namespace}
+		if err := k8sutils.RemoveRedisNodeFromCluster(ctx, r.K8sClient, instance, pod); err != nil {
+			return nil, 0, err
+		}
+		monitoring.RedisClusterReshardTotal.WithLabelValues(instance.Namespace, instance.Name).Inc()
+		next.MigratedSlots = ""
+		if state.Shard-1 >= state.ToLeaders {
+			// Round robin over the remaining leaders to pick the target of the next shard, which avoids
+			// overloading a single leader and makes the final rebalance cheaper.
+			next.Shard = state.Shard - 1
+			next.Target = next.Shard % state.ToLeaders
+			next.Step = rcvb2.ScaleDownFailover
+		} else {
+			next.Step = rcvb2.ScaleDownRebalance
+		}
+
+	case rcvb2.ScaleDownRebalance:
+		// With a single remaining leader there is nothing to rebalance: it serves all the slots.
+		if state.ToLeaders > 1 {
+			if err := k8sutils.RebalanceRedisCluster(ctx, r.K8sClient, instance); err != nil {
+				return nil, 0, err
+			}
+			monitoring.RedisClusterRebalanceTotal.WithLabelValues(instance.Namespace, instance.Name).Inc()
+		}
+		return nil, 10 * time.Second, nil
+
+	default:
+		return nil, 0, fmt.Errorf("unknown scale-down step %q", state.Step)
+	}
+	return next, time.Second, nil
+}
@@ -0,0 +1,45 @@
+package rediscluster
+
+import (
+	"testing"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanScaleDown(t *testing.T) {
+	start := planScaleDown(nil, 6, 3)
+	assert.Equal(t, &rcvb2.ScaleDownStatus{
+		Step:        rcvb2.ScaleDownPromoteTargets,
+		FromLeaders: 6,
+		ToLeaders:   3,
+		Shard:       5,
+		Target:      2,
+	}, start)
+
+	t.Run("resumes from the checkpoint", func(t *testing.T) {
+		checkpoint := &rcvb2.ScaleDownStatus{Step: rcvb2.ScaleDownReshard, FromLeaders: 6, ToLeaders: 3, Shard: 4, Target: 1, MigratedSlots: "0-1023"}
+		resumed := planScaleDown(checkpoint, 6, 3)
+		assert.Equal(t, checkpoint, resumed)
+		assert.NotSame(t, checkpoint, resumed)
+	})
+
+	t.Run("fewer leaders extend the scale-down", func(t *testing.T) {
+		checkpoint := &rcvb2.ScaleDownStatus{Step: rcvb2.ScaleDownReshard, FromLeaders: 6, ToLeaders: 4, Shard: 5, Target: 1}
+		assert.Equal(t, &rcvb2.ScaleDownStatus{Step: rcvb2.ScaleDownReshard, FromLeaders: 6, ToLeaders: 3, Shard: 5, Target: 1},
+			planScaleDown(checkpoint, 6, 3), "the shard in progress keeps its target")
+
+		rebalancing := &rcvb2.ScaleDownStatus{Step: rcvb2.ScaleDownRebalance, FromLeaders: 6, ToLeaders: 4, Shard: 4, Target: 0}
+		assert.Equal(t, &rcvb2.ScaleDownStatus{Step: rcvb2.ScaleDownFailover, FromLeaders: 6, ToLeaders: 3, Shard: 3, Target: 0},
+			planScaleDown(rebalancing, 6, 3), "the next shard is removed before rebalancing")
+
+		removedTarget := &rcvb2.ScaleDownStatus{Step: rcvb2.ScaleDownReshard, FromLeaders: 8, ToLeaders: 4, Shard: 7, Target: 3, MigratedSlots: "0-1023"}
+		assert.Equal(t, &rcvb2.ScaleDownStatus{Step: rcvb2.ScaleDownReshard, FromLeaders: 8, ToLeaders: 2, Shard: 7, Target: 1},
+			planScaleDown(removedTarget, 8, 2), "a shard whose target is removed too moves its slots to a remaining leader")
+	})
+
+	t.Run("more leaders are left to a scale-up", func(t *testing.T) {
+		checkpoint := &rcvb2.ScaleDownStatus{Step: rcvb2.ScaleDownReshard, FromLeaders: 6, ToLeaders: 3, Shard: 5, Target: 2}
+		assert.Equal(t, checkpoint, planScaleDown(checkpoint, 6, 4))
+	})
+}
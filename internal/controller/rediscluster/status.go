@@ -0,0 +1,73 @@
+package rediscluster
+
+import (
+	"fmt"
+	"strings"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setConditions derives the shared conditions from the state of status. countsDiffer is set while
+// the number of leaders or followers differs from the spec of rc.
+func setConditions(rc *rcvb2.RedisCluster, status *rcvb2.RedisClusterStatus, countsDiffer bool) {
+	ready := common.NewCondition(commonapi.ConditionReady, false, string(status.State), status.Reason)
+	progressing := common.NewCondition(commonapi.ConditionProgressing, false, common.ReasonComplete, "The spec is rolled out")
+	degraded := common.NewCondition(commonapi.ConditionDegraded, false, common.ReasonHealthy, "All cluster nodes are healthy")
+	drift := meta.FindStatusCondition(status.Conditions, commonapi.ConditionConfigDrift)
+	switch status.State {
+	case rcvb2.RedisClusterReady:
+		ready.Status, ready.Reason = metav1.ConditionTrue, common.ReasonReady
+		// The dynamic config is applied right before the cluster turns Ready.
+		drift = nil
+	case rcvb2.RedisClusterFailed:
+		progressing.Reason, progressing.Message = string(status.State), status.Reason
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, string(status.State), status.Reason
+	default:
+		progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionTrue, string(status.State), status.Reason
+	}
+	if ready.Reason == "" {
+		ready.Reason = string(rcvb2.RedisClusterInitializing)
+	}
+
+	conditions := []metav1.Condition{ready, progressing, degraded}
+	if drift == nil {
+		conditions = append(conditions, common.NewCondition(commonapi.ConditionConfigDrift, false, common.ReasonInSync, "The running config matches the spec"))
+	}
+	scaling := common.IsScaling(status.ConditionedStatus, countsDiffer, status.State == rcvb2.RedisClusterReady)
+	msg := fmt.Sprintf("Scaling to %d leaders and %d followers", rc.Spec.GetReplicaCounts("leader"), rc.Spec.GetReplicaCounts("follower"))
+	if sd := status.ScaleDown; sd != nil {
+		msg += fmt.Sprintf(": step %s of the removal of shard %d", sd.Step, sd.Shard)
+	}
+	conditions = append(conditions, common.ScalingCondition(scaling, msg))
+	common.SetConditions(&status.ConditionedStatus, rc.Generation, conditions...)
+}
+
+// Reasons of the ZoneRedundant condition
+const (
+	reasonZoneRedundant    = "ZoneRedundant"
+	reasonSingleZoneShards = "SingleZoneShards"
+	reasonZonesUnknown     = "ZonesUnknown"
+)
+
+// zoneRedundantCondition reports whether every shard of the cluster spans at least two zones.
+func zoneRedundantCondition(zones k8sutils.ClusterZones) metav1.Condition {
+	switch {
+	case len(zones.Unknown) > 0:
+		return metav1.Condition{
+			Type:    rcvb2.RedisClusterConditionZoneRedundant,
+			Status:  metav1.ConditionUnknown,
+			Reason:  reasonZonesUnknown,
+			Message: "The zone of the node of " + strings.Join(zones.Unknown, ", ") + " is unknown",
+		}
+	case len(zones.SingleZoneShards) > 0:
+		return common.NewCondition(rcvb2.RedisClusterConditionZoneRedundant, false, reasonSingleZoneShards,
+			fmt.Sprintf("The shards of %s run in a single zone out of %d", strings.Join(zones.SingleZoneShards, ", "), zones.Zones))
+	}
+	return common.NewCondition(rcvb2.RedisClusterConditionZoneRedundant, true, reasonZoneRedundant,
+		fmt.Sprintf("Every shard spans at least two of %d zones", zones.Zones))
+}
@@ -0,0 +1,99 @@
+package rediscluster
+
+import (
+	"testing"
+
+	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	controllercommon "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestSetConditions(t *testing.T) {
+	rc := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Generation: 4},
+		Spec:       rcvb2.RedisClusterSpec{ClusterSize: ptr.To(int32(3))},
+	}
+	conditions := func(status rcvb2.RedisClusterStatus) map[string]metav1.ConditionStatus {
+		got := map[string]metav1.ConditionStatus{}
+		for _, condition := range status.Conditions {
+			assert.Equal(t, int64(4), condition.ObservedGeneration)
+			got[condition.Type] = condition.Status
+		}
+		return got
+	}
+	next := func(previous rcvb2.RedisClusterStatus, state rcvb2.RedisClusterState, countsDiffer bool) rcvb2.RedisClusterStatus {
+		status := *previous.DeepCopy()
+		status.State = state
+		status.Reason = string(state)
+		setConditions(rc, &status, countsDiffer)
+		return status
+	}
+
+	initializing := next(rcvb2.RedisClusterStatus{}, rcvb2.RedisClusterInitializing, true)
+	assert.Equal(t, int64(4), initializing.ObservedGeneration)
+	assert.Equal(t, map[string]metav1.ConditionStatus{
+		common.ConditionReady:             metav1.ConditionFalse,
+		common.ConditionProgressing:       metav1.ConditionTrue,
+		common.ConditionDegraded:          metav1.ConditionFalse,
+		common.ConditionConfigDrift:       metav1.ConditionFalse,
+		common.ConditionScalingInProgress: metav1.ConditionFalse,
+	}, conditions(initializing), "the initial creation is not scaling")
+
+	ready := next(initializing, rcvb2.RedisClusterReady, false)
+	assert.Equal(t, map[string]metav1.ConditionStatus{
+		common.ConditionReady:             metav1.ConditionTrue,
+		common.ConditionProgressing:       metav1.ConditionFalse,
+		common.ConditionDegraded:          metav1.ConditionFalse,
+		common.ConditionConfigDrift:       metav1.ConditionFalse,
+		common.ConditionScalingInProgress: metav1.ConditionFalse,
+	}, conditions(ready))
+
+	scaling := next(ready, rcvb2.RedisClusterInitializing, true)
+	assert.Equal(t, metav1.ConditionTrue, conditions(scaling)[common.ConditionScalingInProgress])
+	bootstrap := next(scaling, rcvb2.RedisClusterBootstrap, false)
+	assert.Equal(t, metav1.ConditionTrue, conditions(bootstrap)[common.ConditionScalingInProgress], "scaling lasts until the cluster is Ready")
+	assert.Equal(t, metav1.ConditionFalse, conditions(next(bootstrap, rcvb2.RedisClusterReady, false))[common.ConditionScalingInProgress])
+
+	failed := next(ready, rcvb2.RedisClusterFailed, false)
+	assert.Equal(t, metav1.ConditionTrue, conditions(failed)[common.ConditionDegraded])
+	assert.Equal(t, metav1.ConditionFalse, conditions(failed)[common.ConditionReady])
+	assert.Equal(t, metav1.ConditionFalse, conditions(failed)[common.ConditionProgressing])
+
+	t.Run("scale-down in progress", func(t *testing.T) {
+		scalingDown := *ready.DeepCopy()
+		scalingDown.ScaleDown = &rcvb2.ScaleDownStatus{Step: rcvb2.ScaleDownReshard, FromLeaders: 4, ToLeaders: 3, Shard: 3}
+		scalingDown = next(scalingDown, rcvb2.RedisClusterReady, true)
+		scaling := meta.FindStatusCondition(scalingDown.Conditions, common.ConditionScalingInProgress)
+		assert.Equal(t, metav1.ConditionTrue, scaling.Status)
+		assert.Contains(t, scaling.Message, "step Reshard of the removal of shard 3")
+	})
+
+	t.Run("config drift is kept until the cluster is Ready", func(t *testing.T) {
+		drifted := *bootstrap.DeepCopy()
+		controllercommon.SetConditions(&drifted.ConditionedStatus, 4,
+			controllercommon.NewCondition(common.ConditionConfigDrift, true, controllercommon.ReasonConfigSetFailed, "ERR unknown option"))
+		stillDrifted := next(drifted, rcvb2.RedisClusterBootstrap, false)
+		assert.Equal(t, "ERR unknown option", meta.FindStatusCondition(stillDrifted.Conditions, common.ConditionConfigDrift).Message)
+		assert.Equal(t, metav1.ConditionFalse, conditions(next(stillDrifted, rcvb2.RedisClusterReady, false))[common.ConditionConfigDrift])
+	})
+}
+
+func TestZoneRedundantCondition(t *testing.T) {
+	condition := zoneRedundantCondition(k8sutils.ClusterZones{Zones: 3})
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, rcvb2.RedisClusterConditionZoneRedundant, condition.Type)
+
+	condition = zoneRedundantCondition(k8sutils.ClusterZones{Zones: 2, SingleZoneShards: []string{"cluster-leader-0", "cluster-follower-1"}})
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, reasonSingleZoneShards, condition.Reason)
+	assert.Contains(t, condition.Message, "cluster-leader-0, cluster-follower-1")
+
+	condition = zoneRedundantCondition(k8sutils.ClusterZones{Unknown: []string{"cluster-leader-0"}, SingleZoneShards: []string{"cluster-leader-0"}})
+	assert.Equal(t, metav1.ConditionUnknown, condition.Status)
+	assert.Equal(t, reasonZonesUnknown, condition.Reason)
+}
@@ -0,0 +1,22 @@
+package rediscluster
+
+import (
+	"context"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// reconcileTLS requests the TLS secret from cert-manager and watches it, see k8sutils.ReloadRedisClusterTLS.
+func (r *Reconciler) reconcileTLS(ctx context.Context, instance *rcvb2.RedisCluster) (ctrl.Result, error) {
+	return common.ReconcileTLS(ctx, r.Recorder, r.SecretWatcher, instance, instance.Spec.TLS,
+		func(ctx context.Context) error {
+			return k8sutils.CreateOrUpdateRedisClusterCertificate(ctx, r.Client, instance)
+		},
+		func(ctx context.Context) ([]string, error) {
+			return k8sutils.ReloadRedisClusterTLS(ctx, r.K8sClient, instance)
+		},
+	)
+}
@@ -0,0 +1,47 @@
+package rediscluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// recordUpgradeStep reports the step of a managed upgrade taken by the reconcile as an event, and logs
+// why the upgrade waits otherwise.
+func recordUpgradeStep(ctx context.Context, step k8sutils.UpgradeStep, err error) {
+	switch {
+	case err != nil:
+		log.FromContext(ctx).Error(err, "failed to upgrade the pods of the cluster")
+		events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisUpgradeFailed, "", err.Error())
+	case step.Restarted != "":
+		events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisUpgradePodRestarted, step.Restarted,
+			fmt.Sprintf("Restarted %s onto the new revision, %d pod(s) left", step.Restarted, step.Outdated-1))
+	case step.FailedOver != "":
+		events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisUpgradeFailover, step.FailedOver,
+			fmt.Sprintf("Failed the master %s over to the upgraded replica %s", step.FailedOver, step.Promoted))
+	case step.Waiting != "":
+		log.FromContext(ctx).Info("Waiting for the cluster to settle before the next upgrade step", "Reason", step.Waiting, "Outdated", step.Outdated)
+	}
+}
+
+// recordAnnouncementStep reports the restart of a pod announcing a previous address of its load balancer,
+// or the failover of its master, as an event, and logs why the restart waits otherwise.
+func recordAnnouncementStep(ctx context.Context, step k8sutils.UpgradeStep, err error) {
+	switch {
+	case err != nil:
+		log.FromContext(ctx).Error(err, "failed to restart the pods announcing a stale address")
+		events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisAnnouncedAddressFailed, "", err.Error())
+	case step.Restarted != "":
+		events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisAnnouncedAddressRestart, step.Restarted,
+			fmt.Sprintf("Restarted %s to announce the address of its load balancer, %d pod(s) left", step.Restarted, step.Outdated-1))
+	case step.FailedOver != "":
+		events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisAnnouncedAddressFailover, step.FailedOver,
+			fmt.Sprintf("Failed the master %s announcing a stale address over to the replica %s", step.FailedOver, step.Promoted))
+	case step.Waiting != "":
+		log.FromContext(ctx).Info("Waiting for the cluster to settle before restarting the pods announcing a stale address", "Reason", step.Waiting, "Stale", step.Outdated)
+	}
+}
@@ -0,0 +1,36 @@
+package rediscluster
+
+import (
+	"context"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// reconcileVersion checks a change of the Redis version before the StatefulSets roll it out, records the
+// current and target versions, and returns an error while the change fails its checks.
+func (r *Reconciler) reconcileVersion(ctx context.Context, instance *rcvb2.RedisCluster) error {
+	check, err := k8sutils.CheckRedisClusterVersion(ctx, r.K8sClient, instance)
+	for _, message := range check.Deprecated {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisConfigDeprecated, message)
+	}
+	if check.Status != instance.Status.VersionStatus {
+		status := *instance.Status.DeepCopy()
+		status.VersionStatus = check.Status
+		if _, sErr := r.writeStatus(ctx, instance, status); sErr != nil {
+			return sErr
+		}
+		instance.Status = status
+	}
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisVersionChangeBlocked, err.Error())
+		return err
+	}
+	if check.Completed {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonRedisVersionChangeCompleted,
+			"Every pod runs %s", check.Status.CurrentVersion)
+	}
+	return nil
+}
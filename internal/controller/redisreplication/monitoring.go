@@ -0,0 +1,23 @@
+package redisreplication
+
+import (
+	"context"
+
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcileMonitoring creates the ServiceMonitor and the PrometheusRule of the exporters when the
+// Prometheus Operator is installed. Scraping is not needed to serve, a failure doesn't hold back the reconcile.
+func (r *Reconciler) reconcileMonitoring(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	if err := k8sutils.CreateOrUpdateRedisReplicationMonitoring(ctx, r.Client, instance); err != nil {
+		log.FromContext(ctx).Error(err, "failed to reconcile the monitoring of the exporters")
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisMonitoringFailed, err.Error())
+	}
+	return intctrlutil.Reconciled()
+}
@@ -0,0 +1,55 @@
+package redisreplication
+
+import (
+	"context"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// reconcilePassword watches the redisSecret, and rotates a change of its password onto the running servers
+// and sentinels one phase per reconcile, requeueing until the rotation completes.
+func (r *Reconciler) reconcilePassword(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	secret := instance.Spec.KubernetesConfig.ExistingPasswordSecret
+	if secret == nil {
+		return intctrlutil.Reconciled()
+	}
+	r.SecretWatcher.Watch(ctx,
+		types.NamespacedName{Namespace: instance.Namespace, Name: *secret.Name},
+		types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name},
+	)
+
+	rotation, err := k8sutils.RotateRedisReplicationPassword(ctx, r.K8sClient, instance)
+	if rotation != nil && !equality.Semantic.DeepEqual(rotation, instance.Status.PasswordRotation) {
+		previous := instance.Status.PasswordRotation
+		status := *instance.Status.DeepCopy()
+		status.PasswordRotation = rotation
+		if uErr := r.updateStatus(ctx, instance, status); uErr != nil {
+			return intctrlutil.RequeueE(ctx, uErr, "failed to record the password rotation")
+		}
+		switch {
+		case rotation.Phase == commonapi.PasswordRotationAddPassword && (previous == nil || previous.Phase == commonapi.PasswordRotationCompleted):
+			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisPasswordRotationStarted,
+				"Rotating the password of the redisSecret onto the running pods")
+		case rotation.Phase == commonapi.PasswordRotationCompleted:
+			r.Recorder.Event(instance, corev1.EventTypeNormal, events.EventReasonRedisPasswordRotationCompleted,
+				"The pods only accept the new password of the redisSecret")
+		}
+	}
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisPasswordRotationFailed, err.Error())
+		return intctrlutil.RequeueE(ctx, err, "failed to rotate the password")
+	}
+	if rotation != nil && rotation.Phase != commonapi.PasswordRotationCompleted {
+		return intctrlutil.RequeueAfter(ctx, time.Second, "rotating the password", "Phase", rotation.Phase)
+	}
+	return intctrlutil.Reconciled()
+}
@@ -8,6 +8,7 @@ import (
 
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
 	redishealer "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/service"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/statefulset"
@@ -16,9 +17,12 @@ import (
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/monitoring"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -40,6 +44,9 @@ type Reconciler struct {
 	RedisReplicationRealMaster func(context.Context, kubernetes.Interface, *rrvb2.RedisReplication, []string) string
 	CreateRedisReplicationLink func(context.Context, kubernetes.Interface, *rrvb2.RedisReplication, []string, string) error
 	ConfigureSentinel          func(context.Context, *rrvb2.RedisReplication, string) error
+	ReplicationLag             func(context.Context, kubernetes.Interface, *rrvb2.RedisReplication, string, []string) (map[string]int64, error)
+	Recorder                   record.EventRecorder
+	SecretWatcher              *intctrlutil.ResourceWatcher
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -49,6 +56,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if err != nil {
 		return intctrlutil.RequeueECheck(ctx, err, "failed to get RedisReplication instance")
 	}
+	ctx = events.IntoContext(ctx, events.NewObjectRecorder(r.Recorder, instance))
 
 	if k8sutils.IsDeleted(instance) {
 		if err := k8sutils.HandleRedisReplicationFinalizer(ctx, r.Client, instance, RedisReplicationFinalizer); err != nil {
@@ -63,20 +71,34 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	reconcilers := []reconciler{
 		{typ: "finalizer", rec: r.reconcileFinalizer},
+		{typ: "version", rec: r.reconcileVersion},
+		{typ: "tls", rec: r.reconcileTLS},
+		{typ: "monitoring", rec: r.reconcileMonitoring},
 		{typ: "resources", rec: r.reconcileResources},
+		{typ: "password", rec: r.reconcilePassword},
 		{typ: "redis", rec: r.reconcileRedis},
 		{typ: "status", rec: r.reconcileStatus},
+		{typ: "upgrade", rec: r.reconcileUpgrade},
 	}
 
+	var result ctrl.Result
+	timer := monitoring.NewPhaseTimer(ctx, monitoring.RedisReplicationReconcileDurationSeconds, instance.Namespace, instance.Name)
 	for _, reconciler := range reconcilers {
-		result, err := reconciler.rec(ctx, instance)
-		if err != nil {
-			return intctrlutil.RequeueE(ctx, err, "")
-		}
-		if result.Requeue {
-			return result, nil
+		result, err = reconciler.rec(timer.Phase(reconciler.typ), instance)
+		if err != nil || result.Requeue {
+			break
 		}
 	}
+	timer.Done()
+	if cErr := r.updateConditions(ctx, instance, err); cErr != nil {
+		log.FromContext(ctx).Error(cErr, "failed to update RedisReplication conditions")
+	}
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "")
+	}
+	if result.Requeue {
+		return result, nil
+	}
 
 	return intctrlutil.RequeueAfter(ctx, time.Second*30, "")
 }
@@ -101,10 +123,10 @@ func (r *Reconciler) UpdateRedisReplicationMaster(ctx context.Context, instance
 			"previous", instance.Status.MasterNode,
 			"new", masterNode)
 	}
-	return r.updateStatus(ctx, instance, rrvb2.RedisReplicationStatus{
-		MasterNode:     masterNode,
-		ConnectionInfo: connectionInfo,
-	})
+	status := *instance.Status.DeepCopy()
+	status.MasterNode = masterNode
+	status.ConnectionInfo = connectionInfo
+	return r.updateStatus(ctx, instance, status)
 }
 
 func connectionInfoEqual(a, b *rrvb2.ConnectionInfo) bool {
@@ -132,17 +154,36 @@ func (r *Reconciler) redisReplicationRealMaster(ctx context.Context, instance *r
 }
 
 func (r *Reconciler) createRedisReplicationLink(ctx context.Context, instance *rrvb2.RedisReplication, pods []string, realMaster string) error {
+	var err error
 	if r.CreateRedisReplicationLink != nil {
-		return r.CreateRedisReplicationLink(ctx, r.K8sClient, instance, pods, realMaster)
+		err = r.CreateRedisReplicationLink(ctx, r.K8sClient, instance, pods, realMaster)
+	} else {
+		err = k8sutils.CreateMasterSlaveReplication(ctx, r.K8sClient, instance, pods, realMaster)
 	}
-	return k8sutils.CreateMasterSlaveReplication(ctx, r.K8sClient, instance, pods, realMaster)
+	if err != nil {
+		monitoring.RedisReplicationFailedExecTotal.WithLabelValues(instance.Namespace, instance.Name, "replicaof").Inc()
+	}
+	return err
 }
 
 func (r *Reconciler) configureReplicationSentinel(ctx context.Context, instance *rrvb2.RedisReplication, masterPodName string) error {
+	var err error
 	if r.ConfigureSentinel != nil {
-		return r.ConfigureSentinel(ctx, instance, masterPodName)
+		err = r.ConfigureSentinel(ctx, instance, masterPodName)
+	} else {
+		err = r.configureSentinel(ctx, instance, masterPodName)
 	}
-	return r.configureSentinel(ctx, instance, masterPodName)
+	if err != nil {
+		monitoring.RedisReplicationFailedExecTotal.WithLabelValues(instance.Namespace, instance.Name, "sentinel").Inc()
+	}
+	return err
+}
+
+func (r *Reconciler) replicationLag(ctx context.Context, instance *rrvb2.RedisReplication, master string, replicas []string) (map[string]int64, error) {
+	if r.ReplicationLag != nil {
+		return r.ReplicationLag(ctx, r.K8sClient, instance, master, replicas)
+	}
+	return k8sutils.GetRedisReplicationLag(ctx, r.K8sClient, instance, master, replicas)
 }
 
 func (r *Reconciler) observedRedisReplicationMaster(ctx context.Context, instance *rrvb2.RedisReplication, masterPods []string) (string, bool) {
@@ -176,7 +217,11 @@ func (r *Reconciler) reconcileFinalizer(ctx context.Context, instance *rrvb2.Red
 }
 
 func (r *Reconciler) reconcileResources(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
-	if err := k8sutils.CreateReplicationRedis(ctx, instance, r.K8sClient); err != nil {
+	restore, err := k8sutils.ResolveRestoreSource(ctx, r.Client, r.K8sClient, instance.Namespace, instance.Name, instance.Spec.RestoreFrom, instance.Spec.AOFArchive, 1)
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to resolve restoreFrom")
+	}
+	if err := k8sutils.CreateReplicationRedis(ctx, instance, r.K8sClient, restore); err != nil {
 		return intctrlutil.RequeueAfter(ctx, time.Second*60, "")
 	}
 	if err := k8sutils.CreateReplicationService(ctx, instance, r.K8sClient); err != nil {
@@ -301,6 +346,7 @@ func (r *Reconciler) configureSentinelPod(
 		Host:     sentinelPod.Status.PodIP,
 		Port:     "26379",
 		Password: sentinelPassword,
+		Pod:      sentinelPod.Name,
 	}
 
 	sentinelService := redisClient.Connect(sentinelConnInfo)
@@ -334,14 +380,16 @@ func (r *Reconciler) configureSentinelPod(
 		}
 	}
 
-	if err := r.sentinelResetIfNeed(ctx, inst, sentinelService); err != nil {
+	if err := r.sentinelResetIfNeed(ctx, inst, sentinelPod.Name, sentinelService); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (r *Reconciler) sentinelResetIfNeed(ctx context.Context, inst *rrvb2.RedisReplication, redisService redis.Service) error {
+// sentinelResetIfNeed resets the master group on the sentinel running in pod when the replicas or the
+// sentinels it knows differ from the spec, e.g. after a replica or a sentinel was removed.
+func (r *Reconciler) sentinelResetIfNeed(ctx context.Context, inst *rrvb2.RedisReplication, pod string, redisService redis.Service) error {
 	logger := log.FromContext(ctx)
 
 	sentinelInfo, err := redisService.GetInfoSentinel(ctx)
@@ -381,8 +429,13 @@ func (r *Reconciler) sentinelResetIfNeed(ctx context.Context, inst *rrvb2.RedisR
 
 	if needReset {
 		if err := redisService.SentinelReset(ctx, masterGroupName); err != nil {
+			events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisSentinelResetFailed, pod,
+				fmt.Sprintf("Failed to reset master group %s on %s: %v", masterGroupName, pod, err))
 			return fmt.Errorf("reset sentinel: %w", err)
 		}
+		events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisSentinelReset, pod,
+			fmt.Sprintf("Reset master group %s on %s, it knew %d replica(s) and %d sentinel(s) instead of %d and %d",
+				masterGroupName, pod, masterInfo.Slaves, masterInfo.Sentinels, expectedSlaves, expectedSentinels))
 	}
 
 	return nil
@@ -400,7 +453,8 @@ func (r *Reconciler) reconcileRedis(ctx context.Context, instance *rrvb2.RedisRe
 
 	if len(instance.Spec.GetRedisDynamicConfig()) > 0 && r.IsStatefulSetReady(ctx, instance.Namespace, instance.RedisStatefulSet()) {
 		if err := k8sutils.SetRedisReplicationDynamicConfig(ctx, r.K8sClient, instance); err != nil {
-			return intctrlutil.RequeueE(ctx, err, "failed to set dynamic config")
+			monitoring.RedisReplicationFailedExecTotal.WithLabelValues(instance.Namespace, instance.Name, "config_set").Inc()
+			return intctrlutil.RequeueE(ctx, fmt.Errorf("%w: %w", errDynamicConfig, err), "")
 		}
 	}
 
@@ -532,6 +586,18 @@ func (r *Reconciler) reconcileStatus(ctx context.Context, instance *rrvb2.RedisR
 		monitoring.RedisReplicationConnectedSlavesTotal.WithLabelValues(instance.Namespace, instance.Name).Set(float64(0))
 	}
 
+	// Replace the lag of the replicas, dropping the series of the pods no longer replicating.
+	var lag map[string]int64
+	if realMaster != "" {
+		if lag, err = r.replicationLag(ctx, instance, realMaster, slaveNodes); err != nil {
+			log.FromContext(ctx).Error(err, "failed to get the replication lag of the replicas")
+		}
+	}
+	monitoring.RedisReplicationReplicaLagBytes.DeletePartialMatch(prometheus.Labels{"namespace": instance.Namespace, "instance": instance.Name})
+	for pod, bytes := range lag {
+		monitoring.RedisReplicationReplicaLagBytes.WithLabelValues(instance.Namespace, instance.Name, pod).Set(float64(bytes))
+	}
+
 	return intctrlutil.Reconciled()
 }
 
@@ -539,7 +605,13 @@ func (r *Reconciler) updateStatus(ctx context.Context, rr *rrvb2.RedisReplicatio
 	copy := rr.DeepCopy()
 	copy.Spec = rrvb2.RedisReplicationSpec{}
 	copy.Status = status
-	return common.UpdateStatus(ctx, r.Client, copy)
+	if err := common.UpdateStatus(ctx, r.Client, copy); err != nil {
+		return err
+	}
+	// Keep the instance current for the status updates later in the same pass.
+	rr.ResourceVersion = copy.ResourceVersion
+	rr.Status = status
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -547,5 +619,6 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&rrvb2.RedisReplication{}).
 		WithOptions(opts).
-		Complete(r)
+		WatchesMetadata(&corev1.Secret{}, r.SecretWatcher).
+		Complete(tracing.Reconciler("RedisReplication", r))
 }
@@ -24,6 +24,7 @@ import (
 
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
 	redis "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -98,10 +99,12 @@ var _ = BeforeSuite(func() {
 	healer := redis.NewHealer(k8sClient)
 
 	err = (&Reconciler{
-		Client:      k8sManager.GetClient(),
-		K8sClient:   k8sClient,
-		Healer:      healer,
-		StatefulSet: k8sutils.NewStatefulSetService(k8sClient),
+		Client:        k8sManager.GetClient(),
+		K8sClient:     k8sClient,
+		Healer:        healer,
+		Recorder:      k8sManager.GetEventRecorderFor("redisreplication-controller"),
+		StatefulSet:   k8sutils.NewStatefulSetService(k8sClient),
+		SecretWatcher: intctrlutil.NewResourceWatcher(),
 	}).SetupWithManager(k8sManager, controller.Options{})
 	Expect(err).ToNot(HaveOccurred())
 
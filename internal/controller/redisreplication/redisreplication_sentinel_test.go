@@ -292,6 +292,12 @@ func (f *fakeSentinelRedisService) SentinelSet(context.Context, string, string,
 
 func (f *fakeSentinelRedisService) SentinelReset(context.Context, string) error { return nil }
 
+func (f *fakeSentinelRedisService) SentinelFailover(context.Context, string) error { return nil }
+
+func (f *fakeSentinelRedisService) SentinelCKQuorum(context.Context, string) (bool, string, error) {
+	return true, "OK", nil
+}
+
 func (f *fakeSentinelRedisService) GetInfoSentinel(context.Context) (*redis.InfoSentinelResult, error) {
 	return &redis.InfoSentinelResult{
 		Masters: []redis.SentinelMasterInfo{
@@ -303,3 +309,14 @@ func (f *fakeSentinelRedisService) GetInfoSentinel(context.Context) (*redis.Info
 func (f *fakeSentinelRedisService) GetClusterInfo(context.Context) (*redis.ClusterStatus, error) {
 	return &redis.ClusterStatus{}, nil
 }
+
+func (f *fakeSentinelRedisService) GetInfo(context.Context, string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (f *fakeSentinelRedisService) BgSave(context.Context) error { return nil }
+
+func (f *fakeSentinelRedisService) GetRDBPath(context.Context) (string, error) { return "", nil }
+func (f *fakeSentinelRedisService) GetClusterMySlots(context.Context) ([]string, error) {
+	return nil, nil
+}
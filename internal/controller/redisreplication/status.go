@@ -0,0 +1,75 @@
+package redisreplication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// errDynamicConfig marks a failure to apply redisConfig.dynamicConfig, which is reported as ConfigDrift.
+var errDynamicConfig = errors.New("failed to set dynamic config")
+
+// updateConditions records the outcome of a reconcile pass, err, in the conditions of the instance.
+func (r *Reconciler) updateConditions(ctx context.Context, instance *rrvb2.RedisReplication, err error) error {
+	sts, getErr := r.K8sClient.AppsV1().StatefulSets(instance.Namespace).Get(ctx, instance.RedisStatefulSet(), metav1.GetOptions{})
+	if apierrors.IsNotFound(getErr) {
+		sts = nil
+	} else if getErr != nil {
+		return getErr
+	}
+	stsReady := sts != nil && r.IsStatefulSetReady(ctx, instance.Namespace, instance.RedisStatefulSet())
+
+	status := *instance.Status.DeepCopy()
+	setConditions(instance, &status, err, sts, stsReady)
+	if equality.Semantic.DeepEqual(instance.Status, status) {
+		return nil
+	}
+	return r.updateStatus(ctx, instance, status)
+}
+
+// setConditions derives the shared conditions of status from the outcome of a reconcile pass
+// and the redis StatefulSet of the instance, nil while it does not exist.
+func setConditions(instance *rrvb2.RedisReplication, status *rrvb2.RedisReplicationStatus, err error, sts *appsv1.StatefulSet, stsReady bool) {
+	if err != nil {
+		common.SetReconcileFailed(&status.ConditionedStatus, instance.Generation, err)
+		if errors.Is(err, errDynamicConfig) {
+			common.SetConditions(&status.ConditionedStatus, instance.Generation,
+				common.NewCondition(commonapi.ConditionConfigDrift, true, common.ReasonConfigSetFailed, err.Error()))
+		}
+		return
+	}
+
+	ready := common.NewCondition(commonapi.ConditionReady, true, common.ReasonReady, "Replication is ready")
+	progressing := common.NewCondition(commonapi.ConditionProgressing, false, common.ReasonComplete, "The spec is rolled out")
+	degraded := common.NewCondition(commonapi.ConditionDegraded, false, common.ReasonHealthy, "A master is elected")
+	switch {
+	case !stsReady:
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, common.ReasonRollout, "Waiting for the StatefulSet to be ready"
+		progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionTrue, ready.Reason, ready.Message
+	case status.MasterNode == "":
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, "NoMaster", "No master is elected"
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, ready.Reason, ready.Message
+	}
+
+	desired := ptr.Deref(instance.Spec.Size, 1)
+	var pods int32
+	if sts != nil {
+		pods = sts.Status.Replicas
+	}
+	scaling := common.IsScaling(status.ConditionedStatus, pods != desired, ready.Status == metav1.ConditionTrue)
+
+	common.SetConditions(&status.ConditionedStatus, instance.Generation,
+		ready, progressing, degraded,
+		common.ScalingCondition(scaling, fmt.Sprintf("Scaling to %d pods", desired)),
+		common.NewCondition(commonapi.ConditionConfigDrift, false, common.ReasonInSync, "The running config matches the spec"),
+	)
+}
@@ -0,0 +1,60 @@
+package redisreplication
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestSetConditions(t *testing.T) {
+	instance := &rrvb2.RedisReplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "replication", Generation: 2},
+		Spec:       rrvb2.RedisReplicationSpec{Size: ptr.To(int32(3))},
+	}
+	sts := func(pods int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{Replicas: pods}}
+	}
+	is := func(status rrvb2.RedisReplicationStatus, condType string) bool {
+		return meta.IsStatusConditionTrue(status.Conditions, condType)
+	}
+
+	creating := rrvb2.RedisReplicationStatus{}
+	setConditions(instance, &creating, nil, sts(1), false)
+	assert.Equal(t, int64(2), creating.ObservedGeneration)
+	assert.False(t, is(creating, common.ConditionReady))
+	assert.True(t, is(creating, common.ConditionProgressing))
+	assert.False(t, is(creating, common.ConditionScalingInProgress), "the initial creation is not scaling")
+
+	ready := rrvb2.RedisReplicationStatus{MasterNode: "replication-0"}
+	setConditions(instance, &ready, nil, sts(3), true)
+	assert.True(t, is(ready, common.ConditionReady))
+	assert.False(t, is(ready, common.ConditionProgressing))
+	assert.False(t, is(ready, common.ConditionDegraded))
+	assert.False(t, is(ready, common.ConditionConfigDrift))
+
+	scaled := instance.DeepCopy()
+	scaled.Spec.Size = ptr.To(int32(5))
+	scaling := *ready.DeepCopy()
+	setConditions(scaled, &scaling, nil, sts(4), false)
+	assert.True(t, is(scaling, common.ConditionScalingInProgress))
+	assert.True(t, is(scaling, common.ConditionProgressing))
+
+	noMaster := rrvb2.RedisReplicationStatus{}
+	setConditions(instance, &noMaster, nil, sts(3), true)
+	assert.True(t, is(noMaster, common.ConditionDegraded))
+	assert.False(t, is(noMaster, common.ConditionReady))
+
+	drifted := *ready.DeepCopy()
+	setConditions(instance, &drifted, fmt.Errorf("%w: %w", errDynamicConfig, errors.New("ERR unknown option")), sts(3), true)
+	assert.True(t, is(drifted, common.ConditionConfigDrift))
+	assert.True(t, is(drifted, common.ConditionDegraded))
+	assert.False(t, is(drifted, common.ConditionReady))
+}
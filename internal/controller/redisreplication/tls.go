@@ -0,0 +1,22 @@
+package redisreplication
+
+import (
+	"context"
+
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// reconcileTLS requests the TLS secret from cert-manager and watches it, see k8sutils.ReloadRedisReplicationTLS.
+func (r *Reconciler) reconcileTLS(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	return common.ReconcileTLS(ctx, r.Recorder, r.SecretWatcher, instance, instance.Spec.TLS,
+		func(ctx context.Context) error {
+			return k8sutils.CreateOrUpdateRedisReplicationCertificate(ctx, r.Client, instance)
+		},
+		func(ctx context.Context) ([]string, error) {
+			return k8sutils.ReloadRedisReplicationTLS(ctx, r.K8sClient, instance)
+		},
+	)
+}
@@ -0,0 +1,41 @@
+package redisreplication
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// reconcileUpgrade rolls the pods onto the update revision of the StatefulSet when upgradeStrategy is
+// Managed, one step per reconcile, and requeues until they all run it.
+func (r *Reconciler) reconcileUpgrade(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	if !instance.ManagedUpgrade() {
+		return intctrlutil.Reconciled()
+	}
+	step, err := k8sutils.UpgradeRedisReplicationPods(ctx, r.K8sClient, redis.NewClient(), instance)
+	switch {
+	case err != nil:
+		events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisUpgradeFailed, "", err.Error())
+		return intctrlutil.RequeueE(ctx, err, "failed to upgrade the pods")
+	case step.Restarted != "":
+		events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisUpgradePodRestarted, step.Restarted,
+			fmt.Sprintf("Restarted %s onto the new revision, %d pod(s) left", step.Restarted, step.Outdated-1))
+	case step.Promoted != "":
+		events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisUpgradeFailover, step.FailedOver,
+			fmt.Sprintf("Failed the master %s over to the upgraded replica %s", step.FailedOver, step.Promoted))
+	case step.FailedOver != "":
+		events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisUpgradeFailover, step.FailedOver,
+			fmt.Sprintf("Failed the master %s over to an upgraded replica with sentinel", step.FailedOver))
+	case step.Outdated == 0:
+		return intctrlutil.Reconciled()
+	}
+	return intctrlutil.RequeueAfter(ctx, time.Second*10, "upgrading the pods", "Outdated", step.Outdated, "Waiting", step.Waiting)
+}
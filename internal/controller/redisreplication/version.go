@@ -0,0 +1,37 @@
+package redisreplication
+
+import (
+	"context"
+
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// reconcileVersion checks a change of the Redis version before the StatefulSet rolls it out, records the
+// current and target versions, and blocks the reconcile while the change fails its checks.
+func (r *Reconciler) reconcileVersion(ctx context.Context, instance *rrvb2.RedisReplication) (ctrl.Result, error) {
+	check, err := k8sutils.CheckRedisReplicationVersion(ctx, r.K8sClient, instance)
+	for _, message := range check.Deprecated {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisConfigDeprecated, message)
+	}
+	if check.Status != instance.Status.VersionStatus {
+		status := *instance.Status.DeepCopy()
+		status.VersionStatus = check.Status
+		if uErr := r.updateStatus(ctx, instance, status); uErr != nil {
+			return intctrlutil.RequeueE(ctx, uErr, "failed to record the version status")
+		}
+	}
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisVersionChangeBlocked, err.Error())
+		return intctrlutil.RequeueE(ctx, err, "version change blocked")
+	}
+	if check.Completed {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonRedisVersionChangeCompleted,
+			"Every pod runs %s", check.Status.CurrentVersion)
+	}
+	return intctrlutil.Reconciled()
+}
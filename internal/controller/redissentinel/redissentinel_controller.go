@@ -8,16 +8,21 @@ import (
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
 	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
 	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 const (
@@ -30,6 +35,7 @@ type RedisSentinelReconciler struct {
 	Checker            redis.Checker
 	Healer             redis.Healer
 	K8sClient          kubernetes.Interface
+	Recorder           record.EventRecorder
 	ReplicationWatcher *intctrlutil.ResourceWatcher
 }
 
@@ -40,6 +46,7 @@ func (r *RedisSentinelReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	if err != nil {
 		return intctrlutil.RequeueECheck(ctx, err, "failed to get RedisSentinel instance")
 	}
+	ctx = events.IntoContext(ctx, events.NewObjectRecorder(r.Recorder, instance))
 
 	if k8sutils.IsDeleted(instance) {
 		if err := k8sutils.HandleRedisSentinelFinalizer(ctx, r.Client, instance, RedisSentinelFinalizer); err != nil {
@@ -57,11 +64,21 @@ func (r *RedisSentinelReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		{typ: "replication", rec: r.reconcileReplication},
 		{typ: "pdb", rec: r.reconcilePDB},
 		{typ: "service", rec: r.reconcileService},
+		{typ: "tls", rec: r.reconcileTLS},
+		{typ: "monitoring", rec: r.reconcileMonitoring},
 		{typ: "sentinel", rec: r.reconcileSentinel},
+		{typ: "status", rec: r.reconcileStatus},
 	}
 
+	phases := tracing.NewPhases(ctx)
+	defer phases.End()
 	for _, reconciler := range reconcilers {
-		result, err := reconciler.rec(ctx, instance)
+		result, err := reconciler.rec(phases.Start(reconciler.typ), instance)
+		if err != nil || (result.Requeue && reconciler.typ != "status") {
+			if cErr := r.updateConditions(ctx, instance, err); cErr != nil {
+				log.FromContext(ctx).Error(cErr, "failed to update RedisSentinel conditions")
+			}
+		}
 		if err != nil {
 			return intctrlutil.RequeueE(ctx, err, "")
 		}
@@ -157,6 +174,28 @@ func (r *RedisSentinelReconciler) reconcilePDB(ctx context.Context, instance *rs
 	return intctrlutil.Reconciled()
 }
 
+// reconcileTLS requests the TLS secret from cert-manager. Unlike Redis, sentinels load a change of the
+// certificates when they restart.
+func (r *RedisSentinelReconciler) reconcileTLS(ctx context.Context, instance *rsvb2.RedisSentinel) (ctrl.Result, error) {
+	if instance.Spec.TLS == nil {
+		return intctrlutil.Reconciled()
+	}
+	if err := k8sutils.CreateOrUpdateRedisSentinelCertificate(ctx, r.Client, instance); err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisTLSCertificateFailed, err.Error())
+		return intctrlutil.RequeueE(ctx, err, "failed to reconcile the TLS certificates")
+	}
+	return intctrlutil.Reconciled()
+}
+
+// reconcileMonitoring creates the ServiceMonitor and the PrometheusRule of the exporters when the
+// Prometheus Operator is installed. A failure is logged, it doesn't hold back the reconcile.
+func (r *RedisSentinelReconciler) reconcileMonitoring(ctx context.Context, instance *rsvb2.RedisSentinel) (ctrl.Result, error) {
+	if err := k8sutils.CreateOrUpdateRedisSentinelMonitoring(ctx, r.Client, instance); err != nil {
+		log.FromContext(ctx).Error(err, "failed to reconcile the monitoring of the exporters")
+	}
+	return intctrlutil.Reconciled()
+}
+
 func (r *RedisSentinelReconciler) reconcileService(ctx context.Context, instance *rsvb2.RedisSentinel) (ctrl.Result, error) {
 	if err := k8sutils.CreateRedisSentinelService(ctx, instance, r.K8sClient); err != nil {
 		return intctrlutil.RequeueE(ctx, err, "")
@@ -171,5 +210,5 @@ func (r *RedisSentinelReconciler) SetupWithManager(mgr ctrl.Manager, opts contro
 		Owns(&appsv1.StatefulSet{}).
 		WithOptions(opts).
 		Watches(&rrvb2.RedisReplication{}, r.ReplicationWatcher).
-		Complete(r)
+		Complete(tracing.Reconciler("RedisSentinel", r))
 }
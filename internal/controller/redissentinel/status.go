@@ -0,0 +1,190 @@
+package redissentinel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/monitoring"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// statusRefreshInterval is how often the sentinels are polled for the status.
+const statusRefreshInterval = 30 * time.Second
+
+const reasonWaitingForReplication = "WaitingForReplication"
+
+func (r *RedisSentinelReconciler) reconcileStatus(ctx context.Context, instance *rsvb2.RedisSentinel) (ctrl.Result, error) {
+	sts, err := r.K8sClient.AppsV1().StatefulSets(instance.Namespace).Get(ctx, instance.Name+"-sentinel", metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return intctrlutil.RequeueE(ctx, err, "failed to get sentinel StatefulSet")
+	}
+	var pods int32
+	if err == nil {
+		pods = sts.Status.Replicas
+	}
+	stsReady := err == nil && k8sutils.NewStatefulSetService(r.K8sClient).IsStatefulSetReady(ctx, instance.Namespace, sts.Name)
+
+	status := *instance.Status.DeepCopy()
+	if instance.Spec.RedisSentinelConfig != nil {
+		views, err := r.Checker.GetSentinelViews(ctx, instance)
+		if err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to query sentinels")
+		}
+		status = newStatus(instance.Status, views, metav1.Now())
+		recordQuorum(instance, status)
+	}
+	setConditions(instance, &status, stsReady, pods)
+	if err := r.writeStatus(ctx, instance, status); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisSentinel status")
+	}
+	if instance.Spec.RedisSentinelConfig == nil {
+		return intctrlutil.Reconciled()
+	}
+	return intctrlutil.RequeueAfter(ctx, statusRefreshInterval, "")
+}
+
+// recordQuorum exports whether the sentinels, and each of them, can authorize a failover of the master.
+func recordQuorum(instance *rsvb2.RedisSentinel, status rsvb2.RedisSentinelStatus) {
+	reachable := 0.0
+	if status.QuorumReachable {
+		reachable = 1
+	}
+	monitoring.RedisSentinelQuorumReachable.WithLabelValues(instance.Namespace, instance.Name).Set(reachable)
+	monitoring.RedisSentinelQuorumOk.DeletePartialMatch(prometheus.Labels{"namespace": instance.Namespace, "instance": instance.Name})
+	for _, peer := range status.Sentinels {
+		ok := 0.0
+		if peer.Quorum {
+			ok = 1
+		}
+		monitoring.RedisSentinelQuorumOk.WithLabelValues(instance.Namespace, instance.Name, peer.Pod).Set(ok)
+	}
+}
+
+// updateConditions records a reconcile pass that stopped before the status step,
+// either failed with err or waiting for the RedisReplication to be ready.
+func (r *RedisSentinelReconciler) updateConditions(ctx context.Context, instance *rsvb2.RedisSentinel, err error) error {
+	status := *instance.Status.DeepCopy()
+	switch {
+	case err != nil:
+		common.SetReconcileFailed(&status.ConditionedStatus, instance.Generation, err)
+	case instance.Spec.RedisSentinelConfig != nil:
+		msg := fmt.Sprintf("Waiting for RedisReplication %s to be ready", instance.Spec.RedisSentinelConfig.RedisReplicationName)
+		common.SetConditions(&status.ConditionedStatus, instance.Generation,
+			common.NewCondition(commonapi.ConditionReady, false, reasonWaitingForReplication, msg),
+			common.NewCondition(commonapi.ConditionProgressing, true, reasonWaitingForReplication, msg),
+			common.NewCondition(commonapi.ConditionDegraded, false, common.ReasonHealthy, msg),
+		)
+	}
+	return r.writeStatus(ctx, instance, status)
+}
+
+// writeStatus updates the status of the instance unless it is unchanged.
+func (r *RedisSentinelReconciler) writeStatus(ctx context.Context, instance *rsvb2.RedisSentinel, status rsvb2.RedisSentinelStatus) error {
+	if equality.Semantic.DeepEqual(instance.Status, status) {
+		return nil
+	}
+	instance.Status = status
+	return common.UpdateStatus(ctx, r.Client, instance)
+}
+
+// newStatus summarizes the views of the sentinels. The master is the one reported by most sentinels,
+// a change of it from the previous status is recorded as a failover observed at now.
+func newStatus(previous rsvb2.RedisSentinelStatus, views []redis.SentinelView, now metav1.Time) rsvb2.RedisSentinelStatus {
+	status := rsvb2.RedisSentinelStatus{
+		ConditionedStatus: *previous.ConditionedStatus.DeepCopy(),
+		LastFailoverTime:  previous.LastFailoverTime,
+		Sentinels:         make([]rsvb2.SentinelPeerStatus, 0, len(views)),
+	}
+
+	votes := map[string]int{}
+	quorum := 0
+	for _, view := range views {
+		peer := rsvb2.SentinelPeerStatus{Pod: view.Pod}
+		switch {
+		case view.Err != nil:
+			peer.Status = rsvb2.SentinelPeerUnreachable
+			peer.Message = view.Err.Error()
+		case view.Master == nil:
+			peer.Status = rsvb2.SentinelPeerUnmonitored
+		default:
+			peer.Status = view.Master.Status
+			peer.MasterAddress = view.Master.Address
+			peer.Slaves = int32(view.Master.Slaves)
+			peer.Sentinels = int32(view.Master.Sentinels)
+			peer.Quorum = view.Quorum
+			peer.Message = view.QuorumMessage
+			votes[peer.MasterAddress]++
+			if view.Quorum {
+				quorum++
+			}
+		}
+		status.Sentinels = append(status.Sentinels, peer)
+	}
+
+	for addr, n := range votes {
+		if n > votes[status.MasterAddress] || (n == votes[status.MasterAddress] && addr < status.MasterAddress) {
+			status.MasterAddress = addr
+		}
+	}
+	status.MastersAgree = len(votes) == 1
+	status.QuorumReachable = quorum > len(views)/2
+
+	if previous.MasterAddress != "" && status.MasterAddress != "" && previous.MasterAddress != status.MasterAddress {
+		status.LastFailoverTime = &now
+	}
+	if status.MasterAddress == "" {
+		// Keep the last known master while no sentinel can be queried.
+		status.MasterAddress = previous.MasterAddress
+	}
+	return status
+}
+
+// setConditions derives the shared conditions from the summarized views of the sentinels
+// and the sentinel StatefulSet, which runs pods out of the desired size.
+func setConditions(instance *rsvb2.RedisSentinel, status *rsvb2.RedisSentinelStatus, stsReady bool, pods int32) {
+	ready := common.NewCondition(commonapi.ConditionReady, true, common.ReasonReady, "Sentinels are ready")
+	progressing := common.NewCondition(commonapi.ConditionProgressing, false, common.ReasonComplete, "The spec is rolled out")
+	degraded := common.NewCondition(commonapi.ConditionDegraded, false, common.ReasonHealthy, "Sentinels are healthy")
+	switch {
+	case !stsReady:
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, common.ReasonRollout, "Waiting for the StatefulSet to be ready"
+		progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionTrue, ready.Reason, ready.Message
+	case instance.Spec.RedisSentinelConfig == nil:
+	case !status.QuorumReachable:
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, "NoQuorum", "SENTINEL CKQUORUM fails on a majority of the sentinels"
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, ready.Reason, ready.Message
+	case !status.MastersAgree:
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, "SplitBrain", "The sentinels report different masters"
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, ready.Reason, ready.Message
+	}
+
+	drift := common.NewCondition(commonapi.ConditionConfigDrift, false, common.ReasonInSync, "Every sentinel monitors the master group")
+	unmonitored := 0
+	for _, peer := range status.Sentinels {
+		if peer.Status == rsvb2.SentinelPeerUnmonitored {
+			unmonitored++
+		}
+	}
+	if unmonitored > 0 {
+		drift.Status, drift.Reason, drift.Message = metav1.ConditionTrue, "Unmonitored", fmt.Sprintf("%d sentinel(s) do not monitor the master group", unmonitored)
+	}
+
+	desired := ptr.Deref(instance.Spec.Size, 1)
+	scaling := common.IsScaling(status.ConditionedStatus, pods != desired, ready.Status == metav1.ConditionTrue)
+	common.SetConditions(&status.ConditionedStatus, instance.Generation,
+		ready, progressing, degraded, drift,
+		common.ScalingCondition(scaling, fmt.Sprintf("Scaling to %d sentinels", desired)),
+	)
+}
@@ -0,0 +1,120 @@
+package redissentinel
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/redis"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestNewStatus(t *testing.T) {
+	now := metav1.NewTime(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	view := func(pod, addr, state string, quorum bool) redis.SentinelView {
+		return redis.SentinelView{
+			Pod:           pod,
+			Master:        &redisservice.SentinelMasterInfo{Name: "mymaster", Status: state, Address: addr, Slaves: 2, Sentinels: 3},
+			Quorum:        quorum,
+			QuorumMessage: "OK 3 usable Sentinels. Quorum and failover authorization can be reached",
+		}
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		status := newStatus(rsvb2.RedisSentinelStatus{}, []redis.SentinelView{
+			view("sentinel-0", "10.0.0.1:6379", "ok", true),
+			view("sentinel-1", "10.0.0.1:6379", "ok", true),
+			view("sentinel-2", "10.0.0.1:6379", "ok", true),
+		}, now)
+		assert.Equal(t, "10.0.0.1:6379", status.MasterAddress)
+		assert.True(t, status.MastersAgree)
+		assert.True(t, status.QuorumReachable)
+		assert.Nil(t, status.LastFailoverTime)
+		require.Len(t, status.Sentinels, 3)
+		assert.Equal(t, rsvb2.SentinelPeerStatus{
+			Pod:           "sentinel-0",
+			Status:        "ok",
+			MasterAddress: "10.0.0.1:6379",
+			Slaves:        2,
+			Sentinels:     3,
+			Quorum:        true,
+			Message:       "OK 3 usable Sentinels. Quorum and failover authorization can be reached",
+		}, status.Sentinels[0])
+	})
+
+	t.Run("split brain after a failover", func(t *testing.T) {
+		status := newStatus(rsvb2.RedisSentinelStatus{MasterAddress: "10.0.0.1:6379"}, []redis.SentinelView{
+			view("sentinel-0", "10.0.0.2:6379", "ok", true),
+			view("sentinel-1", "10.0.0.2:6379", "ok", false),
+			view("sentinel-2", "10.0.0.1:6379", "odown", false),
+		}, now)
+		assert.Equal(t, "10.0.0.2:6379", status.MasterAddress)
+		assert.False(t, status.MastersAgree)
+		assert.False(t, status.QuorumReachable, "only one of three sentinels reaches the quorum")
+		require.NotNil(t, status.LastFailoverTime)
+		assert.Equal(t, now, *status.LastFailoverTime)
+	})
+
+	t.Run("unreachable sentinels", func(t *testing.T) {
+		failover := metav1.NewTime(now.Add(-time.Hour))
+		previous := rsvb2.RedisSentinelStatus{MasterAddress: "10.0.0.1:6379", LastFailoverTime: &failover}
+		status := newStatus(previous, []redis.SentinelView{
+			{Pod: "sentinel-0", Err: errors.New("connection refused")},
+			{Pod: "sentinel-1"},
+		}, now)
+		assert.Equal(t, "10.0.0.1:6379", status.MasterAddress, "the last known master is kept")
+		assert.Equal(t, &failover, status.LastFailoverTime)
+		assert.False(t, status.QuorumReachable)
+		assert.Equal(t, rsvb2.SentinelPeerUnreachable, status.Sentinels[0].Status)
+		assert.Equal(t, "connection refused", status.Sentinels[0].Message)
+		assert.Equal(t, rsvb2.SentinelPeerUnmonitored, status.Sentinels[1].Status)
+	})
+}
+
+func TestSetConditions(t *testing.T) {
+	instance := &rsvb2.RedisSentinel{
+		ObjectMeta: metav1.ObjectMeta{Name: "sentinel", Generation: 5},
+		Spec: rsvb2.RedisSentinelSpec{
+			Size:                ptr.To(int32(3)),
+			RedisSentinelConfig: &rsvb2.RedisSentinelConfig{},
+		},
+	}
+	is := func(status rsvb2.RedisSentinelStatus, condType string) bool {
+		return meta.IsStatusConditionTrue(status.Conditions, condType)
+	}
+
+	healthy := rsvb2.RedisSentinelStatus{MasterAddress: "10.0.0.1:6379", MastersAgree: true, QuorumReachable: true}
+	setConditions(instance, &healthy, true, 3)
+	assert.Equal(t, int64(5), healthy.ObservedGeneration)
+	assert.True(t, is(healthy, common.ConditionReady))
+	assert.False(t, is(healthy, common.ConditionDegraded))
+	assert.False(t, is(healthy, common.ConditionProgressing))
+
+	rollout := rsvb2.RedisSentinelStatus{}
+	setConditions(instance, &rollout, false, 1)
+	assert.True(t, is(rollout, common.ConditionProgressing))
+	assert.False(t, is(rollout, common.ConditionDegraded))
+	assert.False(t, is(rollout, common.ConditionScalingInProgress))
+
+	splitBrain := *healthy.DeepCopy()
+	splitBrain.MastersAgree = false
+	setConditions(instance, &splitBrain, true, 3)
+	assert.True(t, is(splitBrain, common.ConditionDegraded))
+	assert.Equal(t, "SplitBrain", meta.FindStatusCondition(splitBrain.Conditions, common.ConditionDegraded).Reason)
+
+	unmonitored := *healthy.DeepCopy()
+	unmonitored.Sentinels = []rsvb2.SentinelPeerStatus{{Pod: "sentinel-2", Status: rsvb2.SentinelPeerUnmonitored}}
+	setConditions(instance, &unmonitored, true, 3)
+	assert.True(t, is(unmonitored, common.ConditionConfigDrift))
+
+	scaling := *healthy.DeepCopy()
+	setConditions(instance, &scaling, false, 4)
+	assert.True(t, is(scaling, common.ConditionScalingInProgress))
+}
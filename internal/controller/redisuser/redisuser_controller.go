@@ -0,0 +1,331 @@
+package redisuser
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	ruvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisuser/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	RedisUserFinalizer = "redisUserFinalizer"
+
+	// resyncInterval is how often a user is applied again, which adds it to new pods of the target and
+	// restores it on servers that restarted without a persisted ACL file.
+	resyncInterval = time.Minute
+)
+
+// Reasons of the Ready condition of a RedisUser
+const (
+	reasonTargetNotFound = "TargetNotFound"
+	reasonSyncFailed     = "SyncFailed"
+)
+
+// Reconciler reconciles a RedisUser object
+type Reconciler struct {
+	client.Client
+	K8sClient     kubernetes.Interface
+	Recorder      record.EventRecorder
+	SecretWatcher *intctrlutil.ResourceWatcher
+	// ApplyUser creates or updates the user on the servers of the target, overridable in tests.
+	ApplyUser func(ctx context.Context, client kubernetes.Interface, target client.Object, user k8sutils.ACLUser) ([]ruvb2.UserNodeStatus, error)
+	// DeleteUser deletes the users from the servers of the target, overridable in tests.
+	DeleteUser func(ctx context.Context, client kubernetes.Interface, target client.Object, usernames ...string) error
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	instance := &ruvb2.RedisUser{}
+
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		return intctrlutil.RequeueECheck(ctx, err, "failed to get RedisUser instance")
+	}
+	if k8sutils.IsDeleted(instance) {
+		return r.finalize(ctx, instance)
+	}
+	if common.ShouldSkipReconcile(ctx, instance) {
+		return intctrlutil.Reconciled()
+	}
+	if err := k8sutils.AddFinalizer(ctx, instance, RedisUserFinalizer, r.Client); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to add finalizer")
+	}
+
+	target, err := r.getTarget(ctx, instance)
+	if apierrors.IsNotFound(err) {
+		message := fmt.Sprintf("%s %s not found", instance.Spec.Target.Kind, instance.Spec.Target.Name)
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisUserTargetAbsent, message)
+		instance.Status.Nodes = nil
+		instance.Status.SyncedNodes = ""
+		common.SetConditions(&instance.Status.ConditionedStatus, instance.Generation,
+			common.NewCondition(commonapi.ConditionReady, false, reasonTargetNotFound, message))
+		if err := common.UpdateStatus(ctx, r.Client, instance); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to update RedisUser status")
+		}
+		return intctrlutil.RequeueAfter(ctx, resyncInterval, "waiting for the target of the RedisUser")
+	}
+	if err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to get the target of the RedisUser")
+	}
+
+	nodes, err := r.sync(ctx, instance, target)
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisUserSyncFailed, err.Error())
+		common.SetConditions(&instance.Status.ConditionedStatus, instance.Generation,
+			common.NewCondition(commonapi.ConditionReady, false, common.ReasonReconcileFailed, err.Error()))
+		if err := common.UpdateStatus(ctx, r.Client, instance); err != nil {
+			return intctrlutil.RequeueE(ctx, err, "failed to update RedisUser status")
+		}
+		return intctrlutil.RequeueE(ctx, err, "failed to sync RedisUser")
+	}
+	r.setNodes(instance, nodes)
+	if err := common.UpdateStatus(ctx, r.Client, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to update RedisUser status")
+	}
+	return intctrlutil.RequeueAfter(ctx, resyncInterval, "resyncing RedisUser")
+}
+
+// sync applies the user to the servers of target, after deleting it under the name it had if it was renamed.
+func (r *Reconciler) sync(ctx context.Context, instance *ruvb2.RedisUser, target client.Object) ([]ruvb2.UserNodeStatus, error) {
+	username := instance.GetUsername()
+	if username == "default" {
+		return nil, errors.New("the default user is managed through the redisSecret of the target, set spec.username")
+	}
+	secretName, password, err := r.password(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+	instance.Status.Secret = secretName
+
+	if previous := instance.Status.Username; previous != "" && previous != username {
+		if err := r.DeleteUser(ctx, r.K8sClient, target, previous); err != nil {
+			return nil, fmt.Errorf("failed to delete the renamed user %s: %w", previous, err)
+		}
+		log.FromContext(ctx).Info("Deleted renamed ACL user", "user", previous)
+	}
+	nodes, err := r.ApplyUser(ctx, r.K8sClient, target, k8sutils.ACLUser{
+		Name:     username,
+		Password: password,
+		Rules:    instance.Spec.Rules(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	instance.Status.Username = username
+	return nodes, nil
+}
+
+// setNodes records the sync status of the servers, keeping the last sync time of the ones whose status
+// didn't change, and whether the user is applied to all of them.
+func (r *Reconciler) setNodes(instance *ruvb2.RedisUser, nodes []ruvb2.UserNodeStatus) {
+	previous := make(map[string]ruvb2.UserNodeStatus, len(instance.Status.Nodes))
+	for _, node := range instance.Status.Nodes {
+		previous[node.Pod] = node
+	}
+	unchangedSpec := instance.Status.ObservedGeneration == instance.Generation
+	now := metav1.Now()
+	synced := 0
+	var failed []string
+	for i := range nodes {
+		node := &nodes[i]
+		prev, ok := previous[node.Pod]
+		if ok && unchangedSpec && prev.Synced == node.Synced && prev.Persisted == node.Persisted && prev.Error == node.Error {
+			node.LastSyncTime = prev.LastSyncTime
+		} else {
+			node.LastSyncTime = &now
+		}
+		if node.Synced {
+			synced++
+		}
+		if node.Error != "" {
+			failed = append(failed, fmt.Sprintf("%s: %s", node.Pod, node.Error))
+		}
+	}
+	instance.Status.Nodes = nodes
+	instance.Status.SyncedNodes = fmt.Sprintf("%d/%d", synced, len(nodes))
+
+	wasReady := meta.IsStatusConditionTrue(instance.Status.Conditions, commonapi.ConditionReady)
+	var ready metav1.Condition
+	switch {
+	case len(nodes) == 0:
+		ready = common.NewCondition(commonapi.ConditionReady, false, reasonSyncFailed, "The target has no running server")
+	case synced < len(nodes):
+		ready = common.NewCondition(commonapi.ConditionReady, false, reasonSyncFailed,
+			fmt.Sprintf("The user is applied to %d of %d servers", synced, len(nodes)))
+	default:
+		ready = common.NewCondition(commonapi.ConditionReady, true, common.ReasonInSync,
+			fmt.Sprintf("The user is applied to %d servers", len(nodes)))
+	}
+	common.SetConditions(&instance.Status.ConditionedStatus, instance.Generation, ready)
+
+	if len(failed) > 0 {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisUserSyncFailed, strings.Join(failed, "; "))
+	}
+	if ready.Status == metav1.ConditionTrue && !wasReady {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, events.EventReasonRedisUserSynced,
+			"Applied user %s to %d servers", instance.Status.Username, len(nodes))
+	}
+}
+
+// password returns the Secret holding the password of the user and the password, generated into a Secret
+// owned by the RedisUser unless spec.passwordSecret is set.
+func (r *Reconciler) password(ctx context.Context, instance *ruvb2.RedisUser) (string, string, error) {
+	if ref := instance.Spec.PasswordSecret; ref != nil && ref.Name != nil {
+		key := ruvb2.PasswordKey
+		if ref.Key != nil {
+			key = *ref.Key
+		}
+		r.watchSecret(ctx, instance, *ref.Name)
+		secret, err := r.K8sClient.CoreV1().Secrets(instance.Namespace).Get(ctx, *ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get the password secret: %w", err)
+		}
+		password := strings.TrimSpace(string(secret.Data[key]))
+		if password == "" {
+			return "", "", fmt.Errorf("the secret %s holds no password under the key %s", secret.Name, key)
+		}
+		return secret.Name, password, nil
+	}
+
+	name := instance.GeneratedSecretName()
+	r.watchSecret(ctx, instance, name)
+	secret, err := r.K8sClient.CoreV1().Secrets(instance.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		password, err := r.generateSecret(ctx, instance)
+		return name, password, err
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get the generated secret: %w", err)
+	}
+	password := string(secret.Data[ruvb2.PasswordKey])
+	if password == "" {
+		return "", "", fmt.Errorf("the generated secret %s holds no password", name)
+	}
+	if string(secret.Data[ruvb2.UsernameKey]) != instance.GetUsername() {
+		secret.Data[ruvb2.UsernameKey] = []byte(instance.GetUsername())
+		if _, err := r.K8sClient.CoreV1().Secrets(instance.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			return "", "", fmt.Errorf("failed to update the username of the generated secret: %w", err)
+		}
+	}
+	return name, password, nil
+}
+
+// generateSecret creates the Secret owned by the RedisUser holding its username and a random password,
+// and returns the password.
+func (r *Reconciler) generateSecret(ctx context.Context, instance *ruvb2.RedisUser) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	password := hex.EncodeToString(buf)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: instance.GeneratedSecretName(), Namespace: instance.Namespace},
+		Type:       corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			ruvb2.UsernameKey: []byte(instance.GetUsername()),
+			ruvb2.PasswordKey: []byte(password),
+		},
+	}
+	if err := controllerutil.SetControllerReference(instance, secret, r.Scheme()); err != nil {
+		return "", err
+	}
+	if _, err := r.K8sClient.CoreV1().Secrets(instance.Namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to generate the password secret: %w", err)
+	}
+	log.FromContext(ctx).Info("Generated password secret", "secret", secret.Name)
+	return password, nil
+}
+
+func (r *Reconciler) watchSecret(ctx context.Context, instance *ruvb2.RedisUser, name string) {
+	r.SecretWatcher.Watch(ctx,
+		types.NamespacedName{Namespace: instance.Namespace, Name: name},
+		types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name},
+	)
+}
+
+// getTarget returns the Redis resource the user is created on.
+func (r *Reconciler) getTarget(ctx context.Context, instance *ruvb2.RedisUser) (client.Object, error) {
+	var target client.Object
+	switch instance.Spec.Target.Kind {
+	case ruvb2.TargetKindRedis:
+		target = &rvb2.Redis{}
+	case ruvb2.TargetKindRedisReplication:
+		target = &rrvb2.RedisReplication{}
+	case ruvb2.TargetKindRedisCluster:
+		target = &rcvb2.RedisCluster{}
+	default:
+		return nil, fmt.Errorf("unsupported user target kind %q", instance.Spec.Target.Kind)
+	}
+	key := types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.Target.Name}
+	if err := r.Get(ctx, key, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// finalize deletes the user from the servers of the target before the RedisUser is deleted. Nothing is
+// left to delete once the target is gone.
+func (r *Reconciler) finalize(ctx context.Context, instance *ruvb2.RedisUser) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(instance, RedisUserFinalizer) {
+		return intctrlutil.Reconciled()
+	}
+	target, err := r.getTarget(ctx, instance)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return intctrlutil.RequeueE(ctx, err, "failed to get the target of the RedisUser")
+	}
+	if err == nil && !k8sutils.IsDeleted(target) {
+		// The user may have been applied under its current name before the status recorded it.
+		var usernames []string
+		if username := instance.GetUsername(); username != "default" {
+			usernames = append(usernames, username)
+		}
+		if previous := instance.Status.Username; previous != "" && previous != instance.GetUsername() {
+			usernames = append(usernames, previous)
+		}
+		if len(usernames) > 0 {
+			if err := r.DeleteUser(ctx, r.K8sClient, target, usernames...); err != nil {
+				r.Recorder.Event(instance, corev1.EventTypeWarning, events.EventReasonRedisUserSyncFailed, err.Error())
+				return intctrlutil.RequeueE(ctx, err, "failed to delete the ACL user")
+			}
+		}
+	}
+	controllerutil.RemoveFinalizer(instance, RedisUserFinalizer)
+	if err := r.Update(ctx, instance); err != nil {
+		return intctrlutil.RequeueE(ctx, err, "failed to remove finalizer")
+	}
+	return intctrlutil.Reconciled()
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager, opts controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ruvb2.RedisUser{}).
+		WithOptions(opts).
+		WatchesMetadata(&corev1.Secret{}, r.SecretWatcher).
+		Complete(tracing.Reconciler("RedisUser", r))
+}
@@ -0,0 +1,181 @@
+package redisuser
+
+import (
+	"context"
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	ruvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisuser/v1beta2"
+	intctrlutil "github.com/OT-CONTAINER-KIT/redis-operator/internal/controllerutil"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/k8sutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeServers records the users applied to and deleted from the servers of a target.
+type fakeServers struct {
+	applied []k8sutils.ACLUser
+	deleted []string
+	nodes   []ruvb2.UserNodeStatus
+}
+
+func (f *fakeServers) apply(_ context.Context, _ kubernetes.Interface, _ client.Object, user k8sutils.ACLUser) ([]ruvb2.UserNodeStatus, error) {
+	f.applied = append(f.applied, user)
+	return append([]ruvb2.UserNodeStatus(nil), f.nodes...), nil
+}
+
+func (f *fakeServers) delete(_ context.Context, _ kubernetes.Interface, _ client.Object, usernames ...string) error {
+	f.deleted = append(f.deleted, usernames...)
+	return nil
+}
+
+func newTestReconciler(t *testing.T, user *ruvb2.RedisUser, objects ...runtime.Object) (*Reconciler, *fakeServers, types.NamespacedName) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, rcvb2.AddToScheme(scheme))
+	require.NoError(t, ruvb2.AddToScheme(scheme))
+
+	cluster := &rcvb2.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "default"}}
+	cl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(user, cluster).
+		WithStatusSubresource(&ruvb2.RedisUser{}).
+		Build()
+
+	servers := &fakeServers{nodes: []ruvb2.UserNodeStatus{
+		{Pod: "cache-leader-0", Synced: true, Persisted: true},
+		{Pod: "cache-follower-0", Synced: true, Persisted: true},
+	}}
+	return &Reconciler{
+		Client:        cl,
+		K8sClient:     k8sfake.NewSimpleClientset(objects...),
+		Recorder:      record.NewFakeRecorder(10),
+		SecretWatcher: intctrlutil.NewResourceWatcher(),
+		ApplyUser:     servers.apply,
+		DeleteUser:    servers.delete,
+	}, servers, types.NamespacedName{Name: user.Name, Namespace: user.Namespace}
+}
+
+func newRedisUser() *ruvb2.RedisUser {
+	return &ruvb2.RedisUser{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "default", Generation: 1},
+		Spec: ruvb2.RedisUserSpec{
+			Target:   ruvb2.UserTarget{Kind: ruvb2.TargetKindRedisCluster, Name: "cache"},
+			Keys:     []string{"orders:*"},
+			Channels: []string{"orders-events"},
+			Commands: []string{"+@read", "+set"},
+		},
+	}
+}
+
+func TestReconcileGeneratesPasswordAndAppliesUser(t *testing.T) {
+	r, servers, key := newTestReconciler(t, newRedisUser())
+	ctx := context.Background()
+
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, resyncInterval, result.RequeueAfter)
+
+	secret, err := r.K8sClient.CoreV1().Secrets("default").Get(ctx, "orders-redis-user", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "orders", string(secret.Data[ruvb2.UsernameKey]))
+	password := string(secret.Data[ruvb2.PasswordKey])
+	assert.Len(t, password, 48)
+	require.Len(t, secret.OwnerReferences, 1)
+	assert.Equal(t, "orders", secret.OwnerReferences[0].Name)
+
+	require.Len(t, servers.applied, 1)
+	assert.Equal(t, k8sutils.ACLUser{
+		Name:     "orders",
+		Password: password,
+		Rules:    []string{"reset", "on", "~orders:*", "&orders-events", "+@read", "+set"},
+	}, servers.applied[0])
+
+	user := &ruvb2.RedisUser{}
+	require.NoError(t, r.Get(ctx, key, user))
+	assert.Contains(t, user.Finalizers, RedisUserFinalizer)
+	assert.Equal(t, "orders", user.Status.Username)
+	assert.Equal(t, "orders-redis-user", user.Status.Secret)
+	assert.Equal(t, "2/2", user.Status.SyncedNodes)
+	require.Len(t, user.Status.Nodes, 2)
+	assert.NotNil(t, user.Status.Nodes[0].LastSyncTime)
+	assert.True(t, meta.IsStatusConditionTrue(user.Status.Conditions, commonapi.ConditionReady))
+
+	// The generated password is kept on the next pass.
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.Len(t, servers.applied, 2)
+	assert.Equal(t, password, servers.applied[1].Password)
+}
+
+func TestReconcileReportsUnsyncedNodes(t *testing.T) {
+	r, servers, key := newTestReconciler(t, newRedisUser())
+	servers.nodes[1] = ruvb2.UserNodeStatus{Pod: "cache-follower-0", Error: "ACL SETUSER failed: ERR Unknown command"}
+	ctx := context.Background()
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	user := &ruvb2.RedisUser{}
+	require.NoError(t, r.Get(ctx, key, user))
+	assert.Equal(t, "1/2", user.Status.SyncedNodes)
+	assert.False(t, user.Status.Nodes[1].Synced)
+	assert.Equal(t, "ACL SETUSER failed: ERR Unknown command", user.Status.Nodes[1].Error)
+	assert.False(t, meta.IsStatusConditionTrue(user.Status.Conditions, commonapi.ConditionReady))
+}
+
+func TestReconcileUsesPasswordSecret(t *testing.T) {
+	user := newRedisUser()
+	user.Spec.PasswordSecret = &commonapi.ExistingPasswordSecret{Name: ptr.To("orders-password"), Key: ptr.To("pass")}
+	r, servers, key := newTestReconciler(t, user, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders-password", Namespace: "default"},
+		Data:       map[string][]byte{"pass": []byte("s3cret\n")},
+	})
+	ctx := context.Background()
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	require.Len(t, servers.applied, 1)
+	assert.Equal(t, "s3cret", servers.applied[0].Password)
+	_, err = r.K8sClient.CoreV1().Secrets("default").Get(ctx, "orders-redis-user", metav1.GetOptions{})
+	assert.Error(t, err, "no password is generated")
+}
+
+func TestReconcileRenamesAndDeletesUser(t *testing.T) {
+	r, servers, key := newTestReconciler(t, newRedisUser())
+	ctx := context.Background()
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	user := &ruvb2.RedisUser{}
+	require.NoError(t, r.Get(ctx, key, user))
+	user.Spec.Username = "orders-v2"
+	require.NoError(t, r.Update(ctx, user))
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders"}, servers.deleted)
+	assert.Equal(t, "orders-v2", servers.applied[len(servers.applied)-1].Name)
+
+	require.NoError(t, r.Get(ctx, key, user))
+	require.NoError(t, r.Delete(ctx, user))
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders", "orders-v2"}, servers.deleted)
+	assert.Error(t, r.Get(ctx, key, user), "the finalizer is removed")
+}
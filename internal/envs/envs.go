@@ -34,7 +34,7 @@ const (
 	// MaxConcurrentReconcilesEnv defines the maximum number of concurrent reconciles
 	MaxConcurrentReconcilesEnv = "MAX_CONCURRENT_RECONCILES"
 
-	// ExecCommandTimeoutEnv defines the timeout for commands executed inside redis pods via the Kubernetes exec API
+	// ExecCommandTimeoutEnv defines the timeout of a single cluster management operation, e.g. a reshard
 	ExecCommandTimeoutEnv = "EXEC_COMMAND_TIMEOUT"
 
 	// EnableWebhooksEnv defines whether webhooks are enabled
@@ -48,6 +48,10 @@ const (
 
 	// ServiceDNSDomain defines the DNS domain suffix for Kubernetes services
 	ServiceDNSDomain = "SERVICE_DNS_DOMAIN"
+
+	// OTLPEndpointEnv and OTLPTracesEndpointEnv define the OTLP endpoint of the OpenTelemetry SDK the spans are exported to
+	OTLPEndpointEnv       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	OTLPTracesEndpointEnv = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
 )
 
 var (
@@ -95,8 +99,8 @@ func GetMaxConcurrentReconciles(defaultValue int) int {
 	return defaultValue
 }
 
-// GetExecCommandTimeout returns the timeout applied to commands executed inside redis pods
-// via the Kubernetes exec API.
+// GetExecCommandTimeout returns the timeout applied to a single cluster management operation,
+// such as the creation of a cluster or a reshard.
 func GetExecCommandTimeout(defaultValue time.Duration) time.Duration {
 	if valueStr := os.Getenv(ExecCommandTimeoutEnv); valueStr != "" {
 		if value, err := time.ParseDuration(valueStr); err == nil && value > 0 {
@@ -115,3 +119,8 @@ func IsWebhookEnabled() bool {
 func GetFeatureGates() string {
 	return os.Getenv(FeatureGatesEnv)
 }
+
+// IsOTLPEndpointSet returns true if an OTLP endpoint is set for the spans
+func IsOTLPEndpointSet() bool {
+	return os.Getenv(OTLPEndpointEnv) != "" || os.Getenv(OTLPTracesEndpointEnv) != ""
+}
@@ -237,3 +237,43 @@ func TestGetFeatureGates(t *testing.T) {
 		})
 	}
 }
+
+func TestIsOTLPEndpointSet(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           string
+		envValue      string
+		expectedValue bool
+	}{
+		{
+			name:          "no endpoint",
+			expectedValue: false,
+		},
+		{
+			name:          "endpoint",
+			env:           OTLPEndpointEnv,
+			envValue:      "http://otel-collector:4318",
+			expectedValue: true,
+		},
+		{
+			name:          "traces endpoint",
+			env:           OTLPTracesEndpointEnv,
+			envValue:      "http://otel-collector:4318/v1/traces",
+			expectedValue: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(OTLPEndpointEnv, "")
+			t.Setenv(OTLPTracesEndpointEnv, "")
+			if tt.env != "" {
+				t.Setenv(tt.env, tt.envValue)
+			}
+
+			if actualValue := IsOTLPEndpointSet(); actualValue != tt.expectedValue {
+				t.Errorf("IsOTLPEndpointSet() = %v, want %v", actualValue, tt.expectedValue)
+			}
+		})
+	}
+}
@@ -9,14 +9,21 @@ const (
 	// GenerateConfigInInitContainer enables generating Redis configuration using an init container
 	// instead of a regular container
 	GenerateConfigInInitContainer featuregate.Feature = "GenerateConfigInInitContainer"
-	AvoidCommandLinePassword      featuregate.Feature = "AvoidCommandLinePassword"
+	// AvoidCommandLinePassword prevented passing the password to redis-cli on the command line. The
+	// operator no longer runs redis-cli, the gate is only kept so existing flags stay valid.
+	AvoidCommandLinePassword featuregate.Feature = "AvoidCommandLinePassword"
+	// DrainHandover lets the operator hand the master role of a RedisCluster or RedisReplication pod
+	// over to a healthy replica before the pod is evicted or rolled, coordinated with the pods through
+	// a readiness gate and their preStop hook.
+	DrainHandover featuregate.Feature = "DrainHandover"
 )
 
 // DefaultRedisOperatorFeatureGates consists of all known Redis operator feature gates.
 // To add a new feature, define a key for it above and add it here.
 var DefaultRedisOperatorFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	GenerateConfigInInitContainer: {Default: false, PreRelease: featuregate.Alpha},
-	AvoidCommandLinePassword:      {Default: false, PreRelease: featuregate.Alpha},
+	AvoidCommandLinePassword:      {Default: false, PreRelease: featuregate.Deprecated},
+	DrainHandover:                 {Default: false, PreRelease: featuregate.Alpha},
 }
 
 // MutableFeatureGate is a feature gate that can be dynamically set
@@ -0,0 +1,144 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	ruvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisuser/v1beta2"
+	redis "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ACLUser is a user the operator manages on the servers of a resource.
+type ACLUser struct {
+	Name     string
+	Password string
+	// Rules are the ACL rules of the user, see ruvb2.RedisUserSpec.Rules.
+	Rules []string
+}
+
+// ApplyRedisUser creates or updates user on every running server of target, a Redis, RedisReplication
+// or RedisCluster, and returns the sync status of every server. See applyACLUser.
+func ApplyRedisUser(ctx context.Context, client kubernetes.Interface, target client.Object, user ACLUser) ([]ruvb2.UserNodeStatus, error) {
+	pods, connect, err := aclServers(ctx, client, target)
+	if err != nil {
+		return nil, err
+	}
+	return applyACLUser(ctx, pods, connect, user), nil
+}
+
+// DeleteRedisUser deletes the users named usernames from every running server of target, and persists
+// the deletion to the ACL file of the servers that have one.
+func DeleteRedisUser(ctx context.Context, client kubernetes.Interface, target client.Object, usernames ...string) error {
+	pods, connect, err := aclServers(ctx, client, target)
+	if err != nil {
+		return err
+	}
+	args := []interface{}{"ACL", "DELUSER"}
+	for _, username := range usernames {
+		args = append(args, username)
+	}
+	var errs []error
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		redisClient := connect(pod)
+		err := redisClient.Do(ctx, args...).Err()
+		if err == nil {
+			err = aclSave(ctx, redisClient)
+		}
+		if err != nil && !errors.Is(err, errNoACLFile) {
+			errs = append(errs, fmt.Errorf("pod %s: %w", pod.Name, err))
+		}
+		redisClient.Close()
+	}
+	return errors.Join(errs...)
+}
+
+// aclServers returns the pods of the servers of target and a function connecting to the server of a pod.
+func aclServers(ctx context.Context, client kubernetes.Interface, target client.Object) ([]corev1.Pod, func(*corev1.Pod) *redis.Client, error) {
+	switch cr := target.(type) {
+	case *rvb2.Redis:
+		pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, cr.Name+"-0", metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return []corev1.Pod{*pod}, func(pod *corev1.Pod) *redis.Client {
+			return configureRedisStandaloneClient(ctx, client, cr, pod.Name)
+		}, nil
+	case *rrvb2.RedisReplication:
+		pods, err := replicationPods(ctx, client, cr)
+		if err != nil {
+			return nil, nil, err
+		}
+		return pods, func(pod *corev1.Pod) *redis.Client {
+			return configureRedisReplicationClientForPod(ctx, client, cr, pod)
+		}, nil
+	case *rcvb2.RedisCluster:
+		return clusterPods(ctx, client, cr), func(pod *corev1.Pod) *redis.Client {
+			return configureRedisClient(ctx, client, cr, pod.Name)
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported ACL user target %T", target)
+	}
+}
+
+// applyACLUser resets user on the servers of the running pods to its rules and password with ACL SETUSER,
+// and saves it to their ACL file with ACL SAVE. Each server is synced independently, so a server that
+// fails doesn't hold back the others. A server without an ACL file is synced but not persisted.
+func applyACLUser(ctx context.Context, pods []corev1.Pod, connect func(*corev1.Pod) *redis.Client, user ACLUser) []ruvb2.UserNodeStatus {
+	args := []interface{}{"ACL", "SETUSER", user.Name}
+	for _, rule := range user.Rules {
+		args = append(args, rule)
+	}
+	args = append(args, ">"+user.Password)
+
+	var nodes []ruvb2.UserNodeStatus
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		node := ruvb2.UserNodeStatus{Pod: pod.Name}
+		redisClient := connect(pod)
+		if err := redisClient.Do(ctx, args...).Err(); err != nil {
+			node.Error = fmt.Sprintf("ACL SETUSER failed: %v", err)
+		} else {
+			node.Synced = true
+			err := aclSave(ctx, redisClient)
+			node.Persisted = err == nil
+			if err != nil && !errors.Is(err, errNoACLFile) {
+				node.Error = fmt.Sprintf("ACL SAVE failed: %v", err)
+			}
+		}
+		redisClient.Close()
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// errNoACLFile is returned by aclSave for servers started without an ACL file.
+var errNoACLFile = errors.New("no ACL file configured")
+
+// aclSave saves the users of a server to its ACL file, errNoACLFile when it has none.
+func aclSave(ctx context.Context, redisClient *redis.Client) error {
+	err := redisClient.Do(ctx, "ACL", "SAVE").Err()
+	if err != nil && strings.Contains(err.Error(), "not configured to use an ACL file") {
+		return errNoACLFile
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ruvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisuser/v1beta2"
+	"github.com/go-redis/redismock/v9"
+	redis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_applyACLUser(t *testing.T) {
+	user := ACLUser{Name: "app", Password: "secret", Rules: []string{"reset", "on", "~app:*", "+@read"}}
+	noACLFile := errors.New("ERR This Redis instance is not configured to use an ACL file. You may want to specify users via the ACL SETUSER command and then issue a CONFIG REWRITE (assuming you have a Redis configuration file set) in order to store users in the Redis configuration.")
+
+	tests := []struct {
+		name   string
+		expect func(mock redismock.ClientMock)
+		want   ruvb2.UserNodeStatus
+	}{
+		{
+			name: "applied and saved",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectDo("ACL", "SETUSER", "app", "reset", "on", "~app:*", "+@read", ">secret").SetVal("OK")
+				mock.ExpectDo("ACL", "SAVE").SetVal("OK")
+			},
+			want: ruvb2.UserNodeStatus{Pod: "redis-0", Synced: true, Persisted: true},
+		},
+		{
+			name: "server without an ACL file",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectDo("ACL", "SETUSER", "app", "reset", "on", "~app:*", "+@read", ">secret").SetVal("OK")
+				mock.ExpectDo("ACL", "SAVE").SetErr(noACLFile)
+			},
+			want: ruvb2.UserNodeStatus{Pod: "redis-0", Synced: true},
+		},
+		{
+			name: "read-only ACL file",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectDo("ACL", "SETUSER", "app", "reset", "on", "~app:*", "+@read", ">secret").SetVal("OK")
+				mock.ExpectDo("ACL", "SAVE").SetErr(errors.New("ERR There was an error trying to save the ACLs. Please check the server logs for more information"))
+			},
+			want: ruvb2.UserNodeStatus{Pod: "redis-0", Synced: true, Error: "ACL SAVE failed: ERR There was an error trying to save the ACLs. Please check the server logs for more information"},
+		},
+		{
+			name: "rule rejected",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectDo("ACL", "SETUSER", "app", "reset", "on", "~app:*", "+@read", ">secret").SetErr(errors.New("ERR Error in ACL SETUSER modifier '+@read': Unknown command or category name in ACL"))
+			},
+			want: ruvb2.UserNodeStatus{Pod: "redis-0", Error: "ACL SETUSER failed: ERR Error in ACL SETUSER modifier '+@read': Unknown command or category name in ACL"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "redis-0"}, Status: corev1.PodStatus{PodIP: "10.0.0.1"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "redis-1"}},
+			}
+			var mocks []redismock.ClientMock
+			connect := func(pod *corev1.Pod) *redis.Client {
+				redisClient, mock := redismock.NewClientMock()
+				tt.expect(mock)
+				mocks = append(mocks, mock)
+				return redisClient
+			}
+
+			got := applyACLUser(context.TODO(), pods, connect, user)
+			assert.Equal(t, []ruvb2.UserNodeStatus{tt.want}, got)
+			assert.Len(t, mocks, 1, "pods without an IP are skipped")
+			for _, mock := range mocks {
+				assert.NoError(t, mock.ExpectationsWereMet())
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package k8sutils
+
+import (
+	"errors"
+	"strconv"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const aofArchiveContainer = "aof-archive"
+
+var errAOFArchiveWithoutStorage = errors.New("aofArchive requires persistent storage for the data volume")
+
+// aofArchiveEnvVars enables the timestamp annotations point-in-time restores truncate the AOF at.
+func aofArchiveEnvVars(archive *rbvb2.AOFArchive) []corev1.EnvVar {
+	if archive == nil {
+		return nil
+	}
+	return []corev1.EnvVar{{Name: "AOF_TIMESTAMP_ENABLED", Value: "true"}}
+}
+
+// generateAOFArchiveContainerDef returns the sidecar uploading the AOF files of the pod to the archive.
+func generateAOFArchiveContainerDef(name string, archive *rbvb2.AOFArchive, securityContext *corev1.SecurityContext) corev1.Container {
+	s3 := archive.S3
+	interval := "1m"
+	if archive.Interval != nil {
+		interval = archive.Interval.Duration.String()
+	}
+	return corev1.Container{
+		Name:            aofArchiveContainer,
+		Image:           envs.GetInitContainerImage(),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/operator", "agent"},
+		Args:            []string{"archive"},
+		SecurityContext: securityContext,
+		Env: []corev1.EnvVar{
+			{Name: "ARCHIVE_S3_ENDPOINT", Value: s3.Endpoint},
+			{Name: "ARCHIVE_S3_BUCKET", Value: s3.Bucket},
+			{Name: "ARCHIVE_S3_PREFIX", Value: s3.Prefix},
+			{Name: "ARCHIVE_S3_REGION", Value: s3.Region},
+			{Name: "ARCHIVE_S3_INSECURE", Value: strconv.FormatBool(s3.Insecure)},
+			{Name: "ARCHIVE_INTERVAL", Value: interval},
+			s3CredentialEnv(s3.CredentialsSecret, rbvb2.S3AccessKeyIDKey),
+			s3CredentialEnv(s3.CredentialsSecret, rbvb2.S3SecretAccessKeyKey),
+			{
+				Name: "POD_NAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+				},
+			},
+			{
+				Name: "POD_NAMESPACE",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+				},
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      util.CoalesceEnv1(common.EnvOperatorSTSPVCTemplateName, name),
+			MountPath: "/data",
+		}},
+	}
+}
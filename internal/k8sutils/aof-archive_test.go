@@ -0,0 +1,44 @@
+package k8sutils
+
+import (
+	"testing"
+	"time"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestGenerateContainerDefWithAOFArchive(t *testing.T) {
+	archive := &rbvb2.AOFArchive{
+		S3:       rbvb2.S3Storage{Endpoint: "minio:9000", Bucket: "archive", Prefix: "redis", CredentialsSecret: "s3"},
+		Interval: &metav1.Duration{Duration: 30 * time.Second},
+	}
+	containers := generateContainerDef("redis-standalone", containerParameters{
+		Role:               "standalone",
+		PersistenceEnabled: ptr.To(true),
+		AOFArchive:         archive,
+	}, false, false, false, nil, nil, nil, nil)
+
+	require.Len(t, containers, 2)
+	assert.Contains(t, containers[0].Env, aofArchiveEnvVars(archive)[0])
+
+	sidecar := containers[1]
+	assert.Equal(t, aofArchiveContainer, sidecar.Name)
+	assert.Equal(t, []string{"archive"}, sidecar.Args)
+	env := map[string]string{}
+	for _, e := range sidecar.Env {
+		env[e.Name] = e.Value
+		if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+			assert.Equal(t, "s3", e.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+	assert.Equal(t, "archive", env["ARCHIVE_S3_BUCKET"])
+	assert.Equal(t, "redis", env["ARCHIVE_S3_PREFIX"])
+	assert.Equal(t, "30s", env["ARCHIVE_INTERVAL"])
+	assert.Contains(t, env, "POD_NAMESPACE")
+	require.Len(t, sidecar.VolumeMounts, 1)
+	assert.Equal(t, "redis-standalone", sidecar.VolumeMounts[0].Name)
+}
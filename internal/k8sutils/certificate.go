@@ -0,0 +1,152 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// CertificateGVK is the cert-manager Certificate API. The cert-manager types are not vendored,
+// Certificates are handled as unstructured objects.
+var CertificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// certificateName returns the name of the Certificate issuing the TLS secret of a resource.
+func certificateName(crName string) string {
+	return crName + "-tls"
+}
+
+// tlsStatefulSet is a StatefulSet whose pods serve TLS, and its headless service.
+type tlsStatefulSet struct {
+	name     string
+	replicas int32
+}
+
+// CreateOrUpdateRedisCertificate requests the TLS secret of a standalone Redis from cert-manager.
+func CreateOrUpdateRedisCertificate(ctx context.Context, cl client.Client, cr *rvb2.Redis) error {
+	return createOrUpdateCertificate(ctx, cl, cr.Spec.TLS, cr.Namespace, certificateName(cr.Name), redisAsOwner(cr),
+		[]string{cr.Name, cr.Name + "-headless", cr.Name + "-additional"},
+		[]tlsStatefulSet{{name: cr.Name, replicas: 1}})
+}
+
+// CreateOrUpdateRedisReplicationCertificate requests the TLS secret of a RedisReplication from cert-manager.
+func CreateOrUpdateRedisReplicationCertificate(ctx context.Context, cl client.Client, cr *rrvb2.RedisReplication) error {
+	return createOrUpdateCertificate(ctx, cl, cr.Spec.TLS, cr.Namespace, certificateName(cr.Name), redisReplicationAsOwner(cr),
+		[]string{cr.Name, cr.Name + "-headless", cr.Name + "-additional", cr.MasterService(), cr.Name + "-replica"},
+		[]tlsStatefulSet{{name: cr.RedisStatefulSet(), replicas: cr.Spec.GetReplicationCounts("replication")}})
+}
+
+// CreateOrUpdateRedisClusterCertificate requests the TLS secret of a RedisCluster from cert-manager.
+func CreateOrUpdateRedisClusterCertificate(ctx context.Context, cl client.Client, cr *rcvb2.RedisCluster) error {
+	var services []string
+	var statefulSets []tlsStatefulSet
+	for _, role := range []string{"leader", "follower"} {
+		name := cr.Name + "-" + role
+		services = append(services, name, name+"-headless", name+"-additional")
+		statefulSets = append(statefulSets, tlsStatefulSet{name: name, replicas: cr.Spec.GetReplicaCounts(role)})
+	}
+	return createOrUpdateCertificate(ctx, cl, cr.Spec.TLS, cr.Namespace, certificateName(cr.Name), redisClusterAsOwner(cr), services, statefulSets)
+}
+
+// CreateOrUpdateRedisSentinelCertificate requests the TLS secret of a RedisSentinel from cert-manager.
+func CreateOrUpdateRedisSentinelCertificate(ctx context.Context, cl client.Client, cr *rsvb2.RedisSentinel) error {
+	name := cr.Name + "-sentinel"
+	return createOrUpdateCertificate(ctx, cl, cr.Spec.TLS, cr.Namespace, certificateName(cr.Name), redisSentinelAsOwner(cr),
+		[]string{name, name + "-headless", name + "-additional"},
+		[]tlsStatefulSet{{name: name, replicas: cr.Spec.GetSentinelCounts("sentinel")}})
+}
+
+// createOrUpdateCertificate creates or updates the Certificate writing the TLS secret of tlsConfig when it
+// has an issuerRef. The certificate is valid for the services and for every pod of the StatefulSets, so
+// clients verifying the hostname can reach a single pod through the headless service.
+func createOrUpdateCertificate(ctx context.Context, cl client.Client, tlsConfig *commonapi.TLSConfig, namespace, name string,
+	owner metav1.OwnerReference, services []string, statefulSets []tlsStatefulSet,
+) error {
+	if tlsConfig == nil || tlsConfig.IssuerRef == nil {
+		return nil
+	}
+	desired := newCertificate(namespace, name, tlsConfig, certificateDNSNames(namespace, services, statefulSets))
+	AddOwnerRefToObject(desired, owner)
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(CertificateGVK)
+	err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, current)
+	if apierrors.IsNotFound(err) {
+		if err := cl.Create(ctx, desired); err != nil {
+			return fmt.Errorf("failed to create the cert-manager Certificate %s: %w", name, err)
+		}
+		log.FromContext(ctx).Info("Created the cert-manager Certificate", "Certificate", name, "Secret", tlsConfig.Secret.SecretName)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the cert-manager Certificate %s: %w", name, err)
+	}
+	if equality.Semantic.DeepEqual(current.Object["spec"], desired.Object["spec"]) {
+		return nil
+	}
+	current.Object["spec"] = desired.Object["spec"]
+	if err := cl.Update(ctx, current); err != nil {
+		return fmt.Errorf("failed to update the cert-manager Certificate %s: %w", name, err)
+	}
+	log.FromContext(ctx).Info("Updated the cert-manager Certificate", "Certificate", name)
+	return nil
+}
+
+// newCertificate returns a Certificate writing a server and client certificate for dnsNames to the TLS secret.
+func newCertificate(namespace, name string, tlsConfig *commonapi.TLSConfig, dnsNames []string) *unstructured.Unstructured {
+	issuerRef := map[string]interface{}{
+		"name": tlsConfig.IssuerRef.Name,
+	}
+	if tlsConfig.IssuerRef.Kind != "" {
+		issuerRef["kind"] = tlsConfig.IssuerRef.Kind
+	}
+	if tlsConfig.IssuerRef.Group != "" {
+		issuerRef["group"] = tlsConfig.IssuerRef.Group
+	}
+	names := make([]interface{}, 0, len(dnsNames))
+	for _, dnsName := range dnsNames {
+		names = append(names, dnsName)
+	}
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(CertificateGVK)
+	certificate.SetNamespace(namespace)
+	certificate.SetName(name)
+	certificate.Object["spec"] = map[string]interface{}{
+		"secretName": tlsConfig.Secret.SecretName,
+		"issuerRef":  issuerRef,
+		"dnsNames":   names,
+		// The nodes connect to each other with the certificate for replication and the cluster bus.
+		"usages": []interface{}{"server auth", "client auth"},
+	}
+	return certificate
+}
+
+// certificateDNSNames returns the DNS names of the services, and of every pod of the StatefulSets
+// through their headless service.
+func certificateDNSNames(namespace string, services []string, statefulSets []tlsStatefulSet) []string {
+	domain := envs.GetServiceDNSDomain()
+	var names []string
+	for _, svc := range services {
+		names = append(names, svc, svc+"."+namespace, svc+"."+namespace+".svc", svc+"."+namespace+".svc."+domain)
+	}
+	for _, sts := range statefulSets {
+		for i := int32(0); i < sts.replicas; i++ {
+			pod := fmt.Sprintf("%s-%d.%s-headless.%s.svc", sts.name, i, sts.name, namespace)
+			names = append(names, pod, pod+"."+domain)
+		}
+	}
+	return names
+}
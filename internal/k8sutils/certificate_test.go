@@ -0,0 +1,60 @@
+package k8sutils
+
+import (
+	"context"
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateOrUpdateRedisClusterCertificate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(CertificateGVK, &unstructured.Unstructured{})
+	cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+	ctx := context.Background()
+
+	cr := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "default"},
+		Spec: rcvb2.RedisClusterSpec{
+			ClusterSize: ptr.To(int32(1)),
+			TLS: &commonapi.TLSConfig{
+				Secret:    corev1.SecretVolumeSource{SecretName: "cache-tls"},
+				IssuerRef: &commonapi.CertIssuerRef{Name: "ca", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+			},
+		},
+	}
+	require.NoError(t, CreateOrUpdateRedisClusterCertificate(ctx, cl, cr))
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(CertificateGVK)
+	require.NoError(t, cl.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cache-tls"}, certificate))
+	require.Len(t, certificate.GetOwnerReferences(), 1)
+	assert.Equal(t, "cache", certificate.GetOwnerReferences()[0].Name)
+	secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+	assert.Equal(t, "cache-tls", secretName)
+	issuerRef, _, _ := unstructured.NestedStringMap(certificate.Object, "spec", "issuerRef")
+	assert.Equal(t, map[string]string{"name": "ca", "kind": "ClusterIssuer", "group": "cert-manager.io"}, issuerRef)
+	dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+	assert.Contains(t, dnsNames, "cache-leader")
+	assert.Contains(t, dnsNames, "cache-follower-additional.default.svc.cluster.local")
+	assert.Contains(t, dnsNames, "cache-leader-0.cache-leader-headless.default.svc")
+	assert.Contains(t, dnsNames, "cache-follower-0.cache-follower-headless.default.svc.cluster.local")
+	assert.NotContains(t, dnsNames, "cache-leader-1.cache-leader-headless.default.svc")
+
+	// Scaling the cluster adds the SANs of the new pods.
+	cr.Spec.ClusterSize = ptr.To(int32(3))
+	require.NoError(t, CreateOrUpdateRedisClusterCertificate(ctx, cl, cr))
+	require.NoError(t, cl.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cache-tls"}, certificate))
+	dnsNames, _, _ = unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+	assert.Contains(t, dnsNames, "cache-leader-2.cache-leader-headless.default.svc")
+}
@@ -0,0 +1,381 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// podLocation is where the pod of a cluster node runs.
+type podLocation struct {
+	Pod  string
+	Node string
+	Zone string
+}
+
+// colocation scores how close two pods run: 2 on the same node, 1 in the same zone, and 0 in
+// different zones or when it is unknown.
+func colocation(a, b podLocation) int {
+	switch {
+	case a.Node != "" && a.Node == b.Node:
+		return 2
+	case a.Zone != "" && a.Zone == b.Zone:
+		return 1
+	}
+	return 0
+}
+
+// clusterPods returns the leader and follower pods of cr that exist.
+func clusterPods(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) []corev1.Pod {
+	var pods []corev1.Pod
+	for _, role := range []string{"leader", "follower"} {
+		for i := int32(0); i < cr.Spec.GetReplicaCounts(role); i++ {
+			pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, fmt.Sprintf("%s-%s-%d", cr.Name, role, i), metav1.GetOptions{})
+			if err != nil {
+				log.FromContext(ctx).V(1).Info("Skipping cluster pod", "Pod", fmt.Sprintf("%s-%s-%d", cr.Name, role, i), "Error", err.Error())
+				continue
+			}
+			pods = append(pods, *pod)
+		}
+	}
+	return pods
+}
+
+// podOfNode returns the pod running node among pods, from the hostname the node announces or its IP. A
+// pod behind a load balancer only reachable by name announces that name instead of its own, and its IP.
+func podOfNode(node redisservice.ClusterNode, pods []corev1.Pod) *corev1.Pod {
+	name, _, _ := strings.Cut(node.Hostname, ".")
+	host, _, _ := net.SplitHostPort(node.Addr)
+	for i := range pods {
+		if name != "" && pods[i].Name == name {
+			return &pods[i]
+		}
+		_, lbHostname := podAnnouncedAddress(&pods[i])
+		if (name == "" || lbHostname != "") && host != "" && pods[i].Status.PodIP == host {
+			return &pods[i]
+		}
+	}
+	return nil
+}
+
+// clusterPodLocations returns where each pod of pods runs, by pod name, looking up the zone of its node.
+func clusterPodLocations(ctx context.Context, client kubernetes.Interface, pods []corev1.Pod) map[string]podLocation {
+	zones := map[string]string{}
+	locations := make(map[string]podLocation, len(pods))
+	for _, pod := range pods {
+		nodeName := pod.Spec.NodeName
+		zone, ok := zones[nodeName]
+		if !ok && nodeName != "" {
+			if node, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{}); err != nil {
+				log.FromContext(ctx).V(1).Info("Failed to get the zone of the node", "Node", nodeName, "Error", err.Error())
+			} else {
+				zone = node.Labels[corev1.LabelTopologyZone]
+			}
+			zones[nodeName] = zone
+		}
+		locations[pod.Name] = podLocation{Pod: pod.Name, Node: nodeName, Zone: zone}
+	}
+	return locations
+}
+
+// nodeLocations maps the nodes of the cluster to the location of their pod, by node ID.
+func nodeLocations(nodes []redisservice.ClusterNode, pods []corev1.Pod, locations map[string]podLocation) map[string]podLocation {
+	byNode := make(map[string]podLocation, len(nodes))
+	for _, node := range nodes {
+		if pod := podOfNode(node, pods); pod != nil {
+			byNode[node.ID] = locations[pod.Name]
+		}
+	}
+	return byNode
+}
+
+// replicaPlacement is the assignment of the replicas of a cluster to its masters.
+type replicaPlacement struct {
+	replicasPerShard int
+	// locations are the locations of the nodes by node ID.
+	locations map[string]podLocation
+	// masters are the IDs of the healthy masters serving slots, sorted.
+	masters []string
+	// replicas maps the masters to the IDs of their replicas.
+	replicas map[string][]string
+}
+
+// choose returns the master a replica at loc should replicate: among the masters with fewer than
+// replicasPerShard replicas, the one farthest from loc, then with the fewest replicas. When every
+// master has its replicas, the replica is placed on any master if force is set, and otherwise ""
+// is returned.
+func (p *replicaPlacement) choose(loc podLocation, force bool) string {
+	best, bestScore, bestCount := "", 0, 0
+	for _, m := range p.masters {
+		count := len(p.replicas[m])
+		if count >= p.replicasPerShard {
+			continue
+		}
+		score := colocation(loc, p.locations[m])
+		if best == "" || score < bestScore || (score == bestScore && count < bestCount) {
+			best, bestScore, bestCount = m, score, count
+		}
+	}
+	if best != "" || !force {
+		return best
+	}
+	for _, m := range p.masters {
+		count := len(p.replicas[m])
+		score := colocation(loc, p.locations[m])
+		if best == "" || count < bestCount || (count == bestCount && score < bestScore) {
+			best, bestScore, bestCount = m, score, count
+		}
+	}
+	return best
+}
+
+// replicaMove makes the replica Replica replicate the master Master.
+type replicaMove struct {
+	Replica string
+	Master  string
+}
+
+// planReplicas assigns the healthy replicas of nodes so that every healthy master serving slots has
+// replicasPerShard replicas, away from the node and zone of its master when possible. It moves the
+// orphaned replicas, whose master is gone, is a replica itself or serves no slot, and the replicas a
+// master has beyond replicasPerShard when another master lacks some. Then it swaps the replicas
+// sharing a node or zone with their master with the replicas of other masters when it brings both
+// closer to anti-affinity. The replicas of a failing master are left alone for the cluster to fail
+// it over.
+func planReplicas(nodes []redisservice.ClusterNode, replicasPerShard int, locations map[string]podLocation) (*replicaPlacement, []replicaMove) {
+	p := &replicaPlacement{replicasPerShard: replicasPerShard, locations: locations, replicas: map[string][]string{}}
+	current := map[string]string{}
+	serving := map[string]bool{}
+	for i := range nodes {
+		// A master without slots, such as a follower promoted before a scale-down, takes no replicas.
+		if nodes[i].IsMaster() && nodes[i].Healthy() && nodes[i].SlotCount() > 0 {
+			p.masters = append(p.masters, nodes[i].ID)
+			serving[nodes[i].ID] = true
+		}
+	}
+	sort.Strings(p.masters)
+
+	var orphans, surplus []string
+	byMaster := map[string][]string{}
+	for i := range nodes {
+		n := &nodes[i]
+		if n.IsMaster() || !n.Healthy() {
+			continue
+		}
+		current[n.ID] = n.MasterID
+		master := findClusterNode(nodes, n.MasterID)
+		switch {
+		case serving[n.MasterID]:
+			byMaster[n.MasterID] = append(byMaster[n.MasterID], n.ID)
+		case master == nil || !master.IsMaster() || master.Healthy():
+			orphans = append(orphans, n.ID)
+		}
+	}
+	for _, m := range p.masters {
+		replicas := byMaster[m]
+		sort.Slice(replicas, func(i, j int) bool {
+			si, sj := colocation(locations[replicas[i]], locations[m]), colocation(locations[replicas[j]], locations[m])
+			return si < sj || (si == sj && replicas[i] < replicas[j])
+		})
+		keep := min(len(replicas), replicasPerShard)
+		p.replicas[m] = append(p.replicas[m], replicas[:keep]...)
+		surplus = append(surplus, replicas[keep:]...)
+	}
+	sort.Strings(orphans)
+	sort.Strings(surplus)
+
+	for _, r := range orphans {
+		if m := p.choose(locations[r], true); m != "" {
+			p.replicas[m] = append(p.replicas[m], r)
+		}
+	}
+	for _, r := range surplus {
+		m := p.choose(locations[r], false)
+		if m == "" {
+			// No master lacks replicas: the replica stays with its master.
+			m = current[r]
+		}
+		p.replicas[m] = append(p.replicas[m], r)
+	}
+	p.swapColocated()
+
+	var moves []replicaMove
+	for _, m := range p.masters {
+		for _, r := range p.replicas[m] {
+			if current[r] != m {
+				moves = append(moves, replicaMove{Replica: r, Master: m})
+			}
+		}
+	}
+	return p, moves
+}
+
+// swapColocated swaps the replicas sharing a node or zone with their master with replicas of other
+// masters, whenever the swap lowers the colocation of the two pairs.
+func (p *replicaPlacement) swapColocated() {
+	for _, m := range p.masters {
+		for i, r := range p.replicas[m] {
+			cost := colocation(p.locations[r], p.locations[m])
+			if cost == 0 {
+				continue
+			}
+		swap:
+			for _, m2 := range p.masters {
+				if m2 == m {
+					continue
+				}
+				for j, r2 := range p.replicas[m2] {
+					before := cost + colocation(p.locations[r2], p.locations[m2])
+					after := colocation(p.locations[r], p.locations[m2]) + colocation(p.locations[r2], p.locations[m])
+					if after < before {
+						p.replicas[m][i], p.replicas[m2][j] = r2, r
+						break swap
+					}
+				}
+			}
+		}
+	}
+}
+
+func findClusterNode(nodes []redisservice.ClusterNode, id string) *redisservice.ClusterNode {
+	for i := range nodes {
+		if id != "" && nodes[i].ID == id {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
+
+// BalanceRedisClusterReplicas re-homes the replicas of the cluster: the orphaned replicas of a lost
+// leader, the replicas a leader has beyond its share, and the replicas sharing a node or zone with
+// their master, as planned by planReplicas.
+func BalanceRedisClusterReplicas(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) error {
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return err
+	}
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
+	if err != nil {
+		return err
+	}
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return err
+	}
+	pods := clusterPods(ctx, client, cr)
+	locations := nodeLocations(nodes, pods, clusterPodLocations(ctx, client, pods))
+	_, moves := planReplicas(nodes, int(cr.Spec.GetReplicasPerShard()), locations)
+
+	ctx, cancel := clusterOperationContext(ctx)
+	defer cancel()
+	var errs []error
+	for _, move := range moves {
+		replica := findClusterNode(nodes, move.Replica)
+		log.FromContext(ctx).Info("Moving replica", "Replica", locations[move.Replica].Pod, "Replica.ID", move.Replica,
+			"Master", locations[move.Master].Pod, "Master.ID", move.Master)
+		if err := admin.AddNode(ctx, seed, replica.Addr, move.Master); err != nil {
+			errs = append(errs, fmt.Errorf("failed to move replica %s to master %s: %w", move.Replica, move.Master, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RemoveRedisFollowerPodsFromCluster removes the nodes of the follower pods with an ordinal of at least
+// from from the cluster, ahead of the scale-down of the follower StatefulSet to from pods. A follower
+// pod that was promoted to master is failed over to one of its replicas first, and the removal is
+// retried once it is a replica.
+func RemoveRedisFollowerPodsFromCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, from, to int32) error {
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return err
+	}
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
+	if err != nil {
+		return err
+	}
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return err
+	}
+
+	// The surplus followers are beyond the replicas of the spec, so clusterPods doesn't return them.
+	pods := clusterPods(ctx, client, cr)
+	surplus := map[string]bool{}
+	for i := from; i < to; i++ {
+		pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, fmt.Sprintf("%s-follower-%d", cr.Name, i), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		pods = append(pods, *pod)
+		surplus[pod.Name] = true
+	}
+	followers, err := surplusFollowers(nodes, pods, surplus)
+	errs := []error{err}
+
+	ctx, cancel := clusterOperationContext(ctx)
+	defer cancel()
+	for i := from; i < to; i++ {
+		podName := fmt.Sprintf("%s-follower-%d", cr.Name, i)
+		node, ok := followers[podName]
+		if !ok {
+			continue
+		}
+		if node.IsMaster() && node.SlotCount() > 0 {
+			errs = append(errs, failoverToReplica(ctx, admin, nodes, node))
+			continue
+		}
+		log.FromContext(ctx).Info("Removing follower from the cluster ahead of the scale-down", "Pod", podName, "Node.ID", node.ID)
+		if err := admin.DelNode(ctx, seed, node.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove %s from the cluster: %w", podName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// surplusFollowers returns the nodes of the cluster run by the pods named in surplus, by pod name. A
+// healthy node none of pods runs may be a surplus follower, so it fails to resolve it rather than
+// leaving it in the cluster.
+func surplusFollowers(nodes []redisservice.ClusterNode, pods []corev1.Pod, surplus map[string]bool) (map[string]*redisservice.ClusterNode, error) {
+	followers := map[string]*redisservice.ClusterNode{}
+	var errs []error
+	for i := range nodes {
+		pod := podOfNode(nodes[i], pods)
+		if pod == nil {
+			if nodes[i].Healthy() {
+				errs = append(errs, fmt.Errorf("failed to resolve the pod of node %s at %s", nodes[i].ID, nodes[i].Addr))
+			}
+			continue
+		}
+		if surplus[pod.Name] {
+			followers[pod.Name] = &nodes[i]
+		}
+	}
+	return followers, errors.Join(errs...)
+}
+
+// failoverToReplica makes a healthy replica of master take over its slots.
+func failoverToReplica(ctx context.Context, admin redisservice.ClusterAdmin, nodes []redisservice.ClusterNode, master *redisservice.ClusterNode) error {
+	for _, n := range nodes {
+		if n.MasterID == master.ID && n.Healthy() {
+			if err := admin.Failover(ctx, n.Addr, redisservice.FailoverDefault); err != nil {
+				return err
+			}
+			return fmt.Errorf("master %s serves slots, failing it over to its replica %s first", master.ID, n.ID)
+		}
+	}
+	return fmt.Errorf("master %s serves slots and has no healthy replica to fail over to", master.ID)
+}
@@ -0,0 +1,189 @@
+package k8sutils
+
+import (
+	"testing"
+
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_colocation(t *testing.T) {
+	assert.Equal(t, 2, colocation(podLocation{Node: "n1", Zone: "z1"}, podLocation{Node: "n1", Zone: "z1"}))
+	assert.Equal(t, 1, colocation(podLocation{Node: "n1", Zone: "z1"}, podLocation{Node: "n2", Zone: "z1"}))
+	assert.Equal(t, 0, colocation(podLocation{Node: "n1", Zone: "z1"}, podLocation{Node: "n2", Zone: "z2"}))
+	assert.Equal(t, 0, colocation(podLocation{}, podLocation{}))
+}
+
+func Test_podOfNode(t *testing.T) {
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-leader-0"}, Status: corev1.PodStatus{PodIP: "10.0.0.1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-follower-0"}, Status: corev1.PodStatus{PodIP: "10.0.0.2"}},
+	}
+	pod := podOfNode(redisservice.ClusterNode{Addr: "10.0.0.9:6379", Hostname: "redis-cluster-leader-0.redis-cluster-leader-headless"}, pods)
+	require.NotNil(t, pod)
+	assert.Equal(t, "redis-cluster-leader-0", pod.Name)
+	pod = podOfNode(redisservice.ClusterNode{Addr: "10.0.0.2:6379"}, pods)
+	require.NotNil(t, pod)
+	assert.Equal(t, "redis-cluster-follower-0", pod.Name)
+	assert.Nil(t, podOfNode(redisservice.ClusterNode{Addr: "10.0.0.3:6379"}, pods))
+}
+
+func Test_surplusFollowers(t *testing.T) {
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-leader-0"}, Status: corev1.PodStatus{PodIP: "10.0.0.1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-follower-0"}, Status: corev1.PodStatus{PodIP: "10.0.0.2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-follower-1"}, Status: corev1.PodStatus{PodIP: "10.0.0.3"}},
+	}
+	surplus := map[string]bool{"redis-cluster-follower-1": true}
+	leader := redisservice.ClusterNode{ID: "a", Addr: "10.0.0.1:6379", Flags: []string{"master"}, Connected: true}
+	kept := redisservice.ClusterNode{ID: "b", Addr: "10.0.0.2:6379", Flags: []string{"slave"}, MasterID: "a", Connected: true}
+	removed := redisservice.ClusterNode{ID: "c", Addr: "10.0.0.3:6379", Flags: []string{"slave"}, MasterID: "a", Connected: true}
+
+	followers, err := surplusFollowers([]redisservice.ClusterNode{leader, kept, removed}, pods, surplus)
+	require.NoError(t, err)
+	require.Len(t, followers, 1)
+	assert.Equal(t, "c", followers["redis-cluster-follower-1"].ID)
+
+	// The node of a deleted pod is left to the repair of failed nodes.
+	failed := redisservice.ClusterNode{ID: "d", Addr: "10.0.0.9:6379", Flags: []string{"slave", "fail"}, MasterID: "a"}
+	_, err = surplusFollowers([]redisservice.ClusterNode{leader, kept, removed, failed}, pods, surplus)
+	require.NoError(t, err)
+
+	unresolved := redisservice.ClusterNode{ID: "e", Addr: "10.0.0.8:6379", Flags: []string{"slave"}, MasterID: "a", Connected: true}
+	followers, err = surplusFollowers([]redisservice.ClusterNode{leader, kept, removed, unresolved}, pods, surplus)
+	require.ErrorContains(t, err, "failed to resolve the pod of node e at 10.0.0.8:6379")
+	assert.Len(t, followers, 1)
+}
+
+func Test_planReplicas(t *testing.T) {
+	tests := []struct {
+		name             string
+		nodes            string
+		replicasPerShard int
+		locations        map[string]podLocation
+		want             []replicaMove
+	}{
+		{
+			name: "balanced replicas stay",
+			nodes: "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-8191\n" +
+				"b 10.0.0.2:6379@16379 master - 0 0 2 connected 8192-16383\n" +
+				"r1 10.0.0.3:6379@16379 slave a 0 0 1 connected\n" +
+				"r2 10.0.0.4:6379@16379 slave b 0 0 2 connected\n",
+			replicasPerShard: 1,
+			locations: map[string]podLocation{
+				"a": {Node: "n1", Zone: "z1"}, "b": {Node: "n2", Zone: "z2"},
+				"r1": {Node: "n2", Zone: "z2"}, "r2": {Node: "n1", Zone: "z1"},
+			},
+		},
+		{
+			name: "orphan of a lost leader is re-homed",
+			nodes: "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-8191\n" +
+				"b 10.0.0.2:6379@16379 master - 0 0 3 connected 8192-16383\n" +
+				"r1 10.0.0.3:6379@16379 slave a 0 0 1 connected\n" +
+				"r2 10.0.0.4:6379@16379 slave gone 0 0 2 connected\n",
+			replicasPerShard: 1,
+			locations: map[string]podLocation{
+				"a": {Node: "n1", Zone: "z1"}, "b": {Node: "n2", Zone: "z2"},
+				"r1": {Node: "n2", Zone: "z2"}, "r2": {Node: "n1", Zone: "z1"},
+			},
+			want: []replicaMove{{Replica: "r2", Master: "b"}},
+		},
+		{
+			name: "replica of a promoted replica is re-homed",
+			nodes: "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-8191\n" +
+				"b 10.0.0.2:6379@16379 master - 0 0 3 connected 8192-16383\n" +
+				"r1 10.0.0.3:6379@16379 slave a 0 0 1 connected\n" +
+				"r2 10.0.0.4:6379@16379 slave r1 0 0 1 connected\n",
+			replicasPerShard: 1,
+			want:             []replicaMove{{Replica: "r2", Master: "b"}},
+		},
+		{
+			name: "surplus replica moves to a leader lacking replicas",
+			nodes: "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-8191\n" +
+				"b 10.0.0.2:6379@16379 master - 0 0 2 connected 8192-16383\n" +
+				"r1 10.0.0.3:6379@16379 slave a 0 0 1 connected\n" +
+				"r2 10.0.0.4:6379@16379 slave a 0 0 1 connected\n",
+			replicasPerShard: 1,
+			locations: map[string]podLocation{
+				"a": {Node: "n1", Zone: "z1"}, "b": {Node: "n2", Zone: "z2"},
+				"r1": {Node: "n3", Zone: "z1"}, "r2": {Node: "n4", Zone: "z3"},
+			},
+			want: []replicaMove{{Replica: "r1", Master: "b"}},
+		},
+		{
+			name: "surplus replica stays when no leader lacks replicas",
+			nodes: "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-8191\n" +
+				"b 10.0.0.2:6379@16379 master - 0 0 2 connected 8192-16383\n" +
+				"r1 10.0.0.3:6379@16379 slave a 0 0 1 connected\n" +
+				"r2 10.0.0.4:6379@16379 slave a 0 0 1 connected\n" +
+				"r3 10.0.0.5:6379@16379 slave b 0 0 2 connected\n",
+			replicasPerShard: 1,
+		},
+		{
+			name: "replicas colocated with their leader are swapped",
+			nodes: "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-8191\n" +
+				"b 10.0.0.2:6379@16379 master - 0 0 2 connected 8192-16383\n" +
+				"r1 10.0.0.3:6379@16379 slave a 0 0 1 connected\n" +
+				"r2 10.0.0.4:6379@16379 slave b 0 0 2 connected\n",
+			replicasPerShard: 1,
+			locations: map[string]podLocation{
+				"a": {Node: "n1", Zone: "z1"}, "b": {Node: "n2", Zone: "z2"},
+				"r1": {Node: "n1", Zone: "z1"}, "r2": {Node: "n4", Zone: "z2"},
+			},
+			want: []replicaMove{{Replica: "r2", Master: "a"}, {Replica: "r1", Master: "b"}},
+		},
+		{
+			name: "replicas of a failing leader are left for the failover",
+			nodes: "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-8191\n" +
+				"b 10.0.0.2:6379@16379 master,fail - 0 0 2 disconnected 8192-16383\n" +
+				"r1 10.0.0.3:6379@16379 slave b 0 0 2 connected\n",
+			replicasPerShard: 1,
+		},
+		{
+			name: "leaders without slots take no replicas",
+			nodes: "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-16383\n" +
+				"e 10.0.0.2:6379@16379 master - 0 0 2 connected\n" +
+				"r1 10.0.0.3:6379@16379 slave e 0 0 2 connected\n",
+			replicasPerShard: 1,
+			want:             []replicaMove{{Replica: "r1", Master: "a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := redisservice.ParseClusterNodes(tt.nodes)
+			require.NoError(t, err)
+			_, moves := planReplicas(nodes, tt.replicasPerShard, tt.locations)
+			assert.Equal(t, tt.want, moves)
+		})
+	}
+}
+
+func Test_replicaPlacement_choose(t *testing.T) {
+	nodes, err := redisservice.ParseClusterNodes(
+		"a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-5460\n" +
+			"b 10.0.0.2:6379@16379 master - 0 0 2 connected 5461-10922\n" +
+			"c 10.0.0.3:6379@16379 master - 0 0 3 connected 10923-16383\n" +
+			"r1 10.0.0.4:6379@16379 slave a 0 0 1 connected\n")
+	require.NoError(t, err)
+	locations := map[string]podLocation{
+		"a": {Node: "n1", Zone: "z1"}, "b": {Node: "n2", Zone: "z2"}, "c": {Node: "n3", Zone: "z3"},
+		"r1": {Node: "n2", Zone: "z2"},
+	}
+	p, moves := planReplicas(nodes, 2, locations)
+	assert.Empty(t, moves)
+
+	// A new replica in z2 goes to the leader farthest from it among those with the fewest replicas.
+	assert.Equal(t, "c", p.choose(podLocation{Node: "n4", Zone: "z2"}, false))
+	// A new replica in z3 avoids c and the leader a that already has a replica.
+	assert.Equal(t, "b", p.choose(podLocation{Node: "n5", Zone: "z3"}, false))
+
+	p.replicas["a"] = append(p.replicas["a"], "x")
+	p.replicas["b"] = append(p.replicas["b"], "y", "z")
+	p.replicas["c"] = append(p.replicas["c"], "v", "w")
+	assert.Empty(t, p.choose(podLocation{Zone: "z1"}, false))
+	// When every leader has its replicas, a forced placement still avoids the zone of the leader.
+	assert.Equal(t, "b", p.choose(podLocation{Zone: "z1"}, true))
+}
@@ -2,548 +2,544 @@ package k8sutils
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
 	redis "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-// ClusterHasEmptyMasters returns true if the cluster contains at least one master
-// with no slot ranges assigned.
-// This is useful to decide if `redis-cli --cluster rebalance --cluster-use-empty-masters`
-// should be attempted.
-func ClusterHasEmptyMasters(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (bool, error) {
-	seedPod := fmt.Sprintf("%s-leader-0", cr.Name)
-	redisClient := configureRedisClient(ctx, client, cr, seedPod)
-	defer redisClient.Close()
-
-	nodes, err := clusterNodes(ctx, redisClient)
+// CheckRedisCluster fails unless the cluster is safe for a rebalance: every node is reachable and agrees
+// about the slots configuration, no slot is open and all the slots are served.
+func CheckRedisCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) error {
+	admin, err := newClusterAdmin(ctx, client, cr)
 	if err != nil {
-		return false, err
-	}
-
-	return clusterHasEmptyMasters(nodes), nil
-}
-
-func clusterHasEmptyMasters(nodes []clusterNodesResponse) bool {
-	for _, fields := range nodes {
-		// CLUSTER NODES format:
-		// 0:id 1:addr 2:flags 3:master 4:ping 5:pong 6:epoch 7:link [8+:slots...]
-		if len(fields) < 8 {
-			// malformed line; ignore rather than failing hard
-			continue
-		}
-
-		flags := fields[2]
-		linkState := fields[7]
-
-		if !hasFlag(flags, "master") {
-			continue
-		}
-
-		// Ignore masters that are clearly not healthy participants
-		if hasAnyFlag(flags, "fail", "handshake", "noaddr") || linkState != "connected" {
-			continue
-		}
-
-		// Slots are everything after index 7.
-		// If none exist, this master is "empty".
-		if len(fields) == 8 {
-			return true
-		}
-
-		hasSlotToken := false
-		for _, tok := range fields[8:] {
-			if looksLikeSlotToken(tok) {
-				hasSlotToken = true
-				break
-			}
-		}
-		if !hasSlotToken {
-			return true
-		}
-	}
-
-	return false
-}
-
-func hasAnyFlag(flags string, wanted ...string) bool {
-	for _, w := range wanted {
-		if hasFlag(flags, w) {
-			return true
-		}
+		return err
 	}
-	return false
-}
-
-func hasFlag(flags string, flag string) bool {
-	// flags are comma-separated: "master", "myself,master", "slave", ...
-	for _, f := range strings.Split(flags, ",") {
-		if f == flag {
-			return true
-		}
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
+	if err != nil {
+		return err
 	}
-	return false
+	return admin.Check(ctx, seed)
 }
 
-func looksLikeSlotToken(tok string) bool {
-	// Very permissive on purpose.
-	// We only need to distinguish "some slot-related token exists" from "none".
-	if tok == "" {
-		return false
-	}
-	// migration markers are also slot-related
-	if strings.Contains(tok, "->-") || strings.Contains(tok, "-<-") {
-		return true
-	}
-	// common cases: "0-5460" or "5461"
-	if tok[0] >= '0' && tok[0] <= '9' {
-		return true
-	}
-	// bracketed forms: "[0-5460]" or "[5461->-...]"
-	if strings.HasPrefix(tok, "[") && len(tok) > 1 && tok[1] >= '0' && tok[1] <= '9' {
-		return true
+// ClusterHasEmptyMasters returns whether a healthy master of the cluster serves no slot, e.g. a leader
+// just added by a scale up.
+func ClusterHasEmptyMasters(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (bool, error) {
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return false, err
 	}
-	return false
-}
-
-// ClusterStableNoOpenSlots returns true if the cluster appears safe for
-// disruptive operations like rebalance/reshard:
-//   - cluster_state == ok
-//   - no migrating/importing slot markers in CLUSTER NODES ("->-" / "-<-")
-//   - no nodes in handshake/fail/noaddr
-//   - (optional) cluster_slot_migration_active_tasks == 0 if present in CLUSTER INFO
-func ClusterStableNoOpenSlots(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (bool, error) {
-	seedPod := fmt.Sprintf("%s-leader-0", cr.Name)
-	redisClient := configureRedisClient(ctx, client, cr, seedPod)
-	defer redisClient.Close()
-
-	info, err := redisClient.ClusterInfo(ctx).Result()
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
 	if err != nil {
 		return false, err
 	}
-
-	nodes, err := clusterNodes(ctx, redisClient)
+	nodes, err := admin.Nodes(ctx, seed)
 	if err != nil {
 		return false, err
 	}
-
-	return clusterStableNoOpenSlots(info, nodes), nil
+	for _, node := range nodes {
+		if node.IsMaster() && node.Healthy() && node.SlotCount() == 0 {
+			log.FromContext(ctx).V(1).Info("Found Empty Redis Leader Node", "node", node.ID, "addr", node.Addr)
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
-func clusterStableNoOpenSlots(clusterInfo string, nodes []clusterNodesResponse) bool {
-	// 1) cluster_state gate
-	kv := parseClusterInfo(clusterInfo)
-	if kv["cluster_state"] != "ok" {
-		return false
+// clusterNodeOfPod returns the node among nodes running in the pod podName, nil when it runs none of them.
+func clusterNodeOfPod(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, nodes []redisservice.ClusterNode, podName string) (*redisservice.ClusterNode, error) {
+	pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
 	}
-
-	// 2) migration-task gate (Redis 7 exposes these fields in your output)
-	if v, ok := kv["cluster_slot_migration_active_tasks"]; ok {
-		n, convErr := strconv.Atoi(strings.TrimSpace(v))
-		if convErr == nil && n > 0 {
-			return false
+	pods := []corev1.Pod{*pod}
+	for i := range nodes {
+		if podOfNode(nodes[i], pods) != nil {
+			return &nodes[i], nil
 		}
 	}
+	return nil, nil
+}
 
-	// 3) open slot + node health gate from CLUSTER NODES
-	for _, fields := range nodes {
-		if len(fields) < 8 {
-			// malformed line -> treat as not stable (conservative)
-			return false
-		}
-
-		flags := fields[2]
-		linkState := fields[7]
-
-		// If nodes are not fully established, don't rebalance.
-		if hasAnyFlag(flags, "handshake", "fail", "noaddr") || linkState != "connected" {
-			return false
-		}
-	}
+// defaultClusterOperationTimeout bounds a single cluster administration operation, such as the
+// creation of the cluster or a reshard, so a cluster that cannot converge requeues instead of
+// pinning the reconcile worker forever. Override with EXEC_COMMAND_TIMEOUT.
+const defaultClusterOperationTimeout = 5 * time.Minute
 
-	return !clusterHasOpenSlots(nodes)
+func clusterOperationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, envs.GetExecCommandTimeout(defaultClusterOperationTimeout))
 }
 
-func parseClusterInfo(info string) map[string]string {
-	out := make(map[string]string)
-	for _, line := range strings.Split(info, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) != 2 {
-			continue
+// newClusterAdmin returns the admin managing the topology of cr, connecting to its nodes with
+// their password and TLS configuration.
+func newClusterAdmin(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (redisservice.ClusterAdmin, error) {
+	var password string
+	if secret := cr.Spec.KubernetesConfig.ExistingPasswordSecret; secret != nil {
+		pass, err := getRedisPassword(ctx, client, cr.Namespace, *secret.Name, *secret.Key)
+		if err != nil {
+			return nil, fmt.Errorf("error getting Redis password: %w", err)
 		}
-		out[parts[0]] = strings.TrimSpace(parts[1])
+		password = pass
 	}
-	return out
-}
-
-func clusterHasOpenSlots(nodes []clusterNodesResponse) bool {
-	for _, fields := range nodes {
-		// Open slot markers appear in the slot tokens:
-		// "[5491->-...]" migrating / "[5491-<-...]" importing
-		for _, tok := range fields[8:] {
-			if strings.Contains(tok, "->-") || strings.Contains(tok, "-<-") {
-				return true
-			}
+	var tlsConfig *tls.Config
+	if cr.Spec.TLS != nil {
+		tlsConfig = getRedisTLSConfig(ctx, client, cr.Namespace, cr.Spec.TLS)
+		if tlsConfig == nil && cr.Spec.TLS.Secret.SecretName != "" {
+			return nil, fmt.Errorf("failed to load the TLS configuration from secret %s", cr.Spec.TLS.Secret.SecretName)
 		}
 	}
-	return false
+	return redisservice.NewClusterAdmin(password, tlsConfig, clusterPodNames(ctx, client, cr)), nil
 }
 
-func waitForClusterNoOpenSlots(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, timeout time.Duration) error {
-	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
-	defer redisClient.Close()
-
-	deadline := time.Now().Add(timeout)
-	for time.Now().Before(deadline) {
-		info, err := redisClient.ClusterInfo(ctx).Result()
-		if err != nil || !strings.Contains(info, "cluster_state:ok") {
-			time.Sleep(2 * time.Second)
-			continue
-		}
-
-		nodes, err := clusterNodes(ctx, redisClient)
-		if err != nil {
-			time.Sleep(2 * time.Second)
-			continue
+// clusterPodNames returns the name of the pod of the node of cr at an address, an IP or the hostname of
+// the pod. The pods are only fetched the first time it is called.
+func clusterPodNames(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) func(addr string) string {
+	var once sync.Once
+	var pods []corev1.Pod
+	return func(addr string) string {
+		once.Do(func() { pods = clusterPods(ctx, client, cr) })
+		node := redisservice.ClusterNode{Addr: addr}
+		if host, _, err := net.SplitHostPort(addr); err == nil && net.ParseIP(host) == nil {
+			node.Hostname = host
 		}
-
-		if clusterHasOpenSlots(nodes) {
-			time.Sleep(2 * time.Second)
-			continue
+		if pod := podOfNode(node, pods); pod != nil {
+			return pod.Name
 		}
-
-		return nil
+		return ""
 	}
-	return fmt.Errorf("cluster still has open slots or is not converged after %s", timeout)
 }
 
 // ReshardRedisCluster transfer the slots from the last node to the provided transfer node.
 //
 // NOTE: when all slot been transferred, the node become slave of the transfer node.
-func ReshardRedisCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, shardIdx int32, transferNodeIdx int32, remove bool) {
+func ReshardRedisCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, shardIdx int32, transferNodeIdx int32, remove bool) error {
 	transferNodeName := fmt.Sprintf("%s-leader-%d", cr.Name, transferNodeIdx)
-	redisClient := configureRedisClient(ctx, client, cr, transferNodeName)
-	defer redisClient.Close()
-
-	var cmd []string
-
-	// Transfer Pod details
-	transferPOD := RedisDetails{
-		PodName:   transferNodeName,
-		Namespace: cr.Namespace,
-	}
-	// Remove POD details
 	removePOD := RedisDetails{
 		PodName:   cr.Name + "-leader-" + strconv.Itoa(int(shardIdx)),
 		Namespace: cr.Namespace,
 	}
-	cmd = []string{"redis-cli", "--cluster", "reshard"}
-	cmd = append(cmd, getEndpoint(ctx, client, cr, transferPOD))
-	authArgs, err := getRedisClusterAuthArgs(ctx, client, cr, transferNodeName)
+	admin, err := newClusterAdmin(ctx, client, cr)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to get password authentication arguments")
+		return err
 	}
-	cmd = append(cmd, authArgs...)
-
-	cmd = append(cmd, getRedisTLSArgs(cr.Spec.TLS, transferNodeName)...)
-
-	//--cluster-from <node-id> --cluster-to <node-id> --cluster-slots <number of slots> --cluster-yes
-
-	// Remove Node
-	removeNodeID := getRedisNodeID(ctx, client, cr, removePOD)
-	cmd = append(cmd, "--cluster-from")
-	cmd = append(cmd, removeNodeID)
-
-	// Transfer Node
-	transferNodeID := getRedisNodeID(ctx, client, cr, transferPOD)
-	cmd = append(cmd, "--cluster-to")
-	cmd = append(cmd, transferNodeID)
-
-	// Cluster Slots
-	slots := getRedisClusterSlots(ctx, redisClient, removeNodeID)
-	if slots == "0" || slots == "" {
-		log.FromContext(ctx).Info("skipping the execution cmd because no slots found", "Cmd", cmd)
-		return
+	seed, err := podEndpoint(ctx, client, cr, transferNodeName)
+	if err != nil {
+		return err
+	}
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return err
+	}
+	removeNode, err := clusterNodeOfPod(ctx, client, cr, nodes, removePOD.PodName)
+	if err != nil {
+		return err
+	}
+	transferNode, err := clusterNodeOfPod(ctx, client, cr, nodes, transferNodeName)
+	if err != nil {
+		return err
+	}
+	if removeNode == nil || transferNode == nil {
+		return fmt.Errorf("%s or %s is not a node of the cluster", removePOD.PodName, transferNodeName)
 	}
-	cmd = append(cmd, "--cluster-slots")
-	cmd = append(cmd, slots)
-
-	cmd = append(cmd, "--cluster-yes")
 
-	log.FromContext(ctx).Info(fmt.Sprintf("transferring %s slots from shard %d to shard %d", slots, shardIdx, transferNodeIdx))
-	executeCommand(ctx, client, cr, cmd, transferNodeName)
-	log.FromContext(ctx).Info(fmt.Sprintf("transferring %s slots from shard %d to shard %d completed", slots, shardIdx, transferNodeIdx))
+	if slots := removeNode.SlotCount(); slots == 0 {
+		log.FromContext(ctx).Info("skipping the reshard because no slots found", "Pod", removePOD.PodName)
+	} else {
+		log.FromContext(ctx).Info(fmt.Sprintf("transferring %d slots from shard %d to shard %d", slots, shardIdx, transferNodeIdx))
+		opCtx, cancel := clusterOperationContext(ctx)
+		defer cancel()
+		if err := admin.Reshard(opCtx, seed, removeNode.ID, transferNode.ID, slots); err != nil {
+			err = fmt.Errorf("failed to transfer the slots of shard %d to shard %d: %w", shardIdx, transferNodeIdx, err)
+			events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisSlotsMoveFailed, removePOD.PodName, err.Error())
+			return err
+		}
+		events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisSlotsMoved, removePOD.PodName,
+			fmt.Sprintf("Moved %d slots of %s to %s", slots, removePOD.PodName, transferNodeName))
+		log.FromContext(ctx).Info(fmt.Sprintf("transferring %d slots from shard %d to shard %d completed", slots, shardIdx, transferNodeIdx))
+	}
 
 	if remove {
-		RemoveRedisNodeFromCluster(ctx, client, cr, removePOD)
+		return RemoveRedisNodeFromCluster(ctx, client, cr, removePOD)
 	}
+	return nil
 }
 
-func getRedisClusterSlots(ctx context.Context, redisClient *redis.Client, nodeID string) string {
-	totalSlots := 0
-
-	redisSlots, err := redisClient.ClusterSlots(ctx).Result()
+// MoveRedisClusterSlots moves up to limit slots of the leader of shard shardIdx to the leader of shard
+// targetIdx, along with their keys. It returns migrated with the moved slots added, and the number of
+// slots the shard still serves. The slots are moved with the idempotent Assign, so a batch that was
+// interrupted is completed by the next call once the cluster is fixed.
+func MoveRedisClusterSlots(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, shardIdx, targetIdx int32, limit int, migrated string) (string, int, error) {
+	admin, err := newClusterAdmin(ctx, client, cr)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "failed to get cluster slots")
-		return ""
+		return migrated, 0, err
 	}
-	for _, slot := range redisSlots {
-		for _, node := range slot.Nodes {
-			if node.ID == nodeID {
-				// Each slot range is a continuous block managed by the node
-				totalSlots += slot.End - slot.Start + 1
-				break
-			}
-		}
+	targetName := fmt.Sprintf("%s-leader-%d", cr.Name, targetIdx)
+	seed, err := podEndpoint(ctx, client, cr, targetName)
+	if err != nil {
+		return migrated, 0, err
 	}
-
-	return strconv.Itoa(totalSlots)
-}
-
-// getRedisNodeID would return nodeID of a redis node by passing pod
-func getRedisNodeID(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, pod RedisDetails) string {
-	redisClient := configureRedisClient(ctx, client, cr, pod.PodName)
-	defer redisClient.Close()
-
-	pong, err := redisClient.Ping(ctx).Result()
-	if err != nil || pong != "PONG" {
-		log.FromContext(ctx).Error(err, "Failed to ping Redis server")
-		return ""
+	shardPod := RedisDetails{PodName: fmt.Sprintf("%s-leader-%d", cr.Name, shardIdx), Namespace: cr.Namespace}
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return migrated, 0, err
 	}
-
-	cmd := redis.NewStringCmd(ctx, "cluster", "myid")
-	err = redisClient.Process(ctx, cmd)
+	shard, err := clusterNodeOfPod(ctx, client, cr, nodes, shardPod.PodName)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Redis command failed with this error")
-		return ""
+		return migrated, 0, err
+	}
+	target, err := clusterNodeOfPod(ctx, client, cr, nodes, targetName)
+	if err != nil {
+		return migrated, 0, err
+	}
+	if shard == nil || target == nil {
+		return migrated, 0, fmt.Errorf("%s or %s is not a node of the cluster", shardPod.PodName, targetName)
+	}
+	batch := firstSlots(shard.Slots, limit)
+	if len(batch) == 0 {
+		return migrated, 0, nil
 	}
 
-	output, err := cmd.Result()
+	opCtx, cancel := clusterOperationContext(ctx)
+	defer cancel()
+	if err := admin.Assign(opCtx, seed, map[string][]redisservice.SlotRange{target.ID: batch}); err != nil {
+		err = fmt.Errorf("failed to move slots %s of shard %d to shard %d: %w", redisservice.FormatSlotRanges(batch), shardIdx, targetIdx, err)
+		events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisSlotsMoveFailed, shardPod.PodName, err.Error())
+		return migrated, 0, err
+	}
+	events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisSlotsMoved, shardPod.PodName,
+		fmt.Sprintf("Moved slots %s of %s to %s", redisservice.FormatSlotRanges(batch), shardPod.PodName, targetName))
+	moved, err := redisservice.ParseSlotRanges(migrated)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Redis command failed with this error")
-		return ""
+		return migrated, 0, err
+	}
+	count := 0
+	for _, r := range batch {
+		count += r.Len()
+	}
+	log.FromContext(ctx).Info("moved slots", "Shard.Index", shardIdx, "Target.Index", targetIdx, "Slots", redisservice.FormatSlotRanges(batch))
+	return redisservice.FormatSlotRanges(append(moved, batch...)), shard.SlotCount() - count, nil
+}
+
+// firstSlots returns the ranges of the first limit slots of ranges.
+func firstSlots(ranges []redisservice.SlotRange, limit int) []redisservice.SlotRange {
+	var first []redisservice.SlotRange
+	for _, r := range ranges {
+		if limit <= 0 {
+			break
+		}
+		if r.Len() > limit {
+			r.End = r.Start + limit - 1
+		}
+		first = append(first, r)
+		limit -= r.Len()
 	}
-	log.FromContext(ctx).V(1).Info("Redis node ID ", "is", output)
-	return output
+	return first
 }
 
-// FixRedisCluster runs `redis-cli --cluster fix` to resolve any open/stuck slots
-// (e.g., slots left in migrating/importing state from a previous interrupted rebalance).
+// FixRedisCluster resolves the open slots left in migrating/importing state by an interrupted
+// reshard or rebalance, and assigns the slots no node serves, as `redis-cli --cluster fix` does.
 // This must be called before add-node or rebalance when the cluster may have open slots.
 func FixRedisCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) error {
-	pod := RedisDetails{
-		PodName:   cr.Name + "-leader-0",
-		Namespace: cr.Namespace,
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return err
 	}
-	cmd := []string{"redis-cli", "--cluster", "fix"}
-	cmd = append(cmd, getEndpoint(ctx, client, cr, pod))
-	authArgs, err := getRedisClusterAuthArgs(ctx, client, cr, cr.Name+"-leader-0")
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to get password authentication arguments")
 		return err
 	}
-	cmd = append(cmd, authArgs...)
-	cmd = append(cmd, "--cluster-yes")
-	cmd = append(cmd, getRedisTLSArgs(cr.Spec.TLS, cr.Name+"-leader-0")...)
+	ctx, cancel := clusterOperationContext(ctx)
+	defer cancel()
+	return admin.Fix(ctx, seed)
+}
 
-	_, err = executeCommand1(ctx, client, cr, cmd, cr.Name+"-leader-0")
-	return err
+// RebalanceRedisClusterEmptyMasters rebalances the slots of the cluster including the masters
+// serving none, e.g. the leaders just added by a scale up.
+func RebalanceRedisClusterEmptyMasters(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) error {
+	return rebalanceRedisCluster(ctx, client, cr, redisservice.RebalanceOptions{UseEmptyMasters: true})
 }
 
-// Rebalance the Redis CLuster using the Empty Master Nodes
-func RebalanceRedisClusterEmptyMasters(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) {
-	if err := waitForClusterNoOpenSlots(ctx, client, cr, 2*time.Minute); err != nil {
-		log.FromContext(ctx).Info("Skipping rebalance: cluster not ready", "reason", err.Error())
-		return
+// CheckIfEmptyMasters rebalances the cluster if any of its healthy masters serves no slot.
+func CheckIfEmptyMasters(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) error {
+	empty, err := ClusterHasEmptyMasters(ctx, client, cr)
+	if err != nil || !empty {
+		return err
 	}
+	return RebalanceRedisClusterEmptyMasters(ctx, client, cr)
+}
 
-	// cmd = redis-cli --cluster rebalance <redis>:<port> --cluster-use-empty-masters
-	var cmd []string
-	pod := RedisDetails{
-		PodName:   cr.Name + "-leader-1",
-		Namespace: cr.Namespace,
+// RebalanceRedisCluster rebalances the slots of the cluster between the masters already serving some.
+func RebalanceRedisCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) error {
+	return rebalanceRedisCluster(ctx, client, cr, redisservice.RebalanceOptions{})
+}
+
+// BalanceRedisClusterSlots converges the slots of the cluster to spec.slotRanges or to the weights of
+// spec.redisLeader.slotWeights. Without either, it only gives slots to the masters serving none.
+func BalanceRedisClusterSlots(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) error {
+	if len(cr.Spec.SlotRanges) == 0 && len(cr.Spec.RedisLeader.SlotWeights) == 0 {
+		return CheckIfEmptyMasters(ctx, client, cr)
 	}
-	cmd = []string{"redis-cli", "--cluster", "rebalance"}
-	cmd = append(cmd, getEndpoint(ctx, client, cr, pod))
-	cmd = append(cmd, "--cluster-use-empty-masters")
-	authArgs, err := getRedisClusterAuthArgs(ctx, client, cr, cr.Name+"-leader-0")
+	return RebalanceRedisClusterEmptyMasters(ctx, client, cr)
+}
+
+// rebalanceRedisCluster moves the slots to the leaders spec.slotRanges pins them to when set, and
+// otherwise rebalances them according to spec.redisLeader.slotWeights.
+func rebalanceRedisCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, opts redisservice.RebalanceOptions) error {
+	owners, err := cr.Spec.GetSlotOwners()
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to get password authentication arguments")
+		return err
 	}
-	cmd = append(cmd, authArgs...)
-
-	cmd = append(cmd, getRedisTLSArgs(cr.Spec.TLS, cr.Name+"-leader-0")...)
-
-	executeCommand(ctx, client, cr, cmd, cr.Name+"-leader-1")
+	weights, err := cr.Spec.GetSlotWeights()
+	if err != nil {
+		return err
+	}
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return err
+	}
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
+	if err != nil {
+		return err
+	}
+	var nodeIDs map[int32]string
+	if owners != nil || len(weights) > 0 {
+		nodes, err := admin.Nodes(ctx, seed)
+		if err != nil {
+			return err
+		}
+		if nodeIDs, err = leaderNodeIDs(cr, nodes, clusterPods(ctx, client, cr)); err != nil {
+			return err
+		}
+	}
+	ctx, cancel := clusterOperationContext(ctx)
+	defer cancel()
+	if owners != nil {
+		return admin.Assign(ctx, seed, slotRangesByNode(owners, nodeIDs))
+	}
+	if len(weights) > 0 {
+		opts.Weights = make(map[string]float64, len(weights))
+		for ordinal, weight := range weights {
+			opts.Weights[nodeIDs[ordinal]] = float64(weight)
+		}
+	}
+	return admin.Rebalance(ctx, seed, opts)
 }
 
-func CheckIfEmptyMasters(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) {
-	totalRedisLeaderNodes := CheckRedisNodeCount(ctx, client, cr, "leader")
-	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
-	defer redisClient.Close()
-
-	for i := 0; i < int(totalRedisLeaderNodes); i++ {
-		pod := RedisDetails{
-			PodName:   cr.Name + "-leader-" + strconv.Itoa(i),
-			Namespace: cr.Namespace,
+// leaderNodeIDs returns the node ID of the master of the shard of each leader pod by ordinal, resolving the
+// pod running each of nodes among pods. A leader pod that was failed over to one of its replicas stands
+// for the master it replicates, so the slots pinned to its ordinal follow the shard.
+func leaderNodeIDs(cr *rcvb2.RedisCluster, nodes []redisservice.ClusterNode, pods []corev1.Pod) (map[int32]string, error) {
+	byPod := make(map[string]*redisservice.ClusterNode, len(nodes))
+	for i := range nodes {
+		if pod := podOfNode(nodes[i], pods); pod != nil {
+			byPod[pod.Name] = &nodes[i]
+		}
+	}
+	leaders := cr.Spec.GetReplicaCounts("leader")
+	nodeIDs := make(map[int32]string, leaders)
+	ordinals := make(map[string]int32, leaders)
+	for i := int32(0); i < leaders; i++ {
+		podName := fmt.Sprintf("%s-leader-%d", cr.Name, i)
+		node, ok := byPod[podName]
+		if !ok {
+			return nil, fmt.Errorf("%s is not a node of the cluster", podName)
+		}
+		id := node.ID
+		if !node.IsMaster() && node.MasterID != "" {
+			id = node.MasterID
 		}
-		podNodeID := getRedisNodeID(ctx, client, cr, pod)
-		podSlots := getRedisClusterSlots(ctx, redisClient, podNodeID)
+		if other, ok := ordinals[id]; ok {
+			return nil, fmt.Errorf("%s and %s-leader-%d belong to the same shard", podName, cr.Name, other)
+		}
+		ordinals[id] = i
+		nodeIDs[i] = id
+	}
+	return nodeIDs, nil
+}
 
-		if podSlots == "0" || podSlots == "" {
-			log.FromContext(ctx).V(1).Info("Found Empty Redis Leader Node", "pod", pod)
-			RebalanceRedisClusterEmptyMasters(ctx, client, cr)
-			break
+// slotRangesByNode turns the leader ordinal of each slot into the ranges of slots of each node.
+func slotRangesByNode(owners []int32, nodeIDs map[int32]string) map[string][]redisservice.SlotRange {
+	ranges := make(map[string][]redisservice.SlotRange)
+	for start := 0; start < len(owners); {
+		end := start
+		for end+1 < len(owners) && owners[end+1] == owners[start] {
+			end++
 		}
+		id := nodeIDs[owners[start]]
+		ranges[id] = append(ranges[id], redisservice.SlotRange{Start: start, End: end})
+		start = end + 1
 	}
+	return ranges
 }
 
-// Rebalance Redis Cluster Would Rebalance the Redis Cluster without using the empty masters
-func RebalanceRedisCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) {
-	// cmd = redis-cli --cluster rebalance <redis>:<port>
-	var cmd []string
-	pod := RedisDetails{
-		PodName:   cr.Name + "-leader-1",
-		Namespace: cr.Namespace,
+// GetRedisClusterShards returns the slots served by each master of the cluster, ordered by their
+// first slot, resolving the pod running each master from the hostname it announces or its IP.
+func GetRedisClusterShards(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) ([]rcvb2.ShardStatus, error) {
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return nil, err
 	}
-	cmd = []string{"redis-cli", "--cluster", "rebalance"}
-	cmd = append(cmd, getEndpoint(ctx, client, cr, pod))
-	authArgs, err := getRedisClusterAuthArgs(ctx, client, cr, cr.Name+"-leader-0")
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to get password authentication arguments")
+		return nil, err
 	}
-	cmd = append(cmd, authArgs...)
-
-	cmd = append(cmd, getRedisTLSArgs(cr.Spec.TLS, cr.Name+"-leader-0")...)
-
-	executeCommand(ctx, client, cr, cmd, cr.Name+"-leader-1")
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return nil, err
+	}
+	return clusterShards(nodes, clusterPods(ctx, client, cr)), nil
 }
 
-func waitForNodePresence(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, nodeIP string, timeout time.Duration) error {
-	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
-	defer redisClient.Close()
-
-	deadline := time.Now().Add(timeout)
-	for time.Now().Before(deadline) {
-		nodes, err := clusterNodes(ctx, redisClient)
-		if err == nil && checkRedisNodePresence(ctx, nodes, nodeIP) {
-			return nil
+func clusterShards(nodes []redisservice.ClusterNode, pods []corev1.Pod) []rcvb2.ShardStatus {
+	var masters []redisservice.ClusterNode
+	for _, node := range nodes {
+		if node.IsMaster() && !node.HasFlag("fail") && !node.HasFlag("noaddr") {
+			masters = append(masters, node)
 		}
-		time.Sleep(2 * time.Second)
 	}
-	return fmt.Errorf("node %s did not appear in cluster nodes after %s", nodeIP, timeout)
+	firstSlot := func(n redisservice.ClusterNode) int {
+		if len(n.Slots) == 0 {
+			return redisservice.TotalClusterSlots
+		}
+		return n.Slots[0].Start
+	}
+	sort.SliceStable(masters, func(i, j int) bool { return firstSlot(masters[i]) < firstSlot(masters[j]) })
+
+	shards := make([]rcvb2.ShardStatus, 0, len(masters))
+	for _, node := range masters {
+		var pod string
+		if p := podOfNode(node, pods); p != nil {
+			pod = p.Name
+		}
+		shards = append(shards, rcvb2.ShardStatus{
+			Pod:        pod,
+			NodeID:     node.ID,
+			Slots:      int32(node.SlotCount()),
+			SlotRanges: redisservice.FormatSlotRanges(node.Slots),
+		})
+	}
+	return shards
 }
 
-// Add redis cluster node would add a node to the existing redis cluster using redis-cli
-func AddRedisNodeToCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) {
-	cmd := []string{"redis-cli", "--cluster", "add-node"}
+// AddRedisNodeToCluster adds the first leader missing from the cluster as an empty master.
+func AddRedisNodeToCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) error {
 	activeRedisNode := CheckRedisNodeCount(ctx, client, cr, "leader")
-	newPod := RedisDetails{
-		PodName:   cr.Name + "-leader-" + strconv.Itoa(int(activeRedisNode)),
-		Namespace: cr.Namespace,
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return err
 	}
-	existingPod := RedisDetails{
-		PodName:   cr.Name + "-leader-0",
-		Namespace: cr.Namespace,
+	podName := cr.Name + "-leader-" + strconv.Itoa(int(activeRedisNode))
+	endpoint, err := podEndpoint(ctx, client, cr, podName)
+	if err != nil {
+		return err
 	}
-	cmd = append(cmd, getEndpoint(ctx, client, cr, newPod))
-	cmd = append(cmd, getEndpoint(ctx, client, cr, existingPod))
-	authArgs, err := getRedisClusterAuthArgs(ctx, client, cr, cr.Name+"-leader-0")
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to get password authentication arguments")
+		return err
 	}
-	cmd = append(cmd, authArgs...)
-
-	cmd = append(cmd, getRedisTLSArgs(cr.Spec.TLS, cr.Name+"-leader-0")...)
-
-	executeCommand(ctx, client, cr, cmd, cr.Name+"-leader-0")
-
-	if err := waitForNodePresence(ctx, client, cr, getRedisServerIP(ctx, client, newPod), 90*time.Second); err != nil {
-		log.FromContext(ctx).Error(err, "node added but not converged yet: %w")
+	opCtx, cancel := clusterOperationContext(ctx)
+	defer cancel()
+	if err := admin.AddNode(opCtx, seed, endpoint, ""); err != nil {
+		events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisNodeAddFailed, podName,
+			fmt.Sprintf("Failed to add %s to the cluster: %v", podName, err))
+		return err
 	}
+	events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisNodeAdded, podName,
+		fmt.Sprintf("Added %s to the cluster as an empty master", podName))
+	return nil
 }
 
-// getAttachedFollowerNodeIDs would return a slice of redis followers attached to a redis leader
-func getAttachedFollowerNodeIDs(ctx context.Context, redisClient *redis.Client, masterNodeID string) []string {
-	// 3acb029fead40752f432c84f9bed2e639119a573 192.168.84.239:6379@16379,redis-cluster-v1beta2-follower-5 slave e3299968586dd457a8dba04fc6c747cecd38510f 0 1713595736542 6 connected
-	slaveNodes, err := redisClient.ClusterSlaves(ctx, masterNodeID).Result()
+// RemoveRedisFollowerNodesFromCluster removes the followers attached to the leader of shard shardIdx from the cluster.
+func RemoveRedisFollowerNodesFromCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, shardIdx int32) error {
+	lastLeaderPod := cr.Name + "-leader-" + strconv.Itoa(int(shardIdx))
+	admin, err := newClusterAdmin(ctx, client, cr)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to get attached follower node IDs", "masterNodeID", masterNodeID)
-		return nil
-	}
-	slaveIDs := make([]string, 0, len(slaveNodes))
-	for _, slave := range slaveNodes {
-		stringSlice := strings.Split(slave, " ")
-		slaveIDs = append(slaveIDs, stringSlice[0])
+		return err
 	}
-	log.FromContext(ctx).V(1).Info("Slaves Nodes attached to", "node", masterNodeID, "are", slaveIDs)
-	return slaveIDs
-}
-
-// Remove redis follower node would remove all follower nodes of last leader node using redis-cli
-func RemoveRedisFollowerNodesFromCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, shardIdx int32) {
-	var cmd []string
-	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
-	defer redisClient.Close()
-
-	existingPod := RedisDetails{
-		PodName:   cr.Name + "-leader-0",
-		Namespace: cr.Namespace,
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
+	if err != nil {
+		return err
 	}
-	lastLeaderPod := RedisDetails{
-		PodName:   cr.Name + "-leader-" + strconv.Itoa(int(shardIdx)),
-		Namespace: cr.Namespace,
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return err
 	}
-
-	cmd = []string{"redis-cli"}
-
-	authArgs, err := getRedisClusterAuthArgs(ctx, client, cr, cr.Name+"-leader-0")
+	leader, err := clusterNodeOfPod(ctx, client, cr, nodes, lastLeaderPod)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to get password authentication arguments")
+		return err
+	}
+	if leader == nil {
+		return fmt.Errorf("%s is not a node of the cluster", lastLeaderPod)
 	}
-	cmd = append(cmd, authArgs...)
-	cmd = append(cmd, getRedisTLSArgs(cr.Spec.TLS, cr.Name+"-leader-0")...)
-
-	lastLeaderPodNodeID := getRedisNodeID(ctx, client, cr, lastLeaderPod)
-	followerNodeIDs := getAttachedFollowerNodeIDs(ctx, redisClient, lastLeaderPodNodeID)
 
-	cmd = append(cmd, "--cluster", "del-node")
-	cmd = append(cmd, getEndpoint(ctx, client, cr, existingPod))
-	for _, followerNodeID := range followerNodeIDs {
-		cmd = append(cmd, followerNodeID)
-		executeCommand(ctx, client, cr, cmd, cr.Name+"-leader-0")
-		cmd = cmd[:len(cmd)-1]
+	opCtx, cancel := clusterOperationContext(ctx)
+	defer cancel()
+	var errs []error
+	for _, follower := range nodes {
+		if follower.MasterID != leader.ID {
+			continue
+		}
+		if err := admin.DelNode(opCtx, seed, follower.ID); err != nil {
+			err = fmt.Errorf("failed to remove follower %s of %s: %w", follower.ID, lastLeaderPod, err)
+			events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisNodeRemoveFailed, lastLeaderPod, err.Error())
+			errs = append(errs, err)
+			continue
+		}
+		events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisNodeRemoved, lastLeaderPod,
+			fmt.Sprintf("Removed follower %s of %s from the cluster", follower.ID, lastLeaderPod))
 	}
+	return errors.Join(errs...)
 }
 
-// Remove redis cluster node would remove last node to the existing redis cluster using redis-cli
-func RemoveRedisNodeFromCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, removePod RedisDetails) {
-	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
-	defer redisClient.Close()
-	existingPod := RedisDetails{
-		PodName:   cr.Name + "-leader-0",
-		Namespace: cr.Namespace,
+// RemoveRedisNodeFromCluster removes the node running in removePod from the cluster. The node must
+// not serve any slot anymore.
+func RemoveRedisNodeFromCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, removePod RedisDetails) error {
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return err
+	}
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
+	if err != nil {
+		return err
+	}
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return err
 	}
-	cmd := []string{"redis-cli", "--cluster", "del-node"}
-	cmd = append(cmd, getEndpoint(ctx, client, cr, existingPod))
-	cmd = append(cmd, getRedisNodeID(ctx, client, cr, removePod))
-	authArgs, err := getRedisClusterAuthArgs(ctx, client, cr, cr.Name+"-leader-0")
+	node, err := clusterNodeOfPod(ctx, client, cr, nodes, removePod.PodName)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to get password authentication arguments")
+		return err
+	}
+	if node == nil {
+		// A removal retried after its checkpoint failed to be recorded.
+		log.FromContext(ctx).Info("Node already removed from the cluster", "Pod", removePod.PodName)
+		return nil
 	}
-	cmd = append(cmd, authArgs...)
-	cmd = append(cmd, getRedisTLSArgs(cr.Spec.TLS, cr.Name+"-leader-0")...)
-	executeCommand(ctx, client, cr, cmd, cr.Name+"-leader-0")
+	opCtx, cancel := clusterOperationContext(ctx)
+	defer cancel()
+	if err := admin.DelNode(opCtx, seed, node.ID); err != nil {
+		events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisNodeRemoveFailed, removePod.PodName,
+			fmt.Sprintf("Failed to remove %s from the cluster: %v", removePod.PodName, err))
+		return err
+	}
+	events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisNodeRemoved, removePod.PodName,
+		fmt.Sprintf("Removed %s from the cluster", removePod.PodName))
+	return nil
 }
 
 // verifyLeaderPod return true if the pod is leader/master
@@ -573,35 +569,24 @@ func verifyLeaderPodInfo(ctx context.Context, redisClient *redis.Client, podName
 	return false
 }
 
+// ClusterFailover makes the replica running in the leader pod of shard shardIdx take over from its master.
 func ClusterFailover(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, shardIdx int32) error {
 	slavePodName := cr.Name + "-leader-" + strconv.Itoa(int(shardIdx))
-	// cmd = redis-cli cluster failover
-	var cmd []string
-	pod := RedisDetails{
-		PodName:   slavePodName,
-		Namespace: cr.Namespace,
-	}
-	endpoint := getEndpoint(ctx, client, cr, pod)
-	lastColon := strings.LastIndex(endpoint, ":")
-	if lastColon < 0 {
-		return fmt.Errorf("invalid endpoint format: %s", endpoint)
-	}
-	host, port := endpoint[:lastColon], endpoint[lastColon+1:]
-	cmd = []string{"redis-cli", "-h", host, "-p", port}
-	authArgs, err := getRedisClusterAuthArgs(ctx, client, cr, slavePodName)
+	admin, err := newClusterAdmin(ctx, client, cr)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to get password authentication arguments")
+		return err
 	}
-	cmd = append(cmd, authArgs...)
-
-	cmd = append(cmd, getRedisTLSArgs(cr.Spec.TLS, slavePodName)...)
-	cmd = append(cmd, "cluster", "failover")
-
-	log.FromContext(ctx).V(1).Info("Redis cluster failover command is", "Command", cmd)
-	execOut, err := executeCommand1(ctx, client, cr, cmd, slavePodName)
+	endpoint, err := podEndpoint(ctx, client, cr, slavePodName)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Could not execute command", "Command", cmd, "Output", execOut)
 		return err
 	}
+	log.FromContext(ctx).V(1).Info("Redis cluster failover", "Pod", slavePodName)
+	if err := admin.Failover(ctx, endpoint, redisservice.FailoverDefault); err != nil {
+		events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisFailoverFailed, slavePodName,
+			fmt.Sprintf("Failed to fail over to %s: %v", slavePodName, err))
+		return err
+	}
+	events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisFailover, slavePodName,
+		fmt.Sprintf("%s took over from its master", slavePodName))
 	return nil
 }
@@ -2,501 +2,21 @@ package k8sutils
 
 import (
 	"context"
-	"fmt"
 	"testing"
 
+	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	mock_utils "github.com/OT-CONTAINER-KIT/redis-operator/mocks/utils"
 	"github.com/go-redis/redismock/v9"
 	redis "github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sClientFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
 )
 
-func Test_clusterHasEmptyMasters_WhenConnectedMasterHasNoSlots_ReturnsTrue(t *testing.T) {
-	nodes := []clusterNodesResponse{
-		// master with slots assigned
-		{
-			"id-master-with-slots",
-			"10.0.0.10:6379@16379",
-			"myself,master",
-			"-",
-			"0",
-			"0",
-			"1",
-			"connected",
-			"0-5460",
-		},
-		// empty master (no slots)
-		{
-			"id-empty-master",
-			"10.0.0.11:6379@16379",
-			"master",
-			"-",
-			"0",
-			"0",
-			"2",
-			"connected",
-			// NOTE: no slot tokens => len(fields) == 8
-		},
-		// a replica (should be ignored)
-		{
-			"id-replica",
-			"10.0.0.12:6379@16379",
-			"slave",
-			"id-master-with-slots",
-			"0",
-			"0",
-			"3",
-			"connected",
-		},
-	}
-
-	// Act:
-	got := clusterHasEmptyMasters(nodes)
-
-	// Assert:
-	if !got {
-		t.Fatalf("expected true, got false")
-	}
-}
-
-func Test_clusterHasEmptyMasters_skipMalformedNode(t *testing.T) {
-	nodes := []clusterNodesResponse{
-		// master with slot token
-		{
-			"id-empty-master",
-			"10.0.0.11:6379@16379",
-			"master",
-			"-",
-			"0",
-			"0",
-			"2",
-			// NOTE: missing linkState => len(fields) < 8
-		},
-	}
-
-	// Act:
-	got := clusterHasEmptyMasters(nodes)
-
-	// Assert:
-	if got {
-		t.Fatalf("expected false, got true")
-	}
-}
-
-func Test_clusterHasEmptyMasters_skipFlags_ReturnsFalse(t *testing.T) {
-	tests := []struct {
-		name      string
-		flags     string
-		linkState string
-	}{
-		{
-			name:      "with non master flag",
-			flags:     "bla",
-			linkState: "connected",
-		},
-		{
-			name:      "with master,fail flag",
-			flags:     "master,fail",
-			linkState: "connected",
-		},
-		{
-			name:      "with master,handshake flag",
-			flags:     "master,handshake",
-			linkState: "connected",
-		},
-		{
-			name:      "with master,noaddr flag",
-			flags:     "master,noaddr",
-			linkState: "connected",
-		},
-		{
-			name:      "with non connected linkState",
-			flags:     "master",
-			linkState: "disconnected",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			nodes := []clusterNodesResponse{
-				// master with slot token
-				{
-					"id-empty-master",
-					"10.0.0.11:6379@16379",
-					tt.flags,
-					"-",
-					"0",
-					"0",
-					"2",
-					tt.linkState,
-				},
-			}
-
-			// Act:
-			got := clusterHasEmptyMasters(nodes)
-
-			// Assert:
-			if got {
-				t.Fatalf("expected false, got true")
-			}
-		})
-	}
-}
-
-func Test_clusterHasEmptyMasters_ReturnsTrue(t *testing.T) {
-	tests := []struct {
-		name              string
-		secondMasterSlots string
-	}{
-		{
-			name:              "with empty string",
-			secondMasterSlots: "",
-		},
-		{
-			name:              "with non slot value",
-			secondMasterSlots: "abc",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			nodes := []clusterNodesResponse{
-				// master with slots assigned
-				{
-					"id-master-with-slots",
-					"10.0.0.10:6379@16379",
-					"myself,master",
-					"-",
-					"0",
-					"0",
-					"1",
-					"connected",
-					"0-5460",
-				},
-				// second master with slot token
-				{
-					"id-empty-master",
-					"10.0.0.11:6379@16379",
-					"master",
-					"-",
-					"0",
-					"0",
-					"2",
-					"connected",
-					tt.secondMasterSlots,
-				},
-				// a replica (should be ignored)
-				{
-					"id-replica",
-					"10.0.0.12:6379@16379",
-					"slave",
-					"id-master-with-slots",
-					"0",
-					"0",
-					"3",
-					"connected",
-				},
-			}
-
-			// Act:
-			got := clusterHasEmptyMasters(nodes)
-
-			// Assert:
-			if !got {
-				t.Fatalf("expected true, got false")
-			}
-		})
-	}
-}
-
-func Test_clusterHasEmptyMasters_ReturnsFalse(t *testing.T) {
-	tests := []struct {
-		name              string
-		expectedBool      bool
-		secondMasterSlots string
-	}{
-		{
-			name:              "with slot (without brackets)",
-			secondMasterSlots: "5461",
-		}, {
-			name:              "with slot (with brackets)",
-			secondMasterSlots: "[5461]",
-		}, {
-			name:              "with migration markers",
-			secondMasterSlots: "[5461->-nodeid]",
-		}, {
-			name:              "with migration markers",
-			secondMasterSlots: "[5461->-nodeid]",
-		}, {
-			name:              "with import markers",
-			secondMasterSlots: "[5461-<-nodeid]",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			nodes := []clusterNodesResponse{
-				// master with slots assigned
-				{
-					"id-master-with-slots",
-					"10.0.0.10:6379@16379",
-					"myself,master",
-					"-",
-					"0",
-					"0",
-					"1",
-					"connected",
-					"0-5460",
-				},
-				// second master with slot token
-				{
-					"id-empty-master",
-					"10.0.0.11:6379@16379",
-					"master",
-					"-",
-					"0",
-					"0",
-					"2",
-					"connected",
-					tt.secondMasterSlots,
-				},
-				// a replica (should be ignored)
-				{
-					"id-replica",
-					"10.0.0.12:6379@16379",
-					"slave",
-					"id-master-with-slots",
-					"0",
-					"0",
-					"3",
-					"connected",
-				},
-			}
-
-			// Act:
-			got := clusterHasEmptyMasters(nodes)
-
-			// Assert:
-			if got {
-				t.Fatalf("expected false, got true")
-			}
-		})
-	}
-}
-
-func Test_clusterStableNoOpenSlots_ReturnsTrue(t *testing.T) {
-	info := "cluster_state:ok\ncluster_slot_migration_active_tasks:0\n"
-	nodes := []clusterNodesResponse{
-		{
-			"id-1",
-			"10.0.0.10:6379@16379",
-			"myself,master",
-			"-",
-			"0",
-			"0",
-			"1",
-			"connected",
-			"0-5460",
-		},
-		{
-			"id-2",
-			"10.0.0.11:6379@16379",
-			"master",
-			"-",
-			"0",
-			"0",
-			"2",
-			"connected",
-			"5461-10922",
-		},
-		{
-			"id-3",
-			"10.0.0.12:6379@16379",
-			"slave",
-			"id-1",
-			"0",
-			"0",
-			"3",
-			"connected",
-		},
-	}
-
-	if got := clusterStableNoOpenSlots(info, nodes); !got {
-		t.Fatalf("expected true, got false")
-	}
-}
-
-// For redis 6 compatibility
-func Test_clusterStableNoOpenSlots_missingMigrationActiveTask_ReturnsTrue(t *testing.T) {
-	info := "cluster_state:ok\n"
-	nodes := []clusterNodesResponse{
-		{
-			"id-1",
-			"10.0.0.10:6379@16379",
-			"myself,master",
-			"-",
-			"0",
-			"0",
-			"1",
-			"connected",
-			"0-5460",
-		},
-		{
-			"id-2",
-			"10.0.0.11:6379@16379",
-			"master",
-			"-",
-			"0",
-			"0",
-			"2",
-			"connected",
-			"5461-10922",
-		},
-		{
-			"id-3",
-			"10.0.0.12:6379@16379",
-			"slave",
-			"id-1",
-			"0",
-			"0",
-			"3",
-			"connected",
-		},
-	}
-
-	if got := clusterStableNoOpenSlots(info, nodes); !got {
-		t.Fatalf("expected true, got false")
-	}
-}
-
-func Test_clusterStableNoOpenSlots_malformedNode_ReturnsFalse(t *testing.T) {
-	info := "cluster_state:ok\ncluster_slot_migration_active_tasks:0\n"
-	nodes := []clusterNodesResponse{
-		{
-			"id-1",
-			"10.0.0.10:6379@16379",
-			"myself,master",
-			"-",
-			"0",
-			"0",
-			"1",
-		},
-	}
-
-	if got := clusterStableNoOpenSlots(info, nodes); got {
-		t.Fatalf("expected false, got true")
-	}
-}
-
-func Test_clusterStableNoOpenSlots_ReturnsFalse(t *testing.T) {
-	tests := []struct {
-		name                            string
-		clusterState                    string
-		clusterSlotMigrationActiveTasks string
-		nodeFields                      string
-		linkState                       string
-		slots                           string
-	}{
-		{
-			name:                            "cluster state no ok",
-			clusterState:                    "bad",
-			clusterSlotMigrationActiveTasks: "0",
-			nodeFields:                      "master",
-			linkState:                       "connected",
-			slots:                           "5461",
-		},
-		{
-			name:                            "active migration tasks",
-			clusterState:                    "ok",
-			clusterSlotMigrationActiveTasks: "2",
-			nodeFields:                      "master",
-			linkState:                       "connected",
-			slots:                           "5461",
-		},
-		{
-			name:                            "handshake flag",
-			clusterState:                    "ok",
-			clusterSlotMigrationActiveTasks: "0",
-			nodeFields:                      "master,handshake",
-			linkState:                       "connected",
-			slots:                           "5461",
-		},
-		{
-			name:                            "fail flag",
-			clusterState:                    "ok",
-			clusterSlotMigrationActiveTasks: "0",
-			nodeFields:                      "master,fail",
-			linkState:                       "connected",
-			slots:                           "5461",
-		},
-		{
-			name:                            "noaddr flag",
-			clusterState:                    "ok",
-			clusterSlotMigrationActiveTasks: "0",
-			nodeFields:                      "master,noaddr",
-			linkState:                       "connected",
-			slots:                           "5461",
-		},
-		{
-			name:                            "linkState not connected",
-			clusterState:                    "ok",
-			clusterSlotMigrationActiveTasks: "0",
-			nodeFields:                      "master",
-			linkState:                       "disconnected",
-			slots:                           "5461",
-		},
-		{
-			name:                            "migrating slots",
-			clusterState:                    "ok",
-			clusterSlotMigrationActiveTasks: "0",
-			nodeFields:                      "master",
-			linkState:                       "connected",
-			slots:                           "[5491->-6000]",
-		},
-		{
-			name:                            "importing slots",
-			clusterState:                    "ok",
-			clusterSlotMigrationActiveTasks: "0",
-			nodeFields:                      "master",
-			linkState:                       "connected",
-			slots:                           "[5491-<-6000]",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			info := "cluster_state:" + tt.clusterState + "\ncluster_slot_migration_active_tasks:" + tt.clusterSlotMigrationActiveTasks + "\n"
-
-			nodes := []clusterNodesResponse{
-				{
-					"id-1",
-					"10.0.0.10:6379@16379",
-					"master",
-					"-",
-					"0",
-					"0",
-					"1",
-					"connected",
-					"0-5460",
-				},
-				{
-					"id-2",
-					"10.0.0.11:6379@16379",
-					tt.nodeFields,
-					"-",
-					"0",
-					"0",
-					"2",
-					tt.linkState,
-					tt.slots,
-				},
-			}
-			if got := clusterStableNoOpenSlots(info, nodes); got {
-				t.Fatalf("expected false, got true")
-			}
-		})
-	}
-}
-
 func Test_verifyLeaderPodInfo(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -547,163 +67,151 @@ func Test_verifyLeaderPodInfo(t *testing.T) {
 	}
 }
 
-func Test_getRedisClusterSlots(t *testing.T) {
-	tests := []struct {
-		name            string
-		nodeID          string
-		clusterSlots    []redis.ClusterSlot
-		clusterSlotsErr error
-		expectedResult  string
-	}{
-		{
-			name:   "successful slot count",
-			nodeID: "node123",
-			clusterSlots: []redis.ClusterSlot{
-				{Start: 0, End: 4999, Nodes: []redis.ClusterNode{{ID: "node123"}}},
-				{Start: 5000, End: 9999, Nodes: []redis.ClusterNode{{ID: "node123"}}},
-			},
-			expectedResult: "10000",
-		},
-		{
-			name:            "error fetching cluster slots",
-			nodeID:          "node123",
-			clusterSlotsErr: redis.ErrClosed,
-			expectedResult:  "",
-		},
-		{
-			name:   "no slots for node",
-			nodeID: "node999",
-			clusterSlots: []redis.ClusterSlot{
-				{Start: 0, End: 4999, Nodes: []redis.ClusterNode{{ID: "node123"}}},
-			},
-			expectedResult: "0",
-		},
-		{
-			name:   "slots for multiple nodes",
-			nodeID: "node123",
-			clusterSlots: []redis.ClusterSlot{
-				{Start: 0, End: 1999, Nodes: []redis.ClusterNode{{ID: "node123"}}},
-				{Start: 2000, End: 3999, Nodes: []redis.ClusterNode{{ID: "node456"}}},
-				{Start: 4000, End: 5999, Nodes: []redis.ClusterNode{{ID: "node123"}, {ID: "node789"}}},
-			},
-			expectedResult: "4000",
-		},
-		{
-			name:   "single slot range",
-			nodeID: "node123",
-			clusterSlots: []redis.ClusterSlot{
-				{Start: 100, End: 100, Nodes: []redis.ClusterNode{{ID: "node123"}}},
-			},
-			expectedResult: "1",
-		},
-		{
-			name:   "mixed slot ranges",
-			nodeID: "node123",
-			clusterSlots: []redis.ClusterSlot{
-				{Start: 0, End: 499, Nodes: []redis.ClusterNode{{ID: "node123"}}},
-				{Start: 500, End: 999, Nodes: []redis.ClusterNode{{ID: "node123"}, {ID: "node999"}}},
-				{Start: 1000, End: 1499, Nodes: []redis.ClusterNode{{ID: "node999"}}},
-				{Start: 1500, End: 1999, Nodes: []redis.ClusterNode{{ID: "node123"}}},
-			},
-			expectedResult: "1500",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctx := context.TODO()
-			client, mock := redismock.NewClientMock()
-
-			if tt.clusterSlotsErr != nil {
-				mock.ExpectClusterSlots().SetErr(tt.clusterSlotsErr)
-			} else {
-				mock.ExpectClusterSlots().SetVal(tt.clusterSlots)
+func Test_newClusterAdmin(t *testing.T) {
+	newCluster := func(withAuth, withTLS bool) *rcvb2.RedisCluster {
+		cr := &rcvb2.RedisCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster", Namespace: "default"},
+			Spec:       rcvb2.RedisClusterSpec{Port: ptr.To(6379)},
+		}
+		if withAuth {
+			cr.Spec.KubernetesConfig.ExistingPasswordSecret = &common.ExistingPasswordSecret{
+				Name: ptr.To("redis-password-secret"),
+				Key:  ptr.To("password"),
 			}
-
-			result := getRedisClusterSlots(ctx, client, tt.nodeID)
-
-			assert.Equal(t, tt.expectedResult, result, "Test case: "+tt.name)
-
-			if err := mock.ExpectationsWereMet(); err != nil {
-				t.Errorf("there were unmet expectations: %s", err)
-			}
-		})
-	}
+		}
+		if withTLS {
+			cr.Spec.TLS = &common.TLSConfig{}
+			cr.Spec.TLS.Secret.SecretName = "redis-tls"
+		}
+		return cr
+	}
+
+	t.Run("no authentication", func(t *testing.T) {
+		admin, err := newClusterAdmin(context.TODO(), k8sClientFake.NewSimpleClientset(), newCluster(false, false))
+		assert.NoError(t, err)
+		assert.NotNil(t, admin)
+	})
+
+	t.Run("password from the secret", func(t *testing.T) {
+		client := k8sClientFake.NewSimpleClientset(mock_utils.CreateFakeObjectWithSecret("redis-password-secret", "default", "password")...)
+		_, err := newClusterAdmin(context.TODO(), client, newCluster(true, false))
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing password secret", func(t *testing.T) {
+		_, err := newClusterAdmin(context.TODO(), k8sClientFake.NewSimpleClientset(), newCluster(true, false))
+		assert.Error(t, err, "the cluster must not be managed unauthenticated")
+	})
+
+	t.Run("missing TLS secret", func(t *testing.T) {
+		_, err := newClusterAdmin(context.TODO(), k8sClientFake.NewSimpleClientset(), newCluster(false, true))
+		assert.Error(t, err, "the cluster must not be managed in plain text")
+	})
 }
 
-func Test_getAttachedFollowerNodeIDs(t *testing.T) {
-	tests := []struct {
-		name                 string
-		masterNodeID         string
-		slaveNodeIDs         []string
-		clusterSlavesErr     error
-		expectedslaveNodeIDs []string
-	}{
-		{
-			name:                 "successful retrieval of slave nodes",
-			masterNodeID:         "master123",
-			slaveNodeIDs:         []string{"slave1", "slave2"},
-			expectedslaveNodeIDs: []string{"slave1", "slave2"},
-		},
-		{
-			name:                 "error fetching slave nodes",
-			masterNodeID:         "master123",
-			clusterSlavesErr:     redis.ErrClosed,
-			expectedslaveNodeIDs: nil,
-		},
-		{
-			name:                 "no attached slave nodes",
-			masterNodeID:         "master456",
-			slaveNodeIDs:         []string{},
-			expectedslaveNodeIDs: []string{},
-		},
-		{
-			name:                 "nil response for slave nodes",
-			masterNodeID:         "masterNode123",
-			slaveNodeIDs:         nil,
-			expectedslaveNodeIDs: nil,
-			clusterSlavesErr:     nil,
-		},
-		{
-			name:                 "large number of attached slave nodes",
-			masterNodeID:         "master123",
-			slaveNodeIDs:         generateLargeListOfSlaves(1000), // Helper function needed
-			expectedslaveNodeIDs: generateLargeListOfSlaves(1000),
-		},
-		{
-			name:                 "invalid master node ID",
-			masterNodeID:         "invalidMasterID",
-			slaveNodeIDs:         nil,
-			expectedslaveNodeIDs: nil,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctx := context.Background()
-			client, mock := redismock.NewClientMock()
-
-			if tt.clusterSlavesErr != nil {
-				mock.ExpectClusterSlaves(tt.masterNodeID).SetErr(tt.clusterSlavesErr)
-			} else {
-				mock.ExpectClusterSlaves(tt.masterNodeID).SetVal(tt.slaveNodeIDs)
-			}
+func Test_slotRangesByNode(t *testing.T) {
+	owners := make([]int32, redisservice.TotalClusterSlots)
+	for slot := range owners {
+		if slot >= 8192 {
+			owners[slot] = 1
+		}
+	}
+	owners[100] = 2
+	ranges := slotRangesByNode(owners, map[int32]string{0: "a", 1: "b", 2: "c"})
+	assert.Equal(t, map[string][]redisservice.SlotRange{
+		"a": {{Start: 0, End: 99}, {Start: 101, End: 8191}},
+		"b": {{Start: 8192, End: 16383}},
+		"c": {{Start: 100, End: 100}},
+	}, ranges)
+}
 
-			result := getAttachedFollowerNodeIDs(ctx, client, tt.masterNodeID)
+func Test_clusterShards(t *testing.T) {
+	nodes, err := redisservice.ParseClusterNodes(
+		"b 10.0.0.2:6379@16379 master - 0 0 2 connected 100 8192-16383\n" +
+			"a 10.0.0.1:6379@16379,redis-cluster-leader-0.redis-cluster-leader-headless.default.svc myself,master - 0 0 1 connected 0-99 101-8191\n" +
+			"r 10.0.0.3:6379@16379 slave a 0 0 1 connected\n" +
+			"e 10.0.0.4:6379@16379 master - 0 0 0 connected\n")
+	assert.NoError(t, err)
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-leader-0"}, Status: corev1.PodStatus{PodIP: "10.0.0.1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-leader-1"}, Status: corev1.PodStatus{PodIP: "10.0.0.2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-follower-0"}, Status: corev1.PodStatus{PodIP: "10.0.0.3"}},
+	}
+	shards := clusterShards(nodes, pods)
+	assert.Equal(t, []rcvb2.ShardStatus{
+		{Pod: "redis-cluster-leader-0", NodeID: "a", Slots: 8191, SlotRanges: "0-99,101-8191"},
+		{Pod: "redis-cluster-leader-1", NodeID: "b", Slots: 8193, SlotRanges: "100,8192-16383"},
+		{NodeID: "e", Slots: 0, SlotRanges: ""},
+	}, shards)
+}
 
-			assert.ElementsMatch(t, tt.expectedslaveNodeIDs, result, "Test case: "+tt.name)
+func Test_clusterNodeOfPod(t *testing.T) {
+	cr := &rcvb2.RedisCluster{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster", Namespace: "default"}}
+	client := k8sClientFake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-leader-1", Namespace: "default"}, Status: corev1.PodStatus{PodIP: "10.0.0.2"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-leader-2", Namespace: "default"}, Status: corev1.PodStatus{PodIP: "10.0.0.3"}},
+	)
+	nodes, err := redisservice.ParseClusterNodes(
+		"a 10.0.0.1:6379@16379,redis-cluster-leader-0 myself,master - 0 0 1 connected 0-8191\n" +
+			"b 10.0.0.2:6379@16379,redis-cluster-leader-1 master - 0 0 2 connected 8192-16383\n")
+	assert.NoError(t, err)
+
+	node, err := clusterNodeOfPod(context.TODO(), client, cr, nodes, "redis-cluster-leader-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", node.ID)
+
+	// The pod of a node removed from the cluster keeps running until the StatefulSet is scaled down.
+	node, err = clusterNodeOfPod(context.TODO(), client, cr, nodes, "redis-cluster-leader-2")
+	assert.NoError(t, err)
+	assert.Nil(t, node)
+
+	_, err = clusterNodeOfPod(context.TODO(), client, cr, nodes, "redis-cluster-leader-3")
+	assert.Error(t, err)
+}
 
-			if err := mock.ExpectationsWereMet(); err != nil {
-				t.Errorf("there were unmet expectations: %s", err)
-			}
-		})
-	}
+func Test_leaderNodeIDs(t *testing.T) {
+	cr := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster", Namespace: "default"},
+		Spec:       rcvb2.RedisClusterSpec{ClusterSize: ptr.To(int32(2))},
+	}
+	nodes, err := redisservice.ParseClusterNodes(
+		"a 10.0.0.1:6379@16379 myself,master - 0 0 1 connected 0-8191\n" +
+			"b 10.0.0.2:6379@16379 master - 0 0 2 connected 8192-16383\n")
+	assert.NoError(t, err)
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-leader-0"}, Status: corev1.PodStatus{PodIP: "10.0.0.1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-leader-1"}, Status: corev1.PodStatus{PodIP: "10.0.0.2"}},
+	}
+	nodeIDs, err := leaderNodeIDs(cr, nodes, pods)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int32]string{0: "a", 1: "b"}, nodeIDs)
+
+	_, err = leaderNodeIDs(cr, nodes[:1], pods)
+	assert.EqualError(t, err, "redis-cluster-leader-1 is not a node of the cluster")
+
+	t.Run("failed over shard", func(t *testing.T) {
+		// leader-1 was failed over to follower-1 and now replicates it.
+		nodes, err := redisservice.ParseClusterNodes(
+			"a 10.0.0.1:6379@16379 myself,master - 0 0 1 connected 0-8191\n" +
+				"b 10.0.0.2:6379@16379 slave c 0 0 2 connected\n" +
+				"c 10.0.0.3:6379@16379 master - 0 0 3 connected 8192-16383\n")
+		assert.NoError(t, err)
+		pods := append(pods, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-follower-1"}, Status: corev1.PodStatus{PodIP: "10.0.0.3"}})
+		nodeIDs, err := leaderNodeIDs(cr, nodes, pods)
+		assert.NoError(t, err)
+		assert.Equal(t, map[int32]string{0: "a", 1: "c"}, nodeIDs)
+
+		// A leader replicating another leader can't stand for a shard of its own.
+		nodes[2].MasterID, nodes[1].MasterID = "", "a"
+		_, err = leaderNodeIDs(cr, nodes, pods)
+		assert.EqualError(t, err, "redis-cluster-leader-1 and redis-cluster-leader-0 belong to the same shard")
+	})
 }
 
-func generateLargeListOfSlaves(n int) []string {
-	var slaves []string
-	for i := 0; i < n; i++ {
-		slaves = append(slaves, fmt.Sprintf("slaveNode%d", i))
-	}
-	return slaves
+func Test_firstSlots(t *testing.T) {
+	ranges := []redisservice.SlotRange{{Start: 0, End: 99}, {Start: 200, End: 299}}
+	assert.Equal(t, []redisservice.SlotRange{{Start: 0, End: 49}}, firstSlots(ranges, 50))
+	assert.Equal(t, []redisservice.SlotRange{{Start: 0, End: 99}, {Start: 200, End: 209}}, firstSlots(ranges, 110))
+	assert.Equal(t, ranges, firstSlots(ranges, 1024))
+	assert.Empty(t, firstSlots(nil, 1024))
 }
@@ -0,0 +1,115 @@
+package k8sutils
+
+import (
+	"context"
+	"strconv"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ClusterTopology is the slot coverage and the replication of a cluster as seen by one of its nodes.
+type ClusterTopology struct {
+	// SlotsAssigned, SlotsOK, SlotsPFail and SlotsFail are the cluster_slots_* fields of CLUSTER INFO.
+	SlotsAssigned int
+	SlotsOK       int
+	SlotsPFail    int
+	SlotsFail     int
+	// OpenSlots is the number of slots migrating or importing.
+	OpenSlots int
+	// ShardReplicas maps the pod of each master serving slots, or its node ID when it can't be resolved,
+	// to the number of its healthy replicas.
+	ShardReplicas map[string]int
+}
+
+// GetRedisClusterTopology returns the topology of the cluster as seen by the first leader. A node only
+// lists its own open slots, every master is asked for them.
+func GetRedisClusterTopology(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (ClusterTopology, error) {
+	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
+	defer redisClient.Close()
+	info, err := redisClient.ClusterInfo(ctx).Result()
+	if err != nil {
+		return ClusterTopology{}, err
+	}
+
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return ClusterTopology{}, err
+	}
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
+	if err != nil {
+		return ClusterTopology{}, err
+	}
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return ClusterTopology{}, err
+	}
+	var masters []redisservice.ClusterNode
+	for _, n := range nodes {
+		if !n.IsMaster() || !n.Healthy() {
+			continue
+		}
+		if n.HasFlag("myself") {
+			masters = append(masters, n)
+			continue
+		}
+		view, err := admin.Nodes(ctx, n.Addr)
+		if err != nil {
+			return ClusterTopology{}, err
+		}
+		for _, self := range view {
+			if self.HasFlag("myself") {
+				masters = append(masters, self)
+			}
+		}
+	}
+	return clusterTopology(info, nodes, masters, clusterPods(ctx, client, cr)), nil
+}
+
+// clusterTopology returns the topology of the CLUSTER INFO and CLUSTER NODES replies of a node, with the
+// open slots listed by masters, the "myself" lines of the CLUSTER NODES replies of the masters.
+func clusterTopology(info string, nodes, masters []redisservice.ClusterNode, pods []corev1.Pod) ClusterTopology {
+	kv := redisservice.ParseInfo(info)
+	field := func(name string) int {
+		n, _ := strconv.Atoi(kv[name])
+		return n
+	}
+	topology := ClusterTopology{
+		SlotsAssigned: field("cluster_slots_assigned"),
+		SlotsOK:       field("cluster_slots_ok"),
+		SlotsPFail:    field("cluster_slots_pfail"),
+		SlotsFail:     field("cluster_slots_fail"),
+		ShardReplicas: map[string]int{},
+	}
+
+	open := map[int]bool{}
+	for _, n := range masters {
+		for slot := range n.Migrating {
+			open[slot] = true
+		}
+		for slot := range n.Importing {
+			open[slot] = true
+		}
+	}
+	topology.OpenSlots = len(open)
+
+	for _, master := range nodes {
+		if !master.IsMaster() || master.SlotCount() == 0 {
+			continue
+		}
+		shard := master.ID
+		if pod := podOfNode(master, pods); pod != nil {
+			shard = pod.Name
+		}
+		replicas := 0
+		for _, n := range nodes {
+			if n.MasterID == master.ID && n.Healthy() {
+				replicas++
+			}
+		}
+		topology.ShardReplicas[shard] = replicas
+	}
+	return topology
+}
@@ -0,0 +1,42 @@
+package k8sutils
+
+import (
+	"testing"
+
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_clusterTopology(t *testing.T) {
+	info := "cluster_state:ok\r\ncluster_slots_assigned:16384\r\ncluster_slots_ok:10923\r\n" +
+		"cluster_slots_pfail:5461\r\ncluster_slots_fail:0\r\ncluster_known_nodes:6\r\n"
+	nodes, err := redisservice.ParseClusterNodes(
+		"a 10.0.0.1:6379@16379,redis-cluster-leader-0 myself,master - 0 0 1 connected 0-5460\n" +
+			"b 10.0.0.2:6379@16379,redis-cluster-leader-1 master - 0 0 2 connected 5461-10922\n" +
+			"c 10.0.0.3:6379@16379 master,fail? - 0 0 3 connected 10923-16383\n" +
+			"d 10.0.0.7:6379@16379 master - 0 0 7 connected\n" +
+			"r1 10.0.0.4:6379@16379 slave a 0 0 1 connected\n" +
+			"r2 10.0.0.5:6379@16379 slave a 0 0 1 connected\n" +
+			"r3 10.0.0.6:6379@16379 slave,fail b 0 0 2 connected\n")
+	require.NoError(t, err)
+	// The "myself" lines of the masters, with a slot migrating from a to b.
+	masters, err := redisservice.ParseClusterNodes(
+		"a 10.0.0.1:6379@16379 myself,master - 0 0 1 connected 0-5460 [100->-b]\n" +
+			"b 10.0.0.2:6379@16379 myself,master - 0 0 2 connected 5461-10922 [100-<-a] [200-<-a]\n")
+	require.NoError(t, err)
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-leader-0"}, Status: corev1.PodStatus{PodIP: "10.0.0.1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-leader-1"}, Status: corev1.PodStatus{PodIP: "10.0.0.2"}},
+	}
+
+	assert.Equal(t, ClusterTopology{
+		SlotsAssigned: 16384,
+		SlotsOK:       10923,
+		SlotsPFail:    5461,
+		OpenSlots:     2,
+		ShardReplicas: map[string]int{"redis-cluster-leader-0": 2, "redis-cluster-leader-1": 0, "c": 0},
+	}, clusterTopology(info, nodes, masters, pods))
+}
@@ -0,0 +1,118 @@
+package k8sutils
+
+import (
+	"context"
+	"sort"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ClusterZones is the spread of the shards of a cluster over the zones of the nodes their pods run on.
+type ClusterZones struct {
+	// Zones is the number of zones the healthy nodes of the cluster run in.
+	Zones int
+	// Unknown lists the pods of the healthy nodes whose zone is unknown, sorted.
+	Unknown []string
+	// SingleZoneShards lists the pods of the masters whose shard runs in a single zone, sorted.
+	SingleZoneShards []string
+	// FailedOver is the pod of the replica promoted to move its master to a zone running fewer masters.
+	FailedOver string
+}
+
+// planZones returns the spread of the shards of nodes over the zones in locations, along with the
+// replica to fail over to move a master from the zone running the most masters to a zone running at
+// least two fewer, nil when the masters are balanced or a zone is unknown.
+func planZones(nodes []redisservice.ClusterNode, locations map[string]podLocation) (ClusterZones, *redisservice.ClusterNode) {
+	var zones ClusterZones
+	podOf := func(id string) string {
+		if pod := locations[id].Pod; pod != "" {
+			return pod
+		}
+		return id
+	}
+	seen := map[string]bool{}
+	mastersPerZone := map[string]int{}
+	var masters []*redisservice.ClusterNode
+	for i := range nodes {
+		n := &nodes[i]
+		if !n.Healthy() {
+			continue
+		}
+		zone := locations[n.ID].Zone
+		if zone == "" {
+			zones.Unknown = append(zones.Unknown, podOf(n.ID))
+			continue
+		}
+		seen[zone] = true
+		if n.IsMaster() && n.SlotCount() > 0 {
+			masters = append(masters, n)
+			mastersPerZone[zone]++
+		}
+	}
+	zones.Zones = len(seen)
+	sort.Strings(zones.Unknown)
+	if len(zones.Unknown) > 0 {
+		return zones, nil
+	}
+	sort.Slice(masters, func(i, j int) bool { return masters[i].ID < masters[j].ID })
+
+	var failover *redisservice.ClusterNode
+	bestGap := 1
+	for _, m := range masters {
+		zone := locations[m.ID].Zone
+		span := map[string]bool{zone: true}
+		for i := range nodes {
+			r := &nodes[i]
+			if r.MasterID != m.ID || !r.Healthy() {
+				continue
+			}
+			span[locations[r.ID].Zone] = true
+			if gap := mastersPerZone[zone] - mastersPerZone[locations[r.ID].Zone]; gap > bestGap {
+				failover, bestGap = r, gap
+			}
+		}
+		if len(span) < 2 {
+			zones.SingleZoneShards = append(zones.SingleZoneShards, podOf(m.ID))
+		}
+	}
+	sort.Strings(zones.SingleZoneShards)
+	return zones, failover
+}
+
+// BalanceRedisClusterZones reports the spread of the shards of the cluster over the zones of their
+// nodes, read from the topology.kubernetes.io/zone label. When a zone runs at least two masters more
+// than another one, it fails over one of its masters to a replica in the less loaded zone, one per call
+// so the cluster settles between the failovers.
+func BalanceRedisClusterZones(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (ClusterZones, error) {
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return ClusterZones{}, err
+	}
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
+	if err != nil {
+		return ClusterZones{}, err
+	}
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return ClusterZones{}, err
+	}
+	pods := clusterPods(ctx, client, cr)
+	locations := nodeLocations(nodes, pods, clusterPodLocations(ctx, client, pods))
+	zones, failover := planZones(nodes, locations)
+	if failover == nil {
+		return zones, nil
+	}
+
+	log.FromContext(ctx).Info("Failing over to move a master to a zone running fewer masters", "Replica", locations[failover.ID].Pod,
+		"Replica.Zone", locations[failover.ID].Zone, "Master.ID", failover.MasterID, "Master.Zone", locations[failover.MasterID].Zone)
+	ctx, cancel := clusterOperationContext(ctx)
+	defer cancel()
+	if err := admin.Failover(ctx, failover.Addr, redisservice.FailoverDefault); err != nil {
+		return zones, err
+	}
+	zones.FailedOver = locations[failover.ID].Pod
+	return zones, nil
+}
@@ -0,0 +1,95 @@
+package k8sutils
+
+import (
+	"testing"
+
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_planZones(t *testing.T) {
+	const threeShards = "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-5460\n" +
+		"b 10.0.0.2:6379@16379 master - 0 0 2 connected 5461-10922\n" +
+		"c 10.0.0.3:6379@16379 master - 0 0 3 connected 10923-16383\n" +
+		"ra 10.0.0.4:6379@16379 slave a 0 0 1 connected\n" +
+		"rb 10.0.0.5:6379@16379 slave b 0 0 2 connected\n" +
+		"rc 10.0.0.6:6379@16379 slave c 0 0 3 connected\n"
+	tests := []struct {
+		name         string
+		nodes        string
+		locations    map[string]podLocation
+		want         ClusterZones
+		wantFailover string
+	}{
+		{
+			name:  "masters spread over the zones",
+			nodes: threeShards,
+			locations: map[string]podLocation{
+				"a": {Pod: "leader-0", Zone: "z1"}, "b": {Pod: "leader-1", Zone: "z2"}, "c": {Pod: "leader-2", Zone: "z3"},
+				"ra": {Pod: "follower-0", Zone: "z2"}, "rb": {Pod: "follower-1", Zone: "z3"}, "rc": {Pod: "follower-2", Zone: "z1"},
+			},
+			want: ClusterZones{Zones: 3},
+		},
+		{
+			name:  "shard in a single zone",
+			nodes: threeShards,
+			locations: map[string]podLocation{
+				"a": {Pod: "leader-0", Zone: "z1"}, "b": {Pod: "leader-1", Zone: "z2"}, "c": {Pod: "leader-2", Zone: "z3"},
+				"ra": {Pod: "follower-0", Zone: "z1"}, "rb": {Pod: "follower-1", Zone: "z3"}, "rc": {Pod: "follower-2", Zone: "z1"},
+			},
+			want: ClusterZones{Zones: 3, SingleZoneShards: []string{"leader-0"}},
+		},
+		{
+			name:  "masters piled up in a zone after failovers",
+			nodes: threeShards,
+			locations: map[string]podLocation{
+				"a": {Pod: "leader-0", Zone: "z1"}, "b": {Pod: "follower-0", Zone: "z1"}, "c": {Pod: "leader-2", Zone: "z1"},
+				"ra": {Pod: "follower-1", Zone: "z2"}, "rb": {Pod: "leader-1", Zone: "z2"}, "rc": {Pod: "follower-2", Zone: "z3"},
+			},
+			want:         ClusterZones{Zones: 3},
+			wantFailover: "ra",
+		},
+		{
+			name:  "masters off by one are balanced",
+			nodes: threeShards,
+			locations: map[string]podLocation{
+				"a": {Pod: "leader-0", Zone: "z1"}, "b": {Pod: "leader-1", Zone: "z1"}, "c": {Pod: "leader-2", Zone: "z2"},
+				"ra": {Pod: "follower-0", Zone: "z2"}, "rb": {Pod: "follower-1", Zone: "z2"}, "rc": {Pod: "follower-2", Zone: "z1"},
+			},
+			want: ClusterZones{Zones: 2},
+		},
+		{
+			name:  "unknown zones",
+			nodes: threeShards,
+			locations: map[string]podLocation{
+				"a": {Pod: "leader-0", Zone: "z1"}, "b": {Pod: "leader-1", Zone: "z1"}, "c": {Pod: "leader-2", Zone: "z1"},
+				"ra": {Pod: "follower-0", Zone: "z2"},
+			},
+			want: ClusterZones{Zones: 2, Unknown: []string{"rb", "rc"}},
+		},
+		{
+			name: "failed replicas don't count",
+			nodes: "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-16383\n" +
+				"ra 10.0.0.4:6379@16379 slave,fail a 0 0 1 disconnected\n",
+			locations: map[string]podLocation{
+				"a": {Pod: "leader-0", Zone: "z1"}, "ra": {Pod: "follower-0", Zone: "z2"},
+			},
+			want: ClusterZones{Zones: 1, SingleZoneShards: []string{"leader-0"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := redisservice.ParseClusterNodes(tt.nodes)
+			require.NoError(t, err)
+			zones, failover := planZones(nodes, tt.locations)
+			assert.Equal(t, tt.want, zones)
+			if tt.wantFailover == "" {
+				assert.Nil(t, failover)
+			} else {
+				require.NotNil(t, failover)
+				assert.Equal(t, tt.wantFailover, failover.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	redis "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// PodConditionInService is the condition of the readiness gate of the RedisCluster and RedisReplication
+// pods when the DrainHandover feature gate is enabled. The operator sets it while the Redis server of the
+// pod serves its role, and clears it once the pod is terminating.
+const PodConditionInService corev1.PodConditionType = "redis.opstreelabs.in/in-service"
+
+// inServiceReadinessGates returns the readiness gates of the pods the operator hands the master role of
+// over before they are evicted or rolled.
+func inServiceReadinessGates() []corev1.PodReadinessGate {
+	return []corev1.PodReadinessGate{{ConditionType: PodConditionInService}}
+}
+
+// PodReplication is the replication state of the Redis server of a pod.
+type PodReplication struct {
+	// Master is set when the server is a master.
+	Master bool
+	// LinkUp is set when the server is a master, or a replica whose link to its master is up.
+	LinkUp bool
+	// Offset is the replication offset of the server, master_repl_offset.
+	Offset int64
+}
+
+// parsePodReplication reads the replication state from the output of INFO Replication.
+func parsePodReplication(info string) PodReplication {
+	var state PodReplication
+	for _, line := range strings.Split(info, "\r\n") {
+		if role, ok := strings.CutPrefix(line, "role:"); ok {
+			state.Master = role == "master"
+		}
+		if offset, ok := strings.CutPrefix(line, "master_repl_offset:"); ok {
+			state.Offset, _ = strconv.ParseInt(offset, 10, 64)
+		}
+	}
+	state.LinkUp = replicationLinkUp(info)
+	return state
+}
+
+func podReplication(ctx context.Context, redisClient *redis.Client) (PodReplication, error) {
+	info, err := redisClient.Info(ctx, "Replication").Result()
+	if err != nil {
+		return PodReplication{}, err
+	}
+	return parsePodReplication(info), nil
+}
+
+// GetRedisClusterPodReplication returns the replication state of the cluster node running in pod.
+func GetRedisClusterPodReplication(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, pod *corev1.Pod) (PodReplication, error) {
+	redisClient := configureRedisClient(ctx, client, cr, pod.Name)
+	defer redisClient.Close()
+	return podReplication(ctx, redisClient)
+}
+
+// GetRedisReplicationPodReplication returns the replication state of the Redis server running in pod.
+func GetRedisReplicationPodReplication(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication, pod *corev1.Pod) (PodReplication, error) {
+	redisClient := configureRedisReplicationClientForPod(ctx, client, cr, pod)
+	defer redisClient.Close()
+	return podReplication(ctx, redisClient)
+}
+
+// HandoverRedisClusterMaster hands the slots of the cluster node running in pod over to one of its
+// healthy replicas whose pod isn't terminating, with a CLUSTER FAILOVER on the replica. It returns the pod
+// of the replica taking over, empty once the node no longer is a master serving slots. The failover
+// completes asynchronously, so the caller checks again until the pod of the replica is empty.
+func HandoverRedisClusterMaster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, pod *corev1.Pod) (string, error) {
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return "", err
+	}
+	seed, err := podEndpoint(ctx, client, cr, pod.Name)
+	if err != nil {
+		return "", err
+	}
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return "", err
+	}
+	var master *redisservice.ClusterNode
+	for i := range nodes {
+		if nodes[i].HasFlag("myself") {
+			master = &nodes[i]
+		}
+	}
+	if master == nil || !master.IsMaster() || master.SlotCount() == 0 {
+		return "", nil
+	}
+
+	pods := clusterPods(ctx, client, cr)
+	for _, n := range nodes {
+		if n.MasterID != master.ID || !n.Healthy() {
+			continue
+		}
+		replicaPod := podOfNode(n, pods)
+		if replicaPod == nil || replicaPod.DeletionTimestamp != nil {
+			continue
+		}
+		log.FromContext(ctx).Info("Handing the slots of the master over to its replica", "Master", pod.Name, "Master.ID", master.ID,
+			"Replica", replicaPod.Name, "Replica.ID", n.ID)
+		ctx, cancel := clusterOperationContext(ctx)
+		defer cancel()
+		if err := admin.Failover(ctx, n.Addr, redisservice.FailoverDefault); err != nil {
+			return "", err
+		}
+		return replicaPod.Name, nil
+	}
+	return "", fmt.Errorf("master %s serves slots and has no healthy replica to hand them over to", pod.Name)
+}
+
+// HandoverRedisReplicationMaster asks the sentinels of cr to fail its master over to a replica, and
+// returns whether a failover is in progress. The sentinels pick the replica, and the failover completes
+// asynchronously.
+func HandoverRedisReplicationMaster(ctx context.Context, client kubernetes.Interface, redisClient redisservice.Client, cr *rrvb2.RedisReplication) (bool, error) {
+	password, err := sentinelPassword(ctx, client, cr)
+	if err != nil {
+		return false, err
+	}
+	selector := labels.SelectorFromSet(getRedisLabels(cr.SentinelStatefulSet(), sentinel, "sentinel", cr.GetLabels()))
+	sentinels, err := client.CoreV1().Pods(cr.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return false, fmt.Errorf("list sentinel pods: %w", err)
+	}
+	var errs []error
+	for _, pod := range sentinels.Items {
+		if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		err := redisClient.Connect(&redisservice.ConnectionInfo{
+			Host:     pod.Status.PodIP,
+			Port:     "26379",
+			Password: password,
+			Pod:      pod.Name,
+		}).SentinelFailover(ctx, cr.SentinelMasterName())
+		// A failover started by an earlier call, or by another sentinel, is still running.
+		if err == nil || strings.HasPrefix(err.Error(), "INPROG") {
+			log.FromContext(ctx).Info("Handing the master over with a sentinel failover", "Sentinel", pod.Name)
+			return true, nil
+		}
+		errs = append(errs, fmt.Errorf("sentinel %s: %w", pod.Name, err))
+	}
+	if len(errs) == 0 {
+		return false, fmt.Errorf("no sentinel of %s is running", cr.Name)
+	}
+	return false, errors.Join(errs...)
+}
+
+// sentinelPassword returns the password of the sentinels of cr, empty when they don't require one.
+func sentinelPassword(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication) (string, error) {
+	ref := cr.Spec.Sentinel.ExistingPasswordSecret
+	if ref == nil {
+		return "", nil
+	}
+	secret, err := client.CoreV1().Secrets(cr.Namespace).Get(ctx, *ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return string(secret.Data[*ref.Key]), nil
+}
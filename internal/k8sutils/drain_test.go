@@ -0,0 +1,36 @@
+package k8sutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parsePodReplication(t *testing.T) {
+	tests := []struct {
+		name string
+		info string
+		want PodReplication
+	}{
+		{
+			name: "master",
+			info: "# Replication\r\nrole:master\r\nconnected_slaves:1\r\nmaster_repl_offset:1024\r\n",
+			want: PodReplication{Master: true, LinkUp: true, Offset: 1024},
+		},
+		{
+			name: "replica in sync",
+			info: "# Replication\r\nrole:slave\r\nmaster_host:10.0.0.1\r\nmaster_link_status:up\r\nmaster_repl_offset:1000\r\n",
+			want: PodReplication{LinkUp: true, Offset: 1000},
+		},
+		{
+			name: "replica syncing",
+			info: "# Replication\r\nrole:slave\r\nmaster_host:10.0.0.1\r\nmaster_link_status:down\r\nmaster_sync_in_progress:1\r\n",
+			want: PodReplication{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parsePodReplication(tt.info))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package k8sutils
+
+import (
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/consts"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// engineCommand returns the command of engine for tool, e.g. valkey-cli for the cli of Valkey.
+func engineCommand(engine commonapi.Engine, tool string) string {
+	if engine == "" {
+		engine = commonapi.EngineRedis
+	}
+	return string(engine) + "-" + tool
+}
+
+// engineConfigFile returns the path of the config file the bootstrap agent generates for the server of
+// engine.
+func engineConfigFile(engine commonapi.Engine) string {
+	if engine == commonapi.EngineValkey {
+		return "/etc/redis/valkey.conf"
+	}
+	return "/etc/redis/redis.conf"
+}
+
+// engineEnvVars returns the environment variables telling the bootstrap agent and the image which engine
+// runs. Redis is left implicit, so the pods of existing resources aren't rolled.
+func engineEnvVars(engine commonapi.Engine) []corev1.EnvVar {
+	if engine != commonapi.EngineValkey {
+		return nil
+	}
+	return []corev1.EnvVar{{Name: consts.ENV_KEY_REDIS_ENGINE, Value: string(engine)}}
+}
@@ -0,0 +1,99 @@
+package k8sutils
+
+import (
+	"testing"
+
+	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/consts"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/features"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/testutil/factories/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/testutil/factories/redisreplication"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGenerateContainerDefEngine(t *testing.T) {
+	require.NoError(t, features.MutableFeatureGate.Set("GenerateConfigInInitContainer=true"))
+	t.Cleanup(func() {
+		require.NoError(t, features.MutableFeatureGate.Set("GenerateConfigInInitContainer=false"))
+	})
+
+	tests := []struct {
+		name       string
+		params     containerParameters
+		wantCmd    []string
+		wantArgs   []string
+		wantCLI    string
+		wantEngine bool
+	}{
+		{
+			name:     "redis",
+			params:   generateRedisStandaloneContainerParams(redis.New("redis")),
+			wantCmd:  []string{"redis-server"},
+			wantArgs: []string{"/etc/redis/redis.conf"},
+			wantCLI:  "redis-cli",
+		},
+		{
+			name:       "valkey",
+			params:     generateRedisStandaloneContainerParams(redis.NewValkey("valkey")),
+			wantCmd:    []string{"valkey-server"},
+			wantArgs:   []string{"/etc/redis/valkey.conf"},
+			wantCLI:    "valkey-cli",
+			wantEngine: true,
+		},
+		{
+			name:       "valkey sentinel",
+			params:     containerParameters{Role: "sentinel", Engine: common.EngineValkey},
+			wantCmd:    []string{"valkey-sentinel"},
+			wantArgs:   []string{"/etc/redis/sentinel.conf"},
+			wantCLI:    "valkey-cli",
+			wantEngine: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containers := generateContainerDef("redis", tt.params, false, false, false, nil, nil, nil, nil)
+			require.NotEmpty(t, containers)
+			assert.Equal(t, tt.wantCmd, containers[0].Command)
+			assert.Equal(t, tt.wantArgs, containers[0].Args)
+			assert.Contains(t, containers[0].ReadinessProbe.Exec.Command[2], tt.wantCLI+" -h")
+			assert.Equal(t, tt.wantEngine, envContains(containers[0].Env, corev1.EnvVar{Name: consts.ENV_KEY_REDIS_ENGINE, Value: "valkey"}))
+
+			initContainers := generateInitContainerDef(tt.params.Role, "redis", initContainerParameters{}, nil, nil, tt.params, nil)
+			require.NotEmpty(t, initContainers)
+			assert.Equal(t, tt.wantEngine, envContains(initContainers[0].Env, corev1.EnvVar{Name: consts.ENV_KEY_REDIS_ENGINE, Value: "valkey"}))
+		})
+	}
+}
+
+func TestGeneratePreStopCommandEngine(t *testing.T) {
+	cr := redisreplication.NewValkey("valkey")
+	params := generateRedisReplicationContainerParams(cr)
+	assert.Equal(t, common.EngineValkey, params.Engine)
+
+	for _, cfg := range []PreStopConfig{
+		{Role: "cluster", Engine: params.Engine, HandoverWaitSeconds: 10},
+		{Role: "replication", Engine: params.Engine, SentinelService: "sentinel", SentinelMasterName: "mymaster", WaitSeconds: 20, HandoverWaitSeconds: 10},
+	} {
+		script := GeneratePreStopCommand(cfg)
+		assert.Contains(t, script, "valkey-cli -h", cfg.Role)
+		assert.NotContains(t, script, "redis-cli", cfg.Role)
+	}
+}
+
+func envContains(env []corev1.EnvVar, want corev1.EnvVar) bool {
+	for _, e := range env {
+		if e == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEngineCommand(t *testing.T) {
+	assert.Equal(t, "redis-cli", engineCommand("", "cli"))
+	assert.Equal(t, "redis-server", engineCommand(common.EngineRedis, "server"))
+	assert.Equal(t, "valkey-sentinel", engineCommand(common.EngineValkey, "sentinel"))
+	assert.Equal(t, "/etc/redis/valkey.conf", engineConfigFile(common.EngineValkey))
+}
@@ -0,0 +1,257 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrLoadBalancerPending is returned while the LoadBalancer Service of a pod has no ingress to announce yet.
+var ErrLoadBalancerPending = errors.New("the load balancers of the pods have no ingress yet")
+
+const (
+	// announceIPEnvPrefix and announceHostnameEnvPrefix prefix the variables holding the address of the
+	// load balancer of each pod, suffixed with the pod name. The agent announces the one of its hostname.
+	announceIPEnvPrefix       = "announce_ip_"
+	announceHostnameEnvPrefix = "announce_hostname_"
+)
+
+// announceEnvSuffix returns the suffix of the announce variables of the pod named podName.
+func announceEnvSuffix(podName string) string {
+	return strings.ReplaceAll(podName, "-", "_")
+}
+
+// clusterLoadBalancerServiceDef returns the LoadBalancer Service exposing the client and cluster-bus ports
+// of a single replica of role. It has the name and the selector of the per-pod NodePort Service.
+func clusterLoadBalancerServiceDef(cr *rcvb2.RedisCluster, role string, replica int) *corev1.Service {
+	objectMetaInfo, busPort := RedisClusterService{RedisServiceRole: role}.clusterNodePortServiceParams(cr, replica)
+	objectMetaInfo.Annotations = generateServiceAnots(cr.ObjectMeta, cr.Spec.ExternalAccess.Annotations, disableMetrics)
+	serviceDef := generateServiceDef(objectMetaInfo, disableMetrics, redisClusterAsOwner(cr), false, "LoadBalancer", *cr.Spec.Port, busPort)
+	serviceDef.Spec.LoadBalancerClass = cr.Spec.ExternalAccess.LoadBalancerClass
+	serviceDef.Spec.LoadBalancerSourceRanges = cr.Spec.ExternalAccess.LoadBalancerSourceRanges
+	return serviceDef
+}
+
+func (service RedisClusterService) createOrUpdateClusterLoadBalancerService(ctx context.Context, cr *rcvb2.RedisCluster, cl kubernetes.Interface) error {
+	replicas := cr.Spec.GetReplicaCounts(service.RedisServiceRole)
+	for i := 0; i < int(replicas); i++ {
+		serviceDef := clusterLoadBalancerServiceDef(cr, service.RedisServiceRole, i)
+		storedService, err := getService(ctx, cl, cr.Namespace, serviceDef.Name)
+		if apierrors.IsNotFound(err) {
+			if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(serviceDef); err != nil {
+				return err
+			}
+			if err := createService(ctx, cl, cr.Namespace, serviceDef); err != nil && !apierrors.IsAlreadyExists(err) {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := patchService(ctx, storedService, serviceDef, cr.Namespace, cl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureRedisClusterLoadBalancerServices creates the missing per-pod LoadBalancer Services of role before
+// the StatefulSet is rendered, and returns ErrLoadBalancerPending until every one of them has an ingress
+// whose address the pod template announces. Like EnsureRedisClusterNodePortServices it never updates a
+// Service, CreateRedisClusterService does once the StatefulSet is reconciled.
+func EnsureRedisClusterLoadBalancerServices(ctx context.Context, cr *rcvb2.RedisCluster, role string, cl kubernetes.Interface) error {
+	if !cr.Spec.LoadBalancerAccess() {
+		return nil
+	}
+
+	var pending []string
+	replicas := cr.Spec.GetReplicaCounts(role)
+	for i := 0; i < int(replicas); i++ {
+		serviceName := cr.Name + "-" + role + "-" + strconv.Itoa(i)
+		svc, err := getService(ctx, cl, cr.Namespace, serviceName)
+		if apierrors.IsNotFound(err) {
+			serviceDef := clusterLoadBalancerServiceDef(cr, role, i)
+			if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(serviceDef); err != nil {
+				return err
+			}
+			if err := createService(ctx, cl, cr.Namespace, serviceDef); err != nil && !apierrors.IsAlreadyExists(err) {
+				return err
+			}
+			pending = append(pending, serviceName)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if ip, hostname := loadBalancerAddress(svc); ip == "" && hostname == "" {
+			pending = append(pending, serviceName)
+		}
+	}
+	if len(pending) > 0 {
+		log.FromContext(ctx).V(1).Info("Waiting for the load balancers of the pods", "Services", pending)
+		return fmt.Errorf("%w: %s", ErrLoadBalancerPending, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// loadBalancerAddress returns the address of the load balancer of svc, its IP, or its hostname for the
+// load balancers that are only reachable by name, empty while it isn't provisioned.
+func loadBalancerAddress(svc *corev1.Service) (string, string) {
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP, ""
+		}
+		if ingress.Hostname != "" {
+			return "", ingress.Hostname
+		}
+	}
+	return "", ""
+}
+
+// clusterLoadBalancerAnnounceEnvVars returns the variables announcing the address of the load balancer of
+// every pod of role. A load balancer only reachable by name is announced with cluster-announce-hostname,
+// which needs the features of Redis 7.
+func clusterLoadBalancerAnnounceEnvVars(ctx context.Context, cl kubernetes.Interface, cr *rcvb2.RedisCluster, role string) ([]corev1.EnvVar, error) {
+	var envVars []corev1.EnvVar
+	replicas := cr.Spec.GetReplicaCounts(role)
+	for i := 0; i < int(replicas); i++ {
+		serviceName := cr.Name + "-" + role + "-" + strconv.Itoa(i)
+		svc, err := getService(ctx, cl, cr.Namespace, serviceName)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get load balancer service %s/%s: %w", cr.Namespace, serviceName, err)
+		}
+		ip, hostname := loadBalancerAddress(svc)
+		switch {
+		case ip != "":
+			envVars = append(envVars, corev1.EnvVar{Name: announceIPEnvPrefix + announceEnvSuffix(serviceName), Value: ip})
+		case hostname == "":
+			return nil, fmt.Errorf("%w: %s", ErrLoadBalancerPending, serviceName)
+		case cr.Spec.ClusterVersion != nil && !util.HasRedis7Features(string(cr.Spec.KubernetesConfig.GetEngine()), *cr.Spec.ClusterVersion):
+			return nil, fmt.Errorf("the load balancer of service %s/%s only has the hostname %s, announcing it needs Redis 7", cr.Namespace, serviceName, hostname)
+		default:
+			envVars = append(envVars, corev1.EnvVar{Name: announceHostnameEnvPrefix + announceEnvSuffix(serviceName), Value: hostname})
+		}
+	}
+	return envVars, nil
+}
+
+// podAnnouncedAddress returns the load balancer address pod was started to announce, its IP or its
+// hostname, both empty when the pod isn't exposed through a load balancer.
+func podAnnouncedAddress(pod *corev1.Pod) (string, string) {
+	suffix := announceEnvSuffix(pod.Name)
+	var ip, hostname string
+	for _, container := range pod.Spec.Containers {
+		for _, env := range container.Env {
+			switch env.Name {
+			case announceIPEnvPrefix + suffix:
+				ip = env.Value
+			case announceHostnameEnvPrefix + suffix:
+				hostname = env.Value
+			}
+		}
+	}
+	return ip, hostname
+}
+
+// podNameOfHostname returns the name of the pod announcing hostname: the first label of the hostname,
+// unless one of pods announces a load balancer hostname with the same first label.
+func podNameOfHostname(hostname string, pods []corev1.Pod) string {
+	name, _, _ := strings.Cut(hostname, ".")
+	for i := range pods {
+		if _, lbHostname := podAnnouncedAddress(&pods[i]); lbHostname != "" {
+			if lbName, _, _ := strings.Cut(lbHostname, "."); lbName == name {
+				return pods[i].Name
+			}
+		}
+	}
+	return name
+}
+
+// staleAnnouncements returns the pods of nodes announcing another address than the load balancer of
+// their Service, given the address of the load balancers by pod. Their pods were started before the
+// load balancer got its current address, and announce one clients outside of Kubernetes can't reach.
+func staleAnnouncements(nodes []redisservice.ClusterNode, pods []corev1.Pod, addresses map[string][2]string) map[string]bool {
+	stale := map[string]bool{}
+	for _, n := range nodes {
+		pod := podOfNode(n, pods)
+		if pod == nil {
+			continue
+		}
+		address, ok := addresses[pod.Name]
+		if !ok {
+			continue
+		}
+		host, _, _ := net.SplitHostPort(n.Addr)
+		switch ip, hostname := address[0], address[1]; {
+		case ip != "" && host != ip:
+			stale[pod.Name] = true
+		case ip == "" && hostname != "" && n.Hostname != hostname:
+			stale[pod.Name] = true
+		}
+	}
+	return stale
+}
+
+// RollRedisClusterStaleAnnouncements restarts the pods of cr announcing another address than the load
+// balancer of their Service, one step per call like a managed upgrade, see rollClusterPods. It waits for
+// the pods running a previous revision to be rolled first, which announces the current address as well.
+func RollRedisClusterStaleAnnouncements(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (UpgradeStep, error) {
+	if !cr.Spec.LoadBalancerAccess() {
+		return UpgradeStep{}, nil
+	}
+	revisions, err := updateRevisions(ctx, client, cr.Namespace, cr.Name+"-leader", cr.Name+"-follower")
+	if err != nil {
+		return UpgradeStep{}, err
+	}
+	pods := clusterPods(ctx, client, cr)
+	for i := range pods {
+		if podOutdated(&pods[i], revisions) {
+			return UpgradeStep{}, nil
+		}
+	}
+	if waitForPods(pods, int(cr.Spec.GetReplicaCounts("leader")+cr.Spec.GetReplicaCounts("follower"))) != "" {
+		return UpgradeStep{}, nil
+	}
+
+	addresses := map[string][2]string{}
+	for i := range pods {
+		svc, err := getService(ctx, client, cr.Namespace, pods[i].Name)
+		if err != nil {
+			return UpgradeStep{}, err
+		}
+		if ip, hostname := loadBalancerAddress(svc); ip != "" || hostname != "" {
+			addresses[pods[i].Name] = [2]string{ip, hostname}
+		}
+	}
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return UpgradeStep{}, err
+	}
+	seed, err := podEndpoint(ctx, client, cr, pods[0].Name)
+	if err != nil {
+		return UpgradeStep{}, err
+	}
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return UpgradeStep{}, err
+	}
+	stale := staleAnnouncements(nodes, pods, addresses)
+	if len(stale) == 0 {
+		return UpgradeStep{}, nil
+	}
+	log.FromContext(ctx).Info("Pods announce another address than their load balancer", "Pods", len(stale))
+	return rollClusterPods(ctx, client, cr, pods, stale)
+}
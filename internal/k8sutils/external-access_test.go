@@ -0,0 +1,137 @@
+package k8sutils
+
+import (
+	"testing"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func newLoadBalancerCluster(replicas int32) *rcvb2.RedisCluster {
+	cluster := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster", Namespace: "redis"},
+		Spec: rcvb2.RedisClusterSpec{
+			ClusterSize: ptr.To(replicas),
+			ExternalAccess: &rcvb2.ExternalAccess{
+				Type:                     rcvb2.ExternalAccessLoadBalancer,
+				Annotations:              map[string]string{"service.beta.kubernetes.io/aws-load-balancer-type": "nlb"},
+				LoadBalancerClass:        ptr.To("service.k8s.aws/nlb"),
+				LoadBalancerSourceRanges: []string{"192.0.2.0/24"},
+			},
+		},
+	}
+	cluster.SetDefault()
+	return cluster
+}
+
+func loadBalancerService(name string, ingress ...corev1.LoadBalancerIngress) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "redis"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		Status:     corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: ingress}},
+	}
+}
+
+func TestEnsureRedisClusterLoadBalancerServices(t *testing.T) {
+	t.Run("does nothing without external access", func(t *testing.T) {
+		client := fake.NewSimpleClientset()
+		cr := newLoadBalancerCluster(3)
+		cr.Spec.ExternalAccess = nil
+
+		require.NoError(t, EnsureRedisClusterLoadBalancerServices(t.Context(), cr, "leader", client))
+		assert.Empty(t, client.Actions())
+	})
+
+	t.Run("creates the per-pod services and waits for their ingress", func(t *testing.T) {
+		client := fake.NewSimpleClientset()
+
+		err := EnsureRedisClusterLoadBalancerServices(t.Context(), newLoadBalancerCluster(2), "leader", client)
+
+		require.ErrorIs(t, err, ErrLoadBalancerPending)
+		assert.Contains(t, err.Error(), "redis-cluster-leader-0, redis-cluster-leader-1")
+		service, err := client.CoreV1().Services("redis").Get(t.Context(), "redis-cluster-leader-1", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, corev1.ServiceTypeLoadBalancer, service.Spec.Type)
+		assert.Equal(t, "redis-cluster-leader-1", service.Spec.Selector["statefulset.kubernetes.io/pod-name"])
+		assert.Equal(t, "nlb", service.Annotations["service.beta.kubernetes.io/aws-load-balancer-type"])
+		assert.Equal(t, ptr.To("service.k8s.aws/nlb"), service.Spec.LoadBalancerClass)
+		assert.Equal(t, []string{"192.0.2.0/24"}, service.Spec.LoadBalancerSourceRanges)
+		ports := map[string]int32{}
+		for _, port := range service.Spec.Ports {
+			ports[port.Name] = port.Port
+		}
+		assert.Equal(t, map[string]int32{"redis-client": 6379, "redis-bus": 16379}, ports)
+	})
+
+	t.Run("returns once every load balancer has an ingress", func(t *testing.T) {
+		client := fake.NewSimpleClientset(
+			loadBalancerService("redis-cluster-leader-0", corev1.LoadBalancerIngress{IP: "203.0.113.10"}),
+			loadBalancerService("redis-cluster-leader-1", corev1.LoadBalancerIngress{Hostname: "leader-1.elb.example.com"}),
+		)
+
+		require.NoError(t, EnsureRedisClusterLoadBalancerServices(t.Context(), newLoadBalancerCluster(2), "leader", client))
+	})
+}
+
+func Test_generateRedisClusterContainerParams_LoadBalancer(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		loadBalancerService("redis-cluster-leader-0", corev1.LoadBalancerIngress{IP: "203.0.113.10"}),
+		loadBalancerService("redis-cluster-leader-1", corev1.LoadBalancerIngress{Hostname: "leader-1.elb.example.com"}),
+	)
+
+	params, err := generateRedisClusterContainerParams(t.Context(), client, newLoadBalancerCluster(2), nil, nil, nil, "leader", nil)
+	require.NoError(t, err)
+	values := map[string]string{}
+	for _, env := range *params.EnvVars {
+		values[env.Name] = env.Value
+	}
+	assert.Equal(t, "203.0.113.10", values["announce_ip_redis_cluster_leader_0"])
+	assert.Equal(t, "leader-1.elb.example.com", values["announce_hostname_redis_cluster_leader_1"])
+	assert.NotContains(t, values, "NODEPORT")
+
+	cr := newLoadBalancerCluster(2)
+	cr.Spec.ClusterVersion = ptr.To("v6")
+	_, err = generateRedisClusterContainerParams(t.Context(), client, cr, nil, nil, nil, "leader", nil)
+	assert.ErrorContains(t, err, "announcing it needs Redis 7")
+
+	_, err = generateRedisClusterContainerParams(t.Context(), client, newLoadBalancerCluster(3), nil, nil, nil, "leader", nil)
+	assert.ErrorContains(t, err, "redis/redis-cluster-leader-2")
+}
+
+func Test_staleAnnouncements(t *testing.T) {
+	announcing := func(name, ip, envName, value string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Env: []corev1.EnvVar{{Name: envName, Value: value}}}}},
+			Status:     corev1.PodStatus{PodIP: ip},
+		}
+	}
+	pods := []corev1.Pod{
+		announcing("redis-cluster-leader-0", "10.0.0.1", "announce_ip_redis_cluster_leader_0", "203.0.113.10"),
+		announcing("redis-cluster-leader-1", "10.0.0.2", "announce_ip_redis_cluster_leader_1", "203.0.113.11"),
+		announcing("redis-cluster-leader-2", "10.0.0.3", "announce_hostname_redis_cluster_leader_2", "old.elb.example.com"),
+		announcing("redis-cluster-leader-3", "10.0.0.4", "announce_hostname_redis_cluster_leader_3", "leader-3.elb.example.com"),
+	}
+	nodes := []redisservice.ClusterNode{
+		{ID: "a", Addr: "203.0.113.10:6379", Hostname: "redis-cluster-leader-0.redis-cluster-leader-headless.redis.svc"},
+		{ID: "b", Addr: "203.0.113.11:6379", Hostname: "redis-cluster-leader-1.redis-cluster-leader-headless.redis.svc"},
+		{ID: "c", Addr: "10.0.0.3:6379", Hostname: "old.elb.example.com"},
+		{ID: "d", Addr: "10.0.0.4:6379", Hostname: "leader-3.elb.example.com"},
+	}
+	addresses := map[string][2]string{
+		"redis-cluster-leader-0": {"203.0.113.10", ""},
+		"redis-cluster-leader-1": {"203.0.113.99", ""},
+		"redis-cluster-leader-2": {"", "new.elb.example.com"},
+		"redis-cluster-leader-3": {"", "leader-3.elb.example.com"},
+	}
+
+	assert.Equal(t, map[string]bool{"redis-cluster-leader-1": true, "redis-cluster-leader-2": true}, staleAnnouncements(nodes, pods, addresses))
+	assert.Equal(t, "redis-cluster-leader-3", podNameOfHostname("leader-3.elb.example.com", pods))
+	assert.Equal(t, "redis-cluster-leader-0", podNameOfHostname("redis-cluster-leader-0.redis-cluster-leader-headless", pods))
+}
@@ -98,6 +98,9 @@ func AddFinalizer(ctx context.Context, cr client.Object, finalizer string, cl cl
 func finalizeRedisPVC(ctx context.Context, client client.Client, cr *rvb2.Redis) error {
 	pvcTemplateName := env.GetString(common.EnvOperatorSTSPVCTemplateName, cr.Name)
 	PVCName := fmt.Sprintf("%s-%s-0", pvcTemplateName, cr.Name)
+	if err := waitForVolumeSnapshots(ctx, client, cr.Namespace, []string{PVCName}); err != nil {
+		return err
+	}
 	pvc := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: cr.Namespace,
@@ -114,6 +117,15 @@ func finalizeRedisPVC(ctx context.Context, client client.Client, cr *rvb2.Redis)
 
 // finalizeRedisClusterPVC delete PVCs
 func finalizeRedisClusterPVC(ctx context.Context, client client.Client, cr *rcvb2.RedisCluster) error {
+	var dataPVCs []string
+	for _, role := range []string{"leader", "follower"} {
+		for i := 0; i < int(cr.Spec.GetReplicaCounts(role)); i++ {
+			dataPVCs = append(dataPVCs, DataPVCName(cr.Name+"-"+role, i))
+		}
+	}
+	if err := waitForVolumeSnapshots(ctx, client, cr.Namespace, dataPVCs); err != nil {
+		return err
+	}
 	for _, role := range []string{"leader", "follower"} {
 		for i := 0; i < int(cr.Spec.GetReplicaCounts(role)); i++ {
 			pvcTemplateName := env.GetString(common.EnvOperatorSTSPVCTemplateName, cr.Name+"-"+role)
@@ -152,6 +164,13 @@ func finalizeRedisClusterPVC(ctx context.Context, client client.Client, cr *rcvb
 
 // finalizeRedisReplicationPVC delete PVCs
 func finalizeRedisReplicationPVC(ctx context.Context, client client.Client, cr *rrvb2.RedisReplication) error {
+	var dataPVCs []string
+	for i := 0; i < int(cr.Spec.GetReplicationCounts("replication")); i++ {
+		dataPVCs = append(dataPVCs, DataPVCName(cr.Name, i))
+	}
+	if err := waitForVolumeSnapshots(ctx, client, cr.Namespace, dataPVCs); err != nil {
+		return err
+	}
 	for i := 0; i < int(cr.Spec.GetReplicationCounts("replication")); i++ {
 		pvcTemplateName := env.GetString(common.EnvOperatorSTSPVCTemplateName, cr.Name)
 		PVCName := fmt.Sprintf("%s-%s-%d", pvcTemplateName, cr.Name, i)
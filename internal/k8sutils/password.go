@@ -0,0 +1,327 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
+	redis "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// The redisSecret only holds the new password once it is changed, so the operator keeps the password the
+// servers of a resource accept in a Secret of its own. While a rotation runs, that Secret holds the old
+// password too.
+const (
+	appliedPasswordKey  = "password"
+	previousPasswordKey = "previous"
+)
+
+// AppliedPasswordSecretName returns the name of the Secret in which the operator keeps the password the
+// servers of the resource name accept.
+func AppliedPasswordSecretName(name string) string {
+	return name + "-applied-password"
+}
+
+// passwordRotation is what a rotation of the password needs to know about a resource.
+type passwordRotation struct {
+	status *commonapi.PasswordRotationStatus
+	secret *commonapi.ExistingPasswordSecret
+	// applied is the meta of the Secret the applied password is kept in.
+	applied metav1.ObjectMeta
+	nodes   func() ([]corev1.Pod, error)
+	// sentinels returns the sentinels that monitor the master as masterName with the password.
+	sentinels  func() ([]corev1.Pod, error)
+	masterName string
+	// skipMasterAuth skips the UpdateMasterAuth phase, for a server without replicas.
+	skipMasterAuth bool
+	// connectNode returns a client of the server of pod authenticating with password.
+	connectNode     func(pod *corev1.Pod, password string) *redis.Client
+	connectSentinel func(pod *corev1.Pod) *redis.Client
+}
+
+// RotateRedisPassword applies a change of the password of the redisSecret of cr to its running server.
+// See rotatePassword.
+func RotateRedisPassword(ctx context.Context, client kubernetes.Interface, cr *rvb2.Redis) (*commonapi.PasswordRotationStatus, error) {
+	if cr.Spec.KubernetesConfig.ExistingPasswordSecret == nil {
+		return cr.Status.PasswordRotation, nil
+	}
+	applied := generateObjectMetaInformation(AppliedPasswordSecretName(cr.Name), cr.Namespace,
+		getRedisLabels(cr.Name, standalone, "standalone", cr.GetLabels()), nil)
+	AddOwnerRefToObject(&applied, redisAsOwner(cr))
+	return rotatePassword(ctx, client, passwordRotation{
+		status:  cr.Status.PasswordRotation,
+		secret:  cr.Spec.KubernetesConfig.ExistingPasswordSecret,
+		applied: applied,
+		nodes: func() ([]corev1.Pod, error) {
+			pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, cr.Name+"-0", metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return []corev1.Pod{*pod}, nil
+		},
+		connectNode: func(pod *corev1.Pod, password string) *redis.Client {
+			return withPassword(configureRedisStandaloneClient(ctx, client, cr, pod.Name), pod.Name, password)
+		},
+		skipMasterAuth: true,
+	})
+}
+
+// RotateRedisClusterPassword applies a change of the password of the redisSecret of cr to its running
+// nodes. See rotatePassword.
+func RotateRedisClusterPassword(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (*commonapi.PasswordRotationStatus, error) {
+	if cr.Spec.KubernetesConfig.ExistingPasswordSecret == nil {
+		return cr.Status.PasswordRotation, nil
+	}
+	applied := generateObjectMetaInformation(AppliedPasswordSecretName(cr.Name), cr.Namespace,
+		getRedisLabels(cr.Name, cluster, "cluster", cr.GetLabels()), nil)
+	AddOwnerRefToObject(&applied, redisClusterAsOwner(cr))
+	return rotatePassword(ctx, client, passwordRotation{
+		status:  cr.Status.PasswordRotation,
+		secret:  cr.Spec.KubernetesConfig.ExistingPasswordSecret,
+		applied: applied,
+		nodes: func() ([]corev1.Pod, error) {
+			return clusterPods(ctx, client, cr), nil
+		},
+		connectNode: func(pod *corev1.Pod, password string) *redis.Client {
+			return withPassword(configureRedisClient(ctx, client, cr, pod.Name), pod.Name, password)
+		},
+	})
+}
+
+// RotateRedisReplicationPassword applies a change of the password of the redisSecret of cr to its
+// running servers and sentinels. See rotatePassword.
+func RotateRedisReplicationPassword(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication) (*commonapi.PasswordRotationStatus, error) {
+	if cr.Spec.KubernetesConfig.ExistingPasswordSecret == nil {
+		return cr.Status.PasswordRotation, nil
+	}
+	applied := generateObjectMetaInformation(AppliedPasswordSecretName(cr.Name), cr.Namespace,
+		getRedisLabels(cr.Name, replication, "replication", cr.GetLabels()), nil)
+	AddOwnerRefToObject(&applied, redisReplicationAsOwner(cr))
+	in := passwordRotation{
+		status:  cr.Status.PasswordRotation,
+		secret:  cr.Spec.KubernetesConfig.ExistingPasswordSecret,
+		applied: applied,
+		nodes: func() ([]corev1.Pod, error) {
+			return replicationPods(ctx, client, cr)
+		},
+		connectNode: func(pod *corev1.Pod, password string) *redis.Client {
+			return withPassword(configureRedisReplicationClientForPod(ctx, client, cr, pod), pod.Name, password)
+		},
+	}
+	if cr.EnableSentinel() {
+		in.masterName = cr.SentinelMasterName()
+		in.sentinels = func() ([]corev1.Pod, error) {
+			selector := labels.SelectorFromSet(getRedisLabels(cr.SentinelStatefulSet(), sentinel, "sentinel", cr.GetLabels()))
+			pods, err := client.CoreV1().Pods(cr.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+			if err != nil {
+				return nil, fmt.Errorf("list sentinel pods: %w", err)
+			}
+			return pods.Items, nil
+		}
+		in.connectSentinel = func(pod *corev1.Pod) *redis.Client {
+			password, err := sentinelPassword(ctx, client, cr)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "Error in getting sentinel password")
+			}
+			return tracing.InstrumentRedis(redis.NewClient(&redis.Options{
+				Addr:         formatRedisAddress(pod.Status.PodIP, common.SentinelPort),
+				Password:     password,
+				DialTimeout:  defaultRedisClientTimeout,
+				ReadTimeout:  defaultRedisClientTimeout,
+				WriteTimeout: defaultRedisClientTimeout,
+			}), pod.Name)
+		}
+	}
+	return rotatePassword(ctx, client, in)
+}
+
+// rotatePassword moves the servers of a resource from the applied password to the one of the
+// redisSecret without restarting them, one phase per call:
+//
+//   - AddPassword adds the new password to the default user with ACL SETUSER, so the servers accept both.
+//   - UpdateMasterAuth sets masterauth to the new password with CONFIG SET, and the auth-pass of the
+//     master on the sentinels with SENTINEL SET. It is skipped for a standalone server.
+//   - RemovePassword sets requirepass to the new password with CONFIG SET, which drops the old one.
+//
+// Pods that restart during the rotation read the new password from the redisSecret. A change of the
+// redisSecret during a rotation is rotated to once the rotation completes. The first call only records
+// the password of the redisSecret as applied. It returns the status of the rotation, nil when none ran
+// yet.
+func rotatePassword(ctx context.Context, client kubernetes.Interface, in passwordRotation) (*commonapi.PasswordRotationStatus, error) {
+	namespace := in.applied.Namespace
+	secret, err := client.CoreV1().Secrets(namespace).Get(ctx, *in.secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return in.status, fmt.Errorf("get the redisSecret: %w", err)
+	}
+	password := strings.TrimSpace(string(secret.Data[*in.secret.Key]))
+	if password == "" {
+		return in.status, fmt.Errorf("the redisSecret %s holds no password under the key %s", secret.Name, *in.secret.Key)
+	}
+
+	applied, err := client.CoreV1().Secrets(namespace).Get(ctx, in.applied.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		applied = &corev1.Secret{ObjectMeta: in.applied, Data: map[string][]byte{appliedPasswordKey: []byte(password)}}
+		if _, err := client.CoreV1().Secrets(namespace).Create(ctx, applied, metav1.CreateOptions{}); err != nil {
+			return in.status, fmt.Errorf("record the applied password: %w", err)
+		}
+		return in.status, nil
+	}
+	if err != nil {
+		return in.status, fmt.Errorf("get the applied password: %w", err)
+	}
+
+	current := string(applied.Data[appliedPasswordKey])
+	previous, rotating := applied.Data[previousPasswordKey]
+	if !rotating {
+		if current == password {
+			return in.status, nil
+		}
+		applied.Data = map[string][]byte{appliedPasswordKey: []byte(password), previousPasswordKey: []byte(current)}
+		if _, err := client.CoreV1().Secrets(namespace).Update(ctx, applied, metav1.UpdateOptions{}); err != nil {
+			return in.status, fmt.Errorf("record the password to rotate to: %w", err)
+		}
+		log.FromContext(ctx).Info("Rotating the password of the redisSecret", "Secret", secret.Name)
+		return newPasswordRotationStatus(commonapi.PasswordRotationAddPassword, secret.ResourceVersion), nil
+	}
+
+	status := in.status
+	if status == nil || status.Phase == commonapi.PasswordRotationCompleted {
+		// The status of the rotation wasn't recorded when it started.
+		status = newPasswordRotationStatus(commonapi.PasswordRotationAddPassword, secret.ResourceVersion)
+	}
+	nodes, err := in.nodes()
+	if err != nil {
+		return status, err
+	}
+	passwords := []string{current, string(previous)}
+	var next commonapi.PasswordRotationPhase
+	switch status.Phase {
+	case commonapi.PasswordRotationAddPassword:
+		err = forEachServer(ctx, nodes, passwords, in.connectNode, func(redisClient *redis.Client) error {
+			return redisClient.Do(ctx, "ACL", "SETUSER", "default", ">"+current).Err()
+		})
+		next = commonapi.PasswordRotationUpdateMasterAuth
+		if in.skipMasterAuth {
+			next = commonapi.PasswordRotationRemovePassword
+		}
+	case commonapi.PasswordRotationUpdateMasterAuth:
+		err = forEachServer(ctx, nodes, passwords, in.connectNode, func(redisClient *redis.Client) error {
+			return redisClient.ConfigSet(ctx, "masterauth", current).Err()
+		})
+		if err == nil && in.sentinels != nil {
+			err = setSentinelAuthPass(ctx, in, current)
+		}
+		next = commonapi.PasswordRotationRemovePassword
+	case commonapi.PasswordRotationRemovePassword:
+		err = forEachServer(ctx, nodes, passwords, in.connectNode, func(redisClient *redis.Client) error {
+			return redisClient.ConfigSet(ctx, "requirepass", current).Err()
+		})
+		if err == nil {
+			delete(applied.Data, previousPasswordKey)
+			_, err = client.CoreV1().Secrets(namespace).Update(ctx, applied, metav1.UpdateOptions{})
+		}
+		next = commonapi.PasswordRotationCompleted
+	}
+	if err != nil {
+		return status, fmt.Errorf("%s phase of the password rotation: %w", status.Phase, err)
+	}
+	log.FromContext(ctx).Info("Password rotation phase completed", "Phase", status.Phase, "Next", next)
+	return newPasswordRotationStatus(next, status.SecretResourceVersion), nil
+}
+
+func newPasswordRotationStatus(phase commonapi.PasswordRotationPhase, secretResourceVersion string) *commonapi.PasswordRotationStatus {
+	now := metav1.Now()
+	return &commonapi.PasswordRotationStatus{Phase: phase, SecretResourceVersion: secretResourceVersion, LastTransitionTime: &now}
+}
+
+// forEachServer runs cmd on the servers of the running pods, authenticating with the first of passwords
+// they accept. Pods that are starting or terminating are skipped, they start with the new password.
+func forEachServer(ctx context.Context, pods []corev1.Pod, passwords []string, connect func(*corev1.Pod, string) *redis.Client, cmd func(*redis.Client) error) error {
+	var errs []error
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		redisClient, err := authenticate(ctx, pod, passwords, connect)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pod %s: %w", pod.Name, err))
+			continue
+		}
+		if err := cmd(redisClient); err != nil {
+			errs = append(errs, fmt.Errorf("pod %s: %w", pod.Name, err))
+		}
+		redisClient.Close()
+	}
+	return errors.Join(errs...)
+}
+
+// authenticate returns a client of the server of pod authenticated with the first of passwords it
+// accepts.
+func authenticate(ctx context.Context, pod *corev1.Pod, passwords []string, connect func(*corev1.Pod, string) *redis.Client) (*redis.Client, error) {
+	var err error
+	for _, password := range passwords {
+		redisClient := connect(pod, password)
+		if err = redisClient.Ping(ctx).Err(); err == nil {
+			return redisClient, nil
+		}
+		redisClient.Close()
+		if !isAuthError(err) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("neither the new nor the old password is accepted: %w", err)
+}
+
+// isAuthError returns whether err is the reply of a server refusing a password.
+func isAuthError(err error) bool {
+	message := err.Error()
+	return strings.HasPrefix(message, "WRONGPASS") || strings.HasPrefix(message, "NOAUTH") ||
+		strings.Contains(message, "invalid password")
+}
+
+// setSentinelAuthPass sets the password the sentinels of in authenticate to the master with.
+func setSentinelAuthPass(ctx context.Context, in passwordRotation, password string) error {
+	sentinels, err := in.sentinels()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range sentinels {
+		pod := &sentinels[i]
+		if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		redisClient := in.connectSentinel(pod)
+		if err := redisClient.Do(ctx, "SENTINEL", "SET", in.masterName, "auth-pass", password).Err(); err != nil {
+			errs = append(errs, fmt.Errorf("sentinel %s: %w", pod.Name, err))
+		}
+		redisClient.Close()
+	}
+	return errors.Join(errs...)
+}
+
+// withPassword returns a client of the server of redisClient, running in pod, authenticating with
+// password, and closes redisClient.
+func withPassword(redisClient *redis.Client, pod, password string) *redis.Client {
+	opts := *redisClient.Options()
+	redisClient.Close()
+	opts.Password = password
+	return tracing.InstrumentRedis(redis.NewClient(&opts), pod)
+}
@@ -0,0 +1,212 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"github.com/go-redis/redismock/v9"
+	redis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sClientFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func passwordTestSecret(name string, data map[string]string) *corev1.Secret {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Data: map[string][]byte{}}
+	for k, v := range data {
+		secret.Data[k] = []byte(v)
+	}
+	return secret
+}
+
+func Test_rotatePassword(t *testing.T) {
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-0"}, Status: corev1.PodStatus{PodIP: "10.0.0.1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-1"}, Status: corev1.PodStatus{PodIP: "10.0.0.2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "redis-2"}},
+	}
+	wrongPass := errors.New("WRONGPASS invalid username-password pair or user is disabled.")
+	redisSecret := passwordTestSecret("redis-secret", map[string]string{"password": "new\n"})
+
+	tests := []struct {
+		name    string
+		applied *corev1.Secret
+		status  *commonapi.PasswordRotationStatus
+		// expect sets the expectations of the server of pod authenticating with password, nil when the
+		// test doesn't connect with it.
+		expect       func(mock redismock.ClientMock, pod, password string)
+		sentinel     func(mock redismock.ClientMock)
+		standalone   bool
+		wantPhase    commonapi.PasswordRotationPhase
+		wantApplied  map[string]string
+		wantErr      string
+		wantNoStatus bool
+	}{
+		{
+			name:         "first call records the applied password",
+			wantApplied:  map[string]string{"password": "new"},
+			wantNoStatus: true,
+		},
+		{
+			name:         "unchanged password",
+			applied:      passwordTestSecret("redis-applied-password", map[string]string{"password": "new"}),
+			wantApplied:  map[string]string{"password": "new"},
+			wantNoStatus: true,
+		},
+		{
+			name:        "changed password starts a rotation",
+			applied:     passwordTestSecret("redis-applied-password", map[string]string{"password": "old"}),
+			status:      &commonapi.PasswordRotationStatus{Phase: commonapi.PasswordRotationCompleted},
+			wantPhase:   commonapi.PasswordRotationAddPassword,
+			wantApplied: map[string]string{"password": "new", "previous": "old"},
+		},
+		{
+			name:    "new password added next to the old one",
+			applied: passwordTestSecret("redis-applied-password", map[string]string{"password": "new", "previous": "old"}),
+			status:  &commonapi.PasswordRotationStatus{Phase: commonapi.PasswordRotationAddPassword},
+			expect: func(mock redismock.ClientMock, pod, password string) {
+				// redis-0 only accepts the old password, redis-1 restarted with the new one.
+				if pod == "redis-0" && password == "new" {
+					mock.ExpectPing().SetErr(wrongPass)
+					return
+				}
+				mock.ExpectPing().SetVal("PONG")
+				mock.ExpectDo("ACL", "SETUSER", "default", ">new").SetVal("OK")
+			},
+			wantPhase:   commonapi.PasswordRotationUpdateMasterAuth,
+			wantApplied: map[string]string{"password": "new", "previous": "old"},
+		},
+		{
+			name:    "standalone server skips masterauth",
+			applied: passwordTestSecret("redis-applied-password", map[string]string{"password": "new", "previous": "old"}),
+			status:  &commonapi.PasswordRotationStatus{Phase: commonapi.PasswordRotationAddPassword},
+			expect: func(mock redismock.ClientMock, pod, password string) {
+				mock.ExpectPing().SetVal("PONG")
+				mock.ExpectDo("ACL", "SETUSER", "default", ">new").SetVal("OK")
+			},
+			standalone:  true,
+			wantPhase:   commonapi.PasswordRotationRemovePassword,
+			wantApplied: map[string]string{"password": "new", "previous": "old"},
+		},
+		{
+			name:    "masterauth and sentinel auth-pass updated",
+			applied: passwordTestSecret("redis-applied-password", map[string]string{"password": "new", "previous": "old"}),
+			status:  &commonapi.PasswordRotationStatus{Phase: commonapi.PasswordRotationUpdateMasterAuth},
+			expect: func(mock redismock.ClientMock, pod, password string) {
+				mock.ExpectPing().SetVal("PONG")
+				mock.ExpectConfigSet("masterauth", "new").SetVal("OK")
+			},
+			sentinel: func(mock redismock.ClientMock) {
+				mock.ExpectDo("SENTINEL", "SET", "mymaster", "auth-pass", "new").SetVal("OK")
+			},
+			wantPhase:   commonapi.PasswordRotationRemovePassword,
+			wantApplied: map[string]string{"password": "new", "previous": "old"},
+		},
+		{
+			name:    "old password removed",
+			applied: passwordTestSecret("redis-applied-password", map[string]string{"password": "new", "previous": "old"}),
+			status:  &commonapi.PasswordRotationStatus{Phase: commonapi.PasswordRotationRemovePassword},
+			expect: func(mock redismock.ClientMock, pod, password string) {
+				mock.ExpectPing().SetVal("PONG")
+				mock.ExpectConfigSet("requirepass", "new").SetVal("OK")
+			},
+			wantPhase:   commonapi.PasswordRotationCompleted,
+			wantApplied: map[string]string{"password": "new"},
+		},
+		{
+			name:    "rotation resumed when its start wasn't recorded",
+			applied: passwordTestSecret("redis-applied-password", map[string]string{"password": "new", "previous": "old"}),
+			expect: func(mock redismock.ClientMock, pod, password string) {
+				mock.ExpectPing().SetVal("PONG")
+				mock.ExpectDo("ACL", "SETUSER", "default", ">new").SetVal("OK")
+			},
+			wantPhase:   commonapi.PasswordRotationUpdateMasterAuth,
+			wantApplied: map[string]string{"password": "new", "previous": "old"},
+		},
+		{
+			name:    "server accepting neither password halts the rotation",
+			applied: passwordTestSecret("redis-applied-password", map[string]string{"password": "new", "previous": "old"}),
+			status:  &commonapi.PasswordRotationStatus{Phase: commonapi.PasswordRotationAddPassword},
+			expect: func(mock redismock.ClientMock, pod, password string) {
+				if pod == "redis-1" {
+					mock.ExpectPing().SetErr(wrongPass)
+					return
+				}
+				mock.ExpectPing().SetVal("PONG")
+				mock.ExpectDo("ACL", "SETUSER", "default", ">new").SetVal("OK")
+			},
+			wantPhase:   commonapi.PasswordRotationAddPassword,
+			wantApplied: map[string]string{"password": "new", "previous": "old"},
+			wantErr:     "pod redis-1: neither the new nor the old password is accepted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []runtime.Object{redisSecret.DeepCopy()}
+			if tt.applied != nil {
+				objects = append(objects, tt.applied)
+			}
+			client := k8sClientFake.NewSimpleClientset(objects...)
+
+			var mocks []redismock.ClientMock
+			in := passwordRotation{
+				status:  tt.status,
+				secret:  &commonapi.ExistingPasswordSecret{Name: ptr.To("redis-secret"), Key: ptr.To("password")},
+				applied: metav1.ObjectMeta{Name: "redis-applied-password", Namespace: "default"},
+				nodes: func() ([]corev1.Pod, error) {
+					return pods, nil
+				},
+				connectNode: func(pod *corev1.Pod, password string) *redis.Client {
+					redisClient, mock := redismock.NewClientMock()
+					tt.expect(mock, pod.Name, password)
+					mocks = append(mocks, mock)
+					return redisClient
+				},
+				skipMasterAuth: tt.standalone,
+			}
+			if tt.sentinel != nil {
+				in.masterName = "mymaster"
+				in.sentinels = func() ([]corev1.Pod, error) {
+					return pods[:1], nil
+				}
+				in.connectSentinel = func(pod *corev1.Pod) *redis.Client {
+					redisClient, mock := redismock.NewClientMock()
+					tt.sentinel(mock)
+					mocks = append(mocks, mock)
+					return redisClient
+				}
+			}
+
+			got, err := rotatePassword(context.TODO(), client, in)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.wantNoStatus {
+				assert.Nil(t, got)
+			} else {
+				require.NotNil(t, got)
+				assert.Equal(t, tt.wantPhase, got.Phase)
+			}
+			for _, mock := range mocks {
+				assert.NoError(t, mock.ExpectationsWereMet())
+			}
+
+			applied, err := client.CoreV1().Secrets("default").Get(context.TODO(), "redis-applied-password", metav1.GetOptions{})
+			require.NoError(t, err)
+			data := map[string]string{}
+			for k, v := range applied.Data {
+				data[k] = string(v)
+			}
+			assert.Equal(t, tt.wantApplied, data)
+		})
+	}
+}
@@ -1,11 +1,18 @@
 package k8sutils
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
 
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -22,3 +29,45 @@ func IsPodRunning(ctx context.Context, client kubernetes.Interface, namespace, p
 	}
 	return pod.Status.Phase == corev1.PodRunning
 }
+
+// StreamPodFile copies the file at path inside the redis container of the pod to w.
+// The stream is not bounded by a timeout, since
+// dump files can take arbitrarily long to transfer; cancel ctx to abort it.
+func StreamPodFile(ctx context.Context, client kubernetes.Interface, pod *corev1.Pod, path string, w io.Writer) (err error) {
+	ctx, span := tracing.Start(ctx, "exec cat",
+		semconv.K8SNamespaceName(pod.Namespace),
+		semconv.K8SPodName(pod.Name),
+		semconv.ProcessCommand("cat"),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if len(pod.Spec.Containers) == 0 {
+		return fmt.Errorf("pod %s has no containers", pod.Name)
+	}
+	config, err := GenerateK8sConfig()()
+	if err != nil {
+		return err
+	}
+
+	var execErr bytes.Buffer
+	req := client.CoreV1().RESTClient().Post().Resource("pods").Name(pod.Name).Namespace(pod.Namespace).SubResource("exec")
+	req.VersionedParams(&corev1.PodExecOptions{
+		// The redis server is always the first container, see generateContainerDef.
+		Container: pod.Spec.Containers[0].Name,
+		Command:   []string{"cat", path},
+		Stdout:    true,
+		Stderr:    true,
+	}, scheme.ParameterCodec)
+	exec, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: w,
+		Stderr: &execErr,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read %s from pod %s: %w, stderr: %s", path, pod.Name, err, execErr.String())
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package k8sutils
+
+import (
+	"fmt"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util/maps"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// alertRule is an alert of the PrometheusRule of a resource.
+type alertRule struct {
+	alert       string
+	expr        string
+	duration    string
+	severity    string
+	summary     string
+	description string
+}
+
+// defaultAlertRules returns the alerts of setup on the series of the exporters matching selector, a list
+// of label matchers. The servers alert when they are down, close to their memory or connection limits, or
+// lose their master. A replication alerts when it has no master or more than one, a cluster when its state
+// or slots fail, and sentinels when they see their master down or without healthy replicas.
+func defaultAlertRules(setup setupType, selector string) []alertRule {
+	with := func(matchers ...string) string {
+		s := selector
+		for _, m := range matchers {
+			s += "," + m
+		}
+		return "{" + s + "}"
+	}
+	if setup == sentinel {
+		return []alertRule{
+			{
+				alert: "RedisSentinelDown", expr: fmt.Sprintf("redis_up%s == 0", with()), duration: "1m", severity: "critical",
+				summary:     "Redis sentinel is down",
+				description: "The sentinel {{ $labels.pod }} in {{ $labels.namespace }} is down.",
+			},
+			{
+				alert: "RedisSentinelMasterDown", expr: fmt.Sprintf("redis_sentinel_master_status%s == 0", with()), duration: "1m", severity: "critical",
+				summary:     "Redis sentinel sees its master down",
+				description: "The sentinel {{ $labels.pod }} in {{ $labels.namespace }} sees the master {{ $labels.master_name }} down.",
+			},
+			{
+				alert: "RedisSentinelNoHealthyReplica", expr: fmt.Sprintf("redis_sentinel_master_ok_slaves%s == 0", with()), duration: "5m", severity: "warning",
+				summary:     "Redis master has no healthy replica to fail over to",
+				description: "The sentinel {{ $labels.pod }} in {{ $labels.namespace }} sees no healthy replica of the master {{ $labels.master_name }}.",
+			},
+		}
+	}
+
+	rules := []alertRule{
+		{
+			alert: "RedisDown", expr: fmt.Sprintf("redis_up%s == 0", with()), duration: "1m", severity: "critical",
+			summary:     "Redis is down",
+			description: "Redis {{ $labels.pod }} in {{ $labels.namespace }} is down.",
+		},
+		{
+			alert: "RedisMemoryNearLimit", expr: fmt.Sprintf("redis_memory_used_bytes%s / (redis_memory_max_bytes%s > 0) > 0.9", with(), with()),
+			duration: "5m", severity: "warning",
+			summary:     "Redis is close to its maxmemory",
+			description: "Redis {{ $labels.pod }} in {{ $labels.namespace }} uses {{ $value | humanizePercentage }} of its maxmemory.",
+		},
+		{
+			alert: "RedisTooManyConnections", expr: fmt.Sprintf("redis_connected_clients%s / redis_config_maxclients%s > 0.9", with(), with()),
+			duration: "5m", severity: "warning",
+			summary:     "Redis is close to its maxclients",
+			description: "Redis {{ $labels.pod }} in {{ $labels.namespace }} serves {{ $value | humanizePercentage }} of its maxclients.",
+		},
+		{
+			alert: "RedisRejectedConnections", expr: fmt.Sprintf("increase(redis_rejected_connections_total%s[5m]) > 0", with()), severity: "warning",
+			summary:     "Redis rejects connections",
+			description: "Redis {{ $labels.pod }} in {{ $labels.namespace }} rejected connections in the last 5 minutes.",
+		},
+	}
+	if setup == standalone {
+		return rules
+	}
+	rules = append(rules, alertRule{
+		alert: "RedisReplicationBroken", expr: fmt.Sprintf("redis_master_link_up%s == 0", with()), duration: "2m", severity: "critical",
+		summary:     "Redis replica lost its master",
+		description: "The replica {{ $labels.pod }} in {{ $labels.namespace }} is not connected to its master.",
+	})
+	if setup == replication {
+		return append(rules,
+			alertRule{
+				alert: "RedisMasterMissing",
+				expr: fmt.Sprintf(`count(redis_up%s == 1) > 0 unless count(redis_instance_info%s) > 0`,
+					with(), with(`role="master"`)),
+				duration: "1m", severity: "critical",
+				summary:     "Redis replication has no master",
+				description: "No pod of the replication is a master.",
+			},
+			alertRule{
+				alert: "RedisMultipleMasters", expr: fmt.Sprintf(`count(redis_instance_info%s) > 1`, with(`role="master"`)),
+				duration: "1m", severity: "critical",
+				summary:     "Redis replication has more than one master",
+				description: "{{ $value }} pods of the replication are masters.",
+			},
+		)
+	}
+	return append(rules,
+		alertRule{
+			alert: "RedisClusterStateFail", expr: fmt.Sprintf("redis_cluster_state%s == 0", with()), duration: "1m", severity: "critical",
+			summary:     "Redis cluster is down",
+			description: "The node {{ $labels.pod }} in {{ $labels.namespace }} reports the cluster state fail.",
+		},
+		alertRule{
+			alert: "RedisClusterSlotsFailing", expr: fmt.Sprintf("redis_cluster_slots_fail%s + redis_cluster_slots_pfail%s > 0", with(), with()),
+			duration: "1m", severity: "critical",
+			summary:     "Redis cluster slots are not served",
+			description: "The node {{ $labels.pod }} in {{ $labels.namespace }} sees {{ $value }} slots on failing nodes.",
+		},
+	)
+}
+
+// newPrometheusRule returns a PrometheusRule with rules in a group named after the resource.
+func newPrometheusRule(namespace, name, crName string, setup setupType, config *commonapi.PrometheusRuleConfig, rules []alertRule) *unstructured.Unstructured {
+	ruleLabels := map[string]string{}
+	for k, v := range config.AdditionalLabels {
+		ruleLabels[k] = v
+	}
+	items := make([]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		alertLabels := map[string]interface{}{"severity": rule.severity}
+		for k, v := range ruleLabels {
+			alertLabels[k] = v
+		}
+		item := map[string]interface{}{
+			"alert":  rule.alert,
+			"expr":   rule.expr,
+			"labels": alertLabels,
+			"annotations": map[string]interface{}{
+				"summary":     rule.summary,
+				"description": rule.description,
+			},
+		}
+		if rule.duration != "" {
+			item["for"] = rule.duration
+		}
+		items = append(items, item)
+	}
+	resourceLabels := map[string]string{"app": crName, "redis_setup_type": string(setup)}
+
+	prometheusRule := &unstructured.Unstructured{}
+	prometheusRule.SetGroupVersionKind(PrometheusRuleGVK)
+	prometheusRule.SetNamespace(namespace)
+	prometheusRule.SetName(name)
+	prometheusRule.SetLabels(maps.Merge(resourceLabels, config.Labels))
+	prometheusRule.Object["spec"] = map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name":  namespace + "." + crName,
+				"rules": items,
+			},
+		},
+	}
+	return prometheusRule
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -101,12 +102,18 @@ func HandlePVCResizing(ctx context.Context, storedStateful, newStateful *appsv1.
 		}
 		currentCapacity := pvc.Spec.Resources.Requests.Storage().Value()
 		if currentCapacity != desiredCapacity {
+			currentSize := pvc.Spec.Resources.Requests.Storage().String()
 			pvc.Spec.Resources.Requests = newStateful.Spec.VolumeClaimTemplates[targetIndex].Spec.Resources.Requests
+			podName := strings.TrimPrefix(pvc.Name, pvcPrefix)
 			if _, err := cl.CoreV1().PersistentVolumeClaims(storedStateful.Namespace).Update(context.Background(), pvc, metav1.UpdateOptions{}); err != nil {
 				updateFailed = true
 				log.FromContext(ctx).Error(fmt.Errorf("sts:%s resize pvc [%s] failed: %s", storedStateful.Name, pvc.Name, err.Error()), "")
+				events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisPVCResizeFailed, podName,
+					fmt.Sprintf("Failed to resize %s of %s to %s: %v", pvc.Name, podName, pvc.Spec.Resources.Requests.Storage(), err))
 			} else {
 				log.FromContext(ctx).Info(fmt.Sprintf("sts:%s resized pvc [%s] from %d to %d", storedStateful.Name, pvc.Name, currentCapacity, desiredCapacity))
+				events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisPVCResized, podName,
+					fmt.Sprintf("Resized %s of %s from %s to %s", pvc.Name, podName, currentSize, pvc.Spec.Resources.Requests.Storage()))
 			}
 		}
 	}
@@ -3,9 +3,11 @@ package k8sutils
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strconv"
 	"testing"
 
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -80,7 +82,8 @@ func TestHandlePVCResizing_NoUpdateNeeded(t *testing.T) {
 // TestHandlePVCResizing_UpdatePVC verifies that when the desired capacity differs for the target template,
 // the matching PVC is updated and the annotation is updated accordingly.
 func TestHandlePVCResizing_UpdatePVC(t *testing.T) {
-	ctx := context.Background()
+	recorder := events.NewRecorder()
+	ctx := events.IntoContext(context.Background(), recorder)
 
 	// Stored PVC spec with 5Gi and new spec with 10Gi for the target (redis-data).
 	storedQuantity := resource.MustParse("5Gi")
@@ -145,7 +148,7 @@ func TestHandlePVCResizing_UpdatePVC(t *testing.T) {
 	// Its name is expected to start with "redis-data-".
 	existingPVC := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "redis-data-0",
+			Name:      "redis-data-redis-0",
 			Namespace: "default",
 			Labels: map[string]string{
 				"app":                         "redis",
@@ -168,7 +171,7 @@ func TestHandlePVCResizing_UpdatePVC(t *testing.T) {
 	}
 
 	// Verify the PVC was updated.
-	updatedPVC, err := cl.CoreV1().PersistentVolumeClaims("default").Get(ctx, "redis-data-0", metav1.GetOptions{})
+	updatedPVC, err := cl.CoreV1().PersistentVolumeClaims("default").Get(ctx, "redis-data-redis-0", metav1.GetOptions{})
 	if err != nil {
 		t.Fatalf("Failed to get PVC: %v", err)
 	}
@@ -176,6 +179,17 @@ func TestHandlePVCResizing_UpdatePVC(t *testing.T) {
 	if updatedCapacity != desiredQuantity.Value() {
 		t.Errorf("Expected PVC capacity to be %d, got %d", desiredQuantity.Value(), updatedCapacity)
 	}
+
+	// Verify the resize was recorded as an event about the pod of the PVC.
+	want := []events.Event{{
+		EventType: corev1.EventTypeNormal,
+		Reason:    events.EventReasonRedisPVCResized,
+		Pod:       "redis-0",
+		Message:   "Resized redis-data-redis-0 of redis-0 from 5Gi to 10Gi",
+	}}
+	if got := recorder.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected events %v, got %v", want, got)
+	}
 }
 
 // TestHandlePVCResizing_ShrinkSkipped verifies that when the desired capacity is smaller than
@@ -276,7 +290,8 @@ func TestHandlePVCResizing_ShrinkSkipped(t *testing.T) {
 
 // TestHandlePVCResizing_UpdateFailure simulates a failure during PVC update and verifies that an error is returned.
 func TestHandlePVCResizing_UpdateFailure(t *testing.T) {
-	ctx := context.Background()
+	recorder := events.NewRecorder()
+	ctx := events.IntoContext(context.Background(), recorder)
 
 	// Stored PVC spec with 5Gi and new spec with 10Gi for the target (redis-data).
 	storedQuantity := resource.MustParse("5Gi")
@@ -340,7 +355,7 @@ func TestHandlePVCResizing_UpdateFailure(t *testing.T) {
 	// Create a fake PVC corresponding to the redis-data template.
 	existingPVC := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "redis-data-0",
+			Name:      "redis-data-redis-0",
 			Namespace: "default",
 			Labels: map[string]string{
 				"app":                         "redis",
@@ -360,4 +375,8 @@ func TestHandlePVCResizing_UpdateFailure(t *testing.T) {
 	if err == nil {
 		t.Fatalf("Expected error due to simulated update failure, got nil")
 	}
+	if got := recorder.Events(); len(got) != 1 || got[0].EventType != corev1.EventTypeWarning ||
+		got[0].Reason != events.EventReasonRedisPVCResizeFailed || got[0].Pod != "redis-0" {
+		t.Errorf("Expected a %s warning about redis-0, got %v", events.EventReasonRedisPVCResizeFailed, got)
+	}
 }
@@ -8,8 +8,10 @@ import (
 
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/features"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,6 +34,8 @@ type RedisClusterSTS struct {
 	NodeSelector                  map[string]string
 	TopologySpreadConstraints     []corev1.TopologySpreadConstraint
 	Tolerations                   *[]corev1.Toleration
+	// Restore seeds the data volume of every pod with the shard matching its ordinal.
+	Restore *RestoreSource
 }
 
 // RedisClusterService is a interface to call Redis Service function
@@ -67,6 +71,9 @@ func generateRedisClusterParams(ctx context.Context, cr *rcvb2.RedisCluster, rep
 	if cr.Spec.PodManagementPolicy != nil {
 		res.PodManagementPolicy = cr.Spec.PodManagementPolicy
 	}
+	if cr.Spec.ManagedUpgrade() {
+		res.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+	}
 
 	if cr.Spec.RedisExporter != nil {
 		res.EnableMetrics = cr.Spec.RedisExporter.Enabled
@@ -86,6 +93,9 @@ func generateRedisClusterParams(ctx context.Context, cr *rcvb2.RedisCluster, rep
 		res.RecreateStatefulSet = true
 		res.RecreateStatefulsetStrategy = getDeletionPropagationStrategy(cr.GetAnnotations())
 	}
+	if features.Enabled(features.DrainHandover) {
+		res.ReadinessGates = inServiceReadinessGates()
+	}
 	return res
 }
 
@@ -121,8 +131,8 @@ func generateRedisClusterInitContainerParams(cr *rcvb2.RedisCluster) initContain
 }
 
 // generateRedisClusterContainerParams generates Redis container information. It
-// returns an error when the per-pod NodePort Services required to build the
-// cluster announce variables cannot be read, so that an incomplete pod template
+// returns an error when the per-pod NodePort or LoadBalancer Services required to
+// build the cluster announce variables cannot be read, so that an incomplete pod template
 // is never handed to the StatefulSet reconciler.
 func generateRedisClusterContainerParams(ctx context.Context, cl kubernetes.Interface, cr *rcvb2.RedisCluster, securityContext *corev1.SecurityContext, readinessProbeDef *corev1.Probe, livenessProbeDef *corev1.Probe, role string, resources *corev1.ResourceRequirements) (containerParameters, error) {
 	trueProperty := true
@@ -130,6 +140,7 @@ func generateRedisClusterContainerParams(ctx context.Context, cl kubernetes.Inte
 	containerProp := containerParameters{
 		Role:            "cluster",
 		Image:           cr.Spec.KubernetesConfig.Image,
+		Engine:          cr.Spec.KubernetesConfig.GetEngine(),
 		ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
 		Resources:       resources,
 		SecurityContext: securityContext,
@@ -188,6 +199,17 @@ func generateRedisClusterContainerParams(ctx context.Context, cl kubernetes.Inte
 			})
 		}
 		containerProp.EnvVars = envVars
+	} else if cr.Spec.LoadBalancerAccess() {
+		announceEnvVars, err := clusterLoadBalancerAnnounceEnvVars(ctx, cl, cr, role)
+		if err != nil {
+			return containerParameters{}, err
+		}
+		envVars := &[]corev1.EnvVar{}
+		if containerProp.EnvVars != nil {
+			*envVars = append(*envVars, *containerProp.EnvVars...)
+		}
+		*envVars = append(*envVars, announceEnvVars...)
+		containerProp.EnvVars = envVars
 	}
 	if cr.Spec.Storage != nil {
 		containerProp.AdditionalVolume = cr.Spec.Storage.VolumeMount.Volume
@@ -256,8 +278,8 @@ func clusterAnnounceNodePorts(svc *corev1.Service) (int32, int32, error) {
 	return announcePort, announceBusPort, nil
 }
 
-// CreateRedisLeader will create a leader redis setup
-func CreateRedisLeader(ctx context.Context, cr *rcvb2.RedisCluster, cl kubernetes.Interface) error {
+// CreateRedisLeader will create a leader redis setup, seeding every leader with its shard from restore if set
+func CreateRedisLeader(ctx context.Context, cr *rcvb2.RedisCluster, cl kubernetes.Interface, restore *RestoreSource) error {
 	prop := RedisClusterSTS{
 		RedisStateFulType:             "leader",
 		Resources:                     cr.Spec.GetRedisLeaderResources(),
@@ -270,6 +292,7 @@ func CreateRedisLeader(ctx context.Context, cr *rcvb2.RedisCluster, cl kubernete
 		Tolerations:    cr.Spec.RedisLeader.Tolerations,
 		ReadinessProbe: cr.Spec.RedisLeader.ReadinessProbe,
 		LivenessProbe:  cr.Spec.RedisLeader.LivenessProbe,
+		Restore:        restore,
 	}
 	if cr.Spec.RedisLeader.RedisConfig != nil {
 		prop.ExternalConfig = cr.Spec.RedisLeader.RedisConfig.AdditionalRedisConfig
@@ -330,6 +353,17 @@ func (service RedisClusterSTS) CreateRedisClusterSetup(ctx context.Context, cr *
 		log.FromContext(ctx).Error(err, "Cannot generate container parameters for Redis", "Setup.Type", service.RedisStateFulType)
 		return err
 	}
+	if features.Enabled(features.DrainHandover) {
+		containerParams.HandoverWaitSeconds = handoverWaitSeconds(service.TerminationGracePeriodSeconds)
+	}
+	initContainerParams := generateRedisClusterInitContainerParams(cr)
+	if service.Restore != nil {
+		if containerParams.PersistenceEnabled == nil || !*containerParams.PersistenceEnabled {
+			return errRestoreWithoutStorage
+		}
+		initContainerParams.Restore = service.Restore
+		initContainerParams.RestorePerOrdinal = true
+	}
 	err = CreateOrUpdateStateFul(
 		ctx,
 		cl,
@@ -337,7 +371,7 @@ func (service RedisClusterSTS) CreateRedisClusterSetup(ctx context.Context, cr *
 		objectMetaInfo,
 		generateRedisClusterParams(ctx, cr, service.getReplicaCount(cr), service.ExternalConfig, service),
 		redisClusterAsOwner(cr),
-		generateRedisClusterInitContainerParams(cr),
+		initContainerParams,
 		containerParams,
 		cr.Spec.Sidecars,
 	)
@@ -415,6 +449,13 @@ func (service RedisClusterService) CreateRedisClusterService(ctx context.Context
 			return err
 		}
 	}
+	if cr.Spec.LoadBalancerAccess() {
+		err = service.createOrUpdateClusterLoadBalancerService(ctx, cr, cl)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Cannot create load balancer service for Redis", "Setup.Type", service.RedisServiceRole)
+			return err
+		}
+	}
 	additionalExtraPorts := []corev1.ServicePort{}
 	if cr.Spec.KubernetesConfig.ShouldIncludeBusPortForAdditional() {
 		additionalExtraPorts = append(additionalExtraPorts, busPort)
@@ -205,6 +205,7 @@ func Test_generateRedisClusterContainerParams(t *testing.T) {
 	path := filepath.Join("..", "..", "tests", "testdata", "redis-cluster.yaml")
 	expectedLeaderContainer := containerParameters{
 		Image:           "quay.io/opstree/redis:v7.0.12",
+		Engine:          common.EngineRedis,
 		ImagePullPolicy: corev1.PullPolicy("IfNotPresent"),
 		Resources: &corev1.ResourceRequirements{
 			Requests: corev1.ResourceList{
@@ -316,6 +317,7 @@ func Test_generateRedisClusterContainerParams(t *testing.T) {
 
 	expectedFollowerContainer := containerParameters{
 		Image:           "quay.io/opstree/redis:v7.0.12",
+		Engine:          common.EngineRedis,
 		ImagePullPolicy: corev1.PullPolicy("IfNotPresent"),
 		Resources: &corev1.ResourceRequirements{
 			Requests: corev1.ResourceList{
@@ -6,8 +6,10 @@ import (
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
 	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/features"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util/maps"
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -76,8 +78,16 @@ func CreateReplicationService(ctx context.Context, cr *rrvb2.RedisReplication, c
 	return nil
 }
 
-// CreateReplicationRedis will create a replication redis setup
-func CreateReplicationRedis(ctx context.Context, cr *rrvb2.RedisReplication, cl kubernetes.Interface) error {
+// CreateReplicationRedis will create a replication redis setup, seeding every data volume from restore if set
+func CreateReplicationRedis(ctx context.Context, cr *rrvb2.RedisReplication, cl kubernetes.Interface, restore *RestoreSource) error {
+	if restore != nil && !storageHasVolumeClaimTemplate(cr.Spec.Storage) {
+		return errRestoreWithoutStorage
+	}
+	if cr.Spec.AOFArchive != nil && !storageHasVolumeClaimTemplate(cr.Spec.Storage) {
+		return errAOFArchiveWithoutStorage
+	}
+	initContainerParams := generateRedisReplicationInitContainerParams(cr)
+	initContainerParams.Restore = restore
 	stateFulName := cr.Name
 	labels := getRedisLabels(cr.Name, replication, "replication", cr.Labels)
 	annotations := generateStatefulSetsAnots(cr.ObjectMeta, cr.Spec.KubernetesConfig.IgnoreAnnotations)
@@ -90,7 +100,7 @@ func CreateReplicationRedis(ctx context.Context, cr *rrvb2.RedisReplication, cl
 		objectMetaInfo,
 		generateRedisReplicationParams(cr),
 		redisReplicationAsOwner(cr),
-		generateRedisReplicationInitContainerParams(cr),
+		initContainerParams,
 		generateRedisReplicationContainerParams(cr),
 		cr.Spec.Sidecars,
 	)
@@ -127,6 +137,9 @@ func generateRedisReplicationParams(cr *rrvb2.RedisReplication) statefulSetParam
 	if cr.Spec.PodManagementPolicy != nil {
 		res.PodManagementPolicy = cr.Spec.PodManagementPolicy
 	}
+	if cr.ManagedUpgrade() {
+		res.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+	}
 
 	if cr.Spec.KubernetesConfig.ImagePullSecrets != nil {
 		res.ImagePullSecrets = cr.Spec.KubernetesConfig.ImagePullSecrets
@@ -147,6 +160,9 @@ func generateRedisReplicationParams(cr *rrvb2.RedisReplication) statefulSetParam
 		res.RecreateStatefulSet = true
 		res.RecreateStatefulsetStrategy = getDeletionPropagationStrategy(cr.GetAnnotations())
 	}
+	if features.Enabled(features.DrainHandover) {
+		res.ReadinessGates = inServiceReadinessGates()
+	}
 	return res
 }
 
@@ -157,6 +173,7 @@ func generateRedisReplicationContainerParams(cr *rrvb2.RedisReplication) contain
 	containerProp := containerParameters{
 		Role:            "replication",
 		Image:           cr.Spec.KubernetesConfig.Image,
+		Engine:          cr.Spec.KubernetesConfig.GetEngine(),
 		ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
 		Resources:       cr.Spec.KubernetesConfig.Resources,
 		SecurityContext: cr.Spec.SecurityContext,
@@ -201,6 +218,7 @@ func generateRedisReplicationContainerParams(cr *rrvb2.RedisReplication) contain
 	}
 	if storageHasVolumeClaimTemplate(cr.Spec.Storage) {
 		containerProp.PersistenceEnabled = &trueProperty
+		containerProp.AOFArchive = cr.Spec.AOFArchive
 	}
 	if cr.Spec.TLS != nil {
 		containerProp.TLSConfig = cr.Spec.TLS
@@ -217,6 +235,9 @@ func generateRedisReplicationContainerParams(cr *rrvb2.RedisReplication) contain
 		containerProp.SentinelMasterName = cr.SentinelMasterName()
 		containerProp.SentinelPort = 26379
 		containerProp.PreStopWaitSeconds = replicationPreStopWaitSeconds(cr.Spec.TerminationGracePeriodSeconds)
+		if features.Enabled(features.DrainHandover) {
+			containerProp.HandoverWaitSeconds = handoverWaitSeconds(cr.Spec.TerminationGracePeriodSeconds)
+		}
 	}
 	return containerProp
 }
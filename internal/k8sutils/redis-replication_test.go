@@ -109,6 +109,7 @@ func Test_generateRedisReplicationContainerParams(t *testing.T) {
 	path := filepath.Join("..", "..", "tests", "testdata", "redis-replication.yaml")
 	expected := containerParameters{
 		Image:           "quay.io/opstree/redis:v7.0.12",
+		Engine:          common.EngineRedis,
 		Port:            ptr.To(6379),
 		ImagePullPolicy: corev1.PullPolicy("IfNotPresent"),
 		Resources: &corev1.ResourceRequirements{
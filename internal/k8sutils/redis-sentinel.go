@@ -162,6 +162,7 @@ func generateRedisSentinelContainerParams(ctx context.Context, client kubernetes
 	containerProp := containerParameters{
 		Role:                  "sentinel",
 		Image:                 cr.Spec.KubernetesConfig.Image,
+		Engine:                cr.Spec.KubernetesConfig.GetEngine(),
 		ImagePullPolicy:       cr.Spec.KubernetesConfig.ImagePullPolicy,
 		Resources:             cr.Spec.KubernetesConfig.Resources,
 		SecurityContext:       cr.Spec.SecurityContext,
@@ -101,6 +101,7 @@ func Test_generateRedisSentinelContainerParams(t *testing.T) {
 	path := filepath.Join("..", "..", "tests", "testdata", "redis-sentinel.yaml")
 	expected := containerParameters{
 		Image:           "quay.io/opstree/redis:v7.0.12",
+		Engine:          common.EngineRedis,
 		ImagePullPolicy: corev1.PullPolicy("IfNotPresent"),
 		Resources: &corev1.ResourceRequirements{
 			Requests: corev1.ResourceList{
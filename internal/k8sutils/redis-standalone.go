@@ -80,8 +80,16 @@ func CreateStandaloneService(ctx context.Context, cr *rvb2.Redis, cl kubernetes.
 	return nil
 }
 
-// CreateStandaloneRedis will create a standalone redis setup
-func CreateStandaloneRedis(ctx context.Context, cr *rvb2.Redis, cl kubernetes.Interface) error {
+// CreateStandaloneRedis will create a standalone redis setup, seeding the data volume from restore if set
+func CreateStandaloneRedis(ctx context.Context, cr *rvb2.Redis, cl kubernetes.Interface, restore *RestoreSource) error {
+	if restore != nil && !storageHasVolumeClaimTemplate(cr.Spec.Storage) {
+		return errRestoreWithoutStorage
+	}
+	if cr.Spec.AOFArchive != nil && !storageHasVolumeClaimTemplate(cr.Spec.Storage) {
+		return errAOFArchiveWithoutStorage
+	}
+	initContainerParams := generateRedisStandaloneInitContainerParams(cr)
+	initContainerParams.Restore = restore
 	labels := getRedisLabels(cr.Name, standalone, "standalone", cr.Labels)
 	annotations := generateStatefulSetsAnots(cr.ObjectMeta, cr.Spec.KubernetesConfig.IgnoreAnnotations)
 	objectMetaInfo := generateObjectMetaInformation(cr.Name, cr.Namespace, labels, annotations)
@@ -92,7 +100,7 @@ func CreateStandaloneRedis(ctx context.Context, cr *rvb2.Redis, cl kubernetes.In
 		objectMetaInfo,
 		generateRedisStandaloneParams(cr),
 		redisAsOwner(cr),
-		generateRedisStandaloneInitContainerParams(cr),
+		initContainerParams,
 		generateRedisStandaloneContainerParams(cr),
 		cr.Spec.Sidecars,
 	)
@@ -154,6 +162,7 @@ func generateRedisStandaloneContainerParams(cr *rvb2.Redis) containerParameters
 	containerProp := containerParameters{
 		Role:            "standalone",
 		Image:           cr.Spec.KubernetesConfig.Image,
+		Engine:          cr.Spec.KubernetesConfig.GetEngine(),
 		ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
 		Resources:       cr.Spec.KubernetesConfig.Resources,
 		SecurityContext: cr.Spec.SecurityContext,
@@ -199,6 +208,7 @@ func generateRedisStandaloneContainerParams(cr *rvb2.Redis) containerParameters
 	}
 	if storageHasVolumeClaimTemplate(cr.Spec.Storage) {
 		containerProp.PersistenceEnabled = &trueProperty
+		containerProp.AOFArchive = cr.Spec.AOFArchive
 	}
 	if cr.Spec.TLS != nil {
 		containerProp.TLSConfig = cr.Spec.TLS
@@ -1,23 +1,24 @@
 package k8sutils
 
 import (
-	"bytes"
 	"context"
 	"encoding/csv"
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
-	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
 	common "github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
-	"github.com/OT-CONTAINER-KIT/redis-operator/internal/features"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
 	retry "github.com/avast/retry-go"
 	redis "github.com/redis/go-redis/v9"
@@ -26,8 +27,6 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/tools/remotecommand"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -83,7 +82,7 @@ func getEndpoint(ctx context.Context, client kubernetes.Interface, cr *rcvb2.Red
 		port int
 	)
 	port = *cr.Spec.Port
-	if cr.Spec.ClusterVersion != nil && util.IsRedisVersionAtLeastV7(*cr.Spec.ClusterVersion) {
+	if cr.Spec.ClusterVersion != nil && util.HasRedis7Features(string(cr.Spec.KubernetesConfig.GetEngine()), *cr.Spec.ClusterVersion) {
 		host = rd.FQDN()
 	} else {
 		host = getRedisServerIP(ctx, client, rd)
@@ -114,122 +113,18 @@ func getEndpoint(ctx context.Context, client kubernetes.Interface, cr *rcvb2.Red
 		}
 		host = pod.Status.HostIP
 	}
-	return host + ":" + strconv.Itoa(port)
-}
-
-// podExecFunc matches executeCommand's signature; it is injected into
-// executeSingleLeaderAddSlots so the command assembly and batching logic
-// can be unit tested without a live pod exec.
-type podExecFunc func(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, cmd []string, podName string)
-
-// checkRedisCLIAuthInEnv returns true if we can use the pod's REDISCLI_AUTH variable instead of sending redis-cli -a <password>.
-// It checks only variables specified via env[].valueFrom since this is what the operator sets; it does not look at envFrom.
-func checkRedisCLIAuthInEnv(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, podName, secretName, secretKey string) (bool, error) {
-	redisPod, err := client.CoreV1().Pods(cr.Namespace).Get(context.TODO(), podName, metav1.GetOptions{})
-	if err != nil {
-		log.FromContext(ctx).Error(err, "Error checking Redis pod's REDISCLI_AUTH variable", "namespace", cr.Namespace, "podName", podName)
-		return false, err
-	}
-
-	for _, tr := range redisPod.Spec.Containers {
-		if tr.Name == cr.Name+"-leader" {
-			for _, e := range tr.Env {
-				if e.Name != "REDISCLI_AUTH" {
-					continue
-				}
-
-				if e.ValueFrom == nil || e.ValueFrom.SecretKeyRef == nil {
-					continue
-				}
-
-				if e.ValueFrom.SecretKeyRef.Name != secretName || e.ValueFrom.SecretKeyRef.Key != secretKey {
-					return false, nil
-				}
-
-				return true, nil
-			}
-
-			log.FromContext(ctx).V(1).Info("Leader container not configured with REDISCLI_AUTH", "podName", podName)
-			return false, nil
-		}
-	}
-
-	log.FromContext(ctx).V(1).Info("Leader container not found in pod", "podName", podName)
-	return false, nil
-}
-
-func getRedisClusterAuthArgs(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, podName string) ([]string, error) {
-	if cr.Spec.KubernetesConfig.ExistingPasswordSecret != nil {
-		passwordInEnv, err := checkRedisCLIAuthInEnv(ctx, client, cr, podName, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Name, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Key)
+	if cr.Spec.LoadBalancerAccess() {
+		// The node announces the IP of its load balancer, and its own IP behind a load balancer hostname.
+		pod, err := client.CoreV1().Pods(rd.Namespace).Get(ctx, rd.PodName, metav1.GetOptions{})
 		if err != nil {
-			return []string{}, fmt.Errorf("error checking pod authentication config: %w", err)
-		}
-
-		if !passwordInEnv {
-			if features.Enabled(features.AvoidCommandLinePassword) {
-				return []string{}, errors.New("refusing to use command-line authentication because AvoidCommandLinePassword is set")
-			}
-
-			pass, err := getRedisPassword(ctx, client, cr.Namespace, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Name, *cr.Spec.KubernetesConfig.ExistingPasswordSecret.Key)
-			if err != nil {
-				return []string{}, fmt.Errorf("error getting Redis password: %w", err)
-			}
-
-			return []string{"-a", pass}, nil
+			log.FromContext(ctx).Error(err, "")
+			return ""
 		}
-	}
-
-	return []string{}, nil
-}
-
-// executeSingleLeaderAddSlots assigns all 16384 hash slots to the single
-// leader node. On Redis 7+ it uses CLUSTER ADDSLOTSRANGE 0 16383 (a single
-// compact command). On older versions it falls back to batched CLUSTER
-// ADDSLOTS calls to stay within the Kubernetes pod exec URL length limit.
-func executeSingleLeaderAddSlots(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, execute podExecFunc) {
-	logger := log.FromContext(ctx)
-
-	podName := cr.Name + "-leader-0"
-
-	authArgs, err := getRedisClusterAuthArgs(ctx, client, cr, podName)
-	if err != nil {
-		// Bail out instead of assigning slots unauthenticated: doing so would
-		// either fail outright or, worse, corrupt the new cluster's topology.
-		logger.Error(err, "Failed to get password authentication arguments")
-		return
-	}
-	var flags []string
-	flags = append(flags, authArgs...)
-	flags = append(flags, getRedisTLSArgs(cr.Spec.TLS, cr.Name+"-leader-0")...)
-
-	// Redis 7+ supports ADDSLOTSRANGE which takes a start-end pair instead
-	// of listing every slot number individually — avoids the URL length issue entirely.
-	if cr.Spec.ClusterVersion != nil && util.IsRedisVersionAtLeastV7(*cr.Spec.ClusterVersion) {
-		cmd := []string{"redis-cli"}
-		cmd = append(cmd, flags...)
-		cmd = append(cmd, "CLUSTER", "ADDSLOTSRANGE", "0", "16383")
-		logger.V(1).Info("Executing CLUSTER ADDSLOTSRANGE 0 16383")
-		execute(ctx, client, cr, cmd, podName)
-		return
-	}
-
-	// Fallback for Redis <7: batch ADDSLOTS into chunks of 1000 to stay
-	// within the pod exec URL length limit. CLUSTER ADDSLOTS is idempotent
-	// for unassigned slots, so partial retries on the next reconcile are safe.
-	const totalSlots = 16384
-	const batchSize = 1000
-	for start := 0; start < totalSlots; start += batchSize {
-		end := min(start+batchSize, totalSlots)
-		cmd := []string{"redis-cli"}
-		cmd = append(cmd, flags...)
-		cmd = append(cmd, "CLUSTER", "ADDSLOTS")
-		for i := start; i < end; i++ {
-			cmd = append(cmd, strconv.Itoa(i))
+		if ip, _ := podAnnouncedAddress(pod); ip != "" {
+			host = ip
 		}
-		logger.V(1).Info("Executing CLUSTER ADDSLOTS batch",
-			"SlotsRange", fmt.Sprintf("%d-%d", start, end-1))
-		execute(ctx, client, cr, cmd, podName)
 	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
 }
 
 // RepairDisconnectedNodes attempts to repair disconnected/failed nodes (both masters and slaves)
@@ -248,6 +143,10 @@ func repairDisconnectedNodes(ctx context.Context, client kubernetes.Interface, c
 	if err != nil {
 		return err
 	}
+	var pods []corev1.Pod
+	if cr.Spec.LoadBalancerAccess() {
+		pods = clusterPods(ctx, client, cr)
+	}
 	var lastError error
 	for _, node := range nodes {
 		if !nodeFailedOrDisconnected(node) {
@@ -259,7 +158,7 @@ func repairDisconnectedNodes(ctx context.Context, client kubernetes.Interface, c
 			log.FromContext(ctx).V(1).Error(err, "Failed to get pod name from cluster node. Continuing with other nodes.", "Node", node)
 			continue
 		}
-		podName := strings.Split(host, ".")[0]
+		podName := podNameOfHostname(host, pods)
 		ip := getRedisServerIP(ctx, client, RedisDetails{
 			PodName:   podName,
 			Namespace: cr.Namespace,
@@ -272,14 +171,23 @@ func repairDisconnectedNodes(ctx context.Context, client kubernetes.Interface, c
 		if err = redisClient.ClusterMeet(ctx, ip, strconv.Itoa(*cr.Spec.Port)).Err(); err != nil {
 			lastError = err
 			log.FromContext(ctx).V(1).Error(err, "Failed to execute CLUSTER MEET on node. Continuing with other nodes.", "Node", node)
+			events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisNodeReconnectFailed, podName,
+				fmt.Sprintf("Failed to reconnect %s at %s to the cluster: %v", podName, ip, err))
 			continue
 		}
+		events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisNodeReconnected, podName,
+			fmt.Sprintf("Reconnected %s at %s to the cluster", podName, ip))
 		if nodeIsOfType(node, "slave") {
 			masterNodeID := node[3]
 			followerClient := makeClient(podName)
 			if err = followerClient.ClusterReplicate(ctx, masterNodeID).Err(); err != nil {
 				lastError = err
 				log.FromContext(ctx).V(1).Error(err, "Failed to execute CLUSTER REPLICATE on follower.", "Follower", podName, "MasterNodeID", masterNodeID)
+				events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisReplicationRelinkFailed, podName,
+					fmt.Sprintf("Failed to relink %s to its master %s: %v", podName, masterNodeID, err))
+			} else {
+				events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisReplicationRelinked, podName,
+					fmt.Sprintf("Relinked %s to its master %s", podName, masterNodeID))
 			}
 			followerClient.Close()
 		}
@@ -303,8 +211,12 @@ func repairDisconnectedNodes(ctx context.Context, client kubernetes.Interface, c
 func RepairStaleReplication(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (int, error) {
 	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
 	defer redisClient.Close()
+	var pods []corev1.Pod
+	if cr.Spec.LoadBalancerAccess() {
+		pods = clusterPods(ctx, client, cr)
+	}
 	return repairStaleReplication(ctx, redisClient, func(podName string) *redis.Client {
-		return configureRedisClient(ctx, client, cr, podName)
+		return configureRedisClient(ctx, client, cr, podNameOfHostname(podName, pods))
 	})
 }
 
@@ -353,8 +265,12 @@ func repairStaleReplication(ctx context.Context, redisClient *redis.Client, make
 			lastError = err
 			logger.Error(err, "Failed to re-establish replication",
 				"Follower", podName, "MasterNodeID", masterNodeID)
+			events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisReplicationRelinkFailed, podName,
+				fmt.Sprintf("Failed to relink %s to its master %s after its replication link went down: %v", podName, masterNodeID, err))
 		} else {
 			repaired++
+			events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisReplicationRelinked, podName,
+				fmt.Sprintf("Relinked %s to its master %s after its replication link went down", podName, masterNodeID))
 		}
 		followerClient.Close()
 	}
@@ -382,155 +298,107 @@ func getHostFromClusterNode(node clusterNodesResponse) (string, error) {
 	return strings.Split(addressAndHost, ",")[1], nil
 }
 
-// CreateMultipleLeaderRedisCommand will create command for single leader cluster creation
-func CreateMultipleLeaderRedisCommand(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) RedisInvocation {
-	cmd := RedisInvocation{
-		Command: []string{"redis-cli", "--cluster", "create"},
-	}
+// leaderEndpoints returns the endpoints of the leaders of cr, in the order of their pods.
+func leaderEndpoints(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) ([]string, error) {
 	replicas := cr.Spec.GetReplicaCounts("leader")
+	endpoints := make([]string, 0, replicas)
 	for podCount := 0; podCount < int(replicas); podCount++ {
-		rd := RedisDetails{
-			PodName:   cr.Name + "-leader-" + strconv.Itoa(podCount),
-			Namespace: cr.Namespace,
+		endpoint, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-"+strconv.Itoa(podCount))
+		if err != nil {
+			return nil, err
 		}
-		cmd.AddFlag(getEndpoint(ctx, client, cr, rd))
+		endpoints = append(endpoints, endpoint)
 	}
-	cmd.AddFlag("--cluster-yes")
-	return cmd
-}
-
-// RedisInvocation models an invocation of redis-cli
-type RedisInvocation struct {
-	Command      []string // e.g. {"redis-cli", "--cluster", "create"}
-	Flags        []string // e.g. {"-h", "localhost", "-p", "6379"}
-	RedisCommand []string // e.g. {"CLUSTER", "ADDSLOTS", "1", "2", "3"}
+	return endpoints, nil
 }
 
-// Builds the full argv for executeCommand
-func (ri *RedisInvocation) Args() []string {
-	args := append([]string{}, ri.Command...)
-	args = append(args, ri.Flags...)
-	args = append(args, ri.RedisCommand...)
-	return args
-}
-
-func (ri *RedisInvocation) AddFlag(flag ...string) *RedisInvocation {
-	ri.Flags = append(ri.Flags, flag...)
-	return ri
+// podEndpoint returns the endpoint of the cluster node running in the pod podName.
+func podEndpoint(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, podName string) (string, error) {
+	endpoint := getEndpoint(ctx, client, cr, RedisDetails{PodName: podName, Namespace: cr.Namespace})
+	if endpoint == "" {
+		return "", fmt.Errorf("failed to get the endpoint of %s", podName)
+	}
+	return endpoint, nil
 }
 
-// ExecuteRedisClusterCommand will execute redis cluster creation command
-func ExecuteRedisClusterCommand(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) {
-	replicas := cr.Spec.GetReplicaCounts("leader")
-	switch int(replicas) {
-	case 1:
+// ExecuteRedisClusterCommand creates the cluster from the leaders, splitting the slots evenly
+// between them. It resumes an earlier creation that was interrupted.
+func ExecuteRedisClusterCommand(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) error {
+	if cr.Spec.GetReplicaCounts("leader") == 1 {
 		err := executeFailoverCommand(ctx, client, cr, "leader")
 		if err != nil {
 			log.FromContext(ctx).Error(err, "error executing failover command")
 		}
-		executeSingleLeaderAddSlots(ctx, client, cr, executeCommand)
-	default:
-		cmd := CreateMultipleLeaderRedisCommand(ctx, client, cr)
-		authArgs, err := getRedisClusterAuthArgs(ctx, client, cr, cr.Name+"-leader-0")
-		if err != nil {
-			// Bail out instead of creating the cluster unauthenticated: that
-			// would either fail or build a cluster the operator cannot manage.
-			log.FromContext(ctx).Error(err, "Failed to get password authentication arguments")
-			return
-		}
-		for _, arg := range authArgs {
-			cmd.AddFlag(arg)
-		}
-		cmd.AddFlag(getRedisTLSArgs(cr.Spec.TLS, cr.Name+"-leader-0")...)
-		executeCommand(ctx, client, cr, cmd.Args(), cr.Name+"-leader-0")
 	}
-}
-
-// getRedisTLSArgs returns the TLS flags for the redis-cli commands the operator
-// executes inside the Redis pods. The client certificate is always sent: Redis
-// defaults to `tls-auth-clients yes`, so a server requiring client certificates
-// rejects the TLS handshake when redis-cli presents none. This matches the flags
-// the generated liveness and readiness probes already use.
-func getRedisTLSArgs(tlsConfig *commonapi.TLSConfig, clientHost string) []string {
-	cmd := []string{}
-	if tlsConfig != nil {
-		caFile, certFile, keyFile := getTLSSecretKeys(tlsConfig)
-		cmd = append(cmd, "--tls")
-		if tlsConfig.CaCertFile != "" {
-			cmd = append(cmd, "--cacert")
-			cmd = append(cmd, "/tls/"+caFile)
-		}
-		cmd = append(cmd, "--cert")
-		cmd = append(cmd, "/tls/"+certFile)
-		cmd = append(cmd, "--key")
-		cmd = append(cmd, "/tls/"+keyFile)
-		cmd = append(cmd, "--insecure")
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return err
 	}
-	return cmd
-}
-
-// createRedisReplicationCommand will create redis replication creation command
-func createRedisReplicationCommand(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, leaderPod RedisDetails, followerPod RedisDetails) []string {
-	cmd := []string{"redis-cli", "--cluster", "add-node"}
-	cmd = append(cmd, getEndpoint(ctx, client, cr, followerPod))
-	cmd = append(cmd, getEndpoint(ctx, client, cr, leaderPod))
-	cmd = append(cmd, "--cluster-slave")
-	authArgs, err := getRedisClusterAuthArgs(ctx, client, cr, leaderPod.PodName)
+	endpoints, err := leaderEndpoints(ctx, client, cr)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to get password authentication arguments")
+		return err
 	}
-	cmd = append(cmd, authArgs...)
-	cmd = append(cmd, getRedisTLSArgs(cr.Spec.TLS, leaderPod.PodName)...)
-	return cmd
+	ctx, cancel := clusterOperationContext(ctx)
+	defer cancel()
+	return admin.Create(ctx, endpoints)
 }
 
-// ExecuteRedisReplicationCommand will execute the replication command
-func ExecuteRedisReplicationCommand(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) {
-	var podIP string
+// ExecuteRedisReplicationCommand adds the followers missing from the cluster as replicas of the
+// leaders, placing each of them on the leader lacking replicas that runs farthest from it: on another
+// node, and in another zone when possible.
+func ExecuteRedisReplicationCommand(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) error {
 	followerCounts := cr.Spec.GetReplicaCounts("follower")
-	leaderCounts := cr.Spec.GetReplicaCounts("leader")
-	followerPerLeader := followerCounts / leaderCounts
-
-	redisClient := configureRedisClient(ctx, client, cr, cr.Name+"-leader-0")
-	defer redisClient.Close()
 
-	nodes, err := clusterNodes(ctx, redisClient)
+	admin, err := newClusterAdmin(ctx, client, cr)
 	if err != nil {
-		log.FromContext(ctx).Error(err, "failed to get cluster nodes")
+		return err
 	}
-	for followerIdx := 0; followerIdx <= int(followerCounts)-1; {
-		for i := 0; i < int(followerPerLeader) && followerIdx <= int(followerCounts)-1; i++ {
-			followerPod := RedisDetails{
-				PodName:   cr.Name + "-follower-" + strconv.Itoa(followerIdx),
-				Namespace: cr.Namespace,
-			}
-			leaderPod := RedisDetails{
-				PodName:   cr.Name + "-leader-" + strconv.Itoa((followerIdx)%int(leaderCounts)),
-				Namespace: cr.Namespace,
-			}
-			podIP = getRedisServerIP(ctx, client, followerPod)
-			if !checkRedisNodePresence(ctx, nodes, podIP) {
-				log.FromContext(ctx).V(1).Info("Adding node to cluster.", "Node.IP", podIP, "Follower.Pod", followerPod)
-				cmd := createRedisReplicationCommand(ctx, client, cr, leaderPod, followerPod)
-				redisClient := configureRedisClient(ctx, client, cr, followerPod.PodName)
-				pong, err := redisClient.Ping(ctx).Result()
-				redisClient.Close()
-				if err != nil {
-					log.FromContext(ctx).Error(err, "Failed to ping Redis server", "Follower.Pod", followerPod)
-					continue
-				}
-				if pong == "PONG" {
-					executeCommand(ctx, client, cr, cmd, cr.Name+"-leader-0")
-				} else {
-					log.FromContext(ctx).V(1).Info("Skipping execution of command due to failed Redis ping", "Follower.Pod", followerPod)
-				}
-			} else {
-				log.FromContext(ctx).V(1).Info("Skipping Adding node to cluster, already present.", "Follower.Pod", followerPod)
-			}
+	seed, err := podEndpoint(ctx, client, cr, cr.Name+"-leader-0")
+	if err != nil {
+		return err
+	}
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster nodes: %w", err)
+	}
+	pods := clusterPods(ctx, client, cr)
+	podLocations := clusterPodLocations(ctx, client, pods)
+	placement, _ := planReplicas(nodes, int(cr.Spec.GetReplicasPerShard()), nodeLocations(nodes, pods, podLocations))
 
-			followerIdx++
+	ctx, cancel := clusterOperationContext(ctx)
+	defer cancel()
+	var errs []error
+	for followerIdx := 0; followerIdx < int(followerCounts); followerIdx++ {
+		followerPod := RedisDetails{
+			PodName:   cr.Name + "-follower-" + strconv.Itoa(followerIdx),
+			Namespace: cr.Namespace,
+		}
+		podIP := getRedisServerIP(ctx, client, followerPod)
+		endpoint, err := podEndpoint(ctx, client, cr, followerPod.PodName)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// A node behind a load balancer announces the address of its endpoint instead of its IP.
+		if podIP != "" && slices.ContainsFunc(nodes, func(n redisservice.ClusterNode) bool {
+			host, _, _ := net.SplitHostPort(n.Addr)
+			return host == podIP || n.Addr == endpoint
+		}) {
+			log.FromContext(ctx).V(1).Info("Skipping Adding node to cluster, already present.", "Follower.Pod", followerPod)
+			continue
+		}
+		log.FromContext(ctx).V(1).Info("Adding node to cluster.", "Node.IP", podIP, "Follower.Pod", followerPod)
+		leaderNodeID := placement.choose(podLocations[followerPod.PodName], true)
+		if leaderNodeID == "" {
+			errs = append(errs, fmt.Errorf("no master serving slots to add %s to", followerPod.PodName))
+			continue
+		}
+		placement.replicas[leaderNodeID] = append(placement.replicas[leaderNodeID], followerPod.PodName)
+		if err := admin.AddNode(ctx, seed, endpoint, leaderNodeID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to add %s as a replica of %s: %w", followerPod.PodName, leaderNodeID, err))
 		}
 	}
+	return errors.Join(errs...)
 }
 
 type clusterNodesResponse []string
@@ -639,11 +507,18 @@ func CheckRedisNodeCount(ctx context.Context, client kubernetes.Interface, cr *r
 	return int32(count)
 }
 
-// RedisClusterStatusHealth use `redis-cli --cluster check 127.0.0.1:6379`
+// RedisClusterStatusHealth checks, as `redis-cli --cluster check` does, that the nodes agree about
+// the slots configuration, that no slot is open and that all the slots are covered.
 func RedisClusterStatusHealth(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) bool {
 	logger := log.FromContext(ctx)
 	leaderReplicas := cr.Spec.GetReplicaCounts("leader")
 
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		logger.Error(err, "Cluster health check failed")
+		return false
+	}
+
 	// Try to check cluster health from multiple leader nodes with retry logic
 	var lastErr error
 	for i := int32(0); i < leaderReplicas; i++ {
@@ -652,7 +527,13 @@ func RedisClusterStatusHealth(ctx context.Context, client kubernetes.Interface,
 		// Retry logic with exponential backoff for each node
 		err := retry.Do(
 			func() error {
-				return checkClusterHealth(ctx, client, cr, podName)
+				seed, err := podEndpoint(ctx, client, cr, podName)
+				if err != nil {
+					return err
+				}
+				checkCtx, cancel := context.WithTimeout(ctx, clusterCheckTimeout)
+				defer cancel()
+				return admin.Check(checkCtx, seed)
 			},
 			retry.Attempts(3),
 			retry.Delay(500*time.Millisecond),
@@ -679,71 +560,11 @@ func RedisClusterStatusHealth(ctx context.Context, client kubernetes.Interface,
 	return false
 }
 
-// clusterCheckExecTimeout bounds a single `redis-cli --cluster check` exec.
-//
-// `--cluster check` dials every node recorded in the cluster config and
-// redis-cli applies no connect timeout of its own, so it blocks indefinitely on
-// any node whose recorded address is stale -- which is exactly the state left
-// behind when pods come back on new IPs (a StatefulSet recreate, an eviction, a
-// node drain). Because the health check runs 3 attempts against each leader, a
-// stuck dial can pin a single reconcile for far longer than
-// defaultExecCommandTimeout, so the reconcile never reaches
-// RepairDisconnectedNodes, which is what would issue the CLUSTER MEET that
-// corrects those addresses. The cluster then stays out of Ready indefinitely
-// instead of self-healing.
-//
-// The bound is applied to the exec context rather than through redis-cli's `-t`
-// flag because `-t` only exists in redis-cli 7.4 and later. Passing it to any
-// older redis-cli aborts the command with "Unrecognized option or bad number of
-// args for: '-t'", which breaks the health check outright on Redis 6.x and
-// 7.0-7.2 clusters. Bounding it operator-side keeps the anti-hang guarantee for
-// every supported Redis version.
-const clusterCheckExecTimeout = 15 * time.Second
-
-// clusterCheckCommand builds the `redis-cli --cluster check` argv. It carries no
-// timeout flag on purpose; see clusterCheckExecTimeout.
-func clusterCheckCommand(port int, authArgs, tlsArgs []string) []string {
-	cmd := []string{"redis-cli", "--cluster", "check", fmt.Sprintf("127.0.0.1:%d", port)}
-	cmd = append(cmd, authArgs...)
-	cmd = append(cmd, tlsArgs...)
-	return cmd
-}
-
-// checkClusterHealth performs a single cluster health check against a specific pod.
-func checkClusterHealth(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, podName string) error {
-	logger := log.FromContext(ctx)
-
-	authArgs, err := getRedisClusterAuthArgs(ctx, client, cr, podName)
-	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to get password authentication arguments")
-	}
-	cmd := clusterCheckCommand(*cr.Spec.Port, authArgs, getRedisTLSArgs(cr.Spec.TLS, podName))
-
-	execCtx, cancel := context.WithTimeout(ctx, clusterCheckExecTimeout)
-	defer cancel()
-
-	out, err := executeCommand1(execCtx, client, cr, cmd, podName)
-	if err != nil {
-		return fmt.Errorf("failed to execute cluster check command: %w", err)
-	}
-
-	// Check for the expected success indicators
-	// [OK] xxx keys in xxx masters.
-	// [OK] All nodes agree about slots configuration.
-	// [OK] All 16384 slots covered.
-	okCount := strings.Count(out, "[OK]")
-	if okCount != 3 {
-		logger.V(1).Info("Cluster health check output", "pod", podName, "okCount", okCount, "output", out)
-		return fmt.Errorf("cluster health check failed: expected 3 [OK] messages, got %d", okCount)
-	}
-
-	// Additional check: ensure no [ERR] or [WARNING] in critical lines
-	if strings.Contains(out, "[ERR]") {
-		return fmt.Errorf("cluster health check found errors in output")
-	}
-
-	return nil
-}
+// clusterCheckTimeout bounds a single cluster health check. The check dials every node recorded
+// in the cluster config, so a node whose recorded address is stale -- the state left behind when
+// pods come back on new IPs -- must not pin the reconcile before it reaches
+// RepairDisconnectedNodes, which is what corrects those addresses.
+const clusterCheckTimeout = 15 * time.Second
 
 // UnhealthyNodesInCluster returns the number of unhealthy nodes in the cluster cr
 func UnhealthyNodesInCluster(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (int, error) {
@@ -796,7 +617,7 @@ func configureRedisClient(ctx context.Context, client kubernetes.Interface, cr *
 	if cr.Spec.TLS != nil {
 		opts.TLSConfig = getRedisTLSConfig(ctx, client, cr.Namespace, cr.Spec.TLS)
 	}
-	return redis.NewClient(opts)
+	return tracing.InstrumentRedis(redis.NewClient(opts), podName)
 }
 
 func configureRedisStandaloneClient(ctx context.Context, client kubernetes.Interface, cr *rvb2.Redis, podName string) *redis.Client {
@@ -820,115 +641,13 @@ func configureRedisStandaloneClient(ctx context.Context, client kubernetes.Inter
 	if cr.Spec.TLS != nil {
 		opts.TLSConfig = getRedisTLSConfig(ctx, client, cr.Namespace, cr.Spec.TLS)
 	}
-	return redis.NewClient(opts)
+	return tracing.InstrumentRedis(redis.NewClient(opts), podName)
 }
 
-// executeCommand will execute the commands in pod
-func executeCommand(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, cmd []string, podName string) {
-	execOut, execErr := executeCommand1(ctx, client, cr, cmd, podName)
-	if execErr != nil {
-		log.FromContext(ctx).Error(execErr, "Could not execute command", "Command", cmd, "Output", execOut)
-		return
-	}
-	log.FromContext(ctx).V(1).Info("Successfully executed the command", "Command", cmd, "Output", execOut)
-}
-
-// defaultExecCommandTimeout bounds a single exec stream against a redis pod. It is generous
-// enough for a legitimate slow `redis-cli --cluster create` while still guaranteeing the stream
-// (and therefore the reconcile worker) cannot block forever. Override with EXEC_COMMAND_TIMEOUT.
-const defaultExecCommandTimeout = 5 * time.Minute
-
 // defaultRedisClientTimeout bounds dial/read/write operations of the go-redis clients the
 // reconciler opens against redis pods, so an unreachable pod cannot stall a reconcile.
 const defaultRedisClientTimeout = 5 * time.Second
 
-// wrapRedisCLIAuthSanitize wraps a redis-cli argv in a shell shim that strips
-// CR/LF from the container's REDISCLI_AUTH before redis-cli reads it (see
-// redisCLIAuthSanitizer for why). The original argv is forwarded via "$@" so
-// no re-quoting is needed and the password still never appears on the command
-// line. Non-redis-cli commands are passed through untouched.
-func wrapRedisCLIAuthSanitize(cmd []string) []string {
-	if len(cmd) == 0 || cmd[0] != "redis-cli" {
-		return cmd
-	}
-	return append([]string{"sh", "-c", redisCLIAuthSanitizer + `; exec "$@"`, "sh"}, cmd...)
-}
-
-func executeCommand1(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, cmd []string, podName string) (stdout string, stderr error) {
-	var (
-		execOut bytes.Buffer
-		execErr bytes.Buffer
-	)
-	cmd = wrapRedisCLIAuthSanitize(cmd)
-	config, err := GenerateK8sConfig()()
-	if err != nil {
-		log.FromContext(ctx).Error(err, "Could not find pod to execute")
-		return "", err
-	}
-	targetContainer, pod := getContainerID(ctx, client, cr, podName)
-	if targetContainer < 0 {
-		log.FromContext(ctx).Error(err, "Could not find pod to execute")
-		return "", err
-	}
-
-	req := client.CoreV1().RESTClient().Post().Resource("pods").Name(podName).Namespace(cr.Namespace).SubResource("exec")
-	req.VersionedParams(&corev1.PodExecOptions{
-		Container: pod.Spec.Containers[targetContainer].Name,
-		Command:   cmd,
-		Stdout:    true,
-		Stderr:    true,
-	}, scheme.ParameterCodec)
-	exec, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
-	if err != nil {
-		log.FromContext(ctx).Error(err, "Failed to init executor")
-		return "", err
-	}
-
-	// Bound the exec stream with the reconcile ctx and a timeout so a blocked command (e.g. a
-	// `redis-cli --cluster create` against pods that cannot yet form a cluster) returns an error
-	// and requeues instead of pinning the reconcile worker forever, which would starve every
-	// other Redis resource across all namespaces.
-	execCtx, cancel := context.WithTimeout(ctx, envs.GetExecCommandTimeout(defaultExecCommandTimeout))
-	defer cancel()
-	err = exec.StreamWithContext(execCtx, remotecommand.StreamOptions{
-		Stdout: &execOut,
-		Stderr: &execErr,
-		Tty:    false,
-	})
-	if err != nil {
-		return execOut.String(), fmt.Errorf("execute command with error: %w, stderr: %s", err, execErr.String())
-	}
-	return execOut.String(), nil
-}
-
-// getContainerID will return the id of container from pod
-func getContainerID(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, podName string) (int, *corev1.Pod) {
-	pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, podName, metav1.GetOptions{})
-	if err != nil {
-		log.FromContext(ctx).Error(err, "Could not get pod info", "Pod Name", podName, "Namespace", cr.Namespace)
-		return -1, nil
-	}
-
-	log.FromContext(ctx).V(1).Info("Pod info retrieved successfully", "Pod Name", podName, "Namespace", cr.Namespace)
-
-	targetContainer := -1
-	for containerID, tr := range pod.Spec.Containers {
-		log.FromContext(ctx).V(1).Info("Inspecting container", "Pod Name", podName, "Container ID", containerID, "Container Name", tr.Name)
-		if tr.Name == cr.Name+"-leader" {
-			targetContainer = containerID
-			log.FromContext(ctx).V(1).Info("Leader container found", "Container ID", containerID, "Container Name", tr.Name)
-			break
-		}
-	}
-
-	if targetContainer == -1 {
-		log.FromContext(ctx).V(1).Info("Leader container not found in pod", "Pod Name", podName)
-		return -1, nil
-	}
-
-	return targetContainer, pod
-}
-
 // checkRedisNodePresence will check if the redis node exist in cluster or not
 func checkRedisNodePresence(ctx context.Context, nodeList []clusterNodesResponse, nodeName string) bool {
 	log.FromContext(ctx).V(1).Info("Checking if Node is in cluster", "Node", nodeName)
@@ -992,7 +711,7 @@ func configureRedisReplicationClientForAddress(ctx context.Context, client kuber
 	if cr.Spec.TLS != nil {
 		opts.TLSConfig = getRedisTLSConfig(ctx, client, cr.Namespace, cr.Spec.TLS)
 	}
-	return redis.NewClient(opts)
+	return tracing.InstrumentRedis(redis.NewClient(opts), redisInfo.PodName)
 }
 
 func formatRedisAddress(ip string, port int) string {
@@ -1056,6 +775,35 @@ func getRedisNodesByRole(ctx context.Context, cl kubernetes.Interface, cr *rrvb2
 	return pods, nil
 }
 
+// GetRedisReplicationLag returns the number of bytes of the replication stream of the master each of the
+// replicas has yet to process, the difference between their replication offsets.
+func GetRedisReplicationLag(ctx context.Context, cl kubernetes.Interface, cr *rrvb2.RedisReplication, master string, replicas []string) (map[string]int64, error) {
+	lag := make(map[string]int64, len(replicas))
+	if len(replicas) == 0 {
+		return lag, nil
+	}
+	offset := func(podName string) (int64, error) {
+		pod, err := cl.CoreV1().Pods(cr.Namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return 0, err
+		}
+		state, err := GetRedisReplicationPodReplication(ctx, cl, cr, pod)
+		return state.Offset, err
+	}
+	masterOffset, err := offset(master)
+	if err != nil {
+		return nil, err
+	}
+	for _, replica := range replicas {
+		replicaOffset, err := offset(replica)
+		if err != nil {
+			return nil, err
+		}
+		lag[replica] = max(masterOffset-replicaOffset, 0)
+	}
+	return lag, nil
+}
+
 func IsRedisPodProbeable(pod *corev1.Pod) bool {
 	if pod == nil || pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
 		return false
@@ -1166,8 +914,12 @@ func CreateMasterSlaveReplication(ctx context.Context, client kubernetes.Interfa
 			err := redisClient.SlaveOf(ctx, realMasterAddr, "6379").Err()
 			if err != nil {
 				log.FromContext(ctx).Error(err, "Failed to set", "pod", masterPods[i], "to slave of", realMasterPod, "masterAddr", realMasterAddr)
+				events.FromContext(ctx).AddEvent(corev1.EventTypeWarning, events.EventReasonRedisReplicaAttachFailed, masterPods[i],
+					fmt.Sprintf("Failed to make %s a replica of %s: %v", masterPods[i], realMasterPod, err))
 				return err
 			}
+			events.FromContext(ctx).AddEvent(corev1.EventTypeNormal, events.EventReasonRedisReplicaAttached, masterPods[i],
+				fmt.Sprintf("Made %s a replica of %s", masterPods[i], realMasterPod))
 		}
 	}
 
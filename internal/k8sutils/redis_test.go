@@ -8,8 +8,8 @@ import (
 	"strings"
 	"testing"
 
-	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common/events"
 	mock_utils "github.com/OT-CONTAINER-KIT/redis-operator/mocks/utils"
 	"github.com/go-redis/redismock/v9"
 	redis "github.com/redis/go-redis/v9"
@@ -17,7 +17,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/kubernetes"
 	k8sClientFake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/utils/ptr"
 )
@@ -56,7 +55,8 @@ func TestCheckRedisNodePresence(t *testing.T) {
 }
 
 func TestRepairDisconnectedNodes(t *testing.T) {
-	ctx := context.Background()
+	recorder := events.NewRecorder()
+	ctx := events.IntoContext(context.Background(), recorder)
 	redisClient, mock := redismock.NewClientMock()
 	mock.ExpectClusterNodes().SetVal(`
 07c37dfeb235213a872192d90877d0cd55635b91 127.0.0.1:30004@31004,redis-cluster-follower-0 slave e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 0 1426238317239 4 connected
@@ -109,6 +109,11 @@ e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:30001@31001,redis-cluster-lea
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet(), "expected CLUSTER MEET for both failed nodes")
 	assert.NoError(t, followerMock.ExpectationsWereMet(), "expected CLUSTER REPLICATE on the failed follower")
+	assert.Equal(t, []events.Event{
+		{EventType: corev1.EventTypeNormal, Reason: events.EventReasonRedisNodeReconnected, Pod: "redis-cluster-leader-0", Message: "Reconnected redis-cluster-leader-0 at 10.0.0.1 to the cluster"},
+		{EventType: corev1.EventTypeNormal, Reason: events.EventReasonRedisNodeReconnected, Pod: "redis-cluster-follower-1", Message: "Reconnected redis-cluster-follower-1 at 10.0.0.2 to the cluster"},
+		{EventType: corev1.EventTypeNormal, Reason: events.EventReasonRedisReplicationRelinked, Pod: "redis-cluster-follower-1", Message: "Relinked redis-cluster-follower-1 to its master 292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f"},
+	}, recorder.Events())
 }
 
 func TestRepairDisconnectedNodesSkipsNodeWithEmptyIP(t *testing.T) {
@@ -230,7 +235,8 @@ func TestReplicationLinkUp(t *testing.T) {
 }
 
 func TestRepairStaleReplication_replicationDown(t *testing.T) {
-	ctx := context.Background()
+	recorder := events.NewRecorder()
+	ctx := events.IntoContext(context.Background(), recorder)
 	redisClient, mock := redismock.NewClientMock()
 
 	mock.ExpectClusterNodes().SetVal(`
@@ -250,6 +256,41 @@ e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:30001@31001,redis-cluster-lea
 	assert.NoError(t, lastError)
 	assert.Equal(t, 1, repaired)
 	assert.NoError(t, followerMock.ExpectationsWereMet(), "expected CLUSTER REPLICATE on the stale follower")
+	assert.Equal(t, []events.Event{{
+		EventType: corev1.EventTypeNormal,
+		Reason:    events.EventReasonRedisReplicationRelinked,
+		Pod:       "redis-cluster-follower-0",
+		Message:   "Relinked redis-cluster-follower-0 to its master e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca after its replication link went down",
+	}}, recorder.Events())
+}
+
+func TestRepairStaleReplication_relinkFailed(t *testing.T) {
+	recorder := events.NewRecorder()
+	ctx := events.IntoContext(context.Background(), recorder)
+	redisClient, mock := redismock.NewClientMock()
+
+	mock.ExpectClusterNodes().SetVal(`
+e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:30001@31001,redis-cluster-leader-0 myself,master - 0 0 1 connected 0-16383
+07c37dfeb235213a872192d90877d0cd55635b91 127.0.0.1:30002@31002,redis-cluster-follower-0 slave e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 0 1426238317239 1 connected
+`)
+
+	followerClient, followerMock := redismock.NewClientMock()
+	followerMock.ExpectInfo("replication").SetVal(
+		"# Replication\r\nrole:slave\r\nmaster_host:10.130.24.167\r\nmaster_port:6379\r\nmaster_link_status:down\r\nmaster_last_io_seconds_ago:-1\r\n",
+	)
+	followerMock.ExpectClusterReplicate("e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca").SetErr(fmt.Errorf("ERR Unknown node"))
+
+	repaired, lastError := repairStaleReplication(ctx, redisClient, func(_ string) *redis.Client {
+		return followerClient
+	})
+	assert.Error(t, lastError)
+	assert.Equal(t, 0, repaired)
+	got := recorder.Events()
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, corev1.EventTypeWarning, got[0].EventType)
+		assert.Equal(t, events.EventReasonRedisReplicationRelinkFailed, got[0].Reason)
+		assert.Equal(t, "redis-cluster-follower-0", got[0].Pod)
+	}
 }
 
 func TestRepairStaleReplication_replicationUp(t *testing.T) {
@@ -434,130 +475,11 @@ func TestGetRedisServerAddress(t *testing.T) {
 	}
 }
 
-func TestExecuteSingleLeaderAddSlots(t *testing.T) {
-	type recordedExec struct {
-		cmd     []string
-		podName string
-	}
-
-	newCluster := func(version *string, withAuth, withTLS bool) *rcvb2.RedisCluster {
-		cr := &rcvb2.RedisCluster{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      "redis-cluster",
-				Namespace: "default",
-			},
-			Spec: rcvb2.RedisClusterSpec{
-				ClusterSize:    ptr.To(int32(1)),
-				ClusterVersion: version,
-				Port:           ptr.To(6379),
-			},
-		}
-		if withAuth {
-			cr.Spec.KubernetesConfig.ExistingPasswordSecret = &common.ExistingPasswordSecret{
-				Name: ptr.To("redis-password-secret"),
-				Key:  ptr.To("password"),
-			}
-		}
-		if withTLS {
-			// CaCertFile is set explicitly so getRedisTLSArgs emits --cacert;
-			// without an explicit CA the operator now relies on --insecure and
-			// the system trust store instead of passing a CA file.
-			cr.Spec.TLS = &common.TLSConfig{CaCertFile: "ca.crt"}
-		}
-		return cr
-	}
-
+func TestLeaderEndpoints(t *testing.T) {
 	tests := []struct {
-		name          string
-		redisCluster  *rcvb2.RedisCluster
-		expectedFlags []string
-		rangeCommand  bool
-	}{
-		{
-			name:         "redis v7 uses a single ADDSLOTSRANGE command",
-			redisCluster: newCluster(ptr.To("v7"), false, false),
-			rangeCommand: true,
-		},
-		{
-			name:          "redis v7 with auth includes password flag",
-			redisCluster:  newCluster(ptr.To("v7"), true, false),
-			expectedFlags: []string{"-a", "password"},
-			rangeCommand:  true,
-		},
-		{
-			name:         "redis v8 also uses a single ADDSLOTSRANGE command",
-			redisCluster: newCluster(ptr.To("v8"), false, false),
-			rangeCommand: true,
-		},
-		{
-			name:         "redis v6 falls back to batched ADDSLOTS",
-			redisCluster: newCluster(ptr.To("v6"), false, false),
-		},
-		{
-			name:         "unset cluster version falls back to batched ADDSLOTS",
-			redisCluster: newCluster(nil, false, false),
-		},
-		{
-			name:          "redis v6 with TLS includes TLS flags in every batch",
-			redisCluster:  newCluster(nil, false, true),
-			expectedFlags: []string{"--tls", "--cacert", "/tls/ca.crt", "--cert", "/tls/tls.crt", "--key", "/tls/tls.key", "--insecure"},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			var objects []runtime.Object
-			if tt.redisCluster.Spec.KubernetesConfig.ExistingPasswordSecret != nil {
-				objects = mock_utils.CreateFakeObjectWithSecret("redis-password-secret", "default", "password")
-				// The leader pod must exist so the operator can check whether
-				// REDISCLI_AUTH is already set before deciding to pass -a on the
-				// command line. This pod has no REDISCLI_AUTH, so the -a fallback
-				// applies and the password flag is still emitted.
-				objects = append(objects, &corev1.Pod{
-					ObjectMeta: metav1.ObjectMeta{Name: "redis-cluster-leader-0", Namespace: "default"},
-					Spec: corev1.PodSpec{
-						Containers: []corev1.Container{{Name: "redis-cluster-leader"}},
-					},
-				})
-			}
-			client := k8sClientFake.NewSimpleClientset(objects...)
-
-			var execs []recordedExec
-			executeSingleLeaderAddSlots(context.TODO(), client, tt.redisCluster, func(_ context.Context, _ kubernetes.Interface, _ *rcvb2.RedisCluster, cmd []string, podName string) {
-				execs = append(execs, recordedExec{cmd: cmd, podName: podName})
-			})
-
-			expectedPrefix := append([]string{"redis-cli"}, tt.expectedFlags...)
-			if tt.rangeCommand {
-				assert.Len(t, execs, 1, "v7 should issue exactly one command")
-				assert.Equal(t, append(expectedPrefix, "CLUSTER", "ADDSLOTSRANGE", "0", "16383"), execs[0].cmd)
-				assert.Equal(t, "redis-cluster-leader-0", execs[0].podName)
-				return
-			}
-
-			assert.Len(t, execs, 17, "16384 slots in batches of 1000 should issue 17 commands")
-			slot := 0
-			for _, exec := range execs {
-				assert.Equal(t, "redis-cluster-leader-0", exec.podName)
-				expectedCmd := append(append([]string{}, expectedPrefix...), "CLUSTER", "ADDSLOTS")
-				assert.Equal(t, expectedCmd, exec.cmd[:len(expectedCmd)])
-				slots := exec.cmd[len(expectedCmd):]
-				assert.LessOrEqual(t, len(slots), 1000, "batch exceeds max size")
-				for _, s := range slots {
-					assert.Equal(t, strconv.Itoa(slot), s, "slots must be contiguous with no gaps or duplicates")
-					slot++
-				}
-			}
-			assert.Equal(t, 16384, slot, "all 16384 slots must be assigned")
-		})
-	}
-}
-
-func TestCreateMultipleLeaderRedisCommand(t *testing.T) {
-	tests := []struct {
-		name             string
-		redisCluster     *rcvb2.RedisCluster
-		expectedCommands []string
+		name              string
+		redisCluster      *rcvb2.RedisCluster
+		expectedEndpoints []string
 	}{
 		{
 			name: "Multiple leaders cluster version v7",
@@ -572,12 +494,10 @@ func TestCreateMultipleLeaderRedisCommand(t *testing.T) {
 					Port:           ptr.To(6379),
 				},
 			},
-			expectedCommands: []string{
-				"redis-cli", "--cluster", "create",
+			expectedEndpoints: []string{
 				"mycluster-leader-0.mycluster-leader-headless.default.svc.cluster.local:6379",
 				"mycluster-leader-1.mycluster-leader-headless.default.svc.cluster.local:6379",
 				"mycluster-leader-2.mycluster-leader-headless.default.svc.cluster.local:6379",
-				"--cluster-yes",
 			},
 		},
 		{
@@ -593,12 +513,10 @@ func TestCreateMultipleLeaderRedisCommand(t *testing.T) {
 					Port:           ptr.To(6379),
 				},
 			},
-			expectedCommands: []string{
-				"redis-cli", "--cluster", "create",
+			expectedEndpoints: []string{
 				"mycluster-leader-0.mycluster-leader-headless.default.svc.cluster.local:6379",
 				"mycluster-leader-1.mycluster-leader-headless.default.svc.cluster.local:6379",
 				"mycluster-leader-2.mycluster-leader-headless.default.svc.cluster.local:6379",
-				"--cluster-yes",
 			},
 		},
 		{
@@ -614,12 +532,10 @@ func TestCreateMultipleLeaderRedisCommand(t *testing.T) {
 					Port:           ptr.To(6379),
 				},
 			},
-			expectedCommands: []string{
-				"redis-cli", "--cluster", "create",
+			expectedEndpoints: []string{
 				"192.168.1.1:6379",
 				"192.168.1.2:6379",
 				"192.168.1.3:6379",
-				"--cluster-yes",
 			},
 		},
 		{
@@ -634,12 +550,10 @@ func TestCreateMultipleLeaderRedisCommand(t *testing.T) {
 					Port:        ptr.To(6379),
 				},
 			},
-			expectedCommands: []string{
-				"redis-cli", "--cluster", "create",
+			expectedEndpoints: []string{
 				"192.168.1.1:6379",
 				"192.168.1.2:6379",
 				"192.168.1.3:6379",
-				"--cluster-yes",
 			},
 		},
 		{
@@ -654,12 +568,10 @@ func TestCreateMultipleLeaderRedisCommand(t *testing.T) {
 					Port:        ptr.To(6379),
 				},
 			},
-			expectedCommands: []string{
-				"redis-cli", "--cluster", "create",
-				"2001:db8:42:1::100:6379",
-				"2001:db8:42:1::101:6379",
-				"2001:db8:42:1::102:6379",
-				"--cluster-yes",
+			expectedEndpoints: []string{
+				"[2001:db8:42:1::100]:6379",
+				"[2001:db8:42:1::101]:6379",
+				"[2001:db8:42:1::102]:6379",
 			},
 		},
 	}
@@ -673,321 +585,9 @@ func TestCreateMultipleLeaderRedisCommand(t *testing.T) {
 				client = mock_utils.CreateFakeClientWithPodIPs_LeaderPods(tt.redisCluster)
 			}
 
-			invocation := CreateMultipleLeaderRedisCommand(context.TODO(), client, tt.redisCluster)
-			cmd := invocation.Args()
-			assert.Equal(t, tt.expectedCommands, cmd)
-		})
-	}
-}
-
-func TestGetRedisTLSArgs(t *testing.T) {
-	tests := []struct {
-		name       string
-		tlsConfig  *common.TLSConfig
-		clientHost string
-		expected   []string
-	}{
-		{
-			name:       "with TLS configuration",
-			tlsConfig:  &common.TLSConfig{},
-			clientHost: "redis-host",
-			expected:   []string{"--tls", "--cert", "/tls/tls.crt", "--key", "/tls/tls.key", "--insecure"},
-		},
-		{
-			name: "with TLS and explicit CA configuration",
-			tlsConfig: &common.TLSConfig{
-				CaCertFile: "custom-ca.crt",
-			},
-			clientHost: "redis-host",
-			expected:   []string{"--tls", "--cacert", "/tls/custom-ca.crt", "--cert", "/tls/tls.crt", "--key", "/tls/tls.key", "--insecure"},
-		},
-		{
-			name: "with custom client certificate and key keys",
-			tlsConfig: &common.TLSConfig{
-				CaCertFile:  "custom-ca.crt",
-				CertKeyFile: "custom-tls.crt",
-				KeyFile:     "custom-tls.key",
-			},
-			clientHost: "redis-host",
-			expected:   []string{"--tls", "--cacert", "/tls/custom-ca.crt", "--cert", "/tls/custom-tls.crt", "--key", "/tls/custom-tls.key", "--insecure"},
-		},
-		{
-			name:       "without TLS configuration",
-			tlsConfig:  nil,
-			clientHost: "redis-host",
-			expected:   []string{},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			cmd := getRedisTLSArgs(tt.tlsConfig, tt.clientHost)
-			assert.Equal(t, tt.expected, cmd, "Expected command arguments do not match")
-		})
-	}
-}
-
-func TestCreateRedisReplicationCommand(t *testing.T) {
-	type secret struct {
-		name      string
-		namespace string
-		key       string
-	}
-	tests := []struct {
-		name         string
-		redisCluster *rcvb2.RedisCluster
-		secret
-		leaderPod       RedisDetails
-		followerPod     RedisDetails
-		expectedCommand []string
-	}{
-		{
-			name: "Test case with cluster version v7",
-			secret: secret{
-				name:      "redis-password-secret",
-				namespace: "default",
-				key:       "password",
-			},
-			redisCluster: &rcvb2.RedisCluster{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "redis-cluster",
-					Namespace: "default",
-				},
-				Spec: rcvb2.RedisClusterSpec{
-					ClusterSize: ptr.To(int32(3)),
-					KubernetesConfig: common.KubernetesConfig{
-						ExistingPasswordSecret: &common.ExistingPasswordSecret{
-							Name: ptr.To("redis-password-secret"),
-							Key:  ptr.To("password"),
-						},
-					},
-					ClusterVersion: ptr.To("v7"),
-					Port:           ptr.To(6379),
-				},
-			},
-			leaderPod: RedisDetails{
-				PodName:   "redis-cluster-leader-0",
-				Namespace: "default",
-			},
-			followerPod: RedisDetails{
-				PodName:   "redis-cluster-follower-0",
-				Namespace: "default",
-			},
-			expectedCommand: []string{
-				"redis-cli", "--cluster", "add-node",
-				"redis-cluster-follower-0.redis-cluster-follower-headless.default.svc.cluster.local:6379",
-				"redis-cluster-leader-0.redis-cluster-leader-headless.default.svc.cluster.local:6379",
-				"--cluster-slave",
-				"-a", "password",
-			},
-		},
-		{
-			name: "Test case with cluster version v7 failed to get password",
-			secret: secret{
-				name:      "wrong-name",
-				namespace: "default",
-				key:       "password",
-			},
-			redisCluster: &rcvb2.RedisCluster{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "redis-cluster",
-					Namespace: "default",
-				},
-				Spec: rcvb2.RedisClusterSpec{
-					ClusterSize: ptr.To(int32(3)),
-					KubernetesConfig: common.KubernetesConfig{
-						ExistingPasswordSecret: &common.ExistingPasswordSecret{
-							Name: ptr.To("redis-password-secret"),
-							Key:  ptr.To("password"),
-						},
-					},
-					ClusterVersion: ptr.To("v7"),
-					Port:           ptr.To(6379),
-				},
-			},
-			leaderPod: RedisDetails{
-				PodName:   "redis-cluster-leader-0",
-				Namespace: "default",
-			},
-			followerPod: RedisDetails{
-				PodName:   "redis-cluster-follower-0",
-				Namespace: "default",
-			},
-			expectedCommand: []string{
-				"redis-cli", "--cluster", "add-node",
-				"redis-cluster-follower-0.redis-cluster-follower-headless.default.svc.cluster.local:6379",
-				"redis-cluster-leader-0.redis-cluster-leader-headless.default.svc.cluster.local:6379",
-				"--cluster-slave",
-			},
-		},
-		{
-			name: "Test case without cluster version v7",
-			redisCluster: &rcvb2.RedisCluster{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "redis-cluster",
-					Namespace: "default",
-				},
-				Spec: rcvb2.RedisClusterSpec{
-					ClusterSize: ptr.To(int32(3)),
-					Port:        ptr.To(6379),
-				},
-			},
-			leaderPod: RedisDetails{
-				PodName:   "redis-cluster-leader-0",
-				Namespace: "default",
-			},
-			followerPod: RedisDetails{
-				PodName:   "redis-cluster-follower-0",
-				Namespace: "default",
-			},
-			expectedCommand: []string{
-				"redis-cli", "--cluster", "add-node",
-				"192.168.2.1:6379",
-				"192.168.1.1:6379",
-				"--cluster-slave",
-			},
-		},
-		{
-			name: "Test case without cluster version v7 with IPv6",
-			redisCluster: &rcvb2.RedisCluster{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "redis-cluster",
-					Namespace: "default",
-				},
-				Spec: rcvb2.RedisClusterSpec{
-					ClusterSize: ptr.To(int32(3)),
-					Port:        ptr.To(6379),
-				},
-			},
-			leaderPod: RedisDetails{
-				PodName:   "redis-cluster-leader-0",
-				Namespace: "default",
-			},
-			followerPod: RedisDetails{
-				PodName:   "redis-cluster-follower-0",
-				Namespace: "default",
-			},
-			expectedCommand: []string{
-				"redis-cli", "--cluster", "add-node",
-				"2001:db8:42:2::200:6379",
-				"2001:db8:42:1::100:6379",
-				"--cluster-slave",
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			var pods []runtime.Object
-			if strings.Contains(tt.name, "IPv6") {
-				pods = mock_utils.CreateFakeObjectWithIPv6PodIPs(tt.redisCluster)
-			} else {
-				pods = mock_utils.CreateFakeObjectWithPodIPs(tt.redisCluster)
-			}
-			var secret []runtime.Object
-			if tt.redisCluster.Spec.KubernetesConfig.ExistingPasswordSecret != nil {
-				secret = mock_utils.CreateFakeObjectWithSecret(tt.secret.name, tt.secret.namespace, tt.secret.key) //nolint
-			}
-
-			var objects []runtime.Object
-			objects = append(objects, pods...)
-			objects = append(objects, secret...)
-
-			client := k8sClientFake.NewSimpleClientset(objects...)
-			cmd := createRedisReplicationCommand(context.TODO(), client, tt.redisCluster, tt.leaderPod, tt.followerPod)
-
-			// Assert the command is as expected using testify
-			assert.Equal(t, tt.expectedCommand, cmd)
-		})
-	}
-}
-
-func TestGetContainerID(t *testing.T) {
-	tests := []struct {
-		name         string
-		setupPod     *corev1.Pod
-		redisCluster *rcvb2.RedisCluster
-		expectedID   int
-		expectError  bool
-	}{
-		{
-			name: "Successful retrieval of leader container",
-			setupPod: &corev1.Pod{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "redis-cluster-leader-0",
-					Namespace: "default",
-				},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name: "redis-cluster-leader",
-						},
-						{
-							Name: "another-container",
-						},
-					},
-				},
-			},
-			redisCluster: &rcvb2.RedisCluster{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "redis-cluster",
-					Namespace: "default",
-				},
-			},
-			expectedID:  0,
-			expectError: false,
-		},
-		{
-			name:     "Pod not found",
-			setupPod: &corev1.Pod{},
-			redisCluster: &rcvb2.RedisCluster{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "redis-cluster",
-					Namespace: "default",
-				},
-			},
-			expectedID:  -1,
-			expectError: true,
-		},
-		{
-			name: "Leader container not found in the pod",
-			setupPod: &corev1.Pod{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "redis-cluster-leader-0",
-					Namespace: "default",
-				},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name: "non-leader-container",
-						},
-					},
-				},
-			},
-			redisCluster: &rcvb2.RedisCluster{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "redis-cluster",
-					Namespace: "default",
-				},
-			},
-			expectedID:  -1,
-			expectError: true,
-		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			client := k8sClientFake.NewSimpleClientset(test.setupPod)
-			id, pod := getContainerID(context.TODO(), client, test.redisCluster, test.setupPod.Name)
-			if test.expectError {
-				assert.Nil(t, pod, "Expected no pod but got one")
-				assert.Equal(t, test.expectedID, id, "Expected ID does not match")
-			} else {
-				assert.NotNil(t, pod, "Expected a pod but got none")
-				assert.Equal(t, test.expectedID, id, "Expected ID does not match")
-				assert.Equal(t, test.setupPod.Name, pod.GetName(), "Pod names do not match")
-				assert.Equal(t, test.setupPod.Namespace, pod.GetNamespace(), "Pod namespaces do not match")
-			}
+			endpoints, err := leaderEndpoints(context.TODO(), client, tt.redisCluster)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEndpoints, endpoints)
 		})
 	}
 }
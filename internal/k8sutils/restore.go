@@ -0,0 +1,217 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	errRestoreWithoutStorage     = errors.New("restoreFrom requires persistent storage for the data volume")
+	errPointInTimeWithoutArchive = errors.New("restoreFrom.pointInTime requires aofArchive")
+)
+
+// RestoreSource is a completed RedisBackup, or a point in time of an AOF archive, resolved for seeding
+// the data volumes of new pods.
+type RestoreSource struct {
+	// Storage is the bucket holding the RDB files or the AOF archive, unset for VolumeSnapshot backups.
+	Storage rbvb2.S3Storage
+	Shards  []rbvb2.BackupShard
+	// PointInTime is set instead of Shards when replaying the AOF archive stored under ArchivePrefix.
+	PointInTime   *commonapi.PointInTimeRestore
+	ArchivePrefix string
+}
+
+// GetRestoreSource returns the source referenced by restoreFrom, or nil if restoreFrom is unset.
+// A backup must have completed with at least one and at most maxShards shards, a point in time
+// is looked up in the archive, which must be configured on the resource.
+func GetRestoreSource(ctx context.Context, ctrlClient client.Client, namespace string, from *commonapi.RestoreFrom, archive *rbvb2.AOFArchive, maxShards int) (*RestoreSource, error) {
+	if from == nil {
+		return nil, nil
+	}
+	if from.PointInTime != nil {
+		if archive == nil {
+			return nil, errPointInTimeWithoutArchive
+		}
+		return &RestoreSource{
+			Storage:       archive.S3,
+			PointInTime:   from.PointInTime,
+			ArchivePrefix: archive.S3.ArchivePrefix(namespace, from.PointInTime.SourcePod),
+		}, nil
+	}
+	backup := &rbvb2.RedisBackup{}
+	if err := ctrlClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: from.BackupName}, backup); err != nil {
+		return nil, fmt.Errorf("failed to get backup %s: %w", from.BackupName, err)
+	}
+	if backup.Status.Phase != rbvb2.RedisBackupCompleted {
+		return nil, fmt.Errorf("backup %s is %q, only completed backups can be restored", backup.Name, backup.Status.Phase)
+	}
+	if n := len(backup.Status.Shards); n == 0 || n > maxShards {
+		return nil, fmt.Errorf("backup %s has %d shards, expected between 1 and %d", backup.Name, n, maxShards)
+	}
+	source := &RestoreSource{Shards: backup.Status.Shards}
+	if backup.Spec.Storage.S3 != nil {
+		source.Storage = *backup.Spec.Storage.S3
+	}
+	return source, nil
+}
+
+// ResolveRestoreSource returns the source referenced by restoreFrom like GetRestoreSource, for the resource
+// whose data volumes are seeded by the StatefulSet stsName. Once the StatefulSet exists its volumes are
+// seeded, so a source that can't be resolved anymore, e.g. a backup pruned by its schedule or fewer leaders
+// than shards after a scale-down, no longer holds back the reconcile: it is logged and nil is returned.
+func ResolveRestoreSource(ctx context.Context, ctrlClient client.Client, k8sClient kubernetes.Interface, namespace, stsName string, from *commonapi.RestoreFrom, archive *rbvb2.AOFArchive, maxShards int) (*RestoreSource, error) {
+	restore, err := GetRestoreSource(ctx, ctrlClient, namespace, from, archive, maxShards)
+	if err == nil {
+		return restore, nil
+	}
+	if _, stsErr := k8sClient.AppsV1().StatefulSets(namespace).Get(ctx, stsName, metav1.GetOptions{}); stsErr != nil {
+		return nil, err
+	}
+	log.FromContext(ctx).Info("Ignoring restoreFrom of a resource that was already restored", "StatefulSet", stsName, "reason", err.Error())
+	return nil, nil
+}
+
+// FromVolumeSnapshots reports whether the data volumes are provisioned from VolumeSnapshots instead of
+// being seeded by the restore init container.
+func (r *RestoreSource) FromVolumeSnapshots() bool {
+	return len(r.Shards) > 0 && r.Shards[0].VolumeSnapshot != ""
+}
+
+// generateRestoreContainerDef returns the init container downloading the RDB file, or replaying the AOF archive,
+// into empty data volumes. With perOrdinal set every pod restores the shard matching its ordinal and pods without
+// one start empty, otherwise every pod restores the first shard.
+func generateRestoreContainerDef(name string, restore *RestoreSource, perOrdinal bool, containerParams containerParameters) corev1.Container {
+	s3 := restore.Storage
+	env := []corev1.EnvVar{
+		{Name: "RESTORE_S3_ENDPOINT", Value: s3.Endpoint},
+		{Name: "RESTORE_S3_BUCKET", Value: s3.Bucket},
+		{Name: "RESTORE_S3_REGION", Value: s3.Region},
+		{Name: "RESTORE_S3_INSECURE", Value: strconv.FormatBool(s3.Insecure)},
+		s3CredentialEnv(s3.CredentialsSecret, rbvb2.S3AccessKeyIDKey),
+		s3CredentialEnv(s3.CredentialsSecret, rbvb2.S3SecretAccessKeyKey),
+	}
+	if restore.PointInTime != nil {
+		env = append(env,
+			corev1.EnvVar{Name: "RESTORE_AOF_PREFIX", Value: restore.ArchivePrefix},
+			corev1.EnvVar{Name: "RESTORE_TARGET_TIME", Value: restore.PointInTime.TargetTime.UTC().Format(time.RFC3339)},
+		)
+	} else {
+		keys := make([]string, 0, len(restore.Shards))
+		for _, shard := range restore.Shards {
+			keys = append(keys, shard.ObjectKey)
+		}
+		env = append(env,
+			corev1.EnvVar{Name: "RESTORE_OBJECT_KEYS", Value: strings.Join(keys, ",")},
+			corev1.EnvVar{Name: "RESTORE_PER_ORDINAL", Value: strconv.FormatBool(perOrdinal)},
+		)
+	}
+	env = append(env, corev1.EnvVar{
+		Name: "POD_NAME",
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+		},
+	})
+	return corev1.Container{
+		Name:            "restore-data",
+		Image:           envs.GetInitContainerImage(),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{"/operator", "agent"},
+		Args:            []string{"restore"},
+		SecurityContext: containerParams.SecurityContext,
+		Env:             env,
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      util.CoalesceEnv1(common.EnvOperatorSTSPVCTemplateName, name),
+			MountPath: "/data",
+		}},
+	}
+}
+
+// s3CredentialEnv exposes a key of the S3 credentials secret under its own name.
+func s3CredentialEnv(secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: key,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: secretName,
+				},
+				Key: key,
+			},
+		},
+	}
+}
+
+// RestoreRedisClusterTopology forms the cluster from the leaders, assigning every leader the slots its shard
+// owned in the backup instead of the even split of ExecuteRedisClusterCommand. Slots not covered by the backup
+// are assigned to the first leader. It resumes an earlier restore that was interrupted.
+func RestoreRedisClusterTopology(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, restore *RestoreSource) error {
+	slots, err := restoredSlotRanges(restore.Shards, int(cr.Spec.GetReplicaCounts("leader")))
+	if err != nil {
+		return err
+	}
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return err
+	}
+	endpoints, err := leaderEndpoints(ctx, client, cr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := clusterOperationContext(ctx)
+	defer cancel()
+	if err := admin.CreateWithSlots(ctx, endpoints, slots); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("Restored cluster topology from backup", "Leaders", len(endpoints), "Shards", len(restore.Shards))
+	return nil
+}
+
+// restoredSlotRanges returns the slots each leader has to serve, indexed by leader ordinal.
+func restoredSlotRanges(shards []rbvb2.BackupShard, leaders int) ([][]redisservice.SlotRange, error) {
+	if len(shards) > leaders {
+		return nil, fmt.Errorf("the backup has %d shards for %d leaders", len(shards), leaders)
+	}
+	owners := make([][]redisservice.SlotRange, leaders)
+	covered := make([]bool, redisservice.TotalClusterSlots)
+	for i, shard := range shards {
+		ranges, err := redisservice.ParseSlotRanges(strings.Join(shard.Slots, ","))
+		if err != nil {
+			return nil, fmt.Errorf("shard %s: %w", shard.Pod, err)
+		}
+		for _, r := range ranges {
+			for slot := r.Start; slot <= r.End; slot++ {
+				covered[slot] = true
+			}
+		}
+		owners[i] = ranges
+	}
+	for start := 0; start < redisservice.TotalClusterSlots; start++ {
+		if covered[start] {
+			continue
+		}
+		end := start
+		for end+1 < redisservice.TotalClusterSlots && !covered[end+1] {
+			end++
+		}
+		owners[0] = append(owners[0], redisservice.SlotRange{Start: start, End: end})
+		start = end
+	}
+	return owners, nil
+}
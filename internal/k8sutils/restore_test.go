@@ -0,0 +1,154 @@
+package k8sutils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sClientFake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetRestoreSource(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, rbvb2.AddToScheme(scheme))
+	backup := func(name string, phase rbvb2.RedisBackupPhase, shards int) *rbvb2.RedisBackup {
+		b := &rbvb2.RedisBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       rbvb2.RedisBackupSpec{Storage: rbvb2.BackupStorage{S3: &rbvb2.S3Storage{Bucket: "backups"}}},
+			Status:     rbvb2.RedisBackupStatus{Phase: phase},
+		}
+		for i := 0; i < shards; i++ {
+			b.Status.Shards = append(b.Status.Shards, rbvb2.BackupShard{ObjectKey: name})
+		}
+		return b
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		backup("completed", rbvb2.RedisBackupCompleted, 3),
+		backup("running", rbvb2.RedisBackupRunning, 0),
+	).Build()
+
+	tests := []struct {
+		name      string
+		from      *commonapi.RestoreFrom
+		maxShards int
+		wantErr   bool
+		wantNil   bool
+	}{
+		{name: "unset", wantNil: true},
+		{name: "completed backup", from: &commonapi.RestoreFrom{BackupName: "completed"}, maxShards: 3},
+		{name: "too many shards", from: &commonapi.RestoreFrom{BackupName: "completed"}, maxShards: 1, wantErr: true},
+		{name: "running backup", from: &commonapi.RestoreFrom{BackupName: "running"}, maxShards: 3, wantErr: true},
+		{name: "missing backup", from: &commonapi.RestoreFrom{BackupName: "missing"}, maxShards: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := GetRestoreSource(context.Background(), cl, "default", tt.from, nil, tt.maxShards)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, source)
+				return
+			}
+			assert.Equal(t, "backups", source.Storage.Bucket)
+			assert.Len(t, source.Shards, 3)
+		})
+	}
+}
+
+func TestResolveRestoreSource(t *testing.T) {
+	cl := fake.NewClientBuilder().Build()
+	from := &commonapi.RestoreFrom{BackupName: "pruned"}
+
+	_, err := ResolveRestoreSource(context.Background(), cl, k8sClientFake.NewSimpleClientset(), "default", "redis-standalone", from, nil, 1)
+	assert.Error(t, err, "a restore that can't be resolved must block the first rollout")
+
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "redis-standalone", Namespace: "default"}}
+	source, err := ResolveRestoreSource(context.Background(), cl, k8sClientFake.NewSimpleClientset(sts), "default", "redis-standalone", from, nil, 1)
+	require.NoError(t, err)
+	assert.Nil(t, source)
+}
+
+func TestGetRestoreSourcePointInTime(t *testing.T) {
+	cl := fake.NewClientBuilder().Build()
+	from := &commonapi.RestoreFrom{PointInTime: &commonapi.PointInTimeRestore{
+		SourcePod:  "redis-standalone-0",
+		TargetTime: metav1.NewTime(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)),
+	}}
+
+	_, err := GetRestoreSource(context.Background(), cl, "default", from, nil, 1)
+	assert.ErrorIs(t, err, errPointInTimeWithoutArchive)
+
+	archive := &rbvb2.AOFArchive{S3: rbvb2.S3Storage{Bucket: "archive", Prefix: "redis"}}
+	source, err := GetRestoreSource(context.Background(), cl, "default", from, archive, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "archive", source.Storage.Bucket)
+	assert.Equal(t, "redis/default/redis-standalone-0/aof", source.ArchivePrefix)
+
+	container := generateRestoreContainerDef("redis-standalone", source, false, containerParameters{})
+	env := map[string]string{}
+	for _, e := range container.Env {
+		env[e.Name] = e.Value
+	}
+	assert.Equal(t, "redis/default/redis-standalone-0/aof", env["RESTORE_AOF_PREFIX"])
+	assert.Equal(t, "2024-05-01T10:00:00Z", env["RESTORE_TARGET_TIME"])
+	assert.NotContains(t, env, "RESTORE_OBJECT_KEYS")
+}
+
+func TestGenerateRestoreContainerDef(t *testing.T) {
+	restore := &RestoreSource{
+		Storage: rbvb2.S3Storage{Endpoint: "minio:9000", Bucket: "backups", CredentialsSecret: "s3"},
+		Shards:  []rbvb2.BackupShard{{ObjectKey: "a/leader-0.rdb"}, {ObjectKey: "a/leader-1.rdb"}},
+	}
+	container := generateRestoreContainerDef("redis-cluster-leader", restore, true, containerParameters{})
+
+	assert.Equal(t, "restore-data", container.Name)
+	assert.Equal(t, []string{"/operator", "agent"}, container.Command)
+	assert.Equal(t, []string{"restore"}, container.Args)
+	env := map[string]string{}
+	for _, e := range container.Env {
+		env[e.Name] = e.Value
+		if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+			assert.Equal(t, "s3", e.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+	assert.Equal(t, "a/leader-0.rdb,a/leader-1.rdb", env["RESTORE_OBJECT_KEYS"])
+	assert.Equal(t, "true", env["RESTORE_PER_ORDINAL"])
+	assert.Equal(t, "backups", env["RESTORE_S3_BUCKET"])
+	assert.Contains(t, env, rbvb2.S3AccessKeyIDKey)
+	require.Len(t, container.VolumeMounts, 1)
+	assert.Equal(t, "redis-cluster-leader", container.VolumeMounts[0].Name)
+	assert.Equal(t, "/data", container.VolumeMounts[0].MountPath)
+}
+
+func TestRestoredSlotRanges(t *testing.T) {
+	shards := []rbvb2.BackupShard{
+		{Pod: "old-leader-0", Slots: []string{"0-8191"}},
+		{Pod: "old-leader-1", Slots: []string{"8192-16000"}},
+	}
+	owners, err := restoredSlotRanges(shards, 3)
+	require.NoError(t, err)
+	assert.Equal(t, [][]redisservice.SlotRange{
+		// Slots missing from the backup go to the first leader.
+		{{Start: 0, End: 8191}, {Start: 16001, End: 16383}},
+		{{Start: 8192, End: 16000}},
+		// Leaders without a shard start empty.
+		nil,
+	}, owners)
+
+	_, err = restoredSlotRanges(shards, 1)
+	assert.ErrorContains(t, err, "2 shards for 1 leaders")
+	_, err = restoredSlotRanges([]rbvb2.BackupShard{{Pod: "old-leader-0", Slots: []string{"10-5"}}}, 1)
+	assert.ErrorContains(t, err, "shard old-leader-0")
+}
@@ -0,0 +1,237 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util/maps"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ServiceMonitorGVK and PrometheusRuleGVK are the Prometheus Operator APIs. The Prometheus Operator
+// types are not vendored, ServiceMonitors and PrometheusRules are handled as unstructured objects.
+var (
+	ServiceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	PrometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+)
+
+// metricsTarget is a metrics Service of a resource, scraped by the ServiceMonitor of the same name.
+type metricsTarget struct {
+	name   string
+	labels map[string]string
+}
+
+// newMetricsTarget returns the metrics Service CreateOrUpdateMetricsService creates for the pods
+// matching the stable labels of name, setup and role.
+func newMetricsTarget(name string, setup setupType, role string) metricsTarget {
+	labels := getRedisStableLabels(name, string(setup), role)
+	labels["app.kubernetes.io/component"] = "metrics"
+	return metricsTarget{name: name + "-metrics", labels: labels}
+}
+
+// prometheusRuleName returns the name of the PrometheusRule with the alerts of a resource.
+func prometheusRuleName(crName string) string {
+	return crName + "-alerts"
+}
+
+// CreateOrUpdateRedisMonitoring creates the ServiceMonitor and PrometheusRule of a standalone Redis.
+func CreateOrUpdateRedisMonitoring(ctx context.Context, cl client.Client, cr *rvb2.Redis) error {
+	return reconcileMonitoring(ctx, cl, cr.Spec.RedisExporter, cr.Namespace, cr.Name, standalone, redisAsOwner(cr),
+		[]metricsTarget{newMetricsTarget(cr.Name, standalone, "standalone")})
+}
+
+// CreateOrUpdateRedisReplicationMonitoring creates the ServiceMonitor and PrometheusRule of a RedisReplication.
+func CreateOrUpdateRedisReplicationMonitoring(ctx context.Context, cl client.Client, cr *rrvb2.RedisReplication) error {
+	return reconcileMonitoring(ctx, cl, cr.Spec.RedisExporter, cr.Namespace, cr.Name, replication, redisReplicationAsOwner(cr),
+		[]metricsTarget{newMetricsTarget(cr.Name, replication, "replication")})
+}
+
+// CreateOrUpdateRedisClusterMonitoring creates the ServiceMonitors of the leaders and the followers, and
+// the PrometheusRule, of a RedisCluster.
+func CreateOrUpdateRedisClusterMonitoring(ctx context.Context, cl client.Client, cr *rcvb2.RedisCluster) error {
+	return reconcileMonitoring(ctx, cl, cr.Spec.RedisExporter, cr.Namespace, cr.Name, cluster, redisClusterAsOwner(cr),
+		[]metricsTarget{newMetricsTarget(cr.Name+"-leader", cluster, "leader"), newMetricsTarget(cr.Name+"-follower", cluster, "follower")})
+}
+
+// CreateOrUpdateRedisSentinelMonitoring creates the ServiceMonitor and PrometheusRule of a RedisSentinel.
+func CreateOrUpdateRedisSentinelMonitoring(ctx context.Context, cl client.Client, cr *rsvb2.RedisSentinel) error {
+	return reconcileMonitoring(ctx, cl, cr.Spec.RedisExporter, cr.Namespace, cr.Name, sentinel, redisSentinelAsOwner(cr),
+		[]metricsTarget{newMetricsTarget(cr.Name+"-sentinel", sentinel, "sentinel")})
+}
+
+// reconcileMonitoring creates or updates a ServiceMonitor per metrics Service and the PrometheusRule of a
+// resource when the exporter is enabled with a serviceMonitor or a prometheusRule, and deletes the ones it
+// owns otherwise. Without the Prometheus Operator CRDs in the cluster there is nothing to do.
+func reconcileMonitoring(ctx context.Context, cl client.Client, exporter *commonapi.RedisExporter, namespace, crName string,
+	setup setupType, owner metav1.OwnerReference, targets []metricsTarget,
+) error {
+	enabled := exporter != nil && exporter.Enabled
+	for _, target := range targets {
+		if !enabled || exporter.ServiceMonitor == nil {
+			if err := deleteOwnedObject(ctx, cl, ServiceMonitorGVK, namespace, target.name, owner); err != nil {
+				return err
+			}
+			continue
+		}
+		desired := newServiceMonitor(namespace, target, exporter.ServiceMonitor)
+		AddOwnerRefToObject(desired, owner)
+		if err := createOrUpdateObject(ctx, cl, desired); err != nil {
+			return err
+		}
+	}
+
+	if !enabled || exporter.PrometheusRule == nil {
+		return deleteOwnedObject(ctx, cl, PrometheusRuleGVK, namespace, prometheusRuleName(crName), owner)
+	}
+	services := make([]string, 0, len(targets))
+	for _, target := range targets {
+		services = append(services, target.name)
+	}
+	selector := fmt.Sprintf(`namespace=%q,service=~%q`, namespace, strings.Join(services, "|"))
+	desired := newPrometheusRule(namespace, prometheusRuleName(crName), crName, setup, exporter.PrometheusRule, defaultAlertRules(setup, selector))
+	AddOwnerRefToObject(desired, owner)
+	return createOrUpdateObject(ctx, cl, desired)
+}
+
+// newServiceMonitor returns a ServiceMonitor scraping the exporter port of the metrics Service target.
+func newServiceMonitor(namespace string, target metricsTarget, config *commonapi.ServiceMonitorConfig) *unstructured.Unstructured {
+	endpoint := map[string]interface{}{
+		"port": common.RedisExporterPortName,
+		"path": "/metrics",
+	}
+	if config.Interval != "" {
+		endpoint["interval"] = config.Interval
+	}
+	if config.ScrapeTimeout != "" {
+		endpoint["scrapeTimeout"] = config.ScrapeTimeout
+	}
+	if len(config.Relabelings) > 0 {
+		relabelings := make([]interface{}, 0, len(config.Relabelings))
+		for _, relabeling := range config.Relabelings {
+			relabelings = append(relabelings, relabelConfig(relabeling))
+		}
+		endpoint["relabelings"] = relabelings
+	}
+	matchLabels := make(map[string]interface{}, len(target.labels))
+	for k, v := range target.labels {
+		matchLabels[k] = v
+	}
+
+	serviceMonitor := &unstructured.Unstructured{}
+	serviceMonitor.SetGroupVersionKind(ServiceMonitorGVK)
+	serviceMonitor.SetNamespace(namespace)
+	serviceMonitor.SetName(target.name)
+	serviceMonitor.SetLabels(maps.Merge(target.labels, config.Labels))
+	serviceMonitor.Object["spec"] = map[string]interface{}{
+		"selector":          map[string]interface{}{"matchLabels": matchLabels},
+		"namespaceSelector": map[string]interface{}{"matchNames": []interface{}{namespace}},
+		"endpoints":         []interface{}{endpoint},
+	}
+	return serviceMonitor
+}
+
+// relabelConfig returns relabeling in the form of the RelabelConfig of the Prometheus Operator.
+func relabelConfig(relabeling commonapi.RelabelConfig) map[string]interface{} {
+	config := map[string]interface{}{}
+	if len(relabeling.SourceLabels) > 0 {
+		sourceLabels := make([]interface{}, 0, len(relabeling.SourceLabels))
+		for _, label := range relabeling.SourceLabels {
+			sourceLabels = append(sourceLabels, label)
+		}
+		config["sourceLabels"] = sourceLabels
+	}
+	if relabeling.Separator != nil {
+		config["separator"] = *relabeling.Separator
+	}
+	if relabeling.TargetLabel != "" {
+		config["targetLabel"] = relabeling.TargetLabel
+	}
+	if relabeling.Regex != "" {
+		config["regex"] = relabeling.Regex
+	}
+	if relabeling.Modulus != 0 {
+		config["modulus"] = int64(relabeling.Modulus)
+	}
+	if relabeling.Replacement != nil {
+		config["replacement"] = *relabeling.Replacement
+	}
+	if relabeling.Action != "" {
+		config["action"] = relabeling.Action
+	}
+	return config
+}
+
+// createOrUpdateObject creates desired, or updates the labels and the spec of the existing object when
+// they differ. A kind whose CRD isn't installed is skipped.
+func createOrUpdateObject(ctx context.Context, cl client.Client, desired *unstructured.Unstructured) error {
+	gvk := desired.GroupVersionKind()
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(gvk)
+	err := cl.Get(ctx, client.ObjectKeyFromObject(desired), current)
+	if meta.IsNoMatchError(err) {
+		log.FromContext(ctx).V(1).Info("The CRD is not installed, skipping", "Kind", gvk.Kind)
+		return nil
+	}
+	if apierrors.IsNotFound(err) {
+		if err := cl.Create(ctx, desired); err != nil {
+			return fmt.Errorf("failed to create the %s %s: %w", gvk.Kind, desired.GetName(), err)
+		}
+		log.FromContext(ctx).Info("Created the "+gvk.Kind, gvk.Kind, desired.GetName())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the %s %s: %w", gvk.Kind, desired.GetName(), err)
+	}
+	if equality.Semantic.DeepEqual(current.Object["spec"], desired.Object["spec"]) &&
+		equality.Semantic.DeepEqual(current.GetLabels(), desired.GetLabels()) &&
+		equality.Semantic.DeepEqual(current.GetOwnerReferences(), desired.GetOwnerReferences()) {
+		return nil
+	}
+	current.Object["spec"] = desired.Object["spec"]
+	current.SetLabels(desired.GetLabels())
+	current.SetOwnerReferences(desired.GetOwnerReferences())
+	if err := cl.Update(ctx, current); err != nil {
+		return fmt.Errorf("failed to update the %s %s: %w", gvk.Kind, desired.GetName(), err)
+	}
+	log.FromContext(ctx).Info("Updated the "+gvk.Kind, gvk.Kind, desired.GetName())
+	return nil
+}
+
+// deleteOwnedObject deletes the object of gvk named name when owner owns it.
+func deleteOwnedObject(ctx context.Context, cl client.Client, gvk schema.GroupVersionKind, namespace, name string, owner metav1.OwnerReference) error {
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(gvk)
+	err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, current)
+	if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the %s %s: %w", gvk.Kind, name, err)
+	}
+	owned := false
+	for _, ref := range current.GetOwnerReferences() {
+		owned = owned || ref.UID == owner.UID
+	}
+	if !owned {
+		return nil
+	}
+	if err := cl.Delete(ctx, current); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the %s %s: %w", gvk.Kind, name, err)
+	}
+	log.FromContext(ctx).Info("Deleted the "+gvk.Kind, gvk.Kind, name)
+	return nil
+}
@@ -0,0 +1,146 @@
+package k8sutils
+
+import (
+	"context"
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rsvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redissentinel/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newMonitoringClient() client.Client {
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(ServiceMonitorGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(PrometheusRuleGVK, &unstructured.Unstructured{})
+	return fake.NewClientBuilder().WithScheme(scheme).Build()
+}
+
+func getMonitoringObject(ctx context.Context, cl client.Client, gvk schema.GroupVersionKind, name string) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	return obj, cl.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, obj)
+}
+
+func TestCreateOrUpdateRedisClusterMonitoring(t *testing.T) {
+	cl := newMonitoringClient()
+	ctx := context.Background()
+
+	cr := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "default", UID: "cache-uid"},
+		Spec: rcvb2.RedisClusterSpec{
+			ClusterSize: ptr.To(int32(3)),
+			RedisExporter: &commonapi.RedisExporter{
+				Enabled: true,
+				ServiceMonitor: &commonapi.ServiceMonitorConfig{
+					Interval: "15s",
+					Labels:   map[string]string{"release": "prometheus"},
+					Relabelings: []commonapi.RelabelConfig{
+						{SourceLabels: []string{"__meta_kubernetes_pod_node_name"}, TargetLabel: "node", Action: "replace"},
+					},
+				},
+				PrometheusRule: &commonapi.PrometheusRuleConfig{AdditionalLabels: map[string]string{"team": "cache"}},
+			},
+		},
+	}
+	require.NoError(t, CreateOrUpdateRedisClusterMonitoring(ctx, cl, cr))
+
+	for _, name := range []string{"cache-leader-metrics", "cache-follower-metrics"} {
+		serviceMonitor, err := getMonitoringObject(ctx, cl, ServiceMonitorGVK, name)
+		require.NoError(t, err)
+		require.Len(t, serviceMonitor.GetOwnerReferences(), 1)
+		assert.Equal(t, "prometheus", serviceMonitor.GetLabels()["release"])
+		endpoints, _, _ := unstructured.NestedSlice(serviceMonitor.Object, "spec", "endpoints")
+		require.Len(t, endpoints, 1)
+		endpoint := endpoints[0].(map[string]interface{})
+		assert.Equal(t, "redis-exporter", endpoint["port"])
+		assert.Equal(t, "15s", endpoint["interval"])
+		assert.Len(t, endpoint["relabelings"], 1)
+	}
+	leader, _ := getMonitoringObject(ctx, cl, ServiceMonitorGVK, "cache-leader-metrics")
+	matchLabels, _, _ := unstructured.NestedStringMap(leader.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, map[string]string{
+		"app": "cache-leader", "redis_setup_type": "cluster", "role": "leader", "app.kubernetes.io/component": "metrics",
+	}, matchLabels)
+
+	rule, err := getMonitoringObject(ctx, cl, PrometheusRuleGVK, "cache-alerts")
+	require.NoError(t, err)
+	groups, _, _ := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	require.Len(t, groups, 1)
+	alerts := map[string]map[string]interface{}{}
+	for _, item := range groups[0].(map[string]interface{})["rules"].([]interface{}) {
+		alert := item.(map[string]interface{})
+		alerts[alert["alert"].(string)] = alert
+	}
+	assert.Contains(t, alerts, "RedisClusterStateFail")
+	assert.NotContains(t, alerts, "RedisMasterMissing")
+	assert.Contains(t, alerts["RedisDown"]["expr"], `service=~"cache-leader-metrics|cache-follower-metrics"`)
+	assert.Equal(t, map[string]interface{}{"severity": "critical", "team": "cache"}, alerts["RedisDown"]["labels"])
+
+	// Dropping the serviceMonitor deletes the ServiceMonitors it owns, disabling the exporter the rule.
+	cr.Spec.RedisExporter.ServiceMonitor = nil
+	require.NoError(t, CreateOrUpdateRedisClusterMonitoring(ctx, cl, cr))
+	_, err = getMonitoringObject(ctx, cl, ServiceMonitorGVK, "cache-leader-metrics")
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = getMonitoringObject(ctx, cl, PrometheusRuleGVK, "cache-alerts")
+	require.NoError(t, err)
+
+	cr.Spec.RedisExporter.Enabled = false
+	require.NoError(t, CreateOrUpdateRedisClusterMonitoring(ctx, cl, cr))
+	_, err = getMonitoringObject(ctx, cl, PrometheusRuleGVK, "cache-alerts")
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestCreateOrUpdateRedisSentinelMonitoring(t *testing.T) {
+	cl := newMonitoringClient()
+	ctx := context.Background()
+
+	// A ServiceMonitor the operator doesn't own isn't deleted with the serviceMonitor unset.
+	foreign := newServiceMonitor("default", newMetricsTarget("sentinel-sentinel", sentinel, "sentinel"), &commonapi.ServiceMonitorConfig{})
+	require.NoError(t, cl.Create(ctx, foreign))
+
+	cr := &rsvb2.RedisSentinel{
+		ObjectMeta: metav1.ObjectMeta{Name: "sentinel", Namespace: "default", UID: "sentinel-uid"},
+		Spec: rsvb2.RedisSentinelSpec{
+			RedisExporter: &commonapi.RedisExporter{Enabled: true, PrometheusRule: &commonapi.PrometheusRuleConfig{}},
+		},
+	}
+	require.NoError(t, CreateOrUpdateRedisSentinelMonitoring(ctx, cl, cr))
+	_, err := getMonitoringObject(ctx, cl, ServiceMonitorGVK, "sentinel-sentinel-metrics")
+	require.NoError(t, err)
+
+	rule, err := getMonitoringObject(ctx, cl, PrometheusRuleGVK, "sentinel-alerts")
+	require.NoError(t, err)
+	groups, _, _ := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	var alerts []string
+	for _, item := range groups[0].(map[string]interface{})["rules"].([]interface{}) {
+		alerts = append(alerts, item.(map[string]interface{})["alert"].(string))
+	}
+	assert.Equal(t, []string{"RedisSentinelDown", "RedisSentinelMasterDown", "RedisSentinelNoHealthyReplica"}, alerts)
+}
+
+func TestCreateOrUpdateRedisClusterMonitoring_NoPrometheusOperator(t *testing.T) {
+	cl := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	cr := &rcvb2.RedisCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "default"},
+		Spec: rcvb2.RedisClusterSpec{
+			ClusterSize: ptr.To(int32(3)),
+			RedisExporter: &commonapi.RedisExporter{
+				Enabled:        true,
+				ServiceMonitor: &commonapi.ServiceMonitorConfig{},
+				PrometheusRule: &commonapi.PrometheusRuleConfig{},
+			},
+		},
+	}
+	assert.NoError(t, CreateOrUpdateRedisClusterMonitoring(context.Background(), cl, cr))
+}
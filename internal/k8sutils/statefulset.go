@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/consts"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
 	"github.com/OT-CONTAINER-KIT/redis-operator/internal/envs"
@@ -59,11 +60,14 @@ func (s *StatefulSetService) IsStatefulSetReady(ctx context.Context, namespace,
 		replicas = int(*sts.Spec.Replicas)
 	}
 
-	if expectedUpdateReplicas := replicas - partition; sts.Status.UpdatedReplicas < int32(expectedUpdateReplicas) {
+	// With OnDelete the pods move to the update revision as they are deleted, by the operator for a
+	// managed upgrade, so pods of the previous revision don't hold the StatefulSet back.
+	onDelete := sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType
+	if expectedUpdateReplicas := replicas - partition; !onDelete && sts.Status.UpdatedReplicas < int32(expectedUpdateReplicas) {
 		log.FromContext(ctx).V(1).Info("StatefulSet is not ready", "Status.UpdatedReplicas", sts.Status.UpdatedReplicas, "ExpectedUpdateReplicas", expectedUpdateReplicas)
 		return false
 	}
-	if partition == 0 && sts.Status.CurrentRevision != sts.Status.UpdateRevision {
+	if !onDelete && partition == 0 && sts.Status.CurrentRevision != sts.Status.UpdateRevision {
 		log.FromContext(ctx).V(1).Info("StatefulSet is not ready", "Status.CurrentRevision", sts.Status.CurrentRevision, "Status.UpdateRevision", sts.Status.UpdateRevision)
 		return false
 	}
@@ -120,11 +124,13 @@ type statefulSetParameters struct {
 	HostNetwork                          bool
 	MinReadySeconds                      int32
 	PodManagementPolicy                  *string
+	ReadinessGates                       []corev1.PodReadinessGate
 }
 
 // containerParameters will define container input params
 type containerParameters struct {
 	Image                        string
+	Engine                       commonapi.Engine
 	ImagePullPolicy              corev1.PullPolicy
 	Resources                    *corev1.ResourceRequirements
 	MaxMemoryPercentOfLimit      *int
@@ -157,6 +163,11 @@ type containerParameters struct {
 	SentinelMasterName string
 	SentinelPort       int
 	PreStopWaitSeconds int
+	// HandoverWaitSeconds is how long the preStop hook of a master waits for the operator to hand
+	// its role over before failing over by itself, see the DrainHandover feature gate.
+	HandoverWaitSeconds int
+	// AOFArchive adds the sidecar archiving the AOF files, it requires PersistenceEnabled.
+	AOFArchive *rbvb2.AOFArchive
 }
 
 type initContainerParameters struct {
@@ -172,6 +183,8 @@ type initContainerParameters struct {
 	AdditionalVolume      []corev1.Volume
 	AdditionalMountPath   []corev1.VolumeMount
 	SecurityContext       *corev1.SecurityContext
+	Restore               *RestoreSource
+	RestorePerOrdinal     bool
 }
 
 // CreateOrUpdateStateFul method will create or update Redis service
@@ -184,6 +197,11 @@ func CreateOrUpdateStateFul(ctx context.Context, cl kubernetes.Interface, namesp
 			return err
 		}
 		if apierrors.IsNotFound(err) {
+			if restore := initcontainerParams.Restore; restore != nil && restore.FromVolumeSnapshots() {
+				if err := createRestoredPVCs(ctx, cl, statefulSetDef, restore, initcontainerParams.RestorePerOrdinal); err != nil {
+					return err
+				}
+			}
 			return createStatefulSet(ctx, cl, namespace, statefulSetDef)
 		}
 		return err
@@ -335,6 +353,7 @@ func generateStatefulSetsDef(stsMeta metav1.ObjectMeta, params statefulSetParame
 					Affinity:                      params.Affinity,
 					TerminationGracePeriodSeconds: params.TerminationGracePeriodSeconds,
 					HostNetwork:                   params.HostNetwork,
+					ReadinessGates:                params.ReadinessGates,
 					Volumes:                       []corev1.Volume{generateConfigVolume(common.VolumeNameConfig)},
 				},
 			},
@@ -486,19 +505,20 @@ func generateContainerDef(name string, containerParams containerParameters, clus
 				containerParams.Resources,
 				containerParams.MaxMemoryPercentOfLimit,
 			),
-			ReadinessProbe: getProbeInfo(containerParams.ReadinessProbe, sentinelCntr, enableTLS),
-			LivenessProbe:  getProbeInfo(containerParams.LivenessProbe, sentinelCntr, enableTLS),
+			ReadinessProbe: getProbeInfo(containerParams.ReadinessProbe, sentinelCntr, enableTLS, containerParams.Engine),
+			LivenessProbe:  getProbeInfo(containerParams.LivenessProbe, sentinelCntr, enableTLS, containerParams.Engine),
 			VolumeMounts:   getVolumeMount(name, containerParams.PersistenceEnabled, clusterMode, nodeConfVolume, externalConfig, mountpath, containerParams.TLSConfig, containerParams.ACLConfig),
 		},
 	}
+	containerDefinition[0].Env = append(containerDefinition[0].Env, engineEnvVars(containerParams.Engine)...)
 
 	if features.Enabled(features.GenerateConfigInInitContainer) {
 		if sentinelCntr {
-			containerDefinition[0].Command = []string{"redis-sentinel"}
+			containerDefinition[0].Command = []string{engineCommand(containerParams.Engine, "sentinel")}
 			containerDefinition[0].Args = []string{"/etc/redis/sentinel.conf"}
 		} else {
-			containerDefinition[0].Command = []string{"redis-server"}
-			containerDefinition[0].Args = []string{"/etc/redis/redis.conf"}
+			containerDefinition[0].Command = []string{engineCommand(containerParams.Engine, "server")}
+			containerDefinition[0].Args = []string{engineConfigFile(containerParams.Engine)}
 		}
 	}
 
@@ -513,12 +533,14 @@ func generateContainerDef(name string, containerParams containerParameters, clus
 	}
 
 	preStopCfg := PreStopConfig{
-		Role:               containerParams.Role,
-		EnableTLS:          enableTLS,
-		SentinelService:    containerParams.SentinelService,
-		SentinelMasterName: containerParams.SentinelMasterName,
-		SentinelPort:       containerParams.SentinelPort,
-		WaitSeconds:        containerParams.PreStopWaitSeconds,
+		Role:                containerParams.Role,
+		Engine:              containerParams.Engine,
+		EnableTLS:           enableTLS,
+		SentinelService:     containerParams.SentinelService,
+		SentinelMasterName:  containerParams.SentinelMasterName,
+		SentinelPort:        containerParams.SentinelPort,
+		WaitSeconds:         containerParams.PreStopWaitSeconds,
+		HandoverWaitSeconds: containerParams.HandoverWaitSeconds,
 	}
 	if preStopCmd := GeneratePreStopCommand(preStopCfg); preStopCmd != "" {
 		containerDefinition[0].Lifecycle = &corev1.Lifecycle{
@@ -545,6 +567,10 @@ func generateContainerDef(name string, containerParams containerParameters, clus
 	if enableMetrics {
 		containerDefinition = append(containerDefinition, enableRedisMonitoring(containerParams))
 	}
+	if containerParams.AOFArchive != nil {
+		containerDefinition[0].Env = append(containerDefinition[0].Env, aofArchiveEnvVars(containerParams.AOFArchive)...)
+		containerDefinition = append(containerDefinition, generateAOFArchiveContainerDef(name, containerParams.AOFArchive, containerParams.SecurityContext))
+	}
 	for _, sidecar := range sidecars {
 		container := corev1.Container{
 			Name:            sidecar.Name,
@@ -590,7 +616,9 @@ const redisCLIAuthSanitizer = `if [ -n "${REDISCLI_AUTH:-}" ]; then REDISCLI_AUT
 
 // PreStopConfig holds the inputs needed to render a container preStop hook.
 type PreStopConfig struct {
-	Role      string
+	Role string
+	// Engine selects the cli the hook runs, redis-cli unless set.
+	Engine    commonapi.Engine
 	EnableTLS bool
 	// SentinelService, SentinelMasterName and SentinelPort describe the
 	// Sentinel that manages failover for the "replication" role. They must be
@@ -605,6 +633,11 @@ type PreStopConfig struct {
 	// to be demoted to a slave. It is kept below terminationGracePeriodSeconds
 	// so the hook returns before the kubelet sends SIGKILL.
 	WaitSeconds int
+	// HandoverWaitSeconds, when positive, makes the hook of a master first wait
+	// that long for the operator to hand the master role over to a replica, and
+	// return as soon as the node is demoted. Only then does the hook fail over by
+	// itself, within what is left of WaitSeconds.
+	HandoverWaitSeconds int
 }
 
 // GeneratePreStopCommand generates the preStop script based on the Redis role.
@@ -616,10 +649,11 @@ type PreStopConfig struct {
 // operator sets on the pod, so the password is never passed on the command line.
 func GeneratePreStopCommand(cfg PreStopConfig) string {
 	tlsArgs := GenerateTLSArgs(cfg.EnableTLS)
+	cli := engineCommand(cfg.Engine, "cli")
 
 	switch cfg.Role {
 	case "cluster":
-		return generateClusterPreStop(tlsArgs)
+		return generateClusterPreStop(cli, tlsArgs, cfg.HandoverWaitSeconds)
 	case "replication":
 		// Without a Sentinel managing failover there is nothing to fail over
 		// to; installing the hook would make every master termination block on
@@ -627,7 +661,7 @@ func GeneratePreStopCommand(cfg PreStopConfig) string {
 		if cfg.SentinelService == "" {
 			return ""
 		}
-		return generateReplicationPreStop(tlsArgs, cfg)
+		return generateReplicationPreStop(cli, tlsArgs, cfg)
 	default:
 		return ""
 	}
@@ -649,6 +683,30 @@ func replicationPreStopWaitSeconds(gracePeriodSeconds *int64) int {
 	return int(max(grace-headroomSeconds, 1))
 }
 
+// handoverWaitSeconds gives the operator half of the demotion wait to hand the
+// master role over, leaving the other half to the failover of the hook itself.
+func handoverWaitSeconds(gracePeriodSeconds *int64) int {
+	return max(replicationPreStopWaitSeconds(gracePeriodSeconds)/2, 1)
+}
+
+// generateHandoverWait generates the part of a preStop script waiting up to
+// waitSeconds for the operator to demote the master, it ends the hook once the
+// node no longer is a master. It is empty when waitSeconds isn't positive.
+func generateHandoverWait(cli, tlsArgs string, waitSeconds int) string {
+	if waitSeconds <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(`for i in $(seq 1 %d); do
+    ROLE=$(%s -h $(hostname) -p ${REDIS_PORT} %s info replication | awk -F: '/role:master/ {print "master"}')
+    if [ "$ROLE" != "master" ]; then
+        exit 0
+    fi
+    sleep 1
+done
+
+`, waitSeconds, cli, tlsArgs)
+}
+
 // GenerateTLSArgs constructs TLS arguments for redis-cli. Authentication is
 // supplied via the REDISCLI_AUTH environment variable, never on the command line.
 func GenerateTLSArgs(enableTLS bool) string {
@@ -662,13 +720,14 @@ func GenerateTLSArgs(enableTLS bool) string {
 
 // generateClusterPreStop generates the preStop script for Redis cluster mode.
 // It identifies the master node and triggers a failover to the best available slave before shutdown.
-func generateClusterPreStop(tlsArgs string) string {
+// With a positive handoverWaitSeconds it first gives the operator that long to do the failover.
+func generateClusterPreStop(cli, tlsArgs string, handoverWaitSeconds int) string {
 	return fmt.Sprintf(`#!/bin/sh
 %s
-ROLE=$(redis-cli -h $(hostname) -p ${REDIS_PORT} %s info replication | awk -F: '/role:master/ {print "master"}')
+%sROLE=$(%s -h $(hostname) -p ${REDIS_PORT} %s info replication | awk -F: '/role:master/ {print "master"}')
 
 if [ "$ROLE" = "master" ]; then
-    BEST_SLAVE=$(redis-cli -h $(hostname) -p ${REDIS_PORT} %s info replication | awk -F: '
+    BEST_SLAVE=$(%s -h $(hostname) -p ${REDIS_PORT} %s info replication | awk -F: '
         BEGIN { maxOffset = -1; bestSlave = "" }
         /slave[0-9]+:ip/ {
             split($2, a, ",");
@@ -685,9 +744,9 @@ if [ "$ROLE" = "master" ]; then
     ')
 
     if [ -n "$BEST_SLAVE" ]; then
-        redis-cli -h "$BEST_SLAVE" -p ${REDIS_PORT} %s cluster failover
+        %s -h "$BEST_SLAVE" -p ${REDIS_PORT} %s cluster failover
     fi
-fi`, redisCLIAuthSanitizer, tlsArgs, tlsArgs, tlsArgs)
+fi`, redisCLIAuthSanitizer, generateHandoverWait(cli, tlsArgs, handoverWaitSeconds), cli, tlsArgs, cli, tlsArgs, cli, tlsArgs)
 }
 
 // generateReplicationPreStop generates the preStop script for Redis replication mode.
@@ -697,28 +756,29 @@ fi`, redisCLIAuthSanitizer, tlsArgs, tlsArgs, tlsArgs)
 // The Sentinel service, master group name and port are injected from the actual
 // (embedded) Sentinel configuration rather than derived in shell, so they stay
 // correct regardless of the resource name or topology. The demotion wait is
-// bounded by cfg.WaitSeconds so the hook returns before the grace period expires.
-func generateReplicationPreStop(tlsArgs string, cfg PreStopConfig) string {
+// bounded by cfg.WaitSeconds so the hook returns before the grace period expires,
+// including the cfg.HandoverWaitSeconds left to the operator to do the failover.
+func generateReplicationPreStop(cli, tlsArgs string, cfg PreStopConfig) string {
 	sentinelPort := cfg.SentinelPort
 	if sentinelPort == 0 {
 		sentinelPort = 26379
 	}
-	waitSeconds := max(cfg.WaitSeconds, 1)
+	waitSeconds := max(cfg.WaitSeconds-max(cfg.HandoverWaitSeconds, 0), 1)
 	return fmt.Sprintf(`#!/bin/sh
 %s
-ROLE=$(redis-cli -h $(hostname) -p ${REDIS_PORT} %s info replication | awk -F: '/role:master/ {print "master"}')
+%sROLE=$(%s -h $(hostname) -p ${REDIS_PORT} %s info replication | awk -F: '/role:master/ {print "master"}')
 
 if [ "$ROLE" = "master" ]; then
-    redis-cli -h "%s" -p %d SENTINEL FAILOVER %s
+    %s -h "%s" -p %d SENTINEL FAILOVER %s
 
     for i in $(seq 1 %d); do
-        NEW_ROLE=$(redis-cli -h $(hostname) -p ${REDIS_PORT} %s info replication | awk -F: '/role:slave/ {print "slave"}')
+        NEW_ROLE=$(%s -h $(hostname) -p ${REDIS_PORT} %s info replication | awk -F: '/role:slave/ {print "slave"}')
         if [ "$NEW_ROLE" = "slave" ]; then
             break
         fi
         sleep 1
     done
-fi`, redisCLIAuthSanitizer, tlsArgs, cfg.SentinelService, sentinelPort, cfg.SentinelMasterName, waitSeconds, tlsArgs)
+fi`, redisCLIAuthSanitizer, generateHandoverWait(cli, tlsArgs, cfg.HandoverWaitSeconds), cli, tlsArgs, cli, cfg.SentinelService, sentinelPort, cfg.SentinelMasterName, waitSeconds, cli, tlsArgs)
 }
 
 func generateInitContainerDef(role, name string, initcontainerParams initContainerParameters, externalConfig *string, mountpath []corev1.VolumeMount, containerParams containerParameters, clusterVersion *string) []corev1.Container {
@@ -730,6 +790,8 @@ func generateInitContainerDef(role, name string, initcontainerParams initContain
 			ptr.Deref(containerParams.EnvVars, []corev1.EnvVar{}),
 			ptr.Deref(containerParams.AdditionalEnvVariable, []corev1.EnvVar{})...,
 		)
+		envVars = append(envVars, aofArchiveEnvVars(containerParams.AOFArchive)...)
+		envVars = append(envVars, engineEnvVars(containerParams.Engine)...)
 
 		VolumeMounts := []corev1.VolumeMount{
 			generateConfigVolumeMount(common.VolumeNameConfig),
@@ -772,6 +834,10 @@ func generateInitContainerDef(role, name string, initcontainerParams initContain
 		containers = append(containers, container)
 	}
 
+	if initcontainerParams.Restore != nil && !initcontainerParams.Restore.FromVolumeSnapshots() {
+		containers = append(containers, generateRestoreContainerDef(name, initcontainerParams.Restore, initcontainerParams.RestorePerOrdinal, containerParams))
+	}
+
 	if initcontainerParams.Enabled != nil && *initcontainerParams.Enabled {
 		containers = append(containers, corev1.Container{
 			Name:            "init" + name,
@@ -792,7 +858,7 @@ func GenerateTLSEnvironmentVariables(tlsconfig *commonapi.TLSConfig) []corev1.En
 	var envVars []corev1.EnvVar
 	root := "/tls/"
 	caCert, tlsCert, tlsCertKey := getTLSSecretKeys(tlsconfig)
-	hasExplicitCA := tlsconfig != nil && tlsconfig.CaCertFile != ""
+	hasExplicitCA := hasTLSCA(tlsconfig)
 
 	envVars = append(envVars, corev1.EnvVar{
 		Name:  "TLS_MODE",
@@ -851,7 +917,7 @@ func getExporterEnvironmentVariables(params containerParameters) []corev1.EnvVar
 			Name:  "REDIS_EXPORTER_TLS_CLIENT_CERT_FILE",
 			Value: path.Join("/tls/", tlsCert),
 		})
-		if params.TLSConfig.CaCertFile != "" {
+		if hasTLSCA(params.TLSConfig) {
 			envVars = append(envVars, corev1.EnvVar{
 				Name:  "REDIS_EXPORTER_TLS_CA_CERT_FILE",
 				Value: path.Join("/tls/", caCert),
@@ -960,13 +1026,13 @@ func getVolumeMount(name string, persistenceEnabled *bool, clusterMode bool, nod
 // getProbeInfo generate probe for Redis StatefulSet
 // The `ping` command will exit successfully even if the node is loading,
 // so we need to verify that the Redis `ping` command returns "PONG".
-func getProbeInfo(probe *corev1.Probe, sentinel, enableTLS bool) *corev1.Probe {
+func getProbeInfo(probe *corev1.Probe, sentinel, enableTLS bool, engine commonapi.Engine) *corev1.Probe {
 	if probe == nil {
 		probe = &corev1.Probe{}
 	}
 	if probe.Exec == nil && probe.HTTPGet == nil && probe.TCPSocket == nil && probe.GRPC == nil {
 		redisHealthCheck := []string{
-			"redis-cli",
+			engineCommand(engine, "cli"),
 			"-h", "$(hostname)",
 		}
 		if sentinel {
@@ -215,6 +215,34 @@ func TestReplicationPreStopWaitSeconds(t *testing.T) {
 	}
 }
 
+func TestHandoverWaitSeconds(t *testing.T) {
+	assert.Equal(t, 10, handoverWaitSeconds(nil))
+	assert.Equal(t, 25, handoverWaitSeconds(ptr.To(int64(60))))
+	assert.Equal(t, 1, handoverWaitSeconds(ptr.To(int64(5))))
+}
+
+func TestGeneratePreStopCommandHandoverWait(t *testing.T) {
+	cluster := GeneratePreStopCommand(PreStopConfig{Role: "cluster", HandoverWaitSeconds: 10})
+	// The hook leaves the operator the time to demote the master, and ends once it is demoted.
+	assert.Contains(t, cluster, "seq 1 10")
+	assert.Contains(t, cluster, `if [ "$ROLE" != "master" ]; then
+        exit 0`)
+	assert.Less(t, strings.Index(cluster, "seq 1 10"), strings.Index(cluster, "cluster failover"))
+	assert.NotContains(t, GeneratePreStopCommand(PreStopConfig{Role: "cluster"}), "exit 0")
+
+	replication := GeneratePreStopCommand(PreStopConfig{
+		Role:                "replication",
+		SentinelService:     "my-replication-s-hl",
+		SentinelMasterName:  "mymaster",
+		WaitSeconds:         20,
+		HandoverWaitSeconds: 10,
+	})
+	assert.Contains(t, replication, "seq 1 10")
+	// The failover of the hook only gets what is left of the demotion wait.
+	assert.Less(t, strings.Index(replication, "seq 1 10"), strings.Index(replication, "SENTINEL FAILOVER"))
+	assert.Equal(t, 2, strings.Count(replication, "seq 1 10"))
+}
+
 func TestGetVolumeMount(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -455,6 +483,50 @@ func Test_GetStatefulSet(t *testing.T) {
 	}
 }
 
+func TestIsStatefulSetReady(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy appsv1.StatefulSetUpdateStrategy
+		status   appsv1.StatefulSetStatus
+		ready    bool
+	}{
+		{
+			name:   "all pods on the update revision",
+			status: appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 3, CurrentRevision: "rev-2", UpdateRevision: "rev-2"},
+			ready:  true,
+		},
+		{
+			name:   "rolling update in progress",
+			status: appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "rev-1", UpdateRevision: "rev-2"},
+			ready:  false,
+		},
+		{
+			name:     "OnDelete with pods of the previous revision",
+			strategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+			status:   appsv1.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "rev-1", UpdateRevision: "rev-2"},
+			ready:    true,
+		},
+		{
+			name:     "OnDelete with a pod not ready",
+			strategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+			status:   appsv1.StatefulSetStatus{ReadyReplicas: 2, UpdatedReplicas: 1, CurrentRevision: "rev-1", UpdateRevision: "rev-2"},
+			ready:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-sts", Namespace: "test-ns"},
+				Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To(int32(3)), UpdateStrategy: tt.strategy},
+				Status:     tt.status,
+			}
+			service := NewStatefulSetService(k8sClientFake.NewSimpleClientset(sts))
+			assert.Equal(t, tt.ready, service.IsStatefulSetReady(context.TODO(), "test-ns", "test-sts"))
+		})
+	}
+}
+
 func Test_createStatefulSet(t *testing.T) {
 	tests := []struct {
 		name    string
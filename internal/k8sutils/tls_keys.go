@@ -25,3 +25,9 @@ func getTLSSecretKeys(tlsConfig *commonapi.TLSConfig) (caFile, certFile, keyFile
 	keyFile = tlsKeyOrDefault(tlsConfig.KeyFile, defaultTLSKeyFile)
 	return caFile, certFile, keyFile
 }
+
+// hasTLSCA returns whether the servers are configured with the CA of the TLS secret, which is always the
+// case for certificates issued by cert-manager.
+func hasTLSCA(tlsConfig *commonapi.TLSConfig) bool {
+	return tlsConfig != nil && (tlsConfig.CaCertFile != "" || tlsConfig.IssuerRef != nil)
+}
@@ -0,0 +1,177 @@
+package k8sutils
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	redis "github.com/redis/go-redis/v9"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ReloadRedisTLS reloads the certificates of the TLS secret on a standalone Redis, see reloadTLS.
+func ReloadRedisTLS(ctx context.Context, client kubernetes.Interface, cr *rvb2.Redis) ([]string, error) {
+	if cr.Spec.TLS == nil {
+		return nil, nil
+	}
+	pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, cr.Name+"-0", metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return reloadServersTLS(ctx, client, cr.Namespace, cr.Spec.TLS, []corev1.Pod{*pod}, common.RedisPort, func(pod *corev1.Pod) *redis.Client {
+		return configureRedisStandaloneClient(ctx, client, cr, pod.Name)
+	})
+}
+
+// ReloadRedisReplicationTLS reloads the certificates of the TLS secret on a RedisReplication, see reloadTLS.
+func ReloadRedisReplicationTLS(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication) ([]string, error) {
+	if cr.Spec.TLS == nil {
+		return nil, nil
+	}
+	pods, err := replicationPods(ctx, client, cr)
+	if err != nil {
+		return nil, err
+	}
+	return reloadServersTLS(ctx, client, cr.Namespace, cr.Spec.TLS, pods, common.RedisPort, func(pod *corev1.Pod) *redis.Client {
+		return configureRedisReplicationClientForPod(ctx, client, cr, pod)
+	})
+}
+
+// ReloadRedisClusterTLS reloads the certificates of the TLS secret on a RedisCluster, see reloadTLS.
+func ReloadRedisClusterTLS(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) ([]string, error) {
+	if cr.Spec.TLS == nil {
+		return nil, nil
+	}
+	return reloadServersTLS(ctx, client, cr.Namespace, cr.Spec.TLS, clusterPods(ctx, client, cr), *cr.Spec.Port, func(pod *corev1.Pod) *redis.Client {
+		return configureRedisClient(ctx, client, cr, pod.Name)
+	})
+}
+
+func reloadServersTLS(ctx context.Context, client kubernetes.Interface, namespace string, tlsConfig *commonapi.TLSConfig,
+	pods []corev1.Pod, port int, connect func(*corev1.Pod) *redis.Client,
+) ([]string, error) {
+	want, err := tlsSecretCertificate(ctx, client, namespace, tlsConfig)
+	if apierrors.IsNotFound(err) {
+		// Not issued yet, the pods can't start without it.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	served := func(ctx context.Context, pod *corev1.Pod) ([]byte, error) {
+		return servedCertificate(ctx, pod, port)
+	}
+	return reloadTLS(ctx, pods, connect, served, tlsConfig, want)
+}
+
+// reloadTLS compares the certificate served by every running pod with want, the certificate of the TLS
+// secret, and has the servers serving another one reload the files of the secret mounted in the pod with
+// CONFIG SET, which makes Redis 6.2 and later load them again, so a renewed certificate is picked up
+// without a restart. Pods that are not running are skipped. The kubelet takes up to a minute to update
+// the mounted files after a change of the secret, a server still loading the previous ones is reloaded again
+// on the next reconcile. A server failing to reload keeps serving its previous certificate, so callers
+// report the error without holding back the reconcile. It returns the pods serving the certificate of
+// the secret once reloaded.
+func reloadTLS(ctx context.Context, pods []corev1.Pod, connect func(*corev1.Pod) *redis.Client,
+	served func(context.Context, *corev1.Pod) ([]byte, error), tlsConfig *commonapi.TLSConfig, want []byte,
+) ([]string, error) {
+	caFile, certFile, keyFile := getTLSSecretKeys(tlsConfig)
+	params := [][2]string{
+		{"tls-cert-file", path.Join("/tls/", certFile)},
+		{"tls-key-file", path.Join("/tls/", keyFile)},
+	}
+	if hasTLSCA(tlsConfig) {
+		params = append(params, [2]string{"tls-ca-cert-file", path.Join("/tls/", caFile)})
+	}
+
+	var reloaded []string
+	var errs []error
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		got, err := served(ctx, pod)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pod %s: %w", pod.Name, err))
+			continue
+		}
+		if bytes.Equal(got, want) {
+			continue
+		}
+
+		redisClient := connect(pod)
+		for _, param := range params {
+			if err = redisClient.ConfigSet(ctx, param[0], param[1]).Err(); err != nil {
+				err = fmt.Errorf("pod %s: CONFIG SET %s failed: %w", pod.Name, param[0], err)
+				break
+			}
+		}
+		redisClient.Close()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if got, err = served(ctx, pod); err != nil {
+			errs = append(errs, fmt.Errorf("pod %s: %w", pod.Name, err))
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			log.FromContext(ctx).V(1).Info("Pod still serves the previous certificate, waiting for the kubelet to update the TLS secret files", "pod", pod.Name)
+			continue
+		}
+		log.FromContext(ctx).Info("Reloaded the certificates of the TLS secret", "pod", pod.Name)
+		reloaded = append(reloaded, pod.Name)
+	}
+	return reloaded, errors.Join(errs...)
+}
+
+// tlsSecretCertificate returns the DER encoding of the server certificate in the TLS secret.
+func tlsSecretCertificate(ctx context.Context, client kubernetes.Interface, namespace string, tlsConfig *commonapi.TLSConfig) ([]byte, error) {
+	secret, err := client.CoreV1().Secrets(namespace).Get(ctx, tlsConfig.Secret.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the TLS secret %s: %w", tlsConfig.Secret.SecretName, err)
+	}
+	_, certFile, _ := getTLSSecretKeys(tlsConfig)
+	block, _ := pem.Decode(secret.Data[certFile])
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no certificate in the %s key of the TLS secret %s", certFile, tlsConfig.Secret.SecretName)
+	}
+	return block.Bytes, nil
+}
+
+// servedCertificate returns the DER encoding of the certificate the server of the pod presents. The
+// certificate is only read, not trusted, so it isn't verified.
+func servedCertificate(ctx context.Context, pod *corev1.Pod, port int) ([]byte, error) {
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: defaultRedisClientTimeout},
+		Config:    &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12}, //nolint:gosec // only reads the served certificate
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", formatRedisAddress(pod.Status.PodIP, port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the served certificate: %w", err)
+	}
+	defer conn.Close()
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, errors.New("the server presented no certificate")
+	}
+	return certs[0].Raw, nil
+}
@@ -0,0 +1,104 @@
+package k8sutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	"github.com/go-redis/redismock/v9"
+	redis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_reloadTLS(t *testing.T) {
+	oldCert, newCert := []byte("old"), []byte("new")
+	tests := []struct {
+		name      string
+		tlsConfig *commonapi.TLSConfig
+		served    [][]byte
+		expect    func(mock redismock.ClientMock)
+		want      []string
+		wantErr   string
+	}{
+		{
+			name:      "serving the certificate of the secret",
+			tlsConfig: &commonapi.TLSConfig{},
+			served:    [][]byte{newCert},
+		},
+		{
+			name:      "reloaded",
+			tlsConfig: &commonapi.TLSConfig{},
+			served:    [][]byte{oldCert, newCert},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectConfigSet("tls-cert-file", "/tls/tls.crt").SetVal("OK")
+				mock.ExpectConfigSet("tls-key-file", "/tls/tls.key").SetVal("OK")
+			},
+			want: []string{"redis-0"},
+		},
+		{
+			name:      "reloaded with the CA of the secret",
+			tlsConfig: &commonapi.TLSConfig{IssuerRef: &commonapi.CertIssuerRef{Name: "ca"}},
+			served:    [][]byte{oldCert, newCert},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectConfigSet("tls-cert-file", "/tls/tls.crt").SetVal("OK")
+				mock.ExpectConfigSet("tls-key-file", "/tls/tls.key").SetVal("OK")
+				mock.ExpectConfigSet("tls-ca-cert-file", "/tls/ca.crt").SetVal("OK")
+			},
+			want: []string{"redis-0"},
+		},
+		{
+			name:      "files not updated yet",
+			tlsConfig: &commonapi.TLSConfig{},
+			served:    [][]byte{oldCert, oldCert},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectConfigSet("tls-cert-file", "/tls/tls.crt").SetVal("OK")
+				mock.ExpectConfigSet("tls-key-file", "/tls/tls.key").SetVal("OK")
+			},
+		},
+		{
+			name:      "reload rejected",
+			tlsConfig: &commonapi.TLSConfig{},
+			served:    [][]byte{oldCert},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectConfigSet("tls-cert-file", "/tls/tls.crt").SetErr(errors.New("ERR CONFIG SET failed (possibly related to argument 'tls-cert-file') - Unable to update TLS configuration"))
+			},
+			wantErr: "pod redis-0: CONFIG SET tls-cert-file failed: ERR CONFIG SET failed (possibly related to argument 'tls-cert-file') - Unable to update TLS configuration",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "redis-0"}, Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "redis-1"}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+			}
+			var mocks []redismock.ClientMock
+			connect := func(pod *corev1.Pod) *redis.Client {
+				redisClient, mock := redismock.NewClientMock()
+				tt.expect(mock)
+				mocks = append(mocks, mock)
+				return redisClient
+			}
+			served := tt.served
+			serve := func(context.Context, *corev1.Pod) ([]byte, error) {
+				cert := served[0]
+				served = served[1:]
+				return cert, nil
+			}
+
+			got, err := reloadTLS(context.TODO(), pods, connect, serve, tt.tlsConfig, newCert)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Empty(t, served, "the served certificate is read again after a reload")
+			for _, mock := range mocks {
+				assert.NoError(t, mock.ExpectationsWereMet())
+			}
+		})
+	}
+}
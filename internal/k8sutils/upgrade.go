@@ -0,0 +1,403 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// maxReplicationLag is the replication offset, in bytes, a replica may lag behind its master and still
+// be in sync. The failover itself waits for the replica to catch up on the last writes.
+const maxReplicationLag = 1 << 20
+
+// UpgradeStep is the step of a managed upgrade taken by a call, which rolls the pods of a resource onto
+// the update revision of their StatefulSet.
+type UpgradeStep struct {
+	// Outdated is the number of pods still running a previous revision, 0 once the upgrade is done.
+	Outdated int
+	// Restarted is the pod deleted for its StatefulSet to recreate it on the update revision.
+	Restarted string
+	// FailedOver is the pod of the outdated master failed over to an upgraded replica.
+	FailedOver string
+	// Promoted is the pod of the replica taking over from the master, empty when the sentinels pick it.
+	Promoted string
+	// Waiting tells why no step was taken while pods are outdated, e.g. a replica still syncing.
+	Waiting string
+}
+
+// updateRevisions returns the update revision of each of the StatefulSets names that exist, by name.
+func updateRevisions(ctx context.Context, client kubernetes.Interface, namespace string, names ...string) (map[string]string, error) {
+	revisions := make(map[string]string, len(names))
+	for _, name := range names {
+		sts, err := GetStatefulSet(ctx, client, namespace, name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		revisions[name] = sts.Status.UpdateRevision
+	}
+	return revisions, nil
+}
+
+// podOutdated returns whether pod runs another revision than the update revision of its StatefulSet.
+func podOutdated(pod *corev1.Pod, revisions map[string]string) bool {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || revisions[owner.Name] == "" {
+		return false
+	}
+	return pod.Labels[appsv1.ControllerRevisionHashLabelKey] != revisions[owner.Name]
+}
+
+// inSync returns whether replica is linked to master and caught up with its replication offset.
+func inSync(replica, master PodReplication) bool {
+	return replica.LinkUp && replica.Offset+maxReplicationLag >= master.Offset
+}
+
+// waitForPods returns why pods aren't ready for the next step of an upgrade, empty when they all run.
+func waitForPods(pods []corev1.Pod, expected int) string {
+	if len(pods) < expected {
+		return fmt.Sprintf("%d of %d pods exist", len(pods), expected)
+	}
+	for i := range pods {
+		if pods[i].DeletionTimestamp != nil || !IsRedisPodProbeable(&pods[i]) {
+			return fmt.Sprintf("pod %s is not ready", pods[i].Name)
+		}
+	}
+	return ""
+}
+
+// restartPod deletes pod for its StatefulSet to recreate it, unless it was already recreated.
+func restartPod(ctx context.Context, client kubernetes.Interface, pod *corev1.Pod) error {
+	err := client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{Preconditions: metav1.NewUIDPreconditions(string(pod.UID))})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete pod %s: %w", pod.Name, err)
+	}
+	return nil
+}
+
+// clusterUpgradePlan is the next step of the upgrade of the pods of a cluster.
+type clusterUpgradePlan struct {
+	// Restart is the pod to restart.
+	Restart string
+	// Master is the outdated master serving slots to fail over when Restart is empty.
+	Master *redisservice.ClusterNode
+	// Replicas are the healthy replicas of Master running the update revision, sorted by pod.
+	Replicas []*redisservice.ClusterNode
+}
+
+// planClusterUpgrade returns the next step of the upgrade of nodes, given the pod of each node by node
+// ID and the outdated pods. The nodes outside of the shards, such as masters without slots, come first.
+// The shards are then upgraded one at a time, by pod of their master: the outdated replicas are restarted
+// first, and the master is failed over to an upgraded replica, or restarted when it has no replicas.
+func planClusterUpgrade(nodes []redisservice.ClusterNode, podOf map[string]string, outdated map[string]bool) clusterUpgradePlan {
+	byPod := func(a, b *redisservice.ClusterNode) bool { return podOf[a.ID] < podOf[b.ID] }
+	var masters []*redisservice.ClusterNode
+	replicas := map[string][]*redisservice.ClusterNode{}
+	for i := range nodes {
+		n := &nodes[i]
+		if n.IsMaster() && n.SlotCount() > 0 {
+			masters = append(masters, n)
+		} else if n.MasterID != "" {
+			replicas[n.MasterID] = append(replicas[n.MasterID], n)
+		}
+	}
+	sort.Slice(masters, func(i, j int) bool { return byPod(masters[i], masters[j]) })
+
+	sharded := map[string]bool{}
+	for _, m := range masters {
+		sharded[m.ID] = true
+		for _, r := range replicas[m.ID] {
+			sharded[r.ID] = true
+		}
+	}
+	var spare []string
+	for _, n := range nodes {
+		if !sharded[n.ID] && outdated[podOf[n.ID]] {
+			spare = append(spare, podOf[n.ID])
+		}
+	}
+	if len(spare) > 0 {
+		sort.Strings(spare)
+		return clusterUpgradePlan{Restart: spare[0]}
+	}
+
+	for _, m := range masters {
+		shard := replicas[m.ID]
+		sort.Slice(shard, func(i, j int) bool { return byPod(shard[i], shard[j]) })
+		for _, r := range shard {
+			if outdated[podOf[r.ID]] {
+				return clusterUpgradePlan{Restart: podOf[r.ID]}
+			}
+		}
+		if !outdated[podOf[m.ID]] {
+			continue
+		}
+		if len(shard) == 0 {
+			return clusterUpgradePlan{Restart: podOf[m.ID]}
+		}
+		plan := clusterUpgradePlan{Master: m}
+		for _, r := range shard {
+			if r.Healthy() {
+				plan.Replicas = append(plan.Replicas, r)
+			}
+		}
+		return plan
+	}
+	return clusterUpgradePlan{}
+}
+
+// outOfSyncReplica returns the pod of the first replica of nodes, by pod, which isn't in sync with its
+// master given the replication state of each node by node ID, empty when they all are.
+func outOfSyncReplica(nodes []redisservice.ClusterNode, podOf map[string]string, states map[string]PodReplication) string {
+	var pods []string
+	for _, n := range nodes {
+		if n.IsMaster() || n.MasterID == "" {
+			continue
+		}
+		if master, ok := states[n.MasterID]; !ok || !inSync(states[n.ID], master) {
+			pods = append(pods, podOf[n.ID])
+		}
+	}
+	sort.Strings(pods)
+	if len(pods) == 0 {
+		return ""
+	}
+	return pods[0]
+}
+
+// UpgradeRedisClusterPods takes the next step of the managed upgrade of the pods of cr, once every node
+// of the cluster is healthy, the cluster check passes and every replica is in sync with its master. It
+// restarts one pod, or fails one master over to an upgraded replica, per call, see rollClusterPods.
+func UpgradeRedisClusterPods(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (UpgradeStep, error) {
+	revisions, err := updateRevisions(ctx, client, cr.Namespace, cr.Name+"-leader", cr.Name+"-follower")
+	if err != nil {
+		return UpgradeStep{}, err
+	}
+	pods := clusterPods(ctx, client, cr)
+	outdated := map[string]bool{}
+	for i := range pods {
+		if podOutdated(&pods[i], revisions) {
+			outdated[pods[i].Name] = true
+		}
+	}
+	if len(outdated) == 0 {
+		return UpgradeStep{}, nil
+	}
+	return rollClusterPods(ctx, client, cr, pods, outdated)
+}
+
+// rollClusterPods takes the next step of rolling the outdated pods of cr, once every node of the cluster
+// is healthy, the cluster check passes and every replica is in sync with its master. It restarts one pod,
+// or fails one master over to a replica which isn't outdated, per call, see planClusterUpgrade.
+func rollClusterPods(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster, pods []corev1.Pod, outdated map[string]bool) (UpgradeStep, error) {
+	step := UpgradeStep{Outdated: len(outdated)}
+	if step.Waiting = waitForPods(pods, int(cr.Spec.GetReplicaCounts("leader")+cr.Spec.GetReplicaCounts("follower"))); step.Waiting != "" {
+		return step, nil
+	}
+
+	admin, err := newClusterAdmin(ctx, client, cr)
+	if err != nil {
+		return step, err
+	}
+	seed, err := podEndpoint(ctx, client, cr, pods[0].Name)
+	if err != nil {
+		return step, err
+	}
+	if cErr := admin.Check(ctx, seed); cErr != nil {
+		step.Waiting = fmt.Sprintf("the cluster check failed: %v", cErr)
+		return step, nil
+	}
+	nodes, err := admin.Nodes(ctx, seed)
+	if err != nil {
+		return step, err
+	}
+	podOf := map[string]string{}
+	states := map[string]PodReplication{}
+	for _, n := range nodes {
+		pod := podOfNode(n, pods)
+		if pod == nil || !n.Healthy() {
+			step.Waiting = fmt.Sprintf("node %s is not healthy", n.ID)
+			return step, nil
+		}
+		state, rErr := GetRedisClusterPodReplication(ctx, client, cr, pod)
+		if rErr != nil {
+			step.Waiting = fmt.Sprintf("pod %s is unreachable", pod.Name)
+			return step, nil
+		}
+		podOf[n.ID], states[n.ID] = pod.Name, state
+	}
+	if len(podOf) < len(pods) {
+		step.Waiting = fmt.Sprintf("%d of %d pods joined the cluster", len(podOf), len(pods))
+		return step, nil
+	}
+	if pod := outOfSyncReplica(nodes, podOf, states); pod != "" {
+		step.Waiting = fmt.Sprintf("replica %s is not in sync with its master", pod)
+		return step, nil
+	}
+
+	plan := planClusterUpgrade(nodes, podOf, outdated)
+	switch {
+	case plan.Restart != "":
+		for i := range pods {
+			if pods[i].Name == plan.Restart {
+				log.FromContext(ctx).Info("Restarting the outdated pod", "Pod", plan.Restart)
+				if err := restartPod(ctx, client, &pods[i]); err != nil {
+					return step, err
+				}
+			}
+		}
+		step.Restarted = plan.Restart
+	case plan.Master != nil && len(plan.Replicas) > 0:
+		replica := plan.Replicas[0]
+		log.FromContext(ctx).Info("Failing the outdated master over to an up-to-date replica", "Master", podOf[plan.Master.ID],
+			"Replica", podOf[replica.ID])
+		ctx, cancel := clusterOperationContext(ctx)
+		defer cancel()
+		if err := admin.Failover(ctx, replica.Addr, redisservice.FailoverDefault); err != nil {
+			return step, err
+		}
+		step.FailedOver, step.Promoted = podOf[plan.Master.ID], podOf[replica.ID]
+	case plan.Master != nil:
+		step.Waiting = fmt.Sprintf("master %s has no healthy upgraded replica to fail over to", podOf[plan.Master.ID])
+	}
+	return step, nil
+}
+
+// planReplicationUpgrade returns the next step of the upgrade of pods, sorted by ordinal, given the pod
+// of the master and the outdated pods: the outdated replica with the highest ordinal to restart, or
+// whether to fail the master over once every replica is upgraded. A master without replicas is restarted.
+func planReplicationUpgrade(pods []string, master string, outdated map[string]bool) (string, bool) {
+	for i := len(pods) - 1; i >= 0; i-- {
+		if pods[i] != master && outdated[pods[i]] {
+			return pods[i], false
+		}
+	}
+	if !outdated[master] {
+		return "", false
+	}
+	if len(pods) == 1 {
+		return master, false
+	}
+	return "", true
+}
+
+// replicationPods returns the pods of cr that exist.
+func replicationPods(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication) ([]corev1.Pod, error) {
+	var pods []corev1.Pod
+	for i := int32(0); i < cr.Spec.GetReplicationCounts("replication"); i++ {
+		pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, fmt.Sprintf("%s-%d", cr.RedisStatefulSet(), i), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, *pod)
+	}
+	return pods, nil
+}
+
+// UpgradeRedisReplicationPods takes the next step of the managed upgrade of the pods of cr, once every
+// pod runs, a single master is elected and every replica is in sync with it. The replicas are restarted
+// first, then the master is failed over once to an upgraded replica, with a sentinel FAILOVER when
+// sentinel is enabled and a FAILOVER TO on the master otherwise, and restarted last.
+func UpgradeRedisReplicationPods(ctx context.Context, client kubernetes.Interface, redisClient redisservice.Client, cr *rrvb2.RedisReplication) (UpgradeStep, error) {
+	revisions, err := updateRevisions(ctx, client, cr.Namespace, cr.RedisStatefulSet())
+	if err != nil {
+		return UpgradeStep{}, err
+	}
+	pods, err := replicationPods(ctx, client, cr)
+	if err != nil {
+		return UpgradeStep{}, err
+	}
+	var names []string
+	outdated := map[string]bool{}
+	for i := range pods {
+		names = append(names, pods[i].Name)
+		if podOutdated(&pods[i], revisions) {
+			outdated[pods[i].Name] = true
+		}
+	}
+	step := UpgradeStep{Outdated: len(outdated)}
+	if step.Outdated == 0 {
+		return step, nil
+	}
+	if step.Waiting = waitForPods(pods, int(cr.Spec.GetReplicationCounts("replication"))); step.Waiting != "" {
+		return step, nil
+	}
+
+	master := -1
+	states := make([]PodReplication, len(pods))
+	for i := range pods {
+		state, rErr := GetRedisReplicationPodReplication(ctx, client, cr, &pods[i])
+		if rErr != nil {
+			step.Waiting = fmt.Sprintf("pod %s is unreachable", pods[i].Name)
+			return step, nil
+		}
+		if state.Master {
+			if master >= 0 {
+				step.Waiting = fmt.Sprintf("pods %s and %s are both masters", pods[master].Name, pods[i].Name)
+				return step, nil
+			}
+			master = i
+		}
+		states[i] = state
+	}
+	if master < 0 {
+		step.Waiting = "no master is elected"
+		return step, nil
+	}
+	replica := -1
+	for i := range pods {
+		if i == master {
+			continue
+		}
+		if !inSync(states[i], states[master]) {
+			step.Waiting = fmt.Sprintf("replica %s is not in sync with its master", pods[i].Name)
+			return step, nil
+		}
+		if replica < 0 {
+			replica = i
+		}
+	}
+
+	restart, failover := planReplicationUpgrade(names, pods[master].Name, outdated)
+	switch {
+	case restart != "":
+		log.FromContext(ctx).Info("Restarting the pod onto the update revision", "Pod", restart)
+		for i := range pods {
+			if pods[i].Name == restart {
+				if err := restartPod(ctx, client, &pods[i]); err != nil {
+					return step, err
+				}
+			}
+		}
+		step.Restarted = restart
+	case failover && cr.EnableSentinel():
+		if _, err := HandoverRedisReplicationMaster(ctx, client, redisClient, cr); err != nil {
+			return step, err
+		}
+		step.FailedOver = pods[master].Name
+	case failover:
+		log.FromContext(ctx).Info("Failing the outdated master over to an upgraded replica", "Master", pods[master].Name, "Replica", pods[replica].Name)
+		masterClient := configureRedisReplicationClientForPod(ctx, client, cr, &pods[master])
+		defer masterClient.Close()
+		if err := masterClient.Do(ctx, "FAILOVER", "TO", pods[replica].Status.PodIP, "6379").Err(); err != nil {
+			return step, fmt.Errorf("FAILOVER TO %s on %s: %w", pods[replica].Name, pods[master].Name, err)
+		}
+		step.FailedOver, step.Promoted = pods[master].Name, pods[replica].Name
+	}
+	return step, nil
+}
@@ -0,0 +1,196 @@
+package k8sutils
+
+import (
+	"testing"
+
+	redisservice "github.com/OT-CONTAINER-KIT/redis-operator/internal/service/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func Test_podOutdated(t *testing.T) {
+	revisions := map[string]string{"redis-leader": "redis-leader-2"}
+	pod := func(owner, revision string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Labels:          map[string]string{appsv1.ControllerRevisionHashLabelKey: revision},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: owner, Controller: ptr.To(true)}},
+		}}
+	}
+	assert.False(t, podOutdated(pod("redis-leader", "redis-leader-2"), revisions))
+	assert.True(t, podOutdated(pod("redis-leader", "redis-leader-1"), revisions))
+	assert.False(t, podOutdated(pod("redis-follower", "redis-follower-1"), revisions))
+	assert.False(t, podOutdated(&corev1.Pod{}, revisions))
+}
+
+func Test_inSync(t *testing.T) {
+	master := PodReplication{Master: true, LinkUp: true, Offset: 10 << 20}
+	assert.True(t, inSync(PodReplication{LinkUp: true, Offset: 10 << 20}, master))
+	assert.True(t, inSync(PodReplication{LinkUp: true, Offset: 9 << 20}, master))
+	assert.False(t, inSync(PodReplication{LinkUp: true, Offset: 8 << 20}, master))
+	assert.False(t, inSync(PodReplication{Offset: 10 << 20}, master))
+}
+
+func Test_planClusterUpgrade(t *testing.T) {
+	const twoShards = "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-8191\n" +
+		"b 10.0.0.2:6379@16379 master - 0 0 2 connected 8192-16383\n" +
+		"ra 10.0.0.3:6379@16379 slave a 0 0 1 connected\n" +
+		"rb 10.0.0.4:6379@16379 slave b 0 0 2 connected\n"
+	podOf := map[string]string{"a": "leader-0", "b": "leader-1", "ra": "follower-0", "rb": "follower-1", "e": "leader-2"}
+	tests := []struct {
+		name         string
+		nodes        string
+		outdated     []string
+		wantRestart  string
+		wantMaster   string
+		wantReplicas []string
+	}{
+		{
+			name:  "nothing outdated",
+			nodes: twoShards,
+		},
+		{
+			name:        "replicas of the first shard first",
+			nodes:       twoShards,
+			outdated:    []string{"leader-0", "leader-1", "follower-0", "follower-1"},
+			wantRestart: "follower-0",
+		},
+		{
+			name:         "master of the first shard once its replica is upgraded",
+			nodes:        twoShards,
+			outdated:     []string{"leader-0", "leader-1", "follower-1"},
+			wantMaster:   "a",
+			wantReplicas: []string{"ra"},
+		},
+		{
+			name:        "next shard once the first is upgraded",
+			nodes:       twoShards,
+			outdated:    []string{"leader-1", "follower-1"},
+			wantRestart: "follower-1",
+		},
+		{
+			name:        "master without slots before the shards",
+			nodes:       twoShards + "e 10.0.0.5:6379@16379 master - 0 0 3 connected\n",
+			outdated:    []string{"leader-0", "follower-0", "leader-2"},
+			wantRestart: "leader-2",
+		},
+		{
+			name:        "master without replicas is restarted",
+			nodes:       "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-16383\n",
+			outdated:    []string{"leader-0"},
+			wantRestart: "leader-0",
+		},
+		{
+			name: "failed replica is no failover target",
+			nodes: "a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-16383\n" +
+				"ra 10.0.0.3:6379@16379 slave,fail a 0 0 1 disconnected\n",
+			outdated:   []string{"leader-0"},
+			wantMaster: "a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := redisservice.ParseClusterNodes(tt.nodes)
+			require.NoError(t, err)
+			outdated := map[string]bool{}
+			for _, pod := range tt.outdated {
+				outdated[pod] = true
+			}
+			plan := planClusterUpgrade(nodes, podOf, outdated)
+			assert.Equal(t, tt.wantRestart, plan.Restart)
+			if tt.wantMaster == "" {
+				assert.Nil(t, plan.Master)
+			} else {
+				require.NotNil(t, plan.Master)
+				assert.Equal(t, tt.wantMaster, plan.Master.ID)
+			}
+			var replicas []string
+			for _, r := range plan.Replicas {
+				replicas = append(replicas, r.ID)
+			}
+			assert.Equal(t, tt.wantReplicas, replicas)
+		})
+	}
+}
+
+func Test_outOfSyncReplica(t *testing.T) {
+	nodes, err := redisservice.ParseClusterNodes("a 10.0.0.1:6379@16379 master - 0 0 1 connected 0-8191\n" +
+		"b 10.0.0.2:6379@16379 master - 0 0 2 connected 8192-16383\n" +
+		"ra 10.0.0.3:6379@16379 slave a 0 0 1 connected\n" +
+		"rb 10.0.0.4:6379@16379 slave b 0 0 2 connected\n")
+	require.NoError(t, err)
+	podOf := map[string]string{"a": "leader-0", "b": "leader-1", "ra": "follower-0", "rb": "follower-1"}
+	states := map[string]PodReplication{
+		"a":  {Master: true, LinkUp: true, Offset: 100},
+		"b":  {Master: true, LinkUp: true, Offset: 5 << 20},
+		"ra": {LinkUp: true, Offset: 100},
+		"rb": {LinkUp: true, Offset: 100},
+	}
+	assert.Equal(t, "follower-1", outOfSyncReplica(nodes, podOf, states))
+
+	states["rb"] = PodReplication{LinkUp: true, Offset: 5 << 20}
+	assert.Empty(t, outOfSyncReplica(nodes, podOf, states))
+
+	states["ra"] = PodReplication{Offset: 100}
+	assert.Equal(t, "follower-0", outOfSyncReplica(nodes, podOf, states))
+}
+
+func Test_planReplicationUpgrade(t *testing.T) {
+	pods := []string{"redis-0", "redis-1", "redis-2"}
+	tests := []struct {
+		name         string
+		pods         []string
+		master       string
+		outdated     []string
+		wantRestart  string
+		wantFailover bool
+	}{
+		{
+			name:   "nothing outdated",
+			pods:   pods,
+			master: "redis-0",
+		},
+		{
+			name:        "replica with the highest ordinal first",
+			pods:        pods,
+			master:      "redis-2",
+			outdated:    []string{"redis-0", "redis-1", "redis-2"},
+			wantRestart: "redis-1",
+		},
+		{
+			name:         "master fails over once the replicas are upgraded",
+			pods:         pods,
+			master:       "redis-0",
+			outdated:     []string{"redis-0"},
+			wantFailover: true,
+		},
+		{
+			name:        "old master restarted once it is a replica",
+			pods:        pods,
+			master:      "redis-2",
+			outdated:    []string{"redis-0"},
+			wantRestart: "redis-0",
+		},
+		{
+			name:        "single pod is restarted",
+			pods:        []string{"redis-0"},
+			master:      "redis-0",
+			outdated:    []string{"redis-0"},
+			wantRestart: "redis-0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outdated := map[string]bool{}
+			for _, pod := range tt.outdated {
+				outdated[pod] = true
+			}
+			restart, failover := planReplicationUpgrade(tt.pods, tt.master, outdated)
+			assert.Equal(t, tt.wantRestart, restart)
+			assert.Equal(t, tt.wantFailover, failover)
+		})
+	}
+}
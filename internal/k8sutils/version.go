@@ -0,0 +1,265 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	rcvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
+	rrvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisreplication/v1beta2"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// VersionCheck is the outcome of the checks of the version of Redis run by the pods of a resource.
+type VersionCheck struct {
+	// Status is the version status to record for the resource.
+	Status commonapi.VersionStatus
+	// Deprecated describes the directives of the additional Redis config the target version deprecates,
+	// set by the check that first sees the target version.
+	Deprecated []string
+	// Completed is set by the check that first sees every pod run the target version.
+	Completed bool
+}
+
+// versionCheck is what the checks need to know about a resource.
+type versionCheck struct {
+	status commonapi.VersionStatus
+	image  string
+	// engine is the engine of the spec, which takes precedence over the one guessed from the image.
+	engine  commonapi.Engine
+	sts     []string
+	configs []*string
+	// clusterVersion is the spec.clusterVersion of a RedisCluster, from which the bootstrap agent
+	// configures the nodes through REDIS_MAJOR_VERSION.
+	clusterVersion *string
+	pods           []corev1.Pod
+	expected       int
+}
+
+// CheckRedisClusterVersion runs the pre-flight checks of a change of the image of cr, and follows the
+// pods onto the new version. See checkVersion.
+func CheckRedisClusterVersion(ctx context.Context, client kubernetes.Interface, cr *rcvb2.RedisCluster) (VersionCheck, error) {
+	in := versionCheck{
+		status:         cr.Status.VersionStatus,
+		image:          cr.Spec.KubernetesConfig.Image,
+		engine:         cr.Spec.KubernetesConfig.GetEngine(),
+		sts:            []string{cr.Name + "-leader", cr.Name + "-follower"},
+		clusterVersion: cr.Spec.ClusterVersion,
+		pods:           clusterPods(ctx, client, cr),
+		expected:       int(cr.Spec.GetReplicaCounts("leader") + cr.Spec.GetReplicaCounts("follower")),
+	}
+	if cr.Spec.RedisLeader.RedisConfig != nil {
+		in.configs = append(in.configs, cr.Spec.RedisLeader.RedisConfig.AdditionalRedisConfig)
+	}
+	if cr.Spec.RedisFollower.RedisConfig != nil {
+		in.configs = append(in.configs, cr.Spec.RedisFollower.RedisConfig.AdditionalRedisConfig)
+	}
+	return checkVersion(ctx, client, cr.Namespace, in)
+}
+
+// CheckRedisReplicationVersion runs the pre-flight checks of a change of the image of cr, and follows
+// the pods onto the new version. See checkVersion.
+func CheckRedisReplicationVersion(ctx context.Context, client kubernetes.Interface, cr *rrvb2.RedisReplication) (VersionCheck, error) {
+	pods, err := replicationPods(ctx, client, cr)
+	if err != nil {
+		return VersionCheck{Status: cr.Status.VersionStatus}, err
+	}
+	in := versionCheck{
+		status:   cr.Status.VersionStatus,
+		image:    cr.Spec.KubernetesConfig.Image,
+		engine:   cr.Spec.KubernetesConfig.GetEngine(),
+		sts:      []string{cr.RedisStatefulSet()},
+		pods:     pods,
+		expected: int(cr.Spec.GetReplicationCounts("replication")),
+	}
+	if cr.Spec.RedisConfig != nil {
+		in.configs = append(in.configs, cr.Spec.RedisConfig.AdditionalRedisConfig)
+	}
+	return checkVersion(ctx, client, cr.Namespace, in)
+}
+
+// CheckRedisVersion runs the pre-flight checks of a change of the image of cr, and follows the pod onto
+// the new version. See checkVersion.
+func CheckRedisVersion(ctx context.Context, client kubernetes.Interface, cr *rvb2.Redis) (VersionCheck, error) {
+	var pods []corev1.Pod
+	pod, err := client.CoreV1().Pods(cr.Namespace).Get(ctx, cr.Name+"-0", metav1.GetOptions{})
+	switch {
+	case err == nil:
+		pods = append(pods, *pod)
+	case !apierrors.IsNotFound(err):
+		return VersionCheck{Status: cr.Status.VersionStatus}, err
+	}
+	in := versionCheck{
+		status:   cr.Status.VersionStatus,
+		image:    cr.Spec.KubernetesConfig.Image,
+		engine:   cr.Spec.KubernetesConfig.GetEngine(),
+		sts:      []string{cr.Name},
+		pods:     pods,
+		expected: 1,
+	}
+	if cr.Spec.RedisConfig != nil {
+		in.configs = append(in.configs, cr.Spec.RedisConfig.AdditionalRedisConfig)
+	}
+	return checkVersion(ctx, client, cr.Namespace, in)
+}
+
+// checkVersion compares the version of the image in the spec with the current version of the pods,
+// taken from the status or, before it is recorded, from the image of the StatefulSets.
+//
+// A change of version fails the pre-flight checks when the new version can't load the RDB snapshots of
+// the current one, or when spec.clusterVersion doesn't follow the major version, and must not be rolled
+// out. Once rolled out, a pod running the new version that keeps restarting, most likely because it
+// can't load its dataset, halts the change with an error too. The version becomes current when every
+// pod runs it and is ready.
+func checkVersion(ctx context.Context, client kubernetes.Interface, namespace string, in versionCheck) (VersionCheck, error) {
+	target, ok := util.ParseImageVersion(in.image)
+	if !ok {
+		log.FromContext(ctx).V(1).Info("Image tag pins no Redis version, skipping the version checks", "Image", in.image)
+		return VersionCheck{}, nil
+	}
+	if in.engine == commonapi.EngineValkey {
+		target.Engine = util.EngineValkey
+	}
+	check := VersionCheck{Status: commonapi.VersionStatus{CurrentVersion: in.status.CurrentVersion, TargetVersion: target.String()}}
+	current, ok := util.ParseVersion(in.status.CurrentVersion)
+	if !ok {
+		image, err := statefulSetImage(ctx, client, namespace, in.sts)
+		if err != nil {
+			return VersionCheck{Status: in.status}, err
+		}
+		if image == "" {
+			current, ok = target, true
+		} else {
+			current, ok = util.ParseImageVersion(image)
+		}
+	}
+
+	if ok && current != target {
+		if err := util.CheckVersionChange(current, target); err != nil {
+			return VersionCheck{Status: in.status}, fmt.Errorf("pre-flight check of the change to %s failed: %w", target, err)
+		}
+		if in.clusterVersion != nil && util.HasRedis7Features(target.Engine, *in.clusterVersion) != util.HasRedis7Features(target.Engine, strconv.Itoa(target.Major)) {
+			return VersionCheck{Status: in.status}, fmt.Errorf("pre-flight check of the change to %s failed: clusterVersion %s doesn't match its major version", target, *in.clusterVersion)
+		}
+	}
+	if in.status.TargetVersion != check.Status.TargetVersion {
+		deprecated, err := deprecatedDirectives(ctx, client, namespace, in.configs, target)
+		if err != nil {
+			return VersionCheck{Status: in.status}, err
+		}
+		check.Deprecated = deprecated
+	}
+
+	if pod := failedUpgradePod(in.pods, in.image); pod != "" {
+		return check, fmt.Errorf("pod %s keeps restarting on %s and may be unable to load its dataset, halting the version change", pod, target)
+	}
+	if check.Status.CurrentVersion != check.Status.TargetVersion && podsRunImage(in.pods, in.image, in.expected) {
+		check.Status.CurrentVersion = check.Status.TargetVersion
+		check.Completed = true
+	}
+	return check, nil
+}
+
+// statefulSetImage returns the image of the Redis container of the first of the StatefulSets names
+// that exists, empty when none does.
+func statefulSetImage(ctx context.Context, client kubernetes.Interface, namespace string, names []string) (string, error) {
+	for _, name := range names {
+		sts, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		for _, container := range sts.Spec.Template.Spec.Containers {
+			if container.Name == name {
+				return container.Image, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// deprecatedDirectives returns the directives of the additional config maps configs that version to
+// deprecates. Config maps that don't exist yet are skipped.
+func deprecatedDirectives(ctx context.Context, client kubernetes.Interface, namespace string, configs []*string, to util.ImageVersion) ([]string, error) {
+	var messages []string
+	seen := map[string]bool{}
+	for _, name := range configs {
+		if name == nil || *name == "" || seen[*name] {
+			continue
+		}
+		seen[*name] = true
+		cm, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, *name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, message := range util.DeprecatedDirectives(cm.Data[key], to) {
+				messages = append(messages, fmt.Sprintf("%s/%s: %s", *name, key, message))
+			}
+		}
+	}
+	return messages, nil
+}
+
+// imageContainerStatus returns whether pod runs image in one of its containers, and the status of that
+// container once reported.
+func imageContainerStatus(pod *corev1.Pod, image string) (*corev1.ContainerStatus, bool) {
+	for _, container := range pod.Spec.Containers {
+		if container.Image != image {
+			continue
+		}
+		for i := range pod.Status.ContainerStatuses {
+			if pod.Status.ContainerStatuses[i].Name == container.Name {
+				return &pod.Status.ContainerStatuses[i], true
+			}
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// failedUpgradePod returns the first of pods running image whose container keeps failing to start,
+// empty when there is none.
+func failedUpgradePod(pods []corev1.Pod, image string) string {
+	for i := range pods {
+		status, ok := imageContainerStatus(&pods[i], image)
+		if !ok || status == nil || status.Ready {
+			continue
+		}
+		crashLooping := status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff"
+		crashed := status.LastTerminationState.Terminated != nil && status.LastTerminationState.Terminated.ExitCode != 0
+		if crashLooping || crashed {
+			return pods[i].Name
+		}
+	}
+	return ""
+}
+
+// podsRunImage returns whether the expected number of pods run image and are ready.
+func podsRunImage(pods []corev1.Pod, image string, expected int) bool {
+	running := 0
+	for i := range pods {
+		if _, ok := imageContainerStatus(&pods[i], image); ok && pods[i].DeletionTimestamp == nil && IsRedisPodProbeable(&pods[i]) {
+			running++
+		}
+	}
+	return running == expected
+}
@@ -0,0 +1,188 @@
+package k8sutils
+
+import (
+	"context"
+	"testing"
+
+	commonapi "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
+	rvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sClientFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func versionTestPod(name, image string, ready bool) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "redis", Image: image}}},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			PodIP:             "10.0.0.1",
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "redis", Ready: ready}},
+		},
+	}
+	if ready {
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	}
+	return pod
+}
+
+func Test_checkVersion(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "redis", Image: "redis:7.4.1"}},
+		}}},
+	}
+	config := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis-config", Namespace: "default"},
+		Data:       map[string]string{"redis-additional.conf": "hash-max-ziplist-entries 128\n"},
+	}
+	crashed := versionTestPod("redis-0", "redis:7.4.2", false)
+	crashed.Status.ContainerStatuses[0].State.Waiting = &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}
+
+	tests := []struct {
+		name           string
+		objects        []runtime.Object
+		in             versionCheck
+		want           VersionCheck
+		wantDeprecated int
+		wantErr        string
+	}{
+		{
+			name: "new resource runs the version of its spec",
+			in: versionCheck{
+				image:    "redis:7.2.4",
+				pods:     []corev1.Pod{versionTestPod("redis-0", "redis:7.2.4", true)},
+				expected: 1,
+			},
+			want: VersionCheck{Status: commonapi.VersionStatus{CurrentVersion: "redis-7.2.4", TargetVersion: "redis-7.2.4"}, Completed: true},
+		},
+		{
+			name: "unpinned tag clears the status",
+			in: versionCheck{
+				status: commonapi.VersionStatus{CurrentVersion: "redis-7.2.4", TargetVersion: "redis-7.2.4"},
+				image:  "redis:latest",
+			},
+		},
+		{
+			name:    "current version taken from the StatefulSet",
+			objects: []runtime.Object{sts},
+			in: versionCheck{
+				image:    "redis:7.4.2",
+				sts:      []string{"redis"},
+				pods:     []corev1.Pod{versionTestPod("redis-0", "redis:7.4.1", true)},
+				expected: 1,
+			},
+			want: VersionCheck{Status: commonapi.VersionStatus{TargetVersion: "redis-7.4.2"}},
+		},
+		{
+			name:    "downgrade to an older RDB format is blocked",
+			objects: []runtime.Object{sts},
+			in: versionCheck{
+				status:   commonapi.VersionStatus{CurrentVersion: "redis-7.4.1", TargetVersion: "redis-7.4.1"},
+				image:    "redis:7.2.4",
+				sts:      []string{"redis"},
+				expected: 1,
+			},
+			want:    VersionCheck{Status: commonapi.VersionStatus{CurrentVersion: "redis-7.4.1", TargetVersion: "redis-7.4.1"}},
+			wantErr: "RDB version 12",
+		},
+		{
+			name: "clusterVersion must follow the major version",
+			in: versionCheck{
+				status:         commonapi.VersionStatus{CurrentVersion: "redis-6.2.14", TargetVersion: "redis-6.2.14"},
+				image:          "redis:7.2.4",
+				clusterVersion: ptr.To("v6"),
+			},
+			want:    VersionCheck{Status: commonapi.VersionStatus{CurrentVersion: "redis-6.2.14", TargetVersion: "redis-6.2.14"}},
+			wantErr: "clusterVersion v6",
+		},
+		{
+			name:    "deprecated directives reported for a new target",
+			objects: []runtime.Object{config},
+			in: versionCheck{
+				status:   commonapi.VersionStatus{CurrentVersion: "redis-6.2.14", TargetVersion: "redis-6.2.14"},
+				image:    "redis:7.2.4",
+				configs:  []*string{ptr.To("redis-config"), ptr.To("redis-config"), ptr.To("missing")},
+				pods:     []corev1.Pod{versionTestPod("redis-0", "redis:6.2.14", true)},
+				expected: 1,
+			},
+			want:           VersionCheck{Status: commonapi.VersionStatus{CurrentVersion: "redis-6.2.14", TargetVersion: "redis-7.2.4"}},
+			wantDeprecated: 1,
+		},
+		{
+			name: "crash looping upgraded pod halts the change",
+			in: versionCheck{
+				status:   commonapi.VersionStatus{CurrentVersion: "redis-7.4.1", TargetVersion: "redis-7.4.2"},
+				image:    "redis:7.4.2",
+				pods:     []corev1.Pod{crashed, versionTestPod("redis-1", "redis:7.4.1", true)},
+				expected: 2,
+			},
+			want:    VersionCheck{Status: commonapi.VersionStatus{CurrentVersion: "redis-7.4.1", TargetVersion: "redis-7.4.2"}},
+			wantErr: "pod redis-0 keeps restarting",
+		},
+		{
+			name: "change completes once every pod runs the target",
+			in: versionCheck{
+				status:   commonapi.VersionStatus{CurrentVersion: "redis-7.4.1", TargetVersion: "redis-7.4.2"},
+				image:    "redis:7.4.2",
+				pods:     []corev1.Pod{versionTestPod("redis-0", "redis:7.4.2", true), versionTestPod("redis-1", "redis:7.4.2", true)},
+				expected: 2,
+			},
+			want: VersionCheck{Status: commonapi.VersionStatus{CurrentVersion: "redis-7.4.2", TargetVersion: "redis-7.4.2"}, Completed: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := k8sClientFake.NewSimpleClientset(tt.objects...)
+			got, err := checkVersion(context.TODO(), client, "default", tt.in)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Len(t, got.Deprecated, tt.wantDeprecated)
+			got.Deprecated = nil
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheckRedisVersion(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "redis", Image: "redis:7.4.1"}},
+		}}},
+	}
+	pod := versionTestPod("redis-0", "redis:7.4.1", true)
+	client := k8sClientFake.NewSimpleClientset(sts, &pod)
+	cr := func(image string) *rvb2.Redis {
+		return &rvb2.Redis{
+			ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"},
+			Spec:       rvb2.RedisSpec{KubernetesConfig: commonapi.KubernetesConfig{Image: image}},
+		}
+	}
+
+	check, err := CheckRedisVersion(context.TODO(), client, cr("redis:7.4.2"))
+	require.NoError(t, err)
+	assert.Equal(t, commonapi.VersionStatus{TargetVersion: "redis-7.4.2"}, check.Status)
+	assert.False(t, check.Completed)
+
+	check, err = CheckRedisVersion(context.TODO(), client, cr("redis:7.2.4"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pre-flight check")
+	assert.Empty(t, check.Status)
+
+	check, err = CheckRedisVersion(context.TODO(), client, cr("redis:7.4.1"))
+	require.NoError(t, err)
+	assert.Equal(t, commonapi.VersionStatus{CurrentVersion: "redis-7.4.1", TargetVersion: "redis-7.4.1"}, check.Status)
+	assert.True(t, check.Completed)
+}
@@ -0,0 +1,132 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/controller/common"
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// VolumeSnapshotGVK is the CSI snapshot API. The external-snapshotter types are not vendored,
+// VolumeSnapshots are handled as unstructured objects.
+var VolumeSnapshotGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+// DataPVCName returns the name of the data PVC the StatefulSet creates for the pod with the given ordinal.
+func DataPVCName(stsName string, ordinal int) string {
+	return fmt.Sprintf("%s-%s-%d", util.CoalesceEnv1(common.EnvOperatorSTSPVCTemplateName, stsName), stsName, ordinal)
+}
+
+// NewVolumeSnapshot returns a VolumeSnapshot of the PVC.
+func NewVolumeSnapshot(namespace, name, pvcName string, className *string) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGVK)
+	snapshot.SetNamespace(namespace)
+	snapshot.SetName(name)
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": pvcName,
+		},
+	}
+	if className != nil {
+		spec["volumeSnapshotClassName"] = *className
+	}
+	snapshot.Object["spec"] = spec
+	return snapshot
+}
+
+// VolumeSnapshotState returns whether the snapshot can be restored from, and the error reported by the CSI driver.
+func VolumeSnapshotState(snapshot *unstructured.Unstructured) (ready bool, errMsg string) {
+	ready, _, _ = unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	errMsg, _, _ = unstructured.NestedString(snapshot.Object, "status", "error", "message")
+	return ready, errMsg
+}
+
+// createRestoredPVCs provisions the data PVCs of a new StatefulSet from the VolumeSnapshots of a backup,
+// the StatefulSet then adopts them instead of creating empty ones. With perOrdinal set every pod gets the
+// snapshot of the shard matching its ordinal and pods without one start empty, otherwise every pod gets
+// the snapshot of the first shard. Existing PVCs are left untouched.
+func createRestoredPVCs(ctx context.Context, cl kubernetes.Interface, sts *appsv1.StatefulSet, restore *RestoreSource, perOrdinal bool) error {
+	templateName := util.CoalesceEnv1(common.EnvOperatorSTSPVCTemplateName, sts.Name)
+	var template *corev1.PersistentVolumeClaim
+	for i := range sts.Spec.VolumeClaimTemplates {
+		if sts.Spec.VolumeClaimTemplates[i].Name == templateName {
+			template = &sts.Spec.VolumeClaimTemplates[i]
+		}
+	}
+	if template == nil {
+		return errRestoreWithoutStorage
+	}
+
+	for i := 0; i < int(ptr.Deref(sts.Spec.Replicas, 1)); i++ {
+		shard := 0
+		if perOrdinal {
+			if i >= len(restore.Shards) {
+				break
+			}
+			shard = i
+		}
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        DataPVCName(sts.Name, i),
+				Namespace:   sts.Namespace,
+				Labels:      template.Labels,
+				Annotations: template.Annotations,
+			},
+			Spec: *template.Spec.DeepCopy(),
+		}
+		pvc.Spec.DataSourceRef = &corev1.TypedObjectReference{
+			APIGroup: ptr.To(VolumeSnapshotGVK.Group),
+			Kind:     VolumeSnapshotGVK.Kind,
+			Name:     restore.Shards[shard].VolumeSnapshot,
+		}
+		_, err := cl.CoreV1().PersistentVolumeClaims(sts.Namespace).Create(ctx, pvc, metav1.CreateOptions{})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create PVC %s from VolumeSnapshot %s: %w", pvc.Name, pvc.Spec.DataSourceRef.Name, err)
+		}
+		if err == nil {
+			log.FromContext(ctx).Info("Created PVC from VolumeSnapshot", "PVC", pvc.Name, "VolumeSnapshot", pvc.Spec.DataSourceRef.Name)
+		}
+	}
+	return nil
+}
+
+// waitForVolumeSnapshots returns an error while a VolumeSnapshot of one of the PVCs is still being cut,
+// so the PVCs are only deleted once the snapshots no longer need them. Clusters without the snapshot API
+// have nothing to wait for.
+func waitForVolumeSnapshots(ctx context.Context, cl client.Client, namespace string, pvcNames []string) error {
+	snapshots := &unstructured.UnstructuredList{}
+	snapshots.SetGroupVersionKind(VolumeSnapshotGVK.GroupVersion().WithKind(VolumeSnapshotGVK.Kind + "List"))
+	if err := cl.List(ctx, snapshots, client.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	pvcs := make(map[string]bool, len(pvcNames))
+	for _, name := range pvcNames {
+		pvcs[name] = true
+	}
+	for i := range snapshots.Items {
+		snapshot := &snapshots.Items[i]
+		source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		if !pvcs[source] || snapshot.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if ready, errMsg := VolumeSnapshotState(snapshot); !ready && errMsg == "" {
+			return fmt.Errorf("waiting for VolumeSnapshot %s of PVC %s before deleting it", snapshot.GetName(), source)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package k8sutils
+
+import (
+	"context"
+	"testing"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sClientFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateRestoredPVCs(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-leader", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr.To(int32(3)),
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-leader"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			}},
+		},
+	}
+	restore := &RestoreSource{Shards: []rbvb2.BackupShard{
+		{Pod: "cluster-leader-0", VolumeSnapshot: "nightly-cluster-leader-0"},
+		{Pod: "cluster-leader-1", VolumeSnapshot: "nightly-cluster-leader-1"},
+	}}
+
+	t.Run("per ordinal", func(t *testing.T) {
+		cl := k8sClientFake.NewSimpleClientset()
+		require.NoError(t, createRestoredPVCs(context.Background(), cl, sts, restore, true))
+
+		pvcs, err := cl.CoreV1().PersistentVolumeClaims("default").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, pvcs.Items, 2, "pods without a shard start empty")
+		for i, pvc := range pvcs.Items {
+			assert.Equal(t, DataPVCName("cluster-leader", i), pvc.Name)
+			require.NotNil(t, pvc.Spec.DataSourceRef)
+			assert.Equal(t, "snapshot.storage.k8s.io", *pvc.Spec.DataSourceRef.APIGroup)
+			assert.Equal(t, "VolumeSnapshot", pvc.Spec.DataSourceRef.Kind)
+			assert.Equal(t, restore.Shards[i].VolumeSnapshot, pvc.Spec.DataSourceRef.Name)
+			assert.Equal(t, resource.MustParse("1Gi"), pvc.Spec.Resources.Requests[corev1.ResourceStorage])
+		}
+	})
+
+	t.Run("every pod from the first shard", func(t *testing.T) {
+		existing := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: DataPVCName("cluster-leader", 0), Namespace: "default"}}
+		cl := k8sClientFake.NewSimpleClientset(existing)
+		require.NoError(t, createRestoredPVCs(context.Background(), cl, sts, restore, false))
+
+		pvcs, err := cl.CoreV1().PersistentVolumeClaims("default").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, pvcs.Items, 3)
+		assert.Nil(t, pvcs.Items[0].Spec.DataSourceRef, "existing PVCs are left untouched")
+		for _, pvc := range pvcs.Items[1:] {
+			assert.Equal(t, "nightly-cluster-leader-0", pvc.Spec.DataSourceRef.Name)
+		}
+	})
+
+	t.Run("without persistence", func(t *testing.T) {
+		ephemeral := sts.DeepCopy()
+		ephemeral.Spec.VolumeClaimTemplates = nil
+		assert.ErrorIs(t, createRestoredPVCs(context.Background(), k8sClientFake.NewSimpleClientset(), ephemeral, restore, true), errRestoreWithoutStorage)
+	})
+}
+
+func TestWaitForVolumeSnapshots(t *testing.T) {
+	snapshot := func(name, pvc string, ready bool) *unstructured.Unstructured {
+		s := NewVolumeSnapshot("default", name, pvc, nil)
+		s.Object["status"] = map[string]interface{}{"readyToUse": ready}
+		return s
+	}
+
+	t.Run("snapshot API not installed", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+		assert.NoError(t, waitForVolumeSnapshots(context.Background(), cl, "default", []string{"redis-redis-0"}))
+	})
+
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(VolumeSnapshotGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(VolumeSnapshotGVK.GroupVersion().WithKind(VolumeSnapshotGVK.Kind+"List"), &unstructured.UnstructuredList{})
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		snapshot("nightly-redis-0", "redis-redis-0", true),
+		snapshot("hourly-redis-0", "other-other-0", false),
+	).Build()
+
+	assert.NoError(t, waitForVolumeSnapshots(context.Background(), cl, "default", []string{"redis-redis-0"}))
+
+	require.NoError(t, cl.Create(context.Background(), snapshot("hourly-redis-0b", "redis-redis-0", false)))
+	assert.Error(t, waitForVolumeSnapshots(context.Background(), cl, "default", []string{"redis-redis-0"}))
+}
@@ -1,6 +1,11 @@
 package monitoring
 
 import (
+	"context"
+	"time"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
@@ -11,6 +16,10 @@ type MetricDescription struct {
 	labels []string
 }
 
+// reconcileDurationBuckets are the buckets of the reconcile duration histograms, from the phases
+// that only read the state of the resources to the ones waiting for a change of the topology.
+var reconcileDurationBuckets = []float64{0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
 func RegisterRedisReplicationMetrics() {
 	metrics.Registry.MustRegister(
 		RedisReplicationSkipReconcile,
@@ -20,17 +29,69 @@ func RegisterRedisReplicationMetrics() {
 		RedisReplicationHasMaster,
 		RedisReplicationMasterRoleChangesTotal,
 		RedisReplicationConnectedSlavesTotal,
+		RedisReplicationReplicaLagBytes,
+		RedisReplicationReconcileDurationSeconds,
+		RedisReplicationFailedExecTotal,
 	)
 }
 
 func RegisterRedisClusterMetrics() {
 	metrics.Registry.MustRegister(
 		RedisClusterHealthy,
+		RedisClusterUnhealthy,
 		RedisClusterSkipReconcile,
 		RedisClusterReplicasSizeDesired,
 		RedisClusterAddingNodeAttempt,
 		RedisClusterRebalanceTotal,
 		RedisClusterRemoveFollowerAttempt,
 		RedisClusterReshardTotal,
+		RedisClusterSlotsAssigned,
+		RedisClusterSlotsOk,
+		RedisClusterSlotsPfail,
+		RedisClusterSlotsFail,
+		RedisClusterOpenSlots,
+		RedisClusterShardReplicas,
+		RedisClusterReconcileDurationSeconds,
+		RedisClusterFailedExecTotal,
 	)
 }
+
+func RegisterRedisSentinelMetrics() {
+	metrics.Registry.MustRegister(
+		RedisSentinelQuorumReachable,
+		RedisSentinelQuorumOk,
+	)
+}
+
+// PhaseTimer observes the time spent in each phase of a reconcile pass of an instance, and traces each
+// phase in a child span of the reconcile.
+type PhaseTimer struct {
+	histogram *prometheus.HistogramVec
+	namespace string
+	name      string
+	phase     string
+	start     time.Time
+	spans     *tracing.Phases
+}
+
+// NewPhaseTimer returns a PhaseTimer of the reconcile traced in ctx observing into histogram, labelled
+// with the namespace, the name of the instance and the phase.
+func NewPhaseTimer(ctx context.Context, histogram *prometheus.HistogramVec, namespace, name string) *PhaseTimer {
+	return &PhaseTimer{histogram: histogram, namespace: namespace, name: name, spans: tracing.NewPhases(ctx)}
+}
+
+// Phase ends the current phase and starts phase, returning the context to run it in.
+func (t *PhaseTimer) Phase(phase string) context.Context {
+	t.Done()
+	t.phase, t.start = phase, time.Now()
+	return t.spans.Start(phase)
+}
+
+// Done ends the current phase.
+func (t *PhaseTimer) Done() {
+	if t.phase != "" {
+		t.histogram.WithLabelValues(t.namespace, t.name, t.phase).Observe(time.Since(t.start).Seconds())
+	}
+	t.phase = ""
+	t.spans.End()
+}
@@ -21,14 +21,21 @@ func main() {
 		return clusterMetrics[i].Name < clusterMetrics[j].Name
 	})
 
+	sentinelMetrics := monitoring.ListRedisSentinelMetrics()
+	sort.Slice(sentinelMetrics, func(i, j int) bool {
+		return sentinelMetrics[i].Name < sentinelMetrics[j].Name
+	})
+
 	type MetricsData struct {
 		Replication []monitoring.MetricDescription
 		Cluster     []monitoring.MetricDescription
+		Sentinel    []monitoring.MetricDescription
 	}
 
 	data := MetricsData{
 		Replication: replicationMetrics,
 		Cluster:     clusterMetrics,
+		Sentinel:    sentinelMetrics,
 	}
 
 	tmpl, err := template.New("Redis Operator metrics").Parse("# Operator Metrics\n" +
@@ -52,6 +59,14 @@ func main() {
 		"Type: {{.Type}}.\n" +
 		"{{end}}" +
 		"\n" +
+		"## Redis Sentinel Metrics" +
+		"\n" +
+		"{{range .Sentinel}}\n" +
+		"### {{.Name}}\n" +
+		"{{.Help}} " +
+		"Type: {{.Type}}.\n" +
+		"{{end}}" +
+		"\n" +
 		"## Developing new metrics\n" +
 		"After developing new metrics or changing old ones, please run \"make generate-metricsdocs\" to regenerate this document.\n\n" +
 		"If you feel that the new metric doesn't follow these rules, please change \"monitoring/metricsdocs\" according to your needs.")
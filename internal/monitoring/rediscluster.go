@@ -48,6 +48,60 @@ var RedisClusterDescription = map[string]MetricDescription{
 		Type:   "Counter",
 		labels: []string{"namespace", "instance"},
 	},
+	"RedisClusterUnhealthy": {
+		Name:   "rediscluster_unhealthy",
+		Help:   "Whether or not the Redis Cluster has failing or disconnected nodes.",
+		Type:   "Gauge",
+		labels: []string{"namespace", "instance"},
+	},
+	"RedisClusterSlotsAssigned": {
+		Name:   "rediscluster_slots_assigned",
+		Help:   "Number of slots assigned to a master, cluster_slots_assigned of CLUSTER INFO.",
+		Type:   "Gauge",
+		labels: []string{"namespace", "instance"},
+	},
+	"RedisClusterSlotsOk": {
+		Name:   "rediscluster_slots_ok",
+		Help:   "Number of slots served by a master that is not failing, cluster_slots_ok of CLUSTER INFO.",
+		Type:   "Gauge",
+		labels: []string{"namespace", "instance"},
+	},
+	"RedisClusterSlotsPfail": {
+		Name:   "rediscluster_slots_pfail",
+		Help:   "Number of slots served by a master possibly failing, cluster_slots_pfail of CLUSTER INFO.",
+		Type:   "Gauge",
+		labels: []string{"namespace", "instance"},
+	},
+	"RedisClusterSlotsFail": {
+		Name:   "rediscluster_slots_fail",
+		Help:   "Number of slots served by a failing master, cluster_slots_fail of CLUSTER INFO.",
+		Type:   "Gauge",
+		labels: []string{"namespace", "instance"},
+	},
+	"RedisClusterOpenSlots": {
+		Name:   "rediscluster_open_slots",
+		Help:   "Number of slots migrating or importing.",
+		Type:   "Gauge",
+		labels: []string{"namespace", "instance"},
+	},
+	"RedisClusterShardReplicas": {
+		Name:   "rediscluster_shard_replicas",
+		Help:   "Number of healthy replicas of the master of a shard, named after the pod of the master.",
+		Type:   "Gauge",
+		labels: []string{"namespace", "instance", "shard"},
+	},
+	"RedisClusterReconcileDurationSeconds": {
+		Name:   "rediscluster_reconcile_duration_seconds",
+		Help:   "Time spent in each phase of the reconcile of RedisCluster.",
+		Type:   "Histogram",
+		labels: []string{"namespace", "instance", "phase"},
+	},
+	"RedisClusterFailedExecTotal": {
+		Name:   "rediscluster_failed_exec_total",
+		Help:   "Total number of failed operations executed on the nodes of the Redis Cluster, by operation.",
+		Type:   "Counter",
+		labels: []string{"namespace", "instance", "operation"},
+	},
 }
 
 var (
@@ -114,6 +168,71 @@ var (
 		},
 		RedisClusterDescription["RedisClusterAddingNodeAttempt"].labels,
 	)
+
+	RedisClusterSlotsAssigned = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: RedisClusterDescription["RedisClusterSlotsAssigned"].Name,
+			Help: RedisClusterDescription["RedisClusterSlotsAssigned"].Help,
+		},
+		RedisClusterDescription["RedisClusterSlotsAssigned"].labels,
+	)
+
+	RedisClusterSlotsOk = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: RedisClusterDescription["RedisClusterSlotsOk"].Name,
+			Help: RedisClusterDescription["RedisClusterSlotsOk"].Help,
+		},
+		RedisClusterDescription["RedisClusterSlotsOk"].labels,
+	)
+
+	RedisClusterSlotsPfail = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: RedisClusterDescription["RedisClusterSlotsPfail"].Name,
+			Help: RedisClusterDescription["RedisClusterSlotsPfail"].Help,
+		},
+		RedisClusterDescription["RedisClusterSlotsPfail"].labels,
+	)
+
+	RedisClusterSlotsFail = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: RedisClusterDescription["RedisClusterSlotsFail"].Name,
+			Help: RedisClusterDescription["RedisClusterSlotsFail"].Help,
+		},
+		RedisClusterDescription["RedisClusterSlotsFail"].labels,
+	)
+
+	RedisClusterOpenSlots = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: RedisClusterDescription["RedisClusterOpenSlots"].Name,
+			Help: RedisClusterDescription["RedisClusterOpenSlots"].Help,
+		},
+		RedisClusterDescription["RedisClusterOpenSlots"].labels,
+	)
+
+	RedisClusterShardReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: RedisClusterDescription["RedisClusterShardReplicas"].Name,
+			Help: RedisClusterDescription["RedisClusterShardReplicas"].Help,
+		},
+		RedisClusterDescription["RedisClusterShardReplicas"].labels,
+	)
+
+	RedisClusterReconcileDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    RedisClusterDescription["RedisClusterReconcileDurationSeconds"].Name,
+			Help:    RedisClusterDescription["RedisClusterReconcileDurationSeconds"].Help,
+			Buckets: reconcileDurationBuckets,
+		},
+		RedisClusterDescription["RedisClusterReconcileDurationSeconds"].labels,
+	)
+
+	RedisClusterFailedExecTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: RedisClusterDescription["RedisClusterFailedExecTotal"].Name,
+			Help: RedisClusterDescription["RedisClusterFailedExecTotal"].Help,
+		},
+		RedisClusterDescription["RedisClusterFailedExecTotal"].labels,
+	)
 )
 
 // ListMetrics will create a slice with the metrics available in metricDescription
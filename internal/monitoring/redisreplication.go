@@ -48,6 +48,24 @@ var metricDescription = map[string]MetricDescription{
 		Type:   "Counter",
 		labels: []string{"namespace", "instance"},
 	},
+	"RedisReplicationReplicaLagBytes": {
+		Name:   "redisreplication_replica_lag_bytes",
+		Help:   "Number of bytes of the replication stream of the master a replica has yet to process.",
+		Type:   "Gauge",
+		labels: []string{"namespace", "instance", "pod"},
+	},
+	"RedisReplicationReconcileDurationSeconds": {
+		Name:   "redisreplication_reconcile_duration_seconds",
+		Help:   "Time spent in each phase of the reconcile of RedisReplication.",
+		Type:   "Histogram",
+		labels: []string{"namespace", "instance", "phase"},
+	},
+	"RedisReplicationFailedExecTotal": {
+		Name:   "redisreplication_failed_exec_total",
+		Help:   "Total number of failed operations executed on the Redis servers of the replication, by operation.",
+		Type:   "Counter",
+		labels: []string{"namespace", "instance", "operation"},
+	},
 }
 
 var (
@@ -101,6 +119,28 @@ var (
 		},
 		metricDescription["RedisReplicationConnectedSlavesTotal"].labels,
 	)
+	RedisReplicationReplicaLagBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: metricDescription["RedisReplicationReplicaLagBytes"].Name,
+			Help: metricDescription["RedisReplicationReplicaLagBytes"].Help,
+		},
+		metricDescription["RedisReplicationReplicaLagBytes"].labels,
+	)
+	RedisReplicationReconcileDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    metricDescription["RedisReplicationReconcileDurationSeconds"].Name,
+			Help:    metricDescription["RedisReplicationReconcileDurationSeconds"].Help,
+			Buckets: reconcileDurationBuckets,
+		},
+		metricDescription["RedisReplicationReconcileDurationSeconds"].labels,
+	)
+	RedisReplicationFailedExecTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: metricDescription["RedisReplicationFailedExecTotal"].Name,
+			Help: metricDescription["RedisReplicationFailedExecTotal"].Help,
+		},
+		metricDescription["RedisReplicationFailedExecTotal"].labels,
+	)
 )
 
 // ListMetrics will create a slice with the metrics available in metricDescription
@@ -0,0 +1,49 @@
+package monitoring
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RedisSentinelDescription is a map of string keys (metrics) to MetricDescription values (Name, Help).
+var RedisSentinelDescription = map[string]MetricDescription{
+	"RedisSentinelQuorumReachable": {
+		Name:   "redissentinel_quorum_reachable",
+		Help:   "Whether or not a majority of the sentinels can authorize a failover of the master, as SENTINEL CKQUORUM reports.",
+		Type:   "Gauge",
+		labels: []string{"namespace", "instance"},
+	},
+	"RedisSentinelQuorumOk": {
+		Name:   "redissentinel_quorum_ok",
+		Help:   "Whether or not a sentinel reaches the quorum and the majority needed to authorize a failover of the master.",
+		Type:   "Gauge",
+		labels: []string{"namespace", "instance", "pod"},
+	},
+}
+
+var (
+	RedisSentinelQuorumReachable = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: RedisSentinelDescription["RedisSentinelQuorumReachable"].Name,
+			Help: RedisSentinelDescription["RedisSentinelQuorumReachable"].Help,
+		},
+		RedisSentinelDescription["RedisSentinelQuorumReachable"].labels,
+	)
+
+	RedisSentinelQuorumOk = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: RedisSentinelDescription["RedisSentinelQuorumOk"].Name,
+			Help: RedisSentinelDescription["RedisSentinelQuorumOk"].Help,
+		},
+		RedisSentinelDescription["RedisSentinelQuorumOk"].labels,
+	)
+)
+
+// ListRedisSentinelMetrics will create a slice with the metrics available in RedisSentinelDescription
+func ListRedisSentinelMetrics() []MetricDescription {
+	v := make([]MetricDescription, 0, len(RedisSentinelDescription))
+	for _, value := range RedisSentinelDescription {
+		v = append(v, value)
+	}
+
+	return v
+}
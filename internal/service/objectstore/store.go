@@ -0,0 +1,112 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	rbvb2 "github.com/OT-CONTAINER-KIT/redis-operator/api/redisbackup/v1beta2"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// uploadPartSize bounds the memory used to buffer a stream of unknown length.
+// With the 10000 parts S3 allows, it caps a single object at ~640GiB.
+const uploadPartSize = 64 << 20
+
+// Config is the connection configuration of an S3-compatible bucket
+type Config struct {
+	// Endpoint is host[:port] of the S3 API
+	Endpoint        string
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// Insecure disables TLS towards the endpoint
+	Insecure bool
+}
+
+// ConfigFromS3 builds the Config of a backup bucket from its spec and credentials secret.
+func ConfigFromS3(s3 *rbvb2.S3Storage, secret *corev1.Secret) Config {
+	return Config{
+		Endpoint:        s3.Endpoint,
+		Bucket:          s3.Bucket,
+		Region:          s3.Region,
+		AccessKeyID:     string(secret.Data[rbvb2.S3AccessKeyIDKey]),
+		SecretAccessKey: string(secret.Data[rbvb2.S3SecretAccessKeyKey]),
+		Insecure:        s3.Insecure,
+	}
+}
+
+// Store reads and writes backup artifacts in object storage
+type Store interface {
+	// Upload streams r to key and returns the number of bytes written.
+	Upload(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Download opens the object stored at key.
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored at key; deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// List returns the keys of all objects below prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+type s3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Store returns a Store backed by an S3-compatible bucket.
+func NewS3Store(cfg Config) (Store, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("s3 endpoint and bucket are required")
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure: !cfg.Insecure,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &s3Store{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (s *s3Store) Upload(ctx context.Context, key string, r io.Reader) (int64, error) {
+	info, err := s.client.PutObject(ctx, s.bucket, key, r, -1, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+		PartSize:    uploadPartSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return info.Size, nil
+}
+
+func (s *s3Store) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject is lazy, stat the object so a missing key fails here rather than on first read.
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *s3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		keys = append(keys, obj.Key)
+	}
+	return keys, nil
+}
@@ -3,10 +3,13 @@ package redis
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"net"
+	"path"
 	"strconv"
 	"strings"
 
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
 	rediscli "github.com/redis/go-redis/v9"
 )
 
@@ -22,6 +25,8 @@ type ConnectionInfo struct {
 	Password string
 	// TLSConfig configuration, nil means TLS is disabled
 	TLSConfig *tls.Config
+	// Pod is the name of the pod of the server, recorded on the spans of its commands
+	Pod string
 }
 
 // ClusterStatus cluster status information, including the number of assigned slots
@@ -56,8 +61,21 @@ type Service interface {
 	SentinelMonitor(ctx context.Context, master *ConnectionInfo, masterGroupName, quorum string) error
 	SentinelSet(ctx context.Context, masterGroupName, key, value string) error
 	SentinelReset(ctx context.Context, masterGroupName string) error
+	// SentinelFailover runs SENTINEL FAILOVER, promoting a replica of the master group without the
+	// agreement of the other sentinels.
+	SentinelFailover(ctx context.Context, masterGroupName string) error
+	// SentinelCKQuorum runs SENTINEL CKQUORUM, a NOQUORUM reply is reported as ok=false with the reason.
+	SentinelCKQuorum(ctx context.Context, masterGroupName string) (ok bool, message string, err error)
 	GetInfoSentinel(ctx context.Context) (*InfoSentinelResult, error)
 	GetClusterInfo(ctx context.Context) (*ClusterStatus, error)
+	// GetInfo returns the key/value pairs of the given INFO section.
+	GetInfo(ctx context.Context, section string) (map[string]string, error)
+	// BgSave asks the server to write an RDB snapshot in the background.
+	BgSave(ctx context.Context) error
+	// GetRDBPath returns the absolute path of the RDB file inside the container.
+	GetRDBPath(ctx context.Context) (string, error)
+	// GetClusterMySlots returns the slot ranges served by the node, e.g. "0-5460".
+	GetClusterMySlots(ctx context.Context) ([]string, error)
 }
 
 type InfoSentinelResult struct {
@@ -92,7 +110,7 @@ func (s *service) createClient() *rediscli.Client {
 	if s.connectionInfo.TLSConfig != nil {
 		opts.TLSConfig = s.connectionInfo.TLSConfig
 	}
-	return rediscli.NewClient(opts)
+	return tracing.InstrumentRedis(rediscli.NewClient(opts), s.connectionInfo.Pod)
 }
 
 func (c *service) GetInfoSentinel(ctx context.Context) (*InfoSentinelResult, error) {
@@ -170,6 +188,24 @@ func (c *service) SentinelSet(ctx context.Context, masterGroupName, key, value s
 	return nil
 }
 
+func (c *service) SentinelCKQuorum(ctx context.Context, masterGroupName string) (bool, string, error) {
+	client := c.createClient()
+	if client == nil {
+		return false, "", nil
+	}
+	defer client.Close()
+
+	reply, err := client.Do(ctx, "SENTINEL", "CKQUORUM", masterGroupName).Text()
+	var redisErr rediscli.Error
+	if errors.As(err, &redisErr) {
+		return false, err.Error(), nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	return true, reply, nil
+}
+
 func (c *service) SentinelReset(ctx context.Context, masterGroupName string) error {
 	client := c.createClient()
 	if client == nil {
@@ -188,6 +224,16 @@ func (c *service) SentinelReset(ctx context.Context, masterGroupName string) err
 	return nil
 }
 
+func (c *service) SentinelFailover(ctx context.Context, masterGroupName string) error {
+	client := c.createClient()
+	if client == nil {
+		return nil
+	}
+	defer client.Close()
+
+	return client.Do(ctx, "SENTINEL", "FAILOVER", masterGroupName).Err()
+}
+
 func (c *service) SentinelMonitor(ctx context.Context, master *ConnectionInfo, masterGroupName, quorum string) error {
 	var (
 		cmd *rediscli.BoolCmd
@@ -335,3 +381,93 @@ func (c *service) GetClusterInfo(ctx context.Context) (*ClusterStatus, error) {
 
 	return status, nil
 }
+
+func (c *service) GetInfo(ctx context.Context, section string) (map[string]string, error) {
+	client := c.createClient()
+	if client == nil {
+		return nil, nil
+	}
+	defer client.Close()
+
+	result, err := client.Info(ctx, section).Result()
+	if err != nil {
+		return nil, err
+	}
+	return ParseInfo(result), nil
+}
+
+// ParseInfo turns the `key:value` lines of an INFO or CLUSTER INFO reply into a map, skipping section
+// headers.
+func ParseInfo(info string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\r\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, found := strings.Cut(line, ":"); found {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+func (c *service) BgSave(ctx context.Context) error {
+	client := c.createClient()
+	if client == nil {
+		return nil
+	}
+	defer client.Close()
+
+	return client.BgSave(ctx).Err()
+}
+
+func (c *service) GetRDBPath(ctx context.Context) (string, error) {
+	client := c.createClient()
+	if client == nil {
+		return "", nil
+	}
+	defer client.Close()
+
+	dir, err := client.ConfigGet(ctx, "dir").Result()
+	if err != nil {
+		return "", err
+	}
+	dbfilename, err := client.ConfigGet(ctx, "dbfilename").Result()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(dir["dir"], dbfilename["dbfilename"]), nil
+}
+
+func (c *service) GetClusterMySlots(ctx context.Context) ([]string, error) {
+	client := c.createClient()
+	if client == nil {
+		return nil, nil
+	}
+	defer client.Close()
+
+	nodes, err := client.ClusterNodes(ctx).Result()
+	if err != nil {
+		return nil, err
+	}
+	return parseMySlots(nodes), nil
+}
+
+// parseMySlots returns the slot ranges of the "myself" line of a CLUSTER NODES reply,
+// skipping the bracketed entries of slots that are being migrated or imported.
+func parseMySlots(nodes string) []string {
+	for _, line := range strings.Split(nodes, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 8 || !strings.Contains(fields[2], "myself") {
+			continue
+		}
+		var slots []string
+		for _, field := range fields[8:] {
+			if !strings.HasPrefix(field, "[") {
+				slots = append(slots, field)
+			}
+		}
+		return slots
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMySlots(t *testing.T) {
+	nodes := "07c37dfeb235213a872192d90877d0cd55635b91 10.0.0.2:6379@16379 slave e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 0 1426238317239 4 connected\n" +
+		"e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 10.0.0.1:6379@16379 myself,master - 0 0 1 connected 0-5460 5462 [5461->-67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1]\n"
+
+	assert.Equal(t, []string{"0-5460", "5462"}, parseMySlots(nodes))
+	assert.Nil(t, parseMySlots(""))
+}
@@ -0,0 +1,852 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OT-CONTAINER-KIT/redis-operator/internal/tracing"
+	rediscli "github.com/redis/go-redis/v9"
+)
+
+// TotalClusterSlots is the number of hash slots of a Redis cluster.
+const TotalClusterSlots = 16384
+
+const (
+	// defaultMigratePipeline is the number of keys moved by a single MIGRATE, as redis-cli --cluster-pipeline.
+	defaultMigratePipeline = 10
+	// defaultMigrateTimeout bounds a single MIGRATE, as redis-cli --cluster-timeout.
+	defaultMigrateTimeout = 60 * time.Second
+	// defaultDialTimeout bounds connecting to a node, so a stale address can't stall an operation.
+	defaultDialTimeout = 5 * time.Second
+	// defaultJoinTimeout bounds waiting for the nodes to agree about the configuration after a change.
+	defaultJoinTimeout = 60 * time.Second
+	// defaultRebalanceThreshold is the imbalance in percent under which Rebalance moves nothing.
+	defaultRebalanceThreshold = 2
+)
+
+// FailoverOption selects how CLUSTER FAILOVER promotes a replica.
+type FailoverOption string
+
+const (
+	// FailoverDefault waits for the master to hand over its replication offset.
+	FailoverDefault FailoverOption = ""
+	// FailoverForce does not coordinate with the master, which may be down.
+	FailoverForce FailoverOption = "FORCE"
+	// FailoverTakeover does not coordinate with the master nor ask the other masters for votes.
+	FailoverTakeover FailoverOption = "TAKEOVER"
+)
+
+// SlotRange is an inclusive range of hash slots.
+type SlotRange struct {
+	Start int
+	End   int
+}
+
+// Len returns the number of slots in the range.
+func (r SlotRange) Len() int {
+	return r.End - r.Start + 1
+}
+
+// slots returns the slots of the range in ascending order.
+func (r SlotRange) slots() []int {
+	slots := make([]int, 0, r.Len())
+	for slot := r.Start; slot <= r.End; slot++ {
+		slots = append(slots, slot)
+	}
+	return slots
+}
+
+func (r SlotRange) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// FormatSlotRanges formats ranges as a comma separated list such as `0-99,200`, in ascending order
+// and merging the ranges that overlap or touch.
+func FormatSlotRanges(ranges []SlotRange) string {
+	sorted := append([]SlotRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	var merged []SlotRange
+	for _, r := range sorted {
+		if last := len(merged) - 1; last >= 0 && r.Start <= merged[last].End+1 {
+			merged[last].End = max(merged[last].End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	parts := make([]string, 0, len(merged))
+	for _, r := range merged {
+		parts = append(parts, r.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseSlotRanges parses a list of slot ranges formatted by FormatSlotRanges.
+func ParseSlotRanges(s string) ([]SlotRange, error) {
+	var ranges []SlotRange
+	for _, field := range strings.Split(s, ",") {
+		if field == "" {
+			continue
+		}
+		start, end, found := strings.Cut(field, "-")
+		if !found {
+			end = start
+		}
+		r := SlotRange{}
+		var err error
+		if r.Start, err = strconv.Atoi(start); err != nil {
+			return nil, fmt.Errorf("invalid slot range %q", field)
+		}
+		if r.End, err = strconv.Atoi(end); err != nil {
+			return nil, fmt.Errorf("invalid slot range %q", field)
+		}
+		if r.Start < 0 || r.End >= TotalClusterSlots || r.Start > r.End {
+			return nil, fmt.Errorf("invalid slot range %q", field)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// ClusterNode is a node of a Redis cluster as listed by CLUSTER NODES.
+type ClusterNode struct {
+	ID string
+	// Addr is the ip:port clients connect to, without the cluster bus port.
+	Addr string
+	// Hostname is announced by Redis 7+ nodes configured with cluster-announce-hostname.
+	Hostname string
+	Flags    []string
+	// MasterID is the ID of the master of a replica, empty for masters.
+	MasterID    string
+	ConfigEpoch int64
+	Connected   bool
+	Slots       []SlotRange
+	// Migrating and Importing map the open slots of the node to the ID of the node on the other end.
+	// CLUSTER NODES only lists them for the node answering the command.
+	Migrating map[int]string
+	Importing map[int]string
+}
+
+// HasFlag reports whether the node is listed with flag, e.g. "myself" or "fail".
+func (n *ClusterNode) HasFlag(flag string) bool {
+	for _, f := range n.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMaster reports whether the node is a master.
+func (n *ClusterNode) IsMaster() bool {
+	return n.HasFlag("master")
+}
+
+// Healthy reports whether the node is connected and neither failing nor joining the cluster.
+func (n *ClusterNode) Healthy() bool {
+	return n.Connected && !n.HasFlag("fail") && !n.HasFlag("fail?") && !n.HasFlag("handshake") && !n.HasFlag("noaddr")
+}
+
+// SlotCount returns the number of slots served by the node.
+func (n *ClusterNode) SlotCount() int {
+	count := 0
+	for _, r := range n.Slots {
+		count += r.Len()
+	}
+	return count
+}
+
+// slotList returns the slots served by the node in ascending order.
+func (n *ClusterNode) slotList() []int {
+	slots := make([]int, 0, n.SlotCount())
+	for _, r := range n.Slots {
+		slots = append(slots, r.slots()...)
+	}
+	return slots
+}
+
+// ParseClusterNodes parses a CLUSTER NODES reply.
+func ParseClusterNodes(reply string) ([]ClusterNode, error) {
+	var nodes []ClusterNode
+	for _, line := range strings.Split(reply, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 8 {
+			return nil, fmt.Errorf("malformed CLUSTER NODES line %q", line)
+		}
+		node := ClusterNode{
+			ID:        fields[0],
+			Flags:     strings.Split(fields[2], ","),
+			Connected: fields[7] == "connected",
+		}
+		addr, hostname, _ := strings.Cut(fields[1], ",")
+		node.Addr, _, _ = strings.Cut(addr, "@")
+		node.Hostname = hostname
+		if fields[3] != "-" {
+			node.MasterID = fields[3]
+		}
+		epoch, err := strconv.ParseInt(fields[6], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed config epoch in CLUSTER NODES line %q", line)
+		}
+		node.ConfigEpoch = epoch
+		for _, field := range fields[8:] {
+			if err := node.addSlotField(field); err != nil {
+				return nil, fmt.Errorf("malformed slots in CLUSTER NODES line %q: %w", line, err)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// addSlotField records a slot field of CLUSTER NODES: "1", "1-5", "[1->-<id>]" or "[1-<-<id>]".
+func (n *ClusterNode) addSlotField(field string) error {
+	if open, ok := strings.CutPrefix(field, "["); ok {
+		open = strings.TrimSuffix(open, "]")
+		if slot, id, found := strings.Cut(open, "->-"); found {
+			s, err := strconv.Atoi(slot)
+			if err != nil {
+				return err
+			}
+			if n.Migrating == nil {
+				n.Migrating = map[int]string{}
+			}
+			n.Migrating[s] = id
+			return nil
+		}
+		if slot, id, found := strings.Cut(open, "-<-"); found {
+			s, err := strconv.Atoi(slot)
+			if err != nil {
+				return err
+			}
+			if n.Importing == nil {
+				n.Importing = map[int]string{}
+			}
+			n.Importing[s] = id
+			return nil
+		}
+		return fmt.Errorf("unknown slot state %q", field)
+	}
+	start, end, found := strings.Cut(field, "-")
+	if !found {
+		end = start
+	}
+	s, err := strconv.Atoi(start)
+	if err != nil {
+		return err
+	}
+	e, err := strconv.Atoi(end)
+	if err != nil {
+		return err
+	}
+	n.Slots = append(n.Slots, SlotRange{Start: s, End: e})
+	return nil
+}
+
+// ClusterAdmin manages the topology of a Redis cluster the way redis-cli --cluster does, talking to the
+// nodes directly. Nodes are addressed by host:port; the seed of an operation is any node of the cluster,
+// whose view of the cluster is used to find the other nodes.
+type ClusterAdmin interface {
+	// Nodes returns the nodes of the cluster as seen by the node at addr.
+	Nodes(ctx context.Context, addr string) ([]ClusterNode, error)
+	// Check fails unless every node is reachable, the nodes agree about the slots configuration,
+	// no slot is open and all the slots are served, as redis-cli --cluster check.
+	Check(ctx context.Context, seed string) error
+	// Create forms a cluster of the empty masters at addrs, splitting the slots evenly between them
+	// in order. A Create that was interrupted can be run again.
+	Create(ctx context.Context, addrs []string) error
+	// CreateWithSlots forms a cluster of the masters at addrs, each serving the slots at its index in
+	// slots. Unlike with Create the nodes may hold the keys of their slots, e.g. loaded from a backup.
+	// A CreateWithSlots that was interrupted can be run again.
+	CreateWithSlots(ctx context.Context, addrs []string, slots [][]SlotRange) error
+	// AddNode joins the empty node at addr to the cluster of seed, as a replica of masterID
+	// unless it is empty, and returns once the seed knows the node. A replica that is already
+	// a member of the cluster is moved to masterID.
+	AddNode(ctx context.Context, seed, addr, masterID string) error
+	// DelNode removes the node nodeID, which must not serve any slot, from the cluster.
+	// Its replicas are moved to the master with the fewest replicas.
+	DelNode(ctx context.Context, seed, nodeID string) error
+	// Reshard moves count slots from the master fromID to the master toID, along with their keys.
+	Reshard(ctx context.Context, seed, fromID, toID string, count int) error
+	// Rebalance moves slots between the masters until each serves a share of the slots
+	// proportional to its weight.
+	Rebalance(ctx context.Context, seed string, opts RebalanceOptions) error
+	// Assign moves the slots listed in owners, keyed by node ID, to the master owning them, along with
+	// their keys. Only the slots served by another master are moved; the slots owners leaves out stay put.
+	Assign(ctx context.Context, seed string, owners map[string][]SlotRange) error
+	// Fix closes the open slots and assigns the slots no master serves, as redis-cli --cluster fix.
+	Fix(ctx context.Context, seed string) error
+	// Failover promotes the replica at addr to master of its shard.
+	Failover(ctx context.Context, addr string, option FailoverOption) error
+}
+
+// RebalanceOptions tunes Rebalance.
+type RebalanceOptions struct {
+	// Weights maps node IDs to their weight, 1 when unset. A master of weight 0 is drained of its slots.
+	Weights map[string]float64
+	// UseEmptyMasters gives slots to the masters without any, which are otherwise left out.
+	UseEmptyMasters bool
+	// Threshold is the imbalance in percent of the expected number of slots of a master under which
+	// nothing is moved, 2 when zero.
+	Threshold float64
+}
+
+type clusterAdmin struct {
+	password  string
+	tlsConfig *tls.Config
+	// pipeline is the number of keys moved by a single MIGRATE.
+	pipeline int
+	// migrateTimeout bounds a single MIGRATE.
+	migrateTimeout time.Duration
+	// joinTimeout bounds waiting for the nodes to agree after a topology change.
+	joinTimeout time.Duration
+	// pollInterval is how often the nodes are polled while waiting.
+	pollInterval time.Duration
+	// podName returns the name of the pod of the node at an address, recorded on the spans of its
+	// commands. It may be nil.
+	podName func(addr string) string
+}
+
+// NewClusterAdmin returns a ClusterAdmin authenticating to every node with password, over TLS when
+// tlsConfig is set. podName, when set, names the pod of the node at an address in the traces.
+func NewClusterAdmin(password string, tlsConfig *tls.Config, podName func(addr string) string) ClusterAdmin {
+	return &clusterAdmin{
+		password:       password,
+		tlsConfig:      tlsConfig,
+		podName:        podName,
+		pipeline:       defaultMigratePipeline,
+		migrateTimeout: defaultMigrateTimeout,
+		joinTimeout:    defaultJoinTimeout,
+		pollInterval:   time.Second,
+	}
+}
+
+// nodeClients holds a connection to each node an operation talks to.
+type nodeClients struct {
+	admin   *clusterAdmin
+	clients map[string]*rediscli.Client
+}
+
+func (c *clusterAdmin) connect() *nodeClients {
+	return &nodeClients{admin: c, clients: map[string]*rediscli.Client{}}
+}
+
+func (p *nodeClients) get(addr string) *rediscli.Client {
+	if client, ok := p.clients[addr]; ok {
+		return client
+	}
+	client := rediscli.NewClient(&rediscli.Options{
+		Addr:        addr,
+		Password:    p.admin.password,
+		TLSConfig:   p.admin.tlsConfig,
+		DialTimeout: defaultDialTimeout,
+		// A MIGRATE blocks the connection for up to its own timeout.
+		ReadTimeout:           p.admin.migrateTimeout + defaultDialTimeout,
+		ContextTimeoutEnabled: true,
+	})
+	client.AddHook(tracing.NewRedisHook(addr, func() string {
+		if p.admin.podName == nil {
+			return ""
+		}
+		return p.admin.podName(addr)
+	}))
+	p.clients[addr] = client
+	return client
+}
+
+func (p *nodeClients) close() {
+	for _, client := range p.clients {
+		client.Close()
+	}
+}
+
+// nodes returns the view of the cluster of the node at addr, the node itself first.
+func (p *nodeClients) nodes(ctx context.Context, addr string) ([]ClusterNode, error) {
+	reply, err := p.get(addr).ClusterNodes(ctx).Result()
+	if err != nil {
+		return nil, fmt.Errorf("CLUSTER NODES on %s: %w", addr, err)
+	}
+	nodes, err := ParseClusterNodes(reply)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].HasFlag("myself") && !nodes[j].HasFlag("myself")
+	})
+	if len(nodes) == 0 || !nodes[0].HasFlag("myself") {
+		return nil, fmt.Errorf("CLUSTER NODES on %s does not list the node itself", addr)
+	}
+	return nodes, nil
+}
+
+func (c *clusterAdmin) Nodes(ctx context.Context, addr string) ([]ClusterNode, error) {
+	p := c.connect()
+	defer p.close()
+	return p.nodes(ctx, addr)
+}
+
+func (c *clusterAdmin) Check(ctx context.Context, seed string) error {
+	p := c.connect()
+	defer p.close()
+	_, err := c.check(ctx, p, seed)
+	return err
+}
+
+// check returns the nodes seen by the seed, with the open slots each node reports about itself,
+// and an error for every problem redis-cli --cluster check would report.
+func (c *clusterAdmin) check(ctx context.Context, p *nodeClients, seed string) ([]ClusterNode, error) {
+	nodes, err := p.nodes(ctx, seed)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	signature := configSignature(nodes)
+	for i := range nodes {
+		view := nodes
+		if i > 0 {
+			if view, err = p.nodes(ctx, nodes[i].Addr); err != nil {
+				errs = append(errs, fmt.Errorf("node %s is unreachable: %w", nodes[i].ID, err))
+				continue
+			}
+			if configSignature(view) != signature {
+				errs = append(errs, fmt.Errorf("node %s does not agree about the slots configuration", nodes[i].ID))
+			}
+		}
+		nodes[i].Migrating, nodes[i].Importing = view[0].Migrating, view[0].Importing
+		if len(view[0].Migrating)+len(view[0].Importing) > 0 {
+			errs = append(errs, fmt.Errorf("node %s has open slots", nodes[i].ID))
+		}
+	}
+	if covered := coveredSlots(nodes); covered < TotalClusterSlots {
+		errs = append(errs, fmt.Errorf("only %d of %d slots are covered", covered, TotalClusterSlots))
+	}
+	return nodes, errors.Join(errs...)
+}
+
+// configSignature sums up the slots served by each master, which all the nodes agree on once a
+// configuration change has propagated.
+func configSignature(nodes []ClusterNode) string {
+	var entries []string
+	for _, n := range nodes {
+		if !n.IsMaster() || len(n.Slots) == 0 {
+			continue
+		}
+		ranges := make([]string, 0, len(n.Slots))
+		for _, r := range n.Slots {
+			ranges = append(ranges, r.String())
+		}
+		entries = append(entries, n.ID+":"+strings.Join(ranges, ","))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, "|")
+}
+
+func coveredSlots(nodes []ClusterNode) int {
+	covered := make([]bool, TotalClusterSlots)
+	count := 0
+	for _, n := range nodes {
+		if !n.IsMaster() {
+			continue
+		}
+		for _, slot := range n.slotList() {
+			if slot >= 0 && slot < TotalClusterSlots && !covered[slot] {
+				covered[slot] = true
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func (c *clusterAdmin) Create(ctx context.Context, addrs []string) error {
+	plan := make([][]SlotRange, 0, len(addrs))
+	for _, r := range splitSlots(len(addrs)) {
+		plan = append(plan, []SlotRange{r})
+	}
+	return c.create(ctx, addrs, plan, true)
+}
+
+func (c *clusterAdmin) CreateWithSlots(ctx context.Context, addrs []string, slots [][]SlotRange) error {
+	if len(slots) != len(addrs) {
+		return fmt.Errorf("got the slots of %d nodes for %d nodes", len(slots), len(addrs))
+	}
+	owners := make(map[int]int)
+	for i, ranges := range slots {
+		for _, r := range ranges {
+			if r.Start < 0 || r.End >= TotalClusterSlots || r.Start > r.End {
+				return fmt.Errorf("invalid slot range %s", r)
+			}
+			for slot := r.Start; slot <= r.End; slot++ {
+				if other, ok := owners[slot]; ok && other != i {
+					return fmt.Errorf("slot %d is assigned to both %s and %s", slot, addrs[other], addrs[i])
+				}
+				owners[slot] = i
+			}
+		}
+	}
+	return c.create(ctx, addrs, slots, false)
+}
+
+// create forms a cluster of the masters at addrs, assigning each the slots at its index in plan.
+// With empty set, the nodes must not hold any key.
+func (c *clusterAdmin) create(ctx context.Context, addrs []string, plan [][]SlotRange, empty bool) error {
+	if len(addrs) == 0 {
+		return errors.New("no node to create the cluster from")
+	}
+	p := c.connect()
+	defer p.close()
+
+	for i, addr := range addrs {
+		view, err := p.nodes(ctx, addr)
+		if err != nil {
+			return err
+		}
+		me := view[0]
+		missing, ok := missingSlots(me.Slots, plan[i])
+		switch {
+		case ok && len(missing) == 0 && len(view) > 1:
+			// Assigned by an earlier, interrupted create.
+			continue
+		case !ok || len(view) > 1:
+			return fmt.Errorf("node %s is not empty: it already knows other nodes or serves slots", addr)
+		}
+		if empty {
+			if err := c.checkEmpty(ctx, p, addr); err != nil {
+				return err
+			}
+		}
+		// CLUSTER ADDSLOTSRANGE needs Redis 7, ADDSLOTS works with every version. A node loading keys
+		// claims their slots, so only the others are left to assign.
+		if len(missing) > 0 {
+			if err := p.get(addr).ClusterAddSlots(ctx, missing...).Err(); err != nil {
+				return fmt.Errorf("failed to assign slots %s to %s: %w", FormatSlotRanges(plan[i]), addr, err)
+			}
+		}
+		// Distinct epochs let the nodes settle the configuration without conflicts, as redis-cli does.
+		// A node refuses a new epoch once it has one.
+		if me.ConfigEpoch == 0 {
+			if err := p.get(addr).Do(ctx, "CLUSTER", "SET-CONFIG-EPOCH", i+1).Err(); err != nil {
+				return fmt.Errorf("failed to set the config epoch of %s: %w", addr, err)
+			}
+		}
+	}
+
+	for _, addr := range addrs[1:] {
+		if err := c.meet(ctx, p, addrs[0], addr); err != nil {
+			return err
+		}
+	}
+	return c.waitForAgreement(ctx, p, addrs)
+}
+
+// missingSlots returns the slots of wanted that are not in served, in ascending order,
+// and false if served has slots outside of wanted.
+func missingSlots(served, wanted []SlotRange) ([]int, bool) {
+	want := make(map[int]bool)
+	for _, r := range wanted {
+		for _, slot := range r.slots() {
+			want[slot] = true
+		}
+	}
+	for _, r := range served {
+		for _, slot := range r.slots() {
+			if !want[slot] {
+				return nil, false
+			}
+			delete(want, slot)
+		}
+	}
+	missing := make([]int, 0, len(want))
+	for slot := range want {
+		missing = append(missing, slot)
+	}
+	sort.Ints(missing)
+	return missing, true
+}
+
+// splitSlots splits the slots into n contiguous ranges as even as redis-cli --cluster create does.
+func splitSlots(n int) []SlotRange {
+	ranges := make([]SlotRange, 0, n)
+	perNode := float64(TotalClusterSlots) / float64(n)
+	first, cursor := 0, 0.0
+	for i := 0; i < n; i++ {
+		last := int(cursor + perNode - 1 + 0.5)
+		if last > TotalClusterSlots-1 || i == n-1 {
+			last = TotalClusterSlots - 1
+		}
+		if last < first {
+			last = first
+		}
+		ranges = append(ranges, SlotRange{Start: first, End: last})
+		first = last + 1
+		cursor += perNode
+	}
+	return ranges
+}
+
+// checkEmpty fails unless the node at addr is a cluster node without keys.
+func (c *clusterAdmin) checkEmpty(ctx context.Context, p *nodeClients, addr string) error {
+	if err := p.get(addr).ClusterInfo(ctx).Err(); err != nil {
+		return fmt.Errorf("node %s is not a cluster node: %w", addr, err)
+	}
+	keys, err := p.get(addr).DBSize(ctx).Result()
+	if err != nil {
+		return fmt.Errorf("DBSIZE on %s: %w", addr, err)
+	}
+	if keys > 0 {
+		return fmt.Errorf("node %s is not empty: it holds %d keys", addr, keys)
+	}
+	return nil
+}
+
+// meet makes the node at from meet the node at to. CLUSTER MEET only takes IP addresses.
+func (c *clusterAdmin) meet(ctx context.Context, p *nodeClients, from, to string) error {
+	ip, port, err := resolveAddr(ctx, to)
+	if err != nil {
+		return err
+	}
+	if err := p.get(from).ClusterMeet(ctx, ip, port).Err(); err != nil {
+		return fmt.Errorf("failed to make %s meet %s: %w", from, to, err)
+	}
+	return nil
+}
+
+// resolveAddr returns the IP address and port of addr.
+func resolveAddr(ctx context.Context, addr string) (string, string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", "", err
+	}
+	if net.ParseIP(host) != nil {
+		return host, port, nil
+	}
+	ips, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	return ips[0], port, nil
+}
+
+// waitForAgreement waits until each node at addrs knows all of them and they agree about the slots.
+func (c *clusterAdmin) waitForAgreement(ctx context.Context, p *nodeClients, addrs []string) error {
+	return c.poll(ctx, func() error {
+		signature := ""
+		for i, addr := range addrs {
+			view, err := p.nodes(ctx, addr)
+			if err != nil {
+				return err
+			}
+			if len(view) < len(addrs) {
+				return fmt.Errorf("node %s knows %d of %d nodes", addr, len(view), len(addrs))
+			}
+			for _, n := range view {
+				if n.HasFlag("handshake") {
+					return fmt.Errorf("node %s is still in handshake with %s", addr, n.Addr)
+				}
+			}
+			if i == 0 {
+				signature = configSignature(view)
+			} else if configSignature(view) != signature {
+				return fmt.Errorf("node %s does not agree about the slots configuration yet", addr)
+			}
+		}
+		return nil
+	})
+}
+
+// poll calls cond until it succeeds, failing with its last error after the join timeout.
+func (c *clusterAdmin) poll(ctx context.Context, cond func() error) error {
+	deadline := time.Now().Add(c.joinTimeout)
+	for {
+		err := cond()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s: %w", c.joinTimeout, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
+func (c *clusterAdmin) AddNode(ctx context.Context, seed, addr, masterID string) error {
+	p := c.connect()
+	defer p.close()
+
+	nodes, err := p.nodes(ctx, seed)
+	if err != nil {
+		return err
+	}
+	if masterID != "" {
+		if master := findNode(nodes, masterID); master == nil || !master.IsMaster() {
+			return fmt.Errorf("node %s is not a master of the cluster", masterID)
+		}
+	}
+	ip, port, err := resolveAddr(ctx, addr)
+	if err != nil {
+		return err
+	}
+	var member *ClusterNode
+	for i := range nodes {
+		if nodes[i].Addr == net.JoinHostPort(ip, port) {
+			member = &nodes[i]
+		}
+	}
+
+	switch {
+	case member != nil && member.MasterID == masterID:
+		return nil
+	case member == nil:
+		if view, err := p.nodes(ctx, addr); err != nil {
+			return err
+		} else if len(view) > 1 || len(view[0].Slots) > 0 {
+			return fmt.Errorf("node %s is not empty: it already knows other nodes or serves slots", addr)
+		}
+		if err := c.checkEmpty(ctx, p, addr); err != nil {
+			return err
+		}
+		if err := c.meet(ctx, p, addr, seed); err != nil {
+			return err
+		}
+	}
+
+	if masterID != "" {
+		// A node only replicates a master it knows, which takes a round of gossip.
+		err := c.poll(ctx, func() error {
+			view, err := p.nodes(ctx, addr)
+			if err != nil {
+				return err
+			}
+			if master := findNode(view, masterID); master == nil || master.HasFlag("handshake") {
+				return fmt.Errorf("node %s does not know master %s yet", addr, masterID)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := p.get(addr).ClusterReplicate(ctx, masterID).Err(); err != nil {
+			return fmt.Errorf("failed to make %s replicate %s: %w", addr, masterID, err)
+		}
+	}
+
+	return c.poll(ctx, func() error {
+		view, err := p.nodes(ctx, seed)
+		if err != nil {
+			return err
+		}
+		for _, n := range view {
+			if n.Addr == net.JoinHostPort(ip, port) && !n.HasFlag("handshake") {
+				return nil
+			}
+		}
+		return fmt.Errorf("node %s has not joined the cluster yet", addr)
+	})
+}
+
+func findNode(nodes []ClusterNode, id string) *ClusterNode {
+	for i := range nodes {
+		if nodes[i].ID == id {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
+
+func (c *clusterAdmin) DelNode(ctx context.Context, seed, nodeID string) error {
+	p := c.connect()
+	defer p.close()
+
+	nodes, err := p.nodes(ctx, seed)
+	if err != nil {
+		return err
+	}
+	target := findNode(nodes, nodeID)
+	if target == nil {
+		return nil
+	}
+	if count := target.SlotCount(); count > 0 {
+		return fmt.Errorf("node %s still serves %d slots", nodeID, count)
+	}
+
+	others := make([]ClusterNode, 0, len(nodes)-1)
+	for _, n := range nodes {
+		if n.ID != nodeID {
+			others = append(others, n)
+		}
+	}
+	for i := range others {
+		if others[i].MasterID != nodeID {
+			continue
+		}
+		master := leastReplicatedMaster(others)
+		if master == nil {
+			return fmt.Errorf("no master left to replicate for the replicas of %s", nodeID)
+		}
+		if err := p.get(others[i].Addr).ClusterReplicate(ctx, master.ID).Err(); err != nil {
+			return fmt.Errorf("failed to move replica %s to master %s: %w", others[i].ID, master.ID, err)
+		}
+		others[i].MasterID = master.ID
+	}
+	for _, n := range others {
+		if n.HasFlag("fail") || n.HasFlag("noaddr") {
+			continue
+		}
+		if err := p.get(n.Addr).ClusterForget(ctx, nodeID).Err(); err != nil {
+			return fmt.Errorf("CLUSTER FORGET %s on %s: %w", nodeID, n.ID, err)
+		}
+	}
+	// The node is gone from the cluster already, a node that can't be reset is left as is.
+	_ = p.get(target.Addr).ClusterResetSoft(ctx).Err()
+	return nil
+}
+
+// leastReplicatedMaster returns the healthy master with the fewest replicas.
+func leastReplicatedMaster(nodes []ClusterNode) *ClusterNode {
+	replicas := map[string]int{}
+	for _, n := range nodes {
+		if n.MasterID != "" {
+			replicas[n.MasterID]++
+		}
+	}
+	var best *ClusterNode
+	for i := range nodes {
+		if !nodes[i].IsMaster() || !nodes[i].Healthy() {
+			continue
+		}
+		if best == nil || replicas[nodes[i].ID] < replicas[best.ID] {
+			best = &nodes[i]
+		}
+	}
+	return best
+}
+
+func (c *clusterAdmin) Failover(ctx context.Context, addr string, option FailoverOption) error {
+	p := c.connect()
+	defer p.close()
+
+	args := []interface{}{"CLUSTER", "FAILOVER"}
+	if option != FailoverDefault {
+		args = append(args, string(option))
+	}
+	if err := p.get(addr).Do(ctx, args...).Err(); err != nil {
+		return fmt.Errorf("CLUSTER FAILOVER on %s: %w", addr, err)
+	}
+	return nil
+}
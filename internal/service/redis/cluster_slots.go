@@ -0,0 +1,522 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+
+	rediscli "github.com/redis/go-redis/v9"
+)
+
+// Reshard moves the first count slots of the master fromID to the master toID one slot at a time, along
+// with their keys. Like Rebalance and Assign it refuses to touch a cluster that fails Check.
+func (c *clusterAdmin) Reshard(ctx context.Context, seed, fromID, toID string, count int) error {
+	p := c.connect()
+	defer p.close()
+
+	nodes, err := c.check(ctx, p, seed)
+	if err != nil {
+		return fmt.Errorf("refusing to reshard an unhealthy cluster, fix it first: %w", err)
+	}
+	from, to := findNode(nodes, fromID), findNode(nodes, toID)
+	if from == nil || !from.IsMaster() {
+		return fmt.Errorf("node %s is not a master of the cluster", fromID)
+	}
+	if to == nil || !to.IsMaster() {
+		return fmt.Errorf("node %s is not a master of the cluster", toID)
+	}
+	slots := from.slotList()
+	if count > len(slots) {
+		return fmt.Errorf("node %s serves %d slots, fewer than the %d to move", fromID, len(slots), count)
+	}
+	for _, slot := range slots[:count] {
+		if err := c.moveSlot(ctx, p, nodes, from, to, slot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveSlot moves slot and its keys from the master source to the master target, announcing the
+// new owner to every master, as redis-cli does for each slot of a reshard.
+func (c *clusterAdmin) moveSlot(ctx context.Context, p *nodeClients, nodes []ClusterNode, source, target *ClusterNode, slot int) error {
+	if err := p.get(target.Addr).Do(ctx, "CLUSTER", "SETSLOT", slot, "IMPORTING", source.ID).Err(); err != nil {
+		return fmt.Errorf("failed to open slot %d for importing on %s: %w", slot, target.ID, err)
+	}
+	if err := p.get(source.Addr).Do(ctx, "CLUSTER", "SETSLOT", slot, "MIGRATING", target.ID).Err(); err != nil {
+		return fmt.Errorf("failed to open slot %d for migrating on %s: %w", slot, source.ID, err)
+	}
+	if err := c.migrateKeys(ctx, p, source, target, slot, false); err != nil {
+		return err
+	}
+	return c.assignSlot(ctx, p, nodes, target, slot)
+}
+
+// migrateKeys moves the keys of slot from source to target with MIGRATE, pipelining the keys in batches.
+// Keys that exist on both nodes fail the migration with BUSYKEY, unless replace is set.
+func (c *clusterAdmin) migrateKeys(ctx context.Context, p *nodeClients, source, target *ClusterNode, slot int, replace bool) error {
+	host, port, err := net.SplitHostPort(target.Addr)
+	if err != nil {
+		return err
+	}
+	client := p.get(source.Addr)
+	for {
+		keys, err := client.ClusterGetKeysInSlot(ctx, slot, c.pipeline).Result()
+		if err != nil {
+			return fmt.Errorf("failed to list the keys of slot %d on %s: %w", slot, source.ID, err)
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		args := []interface{}{"MIGRATE", host, port, "", 0, c.migrateTimeout.Milliseconds()}
+		if replace {
+			args = append(args, "REPLACE")
+		}
+		if c.password != "" {
+			args = append(args, "AUTH", c.password)
+		}
+		args = append(args, "KEYS")
+		for _, key := range keys {
+			args = append(args, key)
+		}
+		if err := client.Do(ctx, args...).Err(); err != nil {
+			return fmt.Errorf("failed to migrate %d keys of slot %d from %s to %s: %w", len(keys), slot, source.ID, target.ID, err)
+		}
+	}
+}
+
+// assignSlot makes owner the owner of slot on the owner first, then on every other master,
+// which closes the slot wherever it is open.
+func (c *clusterAdmin) assignSlot(ctx context.Context, p *nodeClients, nodes []ClusterNode, owner *ClusterNode, slot int) error {
+	if err := p.get(owner.Addr).Do(ctx, "CLUSTER", "SETSLOT", slot, "NODE", owner.ID).Err(); err != nil {
+		return fmt.Errorf("failed to assign slot %d to %s: %w", slot, owner.ID, err)
+	}
+	for _, n := range nodes {
+		if n.ID == owner.ID || !n.IsMaster() || n.HasFlag("fail") || n.HasFlag("noaddr") {
+			continue
+		}
+		if err := p.get(n.Addr).Do(ctx, "CLUSTER", "SETSLOT", slot, "NODE", owner.ID).Err(); err != nil {
+			return fmt.Errorf("failed to announce the owner of slot %d to %s: %w", slot, n.ID, err)
+		}
+	}
+	return nil
+}
+
+// Rebalance moves slots between the masters until each serves a share of the slots proportional to its
+// weight, following the moves planned by planRebalance. It refuses to touch a cluster that fails Check.
+func (c *clusterAdmin) Rebalance(ctx context.Context, seed string, opts RebalanceOptions) error {
+	p := c.connect()
+	defer p.close()
+
+	nodes, err := c.check(ctx, p, seed)
+	if err != nil {
+		return fmt.Errorf("refusing to rebalance an unhealthy cluster, fix it first: %w", err)
+	}
+	moves, err := planRebalance(nodes, opts)
+	if err != nil {
+		return err
+	}
+	slots := map[string][]int{}
+	for i := range nodes {
+		slots[nodes[i].ID] = nodes[i].slotList()
+	}
+	for _, move := range moves {
+		source, target := findNode(nodes, move.From), findNode(nodes, move.To)
+		for _, slot := range slots[move.From][:move.Count] {
+			if err := c.moveSlot(ctx, p, nodes, source, target, slot); err != nil {
+				return err
+			}
+		}
+		slots[move.To] = append(slots[move.To], slots[move.From][:move.Count]...)
+		slots[move.From] = slots[move.From][move.Count:]
+	}
+	return nil
+}
+
+// slotMove moves Count slots from the master From to the master To.
+type slotMove struct {
+	From  string
+	To    string
+	Count int
+}
+
+// planRebalance computes the moves redis-cli --cluster rebalance would do: the expected number of
+// slots of each master is proportional to its weight, nothing moves unless a master is off by more
+// than the threshold, and the masters with the most surplus give to the ones with the most deficit.
+func planRebalance(nodes []ClusterNode, opts RebalanceOptions) ([]slotMove, error) {
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = defaultRebalanceThreshold
+	}
+
+	type master struct {
+		id      string
+		slots   int
+		weight  float64
+		balance int
+	}
+	var masters []*master
+	totalWeight := 0.0
+	for i := range nodes {
+		n := &nodes[i]
+		if !n.IsMaster() || !n.Healthy() {
+			continue
+		}
+		if n.SlotCount() == 0 && !opts.UseEmptyMasters {
+			continue
+		}
+		weight := 1.0
+		if w, ok := opts.Weights[n.ID]; ok {
+			weight = w
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("negative weight %v for node %s", weight, n.ID)
+		}
+		masters = append(masters, &master{id: n.ID, slots: n.SlotCount(), weight: weight})
+		totalWeight += weight
+	}
+	if len(masters) < 2 {
+		return nil, nil
+	}
+	if totalWeight == 0 {
+		return nil, errors.New("all the masters have weight 0")
+	}
+
+	overThreshold := false
+	totalBalance := 0
+	for _, m := range masters {
+		expected := int(float64(TotalClusterSlots) / totalWeight * m.weight)
+		m.balance = m.slots - expected
+		totalBalance += m.balance
+		switch {
+		case m.slots > 0:
+			if diff := 100 - 100*float64(expected)/float64(m.slots); diff > threshold || -diff > threshold {
+				overThreshold = true
+			}
+		case expected > 1:
+			overThreshold = true
+		}
+	}
+	if !overThreshold {
+		return nil, nil
+	}
+
+	// The expected counts are rounded down, so the balances may sum up to more than zero:
+	// make the masters that receive slots take the difference.
+	for adjusted := true; totalBalance > 0 && adjusted; {
+		adjusted = false
+		for _, m := range masters {
+			if m.balance <= 0 && totalBalance > 0 {
+				m.balance--
+				totalBalance--
+				adjusted = true
+			}
+		}
+	}
+
+	sort.SliceStable(masters, func(i, j int) bool { return masters[i].balance < masters[j].balance })
+	var moves []slotMove
+	for dst, src := 0, len(masters)-1; dst < src; {
+		d, s := masters[dst], masters[src]
+		if d.balance >= 0 {
+			dst++
+			continue
+		}
+		if s.balance <= 0 {
+			src--
+			continue
+		}
+		count := min(-d.balance, s.balance)
+		moves = append(moves, slotMove{From: s.id, To: d.id, Count: count})
+		d.balance += count
+		s.balance -= count
+	}
+	return moves, nil
+}
+
+// Assign moves each slot listed in owners that another master serves to its owner, along with its keys,
+// in ascending slot order. It refuses to touch a cluster that fails Check.
+func (c *clusterAdmin) Assign(ctx context.Context, seed string, owners map[string][]SlotRange) error {
+	p := c.connect()
+	defer p.close()
+
+	nodes, err := c.check(ctx, p, seed)
+	if err != nil {
+		return fmt.Errorf("refusing to move slots in an unhealthy cluster, fix it first: %w", err)
+	}
+	moves, err := planAssignment(nodes, owners)
+	if err != nil {
+		return err
+	}
+	for _, move := range moves {
+		if err := c.moveSlot(ctx, p, nodes, findNode(nodes, move.From), findNode(nodes, move.To), move.Slot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// slotAssignment moves Slot from the master From to the master To.
+type slotAssignment struct {
+	Slot int
+	From string
+	To   string
+}
+
+// planAssignment lists the slots whose owner differs from the one in owners, in ascending order.
+func planAssignment(nodes []ClusterNode, owners map[string][]SlotRange) ([]slotAssignment, error) {
+	wanted := make(map[int]string)
+	for id, ranges := range owners {
+		n := findNode(nodes, id)
+		if n == nil || !n.IsMaster() || !n.Healthy() {
+			return nil, fmt.Errorf("node %s is not a healthy master of the cluster", id)
+		}
+		for _, r := range ranges {
+			if r.Start < 0 || r.End >= TotalClusterSlots || r.Start > r.End {
+				return nil, fmt.Errorf("invalid slot range %s", r)
+			}
+			for slot := r.Start; slot <= r.End; slot++ {
+				if other, ok := wanted[slot]; ok && other != id {
+					return nil, fmt.Errorf("slot %d is assigned to both %s and %s", slot, other, id)
+				}
+				wanted[slot] = id
+			}
+		}
+	}
+
+	var moves []slotAssignment
+	for i := range nodes {
+		n := &nodes[i]
+		if !n.IsMaster() {
+			continue
+		}
+		for _, slot := range n.slotList() {
+			if to, ok := wanted[slot]; ok && to != n.ID {
+				moves = append(moves, slotAssignment{Slot: slot, From: n.ID, To: to})
+			}
+		}
+	}
+	sort.Slice(moves, func(i, j int) bool { return moves[i].Slot < moves[j].Slot })
+	return moves, nil
+}
+
+// Fix closes the open slots, then assigns the slots no master serves, as redis-cli --cluster fix. A
+// cluster that passes Check is left as is.
+func (c *clusterAdmin) Fix(ctx context.Context, seed string) error {
+	p := c.connect()
+	defer p.close()
+
+	nodes, err := c.check(ctx, p, seed)
+	if err == nil {
+		return nil
+	}
+	if nodes == nil {
+		return err
+	}
+	for _, slot := range openSlots(nodes) {
+		if err := c.fixOpenSlot(ctx, p, nodes, slot); err != nil {
+			return err
+		}
+	}
+	if nodes, err = p.nodes(ctx, seed); err != nil {
+		return err
+	}
+	return c.fixUncoveredSlots(ctx, p, nodes)
+}
+
+// openSlots returns the slots open on any of nodes, in ascending order.
+func openSlots(nodes []ClusterNode) []int {
+	open := map[int]bool{}
+	for _, n := range nodes {
+		for slot := range n.Migrating {
+			open[slot] = true
+		}
+		for slot := range n.Importing {
+			open[slot] = true
+		}
+	}
+	slots := make([]int, 0, len(open))
+	for slot := range open {
+		slots = append(slots, slot)
+	}
+	sort.Ints(slots)
+	return slots
+}
+
+// fixOpenSlot closes slot. A migration between the owner and the single node importing the slot
+// from it is completed; in any other case the keys of the slot are moved back to its owner, which
+// is the master holding most of them when no master serves the slot.
+func (c *clusterAdmin) fixOpenSlot(ctx context.Context, p *nodeClients, nodes []ClusterNode, slot int) error {
+	var owner *ClusterNode
+	var migrating, importing []*ClusterNode
+	for i := range nodes {
+		n := &nodes[i]
+		for _, r := range n.Slots {
+			if n.IsMaster() && slot >= r.Start && slot <= r.End {
+				owner = n
+			}
+		}
+		if _, ok := n.Migrating[slot]; ok {
+			migrating = append(migrating, n)
+		}
+		if _, ok := n.Importing[slot]; ok {
+			importing = append(importing, n)
+		}
+	}
+
+	if owner != nil && len(migrating) == 1 && len(importing) == 1 && migrating[0] == owner && owner.Migrating[slot] == importing[0].ID {
+		if err := c.migrateKeys(ctx, p, owner, importing[0], slot, true); err != nil {
+			return err
+		}
+		return c.assignSlot(ctx, p, nodes, importing[0], slot)
+	}
+
+	counts, err := c.countKeys(ctx, p, nodes, []int{slot})
+	if err != nil {
+		return err
+	}
+	assign := owner == nil
+	if assign {
+		if owner = mostKeys(nodes, counts, slot, append(migrating, importing...)); owner == nil {
+			return fmt.Errorf("no healthy master to serve slot %d", slot)
+		}
+	}
+	for i := range nodes {
+		if n := &nodes[i]; n != owner && counts[n.ID][slot] > 0 {
+			if err := c.migrateKeys(ctx, p, n, owner, slot, true); err != nil {
+				return err
+			}
+		}
+	}
+	for _, n := range append(migrating, importing...) {
+		if err := p.get(n.Addr).Do(ctx, "CLUSTER", "SETSLOT", slot, "STABLE").Err(); err != nil {
+			return fmt.Errorf("failed to close slot %d on %s: %w", slot, n.ID, err)
+		}
+	}
+	if assign {
+		return c.claimSlots(ctx, p, owner, []int{slot})
+	}
+	return nil
+}
+
+// fixUncoveredSlots assigns each slot no master serves to the master holding most of its keys,
+// moving the keys held by the others there, or to the master with the fewest slots if none has keys.
+func (c *clusterAdmin) fixUncoveredSlots(ctx context.Context, p *nodeClients, nodes []ClusterNode) error {
+	covered := make([]bool, TotalClusterSlots)
+	slotCounts := map[string]int{}
+	for i := range nodes {
+		if !nodes[i].IsMaster() {
+			continue
+		}
+		slotCounts[nodes[i].ID] = nodes[i].SlotCount()
+		for _, slot := range nodes[i].slotList() {
+			covered[slot] = true
+		}
+	}
+	var uncovered []int
+	for slot, ok := range covered {
+		if !ok {
+			uncovered = append(uncovered, slot)
+		}
+	}
+	if len(uncovered) == 0 {
+		return nil
+	}
+
+	counts, err := c.countKeys(ctx, p, nodes, uncovered)
+	if err != nil {
+		return err
+	}
+	claims := map[*ClusterNode][]int{}
+	for _, slot := range uncovered {
+		owner := mostKeys(nodes, counts, slot, nil)
+		if owner == nil {
+			return fmt.Errorf("no healthy master to serve slot %d", slot)
+		}
+		if counts[owner.ID][slot] == 0 {
+			for i := range nodes {
+				if nodes[i].IsMaster() && nodes[i].Healthy() && slotCounts[nodes[i].ID] < slotCounts[owner.ID] {
+					owner = &nodes[i]
+				}
+			}
+		}
+		claims[owner] = append(claims[owner], slot)
+		slotCounts[owner.ID]++
+	}
+	for owner, slots := range claims {
+		if err := c.claimSlots(ctx, p, owner, slots); err != nil {
+			return err
+		}
+		for _, slot := range slots {
+			for i := range nodes {
+				if n := &nodes[i]; n != owner && counts[n.ID][slot] > 0 {
+					if err := c.migrateKeys(ctx, p, n, owner, slot, true); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// countKeys returns the number of keys each master holds in each of slots.
+func (c *clusterAdmin) countKeys(ctx context.Context, p *nodeClients, nodes []ClusterNode, slots []int) (map[string]map[int]int64, error) {
+	counts := map[string]map[int]int64{}
+	for _, n := range nodes {
+		if !n.IsMaster() || !n.Healthy() {
+			continue
+		}
+		cmds, err := p.get(n.Addr).Pipelined(ctx, func(pipe rediscli.Pipeliner) error {
+			for _, slot := range slots {
+				pipe.ClusterCountKeysInSlot(ctx, slot)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count the keys of %s: %w", n.ID, err)
+		}
+		counts[n.ID] = map[int]int64{}
+		for i, cmd := range cmds {
+			counts[n.ID][slots[i]] = cmd.(*rediscli.IntCmd).Val()
+		}
+	}
+	return counts, nil
+}
+
+// mostKeys returns the master holding most keys of slot, preferring the candidates, then the first
+// healthy master, when no master holds any.
+func mostKeys(nodes []ClusterNode, counts map[string]map[int]int64, slot int, candidates []*ClusterNode) *ClusterNode {
+	var best *ClusterNode
+	for _, n := range candidates {
+		if n.IsMaster() && n.Healthy() {
+			best = n
+			break
+		}
+	}
+	for i := range nodes {
+		n := &nodes[i]
+		if !n.IsMaster() || !n.Healthy() {
+			continue
+		}
+		if best == nil || counts[n.ID][slot] > counts[best.ID][slot] {
+			best = n
+		}
+	}
+	return best
+}
+
+// claimSlots makes owner serve slots no master serves, bumping its epoch so the claim wins over
+// stale configurations.
+func (c *clusterAdmin) claimSlots(ctx context.Context, p *nodeClients, owner *ClusterNode, slots []int) error {
+	client := p.get(owner.Addr)
+	if err := client.ClusterAddSlots(ctx, slots...).Err(); err != nil {
+		return fmt.Errorf("failed to assign %d slots to %s: %w", len(slots), owner.ID, err)
+	}
+	if err := client.Do(ctx, "CLUSTER", "BUMPEPOCH").Err(); err != nil {
+		return fmt.Errorf("failed to bump the epoch of %s: %w", owner.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,879 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCluster emulates the cluster commands on top of miniredis servers. The nodes that met share
+// a single view of the cluster, as they eventually do once gossip has propagated a change.
+type fakeCluster struct {
+	mu     sync.Mutex
+	nodes  []*fakeNode
+	owners [TotalClusterSlots]*fakeNode
+	// migrated records the number of keys of each MIGRATE.
+	migrated []int
+	groups   int
+}
+
+type fakeNode struct {
+	id    string
+	srv   *miniredis.Miniredis
+	group int
+	// master is the master of a replica, nil for masters.
+	master    *fakeNode
+	epoch     int
+	migrating map[int]*fakeNode
+	importing map[int]*fakeNode
+}
+
+func newFakeCluster(t *testing.T, size int) *fakeCluster {
+	fc := &fakeCluster{}
+	for i := 0; i < size; i++ {
+		fc.addNode(t)
+	}
+	return fc
+}
+
+func (fc *fakeCluster) addNode(t *testing.T) *fakeNode {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.groups++
+	n := &fakeNode{
+		id:        fmt.Sprintf("%040d", len(fc.nodes)+1),
+		srv:       miniredis.RunT(t),
+		group:     fc.groups,
+		migrating: map[int]*fakeNode{},
+		importing: map[int]*fakeNode{},
+	}
+	n.srv.Server().SetPreHook(func(c *server.Peer, cmd string, args ...string) bool {
+		fc.mu.Lock()
+		defer fc.mu.Unlock()
+		switch cmd {
+		case "CLUSTER":
+			return fc.cluster(c, n, args)
+		case "MIGRATE":
+			fc.migrate(c, n, args)
+			return true
+		}
+		return false
+	})
+	fc.nodes = append(fc.nodes, n)
+	return n
+}
+
+func (fc *fakeCluster) addrs() []string {
+	addrs := make([]string, 0, len(fc.nodes))
+	for _, n := range fc.nodes {
+		addrs = append(addrs, n.srv.Addr())
+	}
+	return addrs
+}
+
+func (fc *fakeCluster) byAddr(addr string) *fakeNode {
+	for _, n := range fc.nodes {
+		if n.srv.Addr() == addr {
+			return n
+		}
+	}
+	return nil
+}
+
+func (fc *fakeCluster) byID(id string) *fakeNode {
+	for _, n := range fc.nodes {
+		if n.id == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// slotCount returns the number of slots owned by n.
+func (fc *fakeCluster) slotCount(n *fakeNode) int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	count := 0
+	for _, owner := range fc.owners {
+		if owner == n {
+			count++
+		}
+	}
+	return count
+}
+
+func (fc *fakeCluster) cluster(c *server.Peer, n *fakeNode, args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch strings.ToUpper(args[0]) {
+	case "NODES":
+		c.WriteBulk(fc.render(n))
+	case "INFO":
+		known, assigned := 0, 0
+		for _, m := range fc.nodes {
+			if m.group == n.group {
+				known++
+			}
+		}
+		for _, owner := range fc.owners {
+			if owner != nil && owner.group == n.group {
+				assigned++
+			}
+		}
+		state := "fail"
+		if assigned == TotalClusterSlots {
+			state = "ok"
+		}
+		c.WriteBulk(fmt.Sprintf("cluster_enabled:1\r\ncluster_state:%s\r\ncluster_slots_assigned:%d\r\ncluster_known_nodes:%d\r\n", state, assigned, known))
+	case "MYID":
+		c.WriteBulk(n.id)
+	case "ADDSLOTS":
+		for _, arg := range args[1:] {
+			slot, _ := strconv.Atoi(arg)
+			if fc.owners[slot] != nil {
+				c.WriteError(fmt.Sprintf("ERR Slot %d is already busy", slot))
+				return true
+			}
+		}
+		for _, arg := range args[1:] {
+			slot, _ := strconv.Atoi(arg)
+			fc.owners[slot] = n
+		}
+		c.WriteOK()
+	case "SET-CONFIG-EPOCH":
+		for _, m := range fc.nodes {
+			if m != n && m.group == n.group {
+				c.WriteError("ERR The user can assign a config epoch only when the node does not know any other node.")
+				return true
+			}
+		}
+		n.epoch, _ = strconv.Atoi(args[1])
+		c.WriteOK()
+	case "MEET":
+		other := fc.byAddr(args[1] + ":" + args[2])
+		if other == nil {
+			c.WriteError("ERR Invalid node address specified")
+			return true
+		}
+		from := other.group
+		for _, m := range fc.nodes {
+			if m.group == from {
+				m.group = n.group
+			}
+		}
+		c.WriteOK()
+	case "REPLICATE":
+		master := fc.byID(args[1])
+		if master == nil || master.group != n.group {
+			c.WriteError("ERR Unknown node " + args[1])
+			return true
+		}
+		for _, owner := range fc.owners {
+			if owner == n {
+				c.WriteError("ERR To set a master the node must be empty and without assigned slots.")
+				return true
+			}
+		}
+		n.master = master
+		c.WriteOK()
+	case "SETSLOT":
+		slot, _ := strconv.Atoi(args[1])
+		switch strings.ToUpper(args[2]) {
+		case "IMPORTING":
+			n.importing[slot] = fc.byID(args[3])
+		case "MIGRATING":
+			n.migrating[slot] = fc.byID(args[3])
+		case "STABLE":
+			delete(n.importing, slot)
+			delete(n.migrating, slot)
+		case "NODE":
+			target := fc.byID(args[3])
+			if fc.owners[slot] == n && target != n && len(fc.keysInSlot(n, slot)) > 0 {
+				c.WriteError(fmt.Sprintf("ERR Can't assign hashslot %d to a different node while I still hold keys for this hash slot.", slot))
+				return true
+			}
+			fc.owners[slot] = target
+			delete(n.importing, slot)
+			delete(n.migrating, slot)
+		}
+		c.WriteOK()
+	case "GETKEYSINSLOT":
+		slot, _ := strconv.Atoi(args[1])
+		count, _ := strconv.Atoi(args[2])
+		keys := fc.keysInSlot(n, slot)
+		if len(keys) > count {
+			keys = keys[:count]
+		}
+		c.WriteLen(len(keys))
+		for _, key := range keys {
+			c.WriteBulk(key)
+		}
+	case "COUNTKEYSINSLOT":
+		slot, _ := strconv.Atoi(args[1])
+		c.WriteInt(len(fc.keysInSlot(n, slot)))
+	case "FORGET":
+		fc.groups++
+		fc.byID(args[1]).group = fc.groups
+		c.WriteOK()
+	case "RESET":
+		fc.groups++
+		n.group, n.master = fc.groups, nil
+		for slot, owner := range fc.owners {
+			if owner == n {
+				fc.owners[slot] = nil
+			}
+		}
+		c.WriteOK()
+	case "BUMPEPOCH":
+		n.epoch++
+		c.WriteInline("BUMPED " + strconv.Itoa(n.epoch))
+	case "FAILOVER":
+		if n.master == nil {
+			c.WriteError("ERR You should send CLUSTER FAILOVER to a replica")
+			return true
+		}
+		old := n.master
+		for slot, owner := range fc.owners {
+			if owner == old {
+				fc.owners[slot] = n
+			}
+		}
+		n.master, old.master = nil, n
+		c.WriteOK()
+	default:
+		return false
+	}
+	return true
+}
+
+// render returns the CLUSTER NODES reply of n.
+func (fc *fakeCluster) render(n *fakeNode) string {
+	var b strings.Builder
+	for _, m := range fc.nodes {
+		if m.group != n.group {
+			continue
+		}
+		flags, master := "master", "-"
+		if m.master != nil {
+			flags, master = "slave", m.master.id
+		}
+		if m == n {
+			flags = "myself," + flags
+		}
+		fmt.Fprintf(&b, "%s %s@1%s %s %s 0 0 %d connected", m.id, m.srv.Addr(), m.srv.Port(), flags, master, m.epoch)
+		for _, r := range fc.ranges(m) {
+			b.WriteString(" " + r.String())
+		}
+		if m == n {
+			for slot, target := range n.migrating {
+				fmt.Fprintf(&b, " [%d->-%s]", slot, target.id)
+			}
+			for slot, source := range n.importing {
+				fmt.Fprintf(&b, " [%d-<-%s]", slot, source.id)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (fc *fakeCluster) ranges(n *fakeNode) []SlotRange {
+	var ranges []SlotRange
+	for slot, owner := range fc.owners {
+		if owner != n {
+			continue
+		}
+		if len(ranges) > 0 && ranges[len(ranges)-1].End == slot-1 {
+			ranges[len(ranges)-1].End = slot
+		} else {
+			ranges = append(ranges, SlotRange{Start: slot, End: slot})
+		}
+	}
+	return ranges
+}
+
+func (fc *fakeCluster) keysInSlot(n *fakeNode, slot int) []string {
+	var keys []string
+	for _, key := range n.srv.Keys() {
+		if keySlot(key) == slot {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// migrate handles MIGRATE host port "" db timeout [REPLACE] [AUTH password] KEYS key...
+func (fc *fakeCluster) migrate(c *server.Peer, n *fakeNode, args []string) {
+	target := fc.byAddr(args[0] + ":" + args[1])
+	if target == nil {
+		c.WriteError("IOERR error or timeout connecting to the client")
+		return
+	}
+	replace := false
+	var keys []string
+	for i := 5; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "REPLACE":
+			replace = true
+		case "AUTH":
+			i++
+		case "KEYS":
+			keys = args[i+1:]
+			i = len(args)
+		}
+	}
+	for _, key := range keys {
+		if target.srv.Exists(key) && !replace {
+			c.WriteError("BUSYKEY Target key name already exists.")
+			return
+		}
+	}
+	for _, key := range keys {
+		value, _ := n.srv.Get(key)
+		_ = target.srv.Set(key, value)
+		n.srv.Del(key)
+	}
+	fc.migrated = append(fc.migrated, len(keys))
+	c.WriteOK()
+}
+
+// keySlot returns the hash slot of key, as CLUSTER KEYSLOT.
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return int(crc) % TotalClusterSlots
+}
+
+func newTestClusterAdmin() *clusterAdmin {
+	admin := NewClusterAdmin("", nil, nil).(*clusterAdmin)
+	admin.pipeline = 2
+	admin.joinTimeout = 2 * time.Second
+	admin.pollInterval = 10 * time.Millisecond
+	return admin
+}
+
+// keyCounts returns the number of keys held by each node.
+func keyCounts(fc *fakeCluster) []int {
+	counts := make([]int, 0, len(fc.nodes))
+	for _, n := range fc.nodes {
+		counts = append(counts, len(n.srv.Keys()))
+	}
+	return counts
+}
+
+func TestParseClusterNodes(t *testing.T) {
+	reply := "07c37dfeb235213a872192d90877d0cd55635b91 10.0.0.2:6379@16379,redis-follower-0 slave e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 0 1426238317239 4 connected\n" +
+		"e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 10.0.0.1:6379@16379 myself,master - 0 0 1 connected 0-5460 5462 [5461->-67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1]\n" +
+		"67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 10.0.0.3:6379@16379 master,fail - 1426238316232 1426238315232 2 disconnected 5463-16383\n"
+
+	nodes, err := ParseClusterNodes(reply)
+	require.NoError(t, err)
+	require.Len(t, nodes, 3)
+
+	replica := nodes[0]
+	assert.Equal(t, "10.0.0.2:6379", replica.Addr)
+	assert.Equal(t, "redis-follower-0", replica.Hostname)
+	assert.Equal(t, "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca", replica.MasterID)
+	assert.False(t, replica.IsMaster())
+	assert.True(t, replica.Healthy())
+
+	me := nodes[1]
+	assert.True(t, me.HasFlag("myself"))
+	assert.Equal(t, []SlotRange{{Start: 0, End: 5460}, {Start: 5462, End: 5462}}, me.Slots)
+	assert.Equal(t, 5462, me.SlotCount())
+	assert.Equal(t, map[int]string{5461: "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1"}, me.Migrating)
+	assert.EqualValues(t, 1, me.ConfigEpoch)
+
+	assert.False(t, nodes[2].Healthy())
+
+	_, err = ParseClusterNodes("e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 10.0.0.1:6379@16379 myself,master -")
+	assert.Error(t, err)
+}
+
+func TestSplitSlots(t *testing.T) {
+	assert.Equal(t, []SlotRange{{Start: 0, End: 16383}}, splitSlots(1))
+	assert.Equal(t, []SlotRange{{Start: 0, End: 5460}, {Start: 5461, End: 10922}, {Start: 10923, End: 16383}}, splitSlots(3))
+	for n := 1; n <= 10; n++ {
+		ranges := splitSlots(n)
+		require.Len(t, ranges, n)
+		next := 0
+		for _, r := range ranges {
+			assert.Equal(t, next, r.Start, "ranges are contiguous")
+			assert.InDelta(t, TotalClusterSlots/n, r.Len(), 1)
+			next = r.End + 1
+		}
+		assert.Equal(t, TotalClusterSlots, next)
+	}
+}
+
+func TestFormatSlotRanges(t *testing.T) {
+	assert.Equal(t, "", FormatSlotRanges(nil))
+	assert.Equal(t, "0-199,300", FormatSlotRanges([]SlotRange{{Start: 100, End: 199}, {Start: 300, End: 300}, {Start: 0, End: 99}}))
+	assert.Equal(t, "0-150", FormatSlotRanges([]SlotRange{{Start: 0, End: 100}, {Start: 50, End: 150}}))
+
+	ranges, err := ParseSlotRanges("0-199,300")
+	require.NoError(t, err)
+	assert.Equal(t, []SlotRange{{Start: 0, End: 199}, {Start: 300, End: 300}}, ranges)
+	ranges, err = ParseSlotRanges("")
+	require.NoError(t, err)
+	assert.Empty(t, ranges)
+	for _, invalid := range []string{"0-x", "10-5", "16384", "[5461->-abc]"} {
+		_, err = ParseSlotRanges(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestPlanRebalance(t *testing.T) {
+	master := func(id string, ranges ...SlotRange) ClusterNode {
+		return ClusterNode{ID: id, Flags: []string{"master"}, Connected: true, Slots: ranges}
+	}
+	thirds := []ClusterNode{
+		master("a", SlotRange{Start: 0, End: 5460}),
+		master("b", SlotRange{Start: 5461, End: 10922}),
+		master("c", SlotRange{Start: 10923, End: 16383}),
+	}
+	moved := func(moves []slotMove) map[string]int {
+		balance := map[string]int{}
+		for _, m := range moves {
+			balance[m.From] -= m.Count
+			balance[m.To] += m.Count
+		}
+		return balance
+	}
+
+	t.Run("balanced", func(t *testing.T) {
+		moves, err := planRebalance(thirds, RebalanceOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, moves)
+	})
+
+	t.Run("empty master left out", func(t *testing.T) {
+		moves, err := planRebalance(append(thirds, master("d")), RebalanceOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, moves)
+	})
+
+	t.Run("empty master filled", func(t *testing.T) {
+		moves, err := planRebalance(append(thirds, master("d")), RebalanceOptions{UseEmptyMasters: true})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"a": -1365, "b": -1366, "c": -1365, "d": 4096}, moved(moves))
+	})
+
+	t.Run("weights", func(t *testing.T) {
+		moves, err := planRebalance(thirds, RebalanceOptions{Weights: map[string]float64{"a": 2}})
+		require.NoError(t, err)
+		balance := moved(moves)
+		assert.Equal(t, 2731, balance["a"])
+		assert.InDelta(t, -1365, balance["b"], 1)
+		assert.Equal(t, -2731, balance["b"]+balance["c"])
+	})
+
+	t.Run("weight 0 drains", func(t *testing.T) {
+		moves, err := planRebalance(thirds, RebalanceOptions{Weights: map[string]float64{"c": 0}})
+		require.NoError(t, err)
+		balance := moved(moves)
+		assert.Equal(t, -5461, balance["c"])
+		assert.InDelta(t, 2730, balance["a"], 1)
+		assert.InDelta(t, 2730, balance["b"], 1)
+	})
+
+	t.Run("within threshold", func(t *testing.T) {
+		skewed := []ClusterNode{
+			master("a", SlotRange{Start: 0, End: 8291}),
+			master("b", SlotRange{Start: 8292, End: 16383}),
+		}
+		moves, err := planRebalance(skewed, RebalanceOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, moves, "100 slots off 8192 is under 2%%")
+		moves, err = planRebalance(skewed, RebalanceOptions{Threshold: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []slotMove{{From: "a", To: "b", Count: 100}}, moves)
+	})
+
+	t.Run("all weights 0", func(t *testing.T) {
+		_, err := planRebalance(thirds, RebalanceOptions{Weights: map[string]float64{"a": 0, "b": 0, "c": 0}})
+		assert.Error(t, err)
+	})
+}
+
+func TestPlanAssignment(t *testing.T) {
+	master := func(id string, ranges ...SlotRange) ClusterNode {
+		return ClusterNode{ID: id, Flags: []string{"master"}, Connected: true, Slots: ranges}
+	}
+	nodes := []ClusterNode{
+		master("a", SlotRange{Start: 0, End: 8191}),
+		master("b", SlotRange{Start: 8192, End: 16383}),
+		{ID: "r", Flags: []string{"slave"}, MasterID: "a", Connected: true},
+	}
+
+	t.Run("in place", func(t *testing.T) {
+		moves, err := planAssignment(nodes, map[string][]SlotRange{
+			"a": {{Start: 0, End: 8191}},
+			"b": {{Start: 8192, End: 16383}},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, moves)
+	})
+
+	t.Run("only the slots changing owner move", func(t *testing.T) {
+		moves, err := planAssignment(nodes, map[string][]SlotRange{
+			"a": {{Start: 0, End: 99}, {Start: 101, End: 8193}},
+			"b": {{Start: 8194, End: 16383}, {Start: 100, End: 100}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []slotAssignment{
+			{Slot: 100, From: "a", To: "b"},
+			{Slot: 8192, From: "b", To: "a"},
+			{Slot: 8193, From: "b", To: "a"},
+		}, moves)
+	})
+
+	t.Run("partial map", func(t *testing.T) {
+		moves, err := planAssignment(nodes, map[string][]SlotRange{"b": {{Start: 0, End: 1}}})
+		require.NoError(t, err)
+		assert.Len(t, moves, 2)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := planAssignment(nodes, map[string][]SlotRange{"r": {{Start: 0, End: 1}}})
+		assert.ErrorContains(t, err, "not a healthy master")
+		_, err = planAssignment(nodes, map[string][]SlotRange{"a": {{Start: 0, End: 10}}, "b": {{Start: 10, End: 20}}})
+		assert.ErrorContains(t, err, "slot 10 is assigned to both")
+		_, err = planAssignment(nodes, map[string][]SlotRange{"a": {{Start: 0, End: TotalClusterSlots}}})
+		assert.ErrorContains(t, err, "invalid slot range")
+	})
+}
+
+func TestClusterAdminCreate(t *testing.T) {
+	ctx := context.Background()
+	fc := newFakeCluster(t, 3)
+	admin := newTestClusterAdmin()
+
+	require.NoError(t, admin.Create(ctx, fc.addrs()))
+	require.NoError(t, admin.Check(ctx, fc.addrs()[1]))
+
+	nodes, err := admin.Nodes(ctx, fc.addrs()[0])
+	require.NoError(t, err)
+	require.Len(t, nodes, 3)
+	for i, want := range splitSlots(3) {
+		n := findNode(nodes, fc.nodes[i].id)
+		require.NotNil(t, n)
+		assert.Equal(t, []SlotRange{want}, n.Slots)
+		assert.EqualValues(t, i+1, n.ConfigEpoch)
+	}
+
+	assert.NoError(t, admin.Create(ctx, fc.addrs()), "an existing cluster is left as is")
+
+	t.Run("nodes with keys", func(t *testing.T) {
+		fc := newFakeCluster(t, 2)
+		fc.nodes[1].srv.Set("key", "value")
+		assert.ErrorContains(t, admin.Create(ctx, fc.addrs()), "holds 1 keys")
+	})
+}
+
+func TestClusterAdminCreateWithSlots(t *testing.T) {
+	ctx := context.Background()
+	fc := newFakeCluster(t, 3)
+	admin := newTestClusterAdmin()
+
+	// The first node loaded a key from a backup and claimed its slot on startup.
+	key, slot := "{tenant42}session", keySlot("{tenant42}session")
+	require.NoError(t, fc.nodes[0].srv.Set(key, "v"))
+	fc.mu.Lock()
+	fc.owners[slot] = fc.nodes[0]
+	fc.mu.Unlock()
+	slots := [][]SlotRange{
+		{{Start: 0, End: 99}, {Start: slot, End: slot}},
+		{{Start: 100, End: slot - 1}},
+		{{Start: slot + 1, End: TotalClusterSlots - 1}},
+	}
+
+	require.NoError(t, admin.CreateWithSlots(ctx, fc.addrs(), slots))
+	require.NoError(t, admin.Check(ctx, fc.addrs()[2]))
+	nodes, err := admin.Nodes(ctx, fc.addrs()[0])
+	require.NoError(t, err)
+	for i, want := range slots {
+		n := findNode(nodes, fc.nodes[i].id)
+		require.NotNil(t, n)
+		assert.Equal(t, FormatSlotRanges(want), FormatSlotRanges(n.Slots))
+		assert.EqualValues(t, i+1, n.ConfigEpoch)
+	}
+	assert.Equal(t, []string{key}, fc.nodes[0].srv.Keys(), "the keys are left in place")
+
+	assert.NoError(t, admin.CreateWithSlots(ctx, fc.addrs(), slots), "an existing cluster is left as is")
+
+	t.Run("invalid", func(t *testing.T) {
+		fc := newFakeCluster(t, 2)
+		assert.ErrorContains(t, admin.CreateWithSlots(ctx, fc.addrs(), slots), "slots of 3 nodes for 2 nodes")
+		assert.ErrorContains(t, admin.CreateWithSlots(ctx, fc.addrs(), [][]SlotRange{{{Start: 0, End: 10}}, {{Start: 10, End: 20}}}), "slot 10 is assigned to both")
+		fc.mu.Lock()
+		fc.owners[30] = fc.nodes[1]
+		fc.mu.Unlock()
+		assert.ErrorContains(t, admin.CreateWithSlots(ctx, fc.addrs(), [][]SlotRange{{{Start: 0, End: 10}}, {{Start: 11, End: 20}}}), "serves slots")
+	})
+}
+
+func TestClusterAdminScaling(t *testing.T) {
+	ctx := context.Background()
+	fc := newFakeCluster(t, 2)
+	admin := newTestClusterAdmin()
+	require.NoError(t, admin.Create(ctx, fc.addrs()))
+	seed := fc.addrs()[0]
+
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		owner := fc.nodes[0]
+		if keySlot(key) > splitSlots(2)[0].End {
+			owner = fc.nodes[1]
+		}
+		require.NoError(t, owner.srv.Set(key, strconv.Itoa(i)))
+	}
+
+	// The nodes outlive the subtests, so they are all started upfront.
+	leader, replica, spare := fc.addNode(t), fc.addNode(t), fc.addNode(t)
+	require.NoError(t, admin.AddNode(ctx, seed, leader.srv.Addr(), ""))
+	assert.NoError(t, admin.AddNode(ctx, seed, leader.srv.Addr(), ""), "adding a member again is a no-op")
+
+	t.Run("reshard", func(t *testing.T) {
+		require.NoError(t, admin.Reshard(ctx, seed, fc.nodes[0].id, leader.id, 1000))
+		assert.Equal(t, 1000, fc.slotCount(leader))
+		assert.Equal(t, 8192-1000, fc.slotCount(fc.nodes[0]))
+		require.NoError(t, admin.Check(ctx, seed))
+		assert.NotZero(t, len(leader.srv.Keys()), "the keys follow their slots")
+		for _, batch := range fc.migrated {
+			assert.LessOrEqual(t, batch, admin.pipeline)
+		}
+		assert.Error(t, admin.Reshard(ctx, seed, fc.nodes[0].id, leader.id, TotalClusterSlots))
+	})
+
+	t.Run("rebalance", func(t *testing.T) {
+		require.NoError(t, admin.Rebalance(ctx, seed, RebalanceOptions{}))
+		for _, n := range []*fakeNode{fc.nodes[0], fc.nodes[1], leader} {
+			assert.InDelta(t, TotalClusterSlots/3, fc.slotCount(n), 1)
+		}
+		require.NoError(t, admin.Check(ctx, seed))
+	})
+
+	t.Run("replica", func(t *testing.T) {
+		require.NoError(t, admin.AddNode(ctx, seed, replica.srv.Addr(), leader.id))
+		nodes, err := admin.Nodes(ctx, seed)
+		require.NoError(t, err)
+		assert.Equal(t, leader.id, findNode(nodes, replica.id).MasterID)
+		assert.Error(t, admin.AddNode(ctx, seed, spare.srv.Addr(), replica.id), "replicas can't be replicated")
+
+		require.NoError(t, admin.AddNode(ctx, seed, replica.srv.Addr(), fc.nodes[1].id), "a replica is moved to another master")
+		nodes, err = admin.Nodes(ctx, seed)
+		require.NoError(t, err)
+		assert.Equal(t, fc.nodes[1].id, findNode(nodes, replica.id).MasterID)
+		require.NoError(t, admin.AddNode(ctx, seed, replica.srv.Addr(), leader.id))
+	})
+
+	t.Run("drain and delete", func(t *testing.T) {
+		assert.ErrorContains(t, admin.DelNode(ctx, seed, leader.id), "still serves")
+		require.NoError(t, admin.Rebalance(ctx, seed, RebalanceOptions{Weights: map[string]float64{leader.id: 0}}))
+		assert.Zero(t, fc.slotCount(leader))
+		assert.Empty(t, leader.srv.Keys())
+
+		require.NoError(t, admin.DelNode(ctx, seed, leader.id))
+		nodes, err := admin.Nodes(ctx, seed)
+		require.NoError(t, err)
+		assert.Nil(t, findNode(nodes, leader.id))
+		assert.Contains(t, []string{fc.nodes[0].id, fc.nodes[1].id}, findNode(nodes, replica.id).MasterID, "the replicas of a deleted master are moved")
+		assert.NoError(t, admin.DelNode(ctx, seed, leader.id), "deleting a removed node is a no-op")
+		require.NoError(t, admin.Check(ctx, seed))
+	})
+
+	total := 0
+	for _, count := range keyCounts(fc) {
+		total += count
+	}
+	assert.Equal(t, 200, total, "no key is lost")
+}
+
+func TestClusterAdminAssign(t *testing.T) {
+	ctx := context.Background()
+	fc := newFakeCluster(t, 3)
+	admin := newTestClusterAdmin()
+	require.NoError(t, admin.Create(ctx, fc.addrs()))
+	seed := fc.addrs()[0]
+
+	hot := "{tenant42}session"
+	slot := keySlot(hot)
+	fc.mu.Lock()
+	owner := fc.owners[slot]
+	fc.mu.Unlock()
+	require.NoError(t, owner.srv.Set(hot, "v"))
+	dedicated := fc.nodes[0]
+	if owner == dedicated {
+		dedicated = fc.nodes[1]
+	}
+
+	// Pin the hot slot to its own shard and hand the other slots of that shard over to its owner.
+	ranges := map[string][]SlotRange{dedicated.id: {{Start: slot, End: slot}}}
+	fc.mu.Lock()
+	for s, n := range fc.owners {
+		if n == dedicated && s != slot {
+			ranges[owner.id] = append(ranges[owner.id], SlotRange{Start: s, End: s})
+		}
+	}
+	fc.mu.Unlock()
+	require.NoError(t, admin.Assign(ctx, seed, ranges))
+	require.NoError(t, admin.Check(ctx, seed))
+	assert.Equal(t, 1, fc.slotCount(dedicated))
+	assert.Equal(t, []string{hot}, dedicated.srv.Keys(), "the keys follow their slot")
+
+	migrated := len(fc.migrated)
+	require.NoError(t, admin.Assign(ctx, seed, ranges))
+	assert.Len(t, fc.migrated, migrated, "a converged cluster is left as is")
+}
+
+func TestClusterAdminReshardUnhealthy(t *testing.T) {
+	ctx := context.Background()
+	fc := newFakeCluster(t, 2)
+	admin := newTestClusterAdmin()
+	require.NoError(t, admin.Create(ctx, fc.addrs()))
+	source, target := fc.nodes[0], fc.nodes[1]
+	slot := keySlot("{user1}a")
+	require.LessOrEqual(t, slot, splitSlots(2)[0].End)
+	require.NoError(t, source.srv.Set("{user1}a", "v"))
+	fc.mu.Lock()
+	target.importing[slot] = source
+	fc.mu.Unlock()
+
+	err := admin.Reshard(ctx, fc.addrs()[0], source.id, target.id, 100)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unhealthy cluster")
+	assert.Empty(t, fc.migrated)
+	assert.Equal(t, splitSlots(2)[0].End+1, fc.slotCount(source))
+}
+
+func TestClusterAdminFix(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("interrupted migration", func(t *testing.T) {
+		fc := newFakeCluster(t, 2)
+		admin := newTestClusterAdmin()
+		require.NoError(t, admin.Create(ctx, fc.addrs()))
+		source, target := fc.nodes[0], fc.nodes[1]
+		keys := []string{"{user1}a", "{user1}b", "{user1}c"}
+		slot := keySlot(keys[0])
+		require.LessOrEqual(t, slot, splitSlots(2)[0].End)
+		for _, key := range keys {
+			require.NoError(t, source.srv.Set(key, "v"))
+		}
+		// A reshard stopped after moving one of the keys.
+		fc.mu.Lock()
+		target.importing[slot], source.migrating[slot] = source, target
+		fc.mu.Unlock()
+		require.NoError(t, target.srv.Set(keys[0], "v"))
+		source.srv.Del(keys[0])
+
+		require.Error(t, admin.Check(ctx, fc.addrs()[0]))
+		require.NoError(t, admin.Fix(ctx, fc.addrs()[0]))
+		require.NoError(t, admin.Check(ctx, fc.addrs()[0]))
+		assert.Empty(t, source.srv.Keys())
+		assert.ElementsMatch(t, keys, target.srv.Keys())
+		fc.mu.Lock()
+		assert.Equal(t, target, fc.owners[slot])
+		fc.mu.Unlock()
+	})
+
+	t.Run("stale importing slot", func(t *testing.T) {
+		fc := newFakeCluster(t, 2)
+		admin := newTestClusterAdmin()
+		require.NoError(t, admin.Create(ctx, fc.addrs()))
+		owner, other := fc.nodes[0], fc.nodes[1]
+		require.NoError(t, other.srv.Set("{user1}a", "v"))
+		slot := keySlot("{user1}a")
+		fc.mu.Lock()
+		other.importing[slot] = owner
+		fc.mu.Unlock()
+
+		require.NoError(t, admin.Fix(ctx, fc.addrs()[1]))
+		require.NoError(t, admin.Check(ctx, fc.addrs()[1]))
+		assert.Equal(t, []string{"{user1}a"}, owner.srv.Keys(), "the keys are moved to the owner")
+	})
+
+	t.Run("uncovered slots", func(t *testing.T) {
+		fc := newFakeCluster(t, 2)
+		admin := newTestClusterAdmin()
+		require.NoError(t, admin.Create(ctx, fc.addrs()))
+		slot := keySlot("{user1}a")
+		fc.mu.Lock()
+		for s := 0; s <= 100; s++ {
+			fc.owners[s] = nil
+		}
+		fc.owners[slot] = nil
+		fc.mu.Unlock()
+		require.NoError(t, fc.nodes[1].srv.Set("{user1}a", "v"))
+
+		require.Error(t, admin.Check(ctx, fc.addrs()[0]))
+		require.NoError(t, admin.Fix(ctx, fc.addrs()[0]))
+		require.NoError(t, admin.Check(ctx, fc.addrs()[0]))
+		fc.mu.Lock()
+		assert.Equal(t, fc.nodes[1], fc.owners[slot], "a slot goes to the node holding its keys")
+		fc.mu.Unlock()
+	})
+
+	t.Run("healthy cluster", func(t *testing.T) {
+		fc := newFakeCluster(t, 3)
+		admin := newTestClusterAdmin()
+		require.NoError(t, admin.Create(ctx, fc.addrs()))
+		require.NoError(t, admin.Fix(ctx, fc.addrs()[0]))
+		assert.Empty(t, fc.migrated)
+	})
+}
+
+func TestClusterAdminFailover(t *testing.T) {
+	ctx := context.Background()
+	fc := newFakeCluster(t, 1)
+	admin := newTestClusterAdmin()
+	require.NoError(t, admin.Create(ctx, fc.addrs()))
+	replica := fc.addNode(t)
+	require.NoError(t, admin.AddNode(ctx, fc.addrs()[0], replica.srv.Addr(), fc.nodes[0].id))
+
+	assert.Error(t, admin.Failover(ctx, fc.addrs()[0], FailoverDefault), "masters can't fail over")
+	require.NoError(t, admin.Failover(ctx, replica.srv.Addr(), FailoverForce))
+	assert.Equal(t, TotalClusterSlots, fc.slotCount(replica))
+}
+
+func TestClusterAdminMiniredis(t *testing.T) {
+	ctx := context.Background()
+	m := miniredis.RunT(t)
+	m.RequireAuth("secret")
+
+	nodes, err := NewClusterAdmin("secret", nil, nil).Nodes(ctx, m.Addr())
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	assert.Equal(t, m.Addr(), nodes[0].Addr)
+	assert.Equal(t, TotalClusterSlots, nodes[0].SlotCount())
+	assert.NoError(t, NewClusterAdmin("secret", nil, nil).Check(ctx, m.Addr()))
+
+	assert.Error(t, NewClusterAdmin("wrong", nil, nil).Check(ctx, m.Addr()))
+}
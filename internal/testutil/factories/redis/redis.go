@@ -1,6 +1,7 @@
 package redis
 
 import (
+	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/api/redis/v1beta2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -19,6 +20,17 @@ func WithIgnoredKeys(keys []string) customFieldOption {
 	}
 }
 
+// ValkeyImage is the image of the Valkey variants.
+const ValkeyImage = "valkey/valkey:8.0.1"
+
+// WithValkey makes the resource run Valkey.
+func WithValkey() customFieldOption {
+	return func(rr *v1beta2.Redis) {
+		rr.Spec.KubernetesConfig.Engine = common.EngineValkey
+		rr.Spec.KubernetesConfig.Image = ValkeyImage
+	}
+}
+
 func New(name string, options ...customFieldOption) *v1beta2.Redis {
 	rr := &v1beta2.Redis{
 		TypeMeta: metav1.TypeMeta{
@@ -35,3 +47,8 @@ func New(name string, options ...customFieldOption) *v1beta2.Redis {
 	}
 	return rr
 }
+
+// NewValkey returns a resource like New, running Valkey.
+func NewValkey(name string, options ...customFieldOption) *v1beta2.Redis {
+	return New(name, append([]customFieldOption{WithValkey()}, options...)...)
+}
@@ -1,6 +1,7 @@
 package rediscluster
 
 import (
+	common "github.com/OT-CONTAINER-KIT/redis-operator/api/common/v1beta2"
 	"github.com/OT-CONTAINER-KIT/redis-operator/api/rediscluster/v1beta2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -25,6 +26,17 @@ func WithIgnoredKeys(keys []string) customFieldOption {
 	}
 }
 
+// ValkeyImage is the image of the Valkey variants.
+const ValkeyImage = "valkey/valkey:8.0.1"
+
+// WithValkey makes the resource run Valkey.
+func WithValkey() customFieldOption {
+	return func(rc *v1beta2.RedisCluster) {
+		rc.Spec.KubernetesConfig.Engine = common.EngineValkey
+		rc.Spec.KubernetesConfig.Image = ValkeyImage
+	}
+}
+
 func New(name string, options ...customFieldOption) *v1beta2.RedisCluster {
 	size := int32(3)
 	rc := &v1beta2.RedisCluster{
@@ -46,3 +58,8 @@ func New(name string, options ...customFieldOption) *v1beta2.RedisCluster {
 	}
 	return rc
 }
+
+// NewValkey returns a resource like New, running Valkey.
+func NewValkey(name string, options ...customFieldOption) *v1beta2.RedisCluster {
+	return New(name, append([]customFieldOption{WithValkey()}, options...)...)
+}
@@ -20,6 +20,17 @@ func WithIgnoredKeys(keys []string) customFieldOption {
 	}
 }
 
+// ValkeyImage is the image of the Valkey variants.
+const ValkeyImage = "valkey/valkey:8.0.1"
+
+// WithValkey makes the resource run Valkey.
+func WithValkey() customFieldOption {
+	return func(rr *v1beta2.RedisReplication) {
+		rr.Spec.KubernetesConfig.Engine = common.EngineValkey
+		rr.Spec.KubernetesConfig.Image = ValkeyImage
+	}
+}
+
 func New(name string, options ...customFieldOption) *v1beta2.RedisReplication {
 	size := int32(3)
 	rr := &v1beta2.RedisReplication{
@@ -41,3 +52,8 @@ func New(name string, options ...customFieldOption) *v1beta2.RedisReplication {
 	}
 	return rr
 }
+
+// NewValkey returns a resource like New, running Valkey.
+func NewValkey(name string, options ...customFieldOption) *v1beta2.RedisReplication {
+	return New(name, append([]customFieldOption{WithValkey()}, options...)...)
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentRedis traces the commands client sends to the server of pod, and returns client. pod may be
+// empty when the server isn't known to run in a pod.
+func InstrumentRedis(client *redis.Client, pod string) *redis.Client {
+	client.AddHook(NewRedisHook(client.Options().Addr, func() string { return pod }))
+	return client
+}
+
+// NewRedisHook returns a go-redis hook tracing each command sent to the server at addr in a leaf span
+// named after it, the pipelines in a single span. pod returns the pod of the server, it is only called
+// for the commands run in a traced reconcile, the others are not traced. The arguments of the commands
+// are never recorded, they can hold passwords.
+func NewRedisHook(addr string, pod func() string) redis.Hook {
+	return &redisHook{addr: addr, pod: pod}
+}
+
+type redisHook struct {
+	addr string
+	pod  func() string
+}
+
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return next(ctx, cmd)
+		}
+		operation := strings.ToUpper(cmd.FullName())
+		ctx, span := Start(ctx, operation, h.attributes(operation)...)
+		err := next(ctx, cmd)
+		End(span, redisError(err))
+		return err
+	}
+}
+
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return next(ctx, cmds)
+		}
+		ctx, span := Start(ctx, "PIPELINE", append(h.attributes("PIPELINE"), semconv.DBOperationBatchSize(len(cmds)))...)
+		err := next(ctx, cmds)
+		End(span, redisError(err))
+		return err
+	}
+}
+
+func (h *redisHook) attributes(operation string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNameRedis,
+		semconv.DBOperationName(operation),
+		semconv.ServerAddress(h.addr),
+	}
+	if pod := h.pod(); pod != "" {
+		attrs = append(attrs, semconv.K8SPodName(pod))
+	}
+	return attrs
+}
+
+// redisError returns err unless it is the reply of a missing key.
+func redisError(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
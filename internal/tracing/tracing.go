@@ -0,0 +1,117 @@
+// Package tracing traces the reconciles of the operator with OpenTelemetry: a span per reconcile, a
+// child span per phase of it, and a leaf span per command sent to a Redis server or pod exec.
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// instrumentationName is the name of the tracer of the operator.
+const instrumentationName = "github.com/OT-CONTAINER-KIT/redis-operator"
+
+// Setup exports the spans to the OTLP/HTTP traces endpoint, a URL like
+// http://otel-collector:4318/v1/traces, or to the one of the OTEL_EXPORTER_OTLP_* variables when empty.
+// The sampler and the resource attributes can be set with the OTEL_* variables of the SDK as well.
+// The returned func flushes the spans left and stops exporting them.
+func Setup(ctx context.Context, endpoint string) (shutdown func(context.Context) error, err error) {
+	var opts []otlptracehttp.Option
+	if endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("redis-operator")),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, errors.Join(err, exporter.Shutdown(ctx))
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name, a child of the span of ctx if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Reconciler returns r reconciling each request in a span named after kind, with the ID of the trace in
+// the logger of the reconcile.
+func Reconciler(kind string, r reconcile.Reconciler) reconcile.Reconciler {
+	return &reconciler{kind: kind, Reconciler: r}
+}
+
+type reconciler struct {
+	kind string
+	reconcile.Reconciler
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := Start(ctx, "Reconcile "+r.kind,
+		semconv.K8SNamespaceName(req.Namespace),
+		attribute.String("redis.operator.kind", r.kind),
+		attribute.String("redis.operator.name", req.Name),
+	)
+	if span.SpanContext().IsValid() {
+		ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("traceID", span.SpanContext().TraceID().String()))
+	}
+	result, err := r.Reconciler.Reconcile(ctx, req)
+	span.SetAttributes(attribute.Bool("redis.operator.requeue", result.Requeue || result.RequeueAfter > 0))
+	End(span, err)
+	return result, err
+}
+
+// Phases traces the phases of a reconcile run one after the other, each in a child span of the
+// reconcile ending when the next one starts.
+type Phases struct {
+	ctx  context.Context
+	span trace.Span
+}
+
+// NewPhases returns the Phases of the reconcile traced in ctx.
+func NewPhases(ctx context.Context) *Phases {
+	return &Phases{ctx: ctx}
+}
+
+// Start ends the current phase and starts phase, returning the context to run it in.
+func (p *Phases) Start(phase string) context.Context {
+	p.End()
+	ctx, span := Start(p.ctx, phase)
+	p.span = span
+	return ctx
+}
+
+// End ends the current phase.
+func (p *Phases) End() {
+	if p.span != nil {
+		p.span.End()
+		p.span = nil
+	}
+}
@@ -0,0 +1,160 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// useRecorder records the spans of the test in the returned recorder.
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestReconciler(t *testing.T) {
+	recorder := useRecorder(t)
+	server := miniredis.RunT(t)
+
+	r := Reconciler("RedisCluster", reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		client := InstrumentRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}), "redis-cluster-leader-0")
+		defer client.Close()
+		phases := NewPhases(ctx)
+		defer phases.End()
+
+		phaseCtx := phases.Start("resources")
+		require.NoError(t, client.Ping(phaseCtx).Err())
+		phaseCtx = phases.Start("status")
+		require.ErrorIs(t, client.Get(phaseCtx, "missing").Err(), redis.Nil)
+		_, err := client.Pipelined(phaseCtx, func(pipe redis.Pipeliner) error {
+			pipe.Set(phaseCtx, "a", "1", 0)
+			pipe.Set(phaseCtx, "b", "2", 0)
+			return nil
+		})
+		require.NoError(t, err)
+		return ctrl.Result{}, errors.New("boom")
+	}))
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "redis", Name: "redis-cluster"}})
+	require.EqualError(t, err, "boom")
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	// The HELLO of the connection is traced under the first command sent on it.
+	require.Len(t, spans, 7)
+	reconcileSpan := spans["Reconcile RedisCluster"]
+	assert.Equal(t, codes.Error, reconcileSpan.Status().Code)
+	assert.Equal(t, "redis-cluster", attributes(reconcileSpan)["redis.operator.name"].AsString())
+
+	assert.Equal(t, reconcileSpan.SpanContext().SpanID(), spans["resources"].Parent().SpanID())
+	assert.Equal(t, reconcileSpan.SpanContext().SpanID(), spans["status"].Parent().SpanID())
+	assert.Equal(t, spans["resources"].SpanContext().SpanID(), spans["PING"].Parent().SpanID())
+	assert.Equal(t, spans["status"].SpanContext().SpanID(), spans["GET"].Parent().SpanID())
+
+	ping := attributes(spans["PING"])
+	assert.Equal(t, "redis-cluster-leader-0", ping["k8s.pod.name"].AsString())
+	assert.Equal(t, server.Addr(), ping["server.address"].AsString())
+	// A missing key is not a failure, and the arguments are never recorded.
+	assert.Equal(t, codes.Unset, spans["GET"].Status().Code)
+	for _, kv := range spans["GET"].Attributes() {
+		assert.NotContains(t, kv.Value.Emit(), "missing")
+	}
+	assert.Equal(t, int64(2), attributes(spans["PIPELINE"])["db.operation.batch.size"].AsInt64())
+}
+
+func TestRedisHook_Untraced(t *testing.T) {
+	recorder := useRecorder(t)
+	server := miniredis.RunT(t)
+	client := InstrumentRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}), "")
+	defer client.Close()
+
+	// Commands run outside of a reconcile, e.g. by a webhook, are not traced.
+	require.NoError(t, client.Ping(context.Background()).Err())
+	assert.Empty(t, recorder.Ended())
+
+	ctx, span := Start(context.Background(), "parent")
+	require.NoError(t, client.Ping(ctx).Err())
+	span.End()
+	require.Len(t, recorder.Ended(), 2)
+	assert.NotContains(t, attributes(recorder.Ended()[0]), attribute.Key("k8s.pod.name"))
+}
+
+// TestSetup exports the spans to a stand-in of an OpenTelemetry collector.
+func TestSetup(t *testing.T) {
+	var mu sync.Mutex
+	var received []*collectortrace.ExportTraceServiceRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "/v1/traces", req.URL.Path)
+		export := &collectortrace.ExportTraceServiceRequest{}
+		assert.NoError(t, proto.Unmarshal(body, export))
+		mu.Lock()
+		received = append(received, export)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer collector.Close()
+
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	shutdown, err := Setup(context.Background(), collector.URL+"/v1/traces")
+	require.NoError(t, err)
+	ctx, span := Start(context.Background(), "Reconcile RedisReplication")
+	_, child := Start(ctx, "status")
+	child.End()
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	var names []string
+	var service string
+	for _, export := range received {
+		for _, resourceSpans := range export.ResourceSpans {
+			for _, kv := range resourceSpans.Resource.Attributes {
+				if kv.Key == "service.name" {
+					service = kv.Value.GetStringValue()
+				}
+			}
+			for _, scopeSpans := range resourceSpans.ScopeSpans {
+				for _, s := range scopeSpans.Spans {
+					names = append(names, s.Name)
+				}
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{"Reconcile RedisReplication", "status"}, names)
+	assert.Equal(t, "redis-operator", service)
+}
@@ -1,6 +1,10 @@
 package util
 
-import "strconv"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // redisDefaultMajorVersion is the major version assumed when the configured
 // value cannot be parsed. It matches the `v7` default of
@@ -40,3 +44,173 @@ func RedisMajorVersion(version string) int {
 func IsRedisVersionAtLeastV7(version string) bool {
 	return RedisMajorVersion(version) >= 7
 }
+
+// HasRedis7Features reports whether engine, at the given major version, has the features introduced in
+// Redis 7.0. Valkey forked from Redis 7.2, so every Valkey version has them whatever its numbering.
+func HasRedis7Features(engine, version string) bool {
+	return engine == EngineValkey || IsRedisVersionAtLeastV7(version)
+}
+
+// Redis engines, as set in KubernetesConfig.Engine and told apart by ParseImageVersion.
+const (
+	EngineRedis  = "redis"
+	EngineValkey = "valkey"
+)
+
+// ImageVersion is the engine and version of Redis run by a container image.
+type ImageVersion struct {
+	Engine string
+	Major  int
+	Minor  int
+	Patch  int
+}
+
+// ParseImageVersion returns the version run by image, from its tag, e.g. `redis:7.2.4-alpine`,
+// `quay.io/opstree/redis:v7.0.15` or `valkey/valkey:8.0.1`. Images whose repository name contains
+// `valkey` run Valkey, the others Redis. Tags that don't pin at least a minor version, such as `latest`
+// or `7`, can't be told apart from the versions they float over and aren't parsed.
+func ParseImageVersion(image string) (ImageVersion, bool) {
+	image, _, _ = strings.Cut(image, "@")
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ImageVersion{}, false
+	}
+	repository, tag := image[:i], image[i+1:]
+	engine := EngineRedis
+	if strings.Contains(repository[strings.LastIndex(repository, "/")+1:], EngineValkey) {
+		engine = EngineValkey
+	}
+	v, ok := parseVersion(strings.TrimPrefix(strings.TrimPrefix(tag, "v"), "V"))
+	if !ok {
+		return ImageVersion{}, false
+	}
+	v.Engine = engine
+	return v, true
+}
+
+// ParseVersion parses a version formatted by ImageVersion.String, e.g. `redis-7.2.4`.
+func ParseVersion(version string) (ImageVersion, bool) {
+	engine, number, found := strings.Cut(version, "-")
+	if !found || (engine != EngineRedis && engine != EngineValkey) {
+		return ImageVersion{}, false
+	}
+	v, ok := parseVersion(number)
+	if !ok {
+		return ImageVersion{}, false
+	}
+	v.Engine = engine
+	return v, true
+}
+
+// parseVersion parses `major.minor` or `major.minor.patch`, followed by an optional `-suffix`.
+func parseVersion(version string) (ImageVersion, bool) {
+	version, _, _ = strings.Cut(version, "-")
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return ImageVersion{}, false
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return ImageVersion{}, false
+		}
+		numbers[i] = n
+	}
+	return ImageVersion{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, true
+}
+
+// String formats v as `<engine>-<major>.<minor>.<patch>`, the form recorded in the status of resources.
+func (v ImageVersion) String() string {
+	return fmt.Sprintf("%s-%d.%d.%d", v.Engine, v.Major, v.Minor, v.Patch)
+}
+
+// atLeast returns whether v is major.minor or newer.
+func (v ImageVersion) atLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+// rdbValkeyFormat is the first RDB version of the formats specific to Valkey, which Redis can't load.
+const rdbValkeyFormat = 80
+
+// RDBVersion returns the version of the RDB format v writes its snapshots in.
+func (v ImageVersion) RDBVersion() int {
+	if v.Engine == EngineValkey {
+		if v.Major >= 9 {
+			return rdbValkeyFormat
+		}
+		return 11
+	}
+	switch {
+	case v.atLeast(7, 4):
+		return 12
+	case v.atLeast(7, 2):
+		return 11
+	case v.atLeast(7, 0):
+		return 10
+	case v.atLeast(5, 0):
+		return 9
+	default:
+		return 8
+	}
+}
+
+// CanLoadRDB returns whether v loads snapshots in the RDB format rdb. Redis loads the formats up to its
+// own. Valkey loads the formats Redis and Valkey shared up to Redis 7.2, and its own formats up to its
+// version; the formats of Redis 7.4 onwards aren't open source and Valkey refuses them.
+func (v ImageVersion) CanLoadRDB(rdb int) bool {
+	own := v.RDBVersion()
+	if v.Engine == EngineValkey {
+		return rdb <= 11 || (rdb >= rdbValkeyFormat && rdb <= own)
+	}
+	return rdb <= own && rdb < rdbValkeyFormat
+}
+
+// CheckVersionChange returns an error when pods moving from version from to version to couldn't load
+// the snapshots written by from, such as a downgrade to an older RDB format or from Redis 7.4 to Valkey.
+func CheckVersionChange(from, to ImageVersion) error {
+	if rdb := from.RDBVersion(); !to.CanLoadRDB(rdb) {
+		return fmt.Errorf("%s writes RDB version %d, which %s cannot load", from, rdb, to)
+	}
+	return nil
+}
+
+// DeprecatedDirectives returns a message for each directive of the Redis configuration config that
+// version to deprecates or ignores, in the order they appear.
+func DeprecatedDirectives(config string, to ImageVersion) []string {
+	var messages []string
+	seen := map[string]bool{}
+	for _, line := range strings.Split(config, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if message := deprecatedDirective(name, to); message != "" {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// deprecatedDirective returns why version to deprecates the directive name, empty when it doesn't.
+func deprecatedDirective(name string, to ImageVersion) string {
+	listpack := to.Engine == EngineValkey || to.Major >= 7
+	switch {
+	case name == "list-max-ziplist-entries" || name == "list-max-ziplist-value":
+		return fmt.Sprintf("%s is no longer supported, use list-max-listpack-size", name)
+	case listpack && strings.Contains(name, "-ziplist-"):
+		return fmt.Sprintf("%s is deprecated in %s, use %s", name, to, strings.Replace(name, "-ziplist-", "-listpack-", 1))
+	case name == "slaveof" || strings.HasPrefix(name, "slave-"):
+		return fmt.Sprintf("%s is deprecated, use %s", name, strings.Replace(name, "slave", "replica", 1))
+	case listpack && name == "lua-replicate-commands":
+		return fmt.Sprintf("%s is ignored by %s, scripts are always replicated by their effects", name, to)
+	case to.Engine == EngineValkey && to.Major >= 8 && name == "io-threads-do-reads":
+		return fmt.Sprintf("%s is ignored by %s, the I/O threads always handle reads", name, to)
+	}
+	return ""
+}
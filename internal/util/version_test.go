@@ -55,3 +55,119 @@ func TestIsRedisVersionAtLeastV7(t *testing.T) {
 		})
 	}
 }
+
+func TestParseImageVersion(t *testing.T) {
+	tests := []struct {
+		name  string
+		image string
+		want  ImageVersion
+		ok    bool
+	}{
+		{name: "plain tag", image: "redis:7.2.4", want: ImageVersion{Engine: EngineRedis, Major: 7, Minor: 2, Patch: 4}, ok: true},
+		{name: "leading v and registry", image: "quay.io/opstree/redis:v7.0.15", want: ImageVersion{Engine: EngineRedis, Major: 7, Minor: 0, Patch: 15}, ok: true},
+		{name: "suffix is ignored", image: "bitnami/redis:6.2.14-debian-12-r0", want: ImageVersion{Engine: EngineRedis, Major: 6, Minor: 2, Patch: 14}, ok: true},
+		{name: "registry with port", image: "registry:5000/valkey/valkey:8.0.1-alpine", want: ImageVersion{Engine: EngineValkey, Major: 8, Minor: 0, Patch: 1}, ok: true},
+		{name: "digest is ignored", image: "valkey/valkey:8.1@sha256:abcd", want: ImageVersion{Engine: EngineValkey, Major: 8, Minor: 1}, ok: true},
+		{name: "floating major", image: "redis:7"},
+		{name: "latest", image: "redis:latest"},
+		{name: "no tag", image: "redis"},
+		{name: "no tag with registry port", image: "registry:5000/redis"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseImageVersion(tt.image)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("ParseImageVersion(%q) = %v, %v, want %v, %v", tt.image, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	v := ImageVersion{Engine: EngineValkey, Major: 8, Minor: 0, Patch: 1}
+	if got, ok := ParseVersion(v.String()); !ok || got != v {
+		t.Errorf("ParseVersion(%q) = %v, %v, want %v, true", v.String(), got, ok, v)
+	}
+	for _, version := range []string{"", "7.2.4", "keydb-6.3.4", "redis-latest"} {
+		if _, ok := ParseVersion(version); ok {
+			t.Errorf("ParseVersion(%q) succeeded, want failure", version)
+		}
+	}
+}
+
+func TestCheckVersionChange(t *testing.T) {
+	redis := func(major, minor int) ImageVersion {
+		return ImageVersion{Engine: EngineRedis, Major: major, Minor: minor}
+	}
+	valkey := func(major, minor int) ImageVersion {
+		return ImageVersion{Engine: EngineValkey, Major: major, Minor: minor}
+	}
+	tests := []struct {
+		name    string
+		from    ImageVersion
+		to      ImageVersion
+		wantErr bool
+	}{
+		{name: "upgrade 6.2 to 7.2", from: redis(6, 2), to: redis(7, 2)},
+		{name: "patch change", from: redis(7, 2), to: redis(7, 2)},
+		{name: "downgrade within the same RDB format", from: redis(6, 2), to: redis(5, 0)},
+		{name: "downgrade 7.0 to 6.2", from: redis(7, 0), to: redis(6, 2), wantErr: true},
+		{name: "downgrade 7.4 to 7.2", from: redis(7, 4), to: redis(7, 2), wantErr: true},
+		{name: "redis 7.2 to valkey 8", from: redis(7, 2), to: valkey(8, 0)},
+		{name: "redis 7.4 to valkey 8", from: redis(7, 4), to: valkey(8, 0), wantErr: true},
+		{name: "valkey 8 back to redis 7.2", from: valkey(8, 0), to: redis(7, 2)},
+		{name: "valkey 8 to valkey 9", from: valkey(8, 1), to: valkey(9, 0)},
+		{name: "valkey 9 back to valkey 8", from: valkey(9, 0), to: valkey(8, 1), wantErr: true},
+		{name: "valkey 9 to redis 8", from: valkey(9, 0), to: redis(8, 0), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckVersionChange(tt.from, tt.to); (err != nil) != tt.wantErr {
+				t.Errorf("CheckVersionChange(%s, %s) = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeprecatedDirectives(t *testing.T) {
+	config := "# hash-max-ziplist-entries 128\n" +
+		"maxmemory 1gb\n" +
+		"hash-max-ziplist-entries 128\n" +
+		"SLAVE-READ-ONLY yes\n" +
+		"io-threads-do-reads yes\n" +
+		"hash-max-ziplist-entries 256\n"
+	tests := []struct {
+		name string
+		to   ImageVersion
+		want int
+	}{
+		{name: "redis 6 keeps ziplist", to: ImageVersion{Engine: EngineRedis, Major: 6, Minor: 2}, want: 1},
+		{name: "redis 7 renames ziplist", to: ImageVersion{Engine: EngineRedis, Major: 7, Minor: 2}, want: 2},
+		{name: "valkey 8 ignores io-threads-do-reads", to: ImageVersion{Engine: EngineValkey, Major: 8}, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeprecatedDirectives(config, tt.to); len(got) != tt.want {
+				t.Errorf("DeprecatedDirectives() = %q, want %d messages", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasRedis7Features(t *testing.T) {
+	tests := []struct {
+		engine  string
+		version string
+		want    bool
+	}{
+		{engine: EngineRedis, version: "v6", want: false},
+		{engine: EngineRedis, version: "v7", want: true},
+		{engine: EngineValkey, version: "v6", want: true},
+		{engine: EngineValkey, version: "v8", want: true},
+	}
+	for _, tt := range tests {
+		if got := HasRedis7Features(tt.engine, tt.version); got != tt.want {
+			t.Errorf("HasRedis7Features(%q, %q) = %v, want %v", tt.engine, tt.version, got, tt.want)
+		}
+	}
+}